@@ -15,6 +15,10 @@ func main() {
 		cmd.NewBuildCommand(build.Run),
 		cmd.NewValidateCommand(build.Validate),
 		cmd.NewVersionCommand(build.Version),
+		cmd.NewCacheCommand(build.CacheActions),
+		cmd.NewDefinitionCommand(build.DefinitionActions),
+		cmd.NewSchemaCommand(build.SchemaActions),
+		cmd.NewVerifyCommand(build.Verify),
 	}
 
 	if err := app.Run(os.Args); err != nil {
@@ -0,0 +1,270 @@
+package attestation
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
+)
+
+// SignatureSuffix is appended to the name of a signed file to form the name of its detached signature.
+const SignatureSuffix = ".sig"
+
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrDigestMismatch   = errors.New("digest mismatch")
+)
+
+// Outputs lists the files written while attesting a build.
+type Outputs struct {
+	Checksums  string
+	Provenance string
+	// Signatures is empty if no signing key is present.
+	Signatures []string
+}
+
+// Attest writes the checksum file and the provenance of the image built for the given context next to it.
+// The given companion files (e.g. the SBOM and the build report) must be located in the
+// same directory as the image and are covered as well.
+//
+// If a signing key is present in the image configuration directory, detached signatures
+// are created for the image, its companion files and the provenance.
+func Attest(ctx *image.Context, buildReport *report.Report, companions ...string) (*Outputs, error) {
+	builtImage := buildReport.FindImage(ctx.OutputImagePath())
+	if builtImage == nil {
+		return nil, fmt.Errorf("build report does not contain the built image")
+	}
+
+	imagePath := builtImage.Path
+	outputDir := filepath.Dir(imagePath)
+
+	subjects := []Subject{newSubject(filepath.Base(imagePath), builtImage.SHA256)}
+	for _, path := range companions {
+		digest, err := fileDigest(path)
+		if err != nil {
+			return nil, fmt.Errorf("calculating digest of '%s': %w", path, err)
+		}
+
+		subjects = append(subjects, newSubject(filepath.Base(path), digest))
+	}
+
+	checksumsPath := imagePath + ChecksumsSuffix
+	if err := writeChecksums(checksumsPath, subjects); err != nil {
+		return nil, err
+	}
+
+	provenancePath := imagePath + ProvenanceSuffix
+	if err := writeStatement(provenancePath, newStatement(ctx, buildReport, subjects)); err != nil {
+		return nil, err
+	}
+
+	outputs := &Outputs{
+		Checksums:  checksumsPath,
+		Provenance: provenancePath,
+	}
+
+	signer, err := loadSigningKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if signer == nil {
+		return outputs, nil
+	}
+
+	provenanceDigest, err := fileDigest(provenancePath)
+	if err != nil {
+		return nil, fmt.Errorf("calculating digest of provenance: %w", err)
+	}
+
+	for _, s := range append(subjects, newSubject(filepath.Base(provenancePath), provenanceDigest)) {
+		signaturePath := filepath.Join(outputDir, s.Name+SignatureSuffix)
+		if err = writeSignature(signer, s, signaturePath); err != nil {
+			return nil, fmt.Errorf("signing '%s': %w", s.Name, err)
+		}
+
+		outputs.Signatures = append(outputs.Signatures, signaturePath)
+	}
+
+	return outputs, nil
+}
+
+// SigningKeyPath returns the path of the private key used to sign the build outputs.
+func SigningKeyPath(ctx *image.Context) string {
+	return filepath.Join(ctx.ImageConfigDir, KeyDir, PrivateKeyFilename)
+}
+
+func loadSigningKey(ctx *image.Context) (crypto.Signer, error) {
+	keyPath := SigningKeyPath(ctx)
+
+	if _, err := os.Stat(keyPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("checking for signing key: %w", err)
+	}
+
+	signer, err := LoadPrivateKey(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading signing key: %w", err)
+	}
+
+	return signer, nil
+}
+
+func writeSignature(signer crypto.Signer, subject Subject, path string) error {
+	digest, err := hex.DecodeString(subject.sha256())
+	if err != nil {
+		return fmt.Errorf("decoding digest: %w", err)
+	}
+
+	signature, err := signDigest(signer, digest)
+	if err != nil {
+		return err
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(signature) + "\n"
+	if err = os.WriteFile(path, []byte(encoded), fileio.NonExecutablePerms); err != nil {
+		return fmt.Errorf("writing signature: %w", err)
+	}
+
+	return nil
+}
+
+// Verification describes the attestations of an image which have been successfully verified.
+type Verification struct {
+	// Subjects are the files whose digests match the checksum file and the provenance.
+	Subjects []Subject
+	// Signed indicates that the signatures of the subjects and the provenance have been verified.
+	Signed     bool
+	Provenance *Statement
+}
+
+// Verify checks the image at the given path against its checksum file and provenance. The files
+// listed in the checksum file are expected to be located next to the image.
+//
+// If a public key is provided, the detached signatures of all subjects and of the provenance
+// must be valid. If a definition file is provided, its digest must match the one recorded
+// in the provenance.
+func Verify(imagePath string, key crypto.PublicKey, definitionPath string) (*Verification, error) {
+	outputDir := filepath.Dir(imagePath)
+	imageName := filepath.Base(imagePath)
+
+	subjects, err := readChecksums(imagePath + ChecksumsSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	statement, err := readStatement(imagePath + ProvenanceSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = compareSubjects(subjects, statement.Subject); err != nil {
+		return nil, err
+	}
+
+	var imageListed bool
+	for _, s := range subjects {
+		imageListed = imageListed || s.Name == imageName
+
+		digest, err := fileDigest(filepath.Join(outputDir, s.Name))
+		if err != nil {
+			return nil, fmt.Errorf("calculating digest of '%s': %w", s.Name, err)
+		}
+
+		if digest != s.sha256() {
+			return nil, fmt.Errorf("verifying '%s': %w", s.Name, ErrDigestMismatch)
+		}
+	}
+
+	if !imageListed {
+		return nil, fmt.Errorf("image '%s' is not listed in the checksum file", imageName)
+	}
+
+	if definitionPath != "" {
+		digest, err := fileDigest(definitionPath)
+		if err != nil {
+			return nil, fmt.Errorf("calculating digest of definition file: %w", err)
+		}
+
+		if digest != statement.DefinitionDigest() {
+			return nil, fmt.Errorf("verifying definition file: %w", ErrDigestMismatch)
+		}
+	}
+
+	verification := &Verification{
+		Subjects:   subjects,
+		Provenance: statement,
+	}
+
+	if key == nil {
+		return verification, nil
+	}
+
+	provenanceName := imageName + ProvenanceSuffix
+	provenanceDigest, err := fileDigest(filepath.Join(outputDir, provenanceName))
+	if err != nil {
+		return nil, fmt.Errorf("calculating digest of provenance: %w", err)
+	}
+
+	for _, s := range append(subjects, newSubject(provenanceName, provenanceDigest)) {
+		if err = verifySignature(key, s, filepath.Join(outputDir, s.Name+SignatureSuffix)); err != nil {
+			return nil, fmt.Errorf("verifying signature of '%s': %w", s.Name, err)
+		}
+	}
+
+	verification.Signed = true
+	return verification, nil
+}
+
+func compareSubjects(checksums, provenance []Subject) error {
+	if len(checksums) != len(provenance) {
+		return fmt.Errorf("checksum file lists %d file(s) while provenance lists %d", len(checksums), len(provenance))
+	}
+
+	digests := map[string]string{}
+	for _, s := range provenance {
+		digests[s.Name] = s.sha256()
+	}
+
+	for _, s := range checksums {
+		digest, ok := digests[s.Name]
+		if !ok {
+			return fmt.Errorf("'%s' is not listed in the provenance", s.Name)
+		}
+
+		if digest != s.sha256() {
+			return fmt.Errorf("provenance of '%s': %w", s.Name, ErrDigestMismatch)
+		}
+	}
+
+	return nil
+}
+
+func verifySignature(key crypto.PublicKey, subject Subject, path string) error {
+	encoded, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading signature: %w", err)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+
+	digest, err := hex.DecodeString(subject.sha256())
+	if err != nil {
+		return fmt.Errorf("decoding digest: %w", err)
+	}
+
+	return verifyDigest(key, digest, signature)
+}
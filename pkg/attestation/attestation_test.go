@@ -0,0 +1,277 @@
+package attestation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
+)
+
+const (
+	testContents       = "some-contents"
+	testContentsSHA256 = "6e32ea34db1b3755d7dec972eb72c705338f0dd8e0be881d966963438fb2e800"
+)
+
+func writeTestFile(t *testing.T, path string) string {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(testContents), 0o600))
+	return path
+}
+
+func writePrivateKey(t *testing.T, configDir string, key crypto.Signer) crypto.PublicKey {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	keyPath := filepath.Join(configDir, KeyDir, PrivateKeyFilename)
+	require.NoError(t, os.MkdirAll(filepath.Dir(keyPath), 0o700))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	return key.Public()
+}
+
+func setupBuild(t *testing.T) (*image.Context, *report.Report, []string) {
+	configDir := t.TempDir()
+
+	ctx := &image.Context{
+		ImageConfigDir: configDir,
+		BuildDir:       filepath.Join(configDir, "_build", "build-Oct17_10-00-00"),
+		ImageDefinition: &image.Definition{
+			Image: image.Image{
+				ImageType:       image.TypeISO,
+				Arch:            image.ArchTypeX86,
+				BaseImage:       "base.iso",
+				OutputImageName: "eib-image.iso",
+			},
+		},
+	}
+
+	r := report.New("v1.2.0")
+	require.NoError(t, r.SetDefinition(writeTestFile(t, filepath.Join(configDir, "definition.yaml")), "1.1",
+		[]string{writeTestFile(t, filepath.Join(configDir, "sites", "site-a.yaml"))}))
+	require.NoError(t, r.AddBaseImage(writeTestFile(t, filepath.Join(configDir, "base-images", "base.iso"))))
+	r.RecordDownload("https://get.rke2.io", writeTestFile(t, filepath.Join(configDir, "_build", "rke2_installer.sh")), false)
+	require.NoError(t, r.AddImage(writeTestFile(t, filepath.Join(configDir, "eib-image.iso")), image.TypeISO, string(image.ArchTypeX86)))
+	r.Finish(nil)
+
+	companions := []string{
+		writeTestFile(t, filepath.Join(configDir, "eib-image.iso.spdx.json")),
+		writeTestFile(t, filepath.Join(configDir, report.Filename)),
+	}
+
+	return ctx, r, companions
+}
+
+func TestAttest_Unsigned(t *testing.T) {
+	ctx, r, companions := setupBuild(t)
+
+	outputs, err := Attest(ctx, r, companions...)
+	require.NoError(t, err)
+
+	imagePath := filepath.Join(ctx.ImageConfigDir, "eib-image.iso")
+	assert.Equal(t, imagePath+ChecksumsSuffix, outputs.Checksums)
+	assert.Equal(t, imagePath+ProvenanceSuffix, outputs.Provenance)
+	assert.Empty(t, outputs.Signatures)
+
+	checksums, err := os.ReadFile(outputs.Checksums)
+	require.NoError(t, err)
+	assert.Equal(t, testContentsSHA256+"  eib-image.iso\n"+
+		testContentsSHA256+"  eib-image.iso.spdx.json\n"+
+		testContentsSHA256+"  build-report.json\n", string(checksums))
+
+	statement, err := readStatement(outputs.Provenance)
+	require.NoError(t, err)
+
+	assert.Len(t, statement.Subject, 3)
+	assert.Equal(t, "v1.2.0", statement.EIBVersion())
+	assert.Equal(t, testContentsSHA256, statement.DefinitionDigest())
+
+	buildDefinition := statement.Predicate.BuildDefinition
+	assert.Equal(t, BuildType, buildDefinition.BuildType)
+	assert.Equal(t, "definition.yaml", buildDefinition.ExternalParameters.Definition.Name)
+	assert.Equal(t, "1.1", buildDefinition.ExternalParameters.SchemaVersion)
+	assert.Equal(t, "iso", buildDefinition.ExternalParameters.ImageType)
+	assert.Equal(t, "x86_64", buildDefinition.ExternalParameters.Arch)
+	assert.Equal(t, []ResourceDescriptor{
+		{Name: "sites/site-a.yaml", Digest: map[string]string{"sha256": testContentsSHA256}},
+		{Name: "base.iso", Digest: map[string]string{"sha256": testContentsSHA256}},
+		{Name: "rke2_installer.sh", URI: "https://get.rke2.io", Digest: map[string]string{"sha256": testContentsSHA256}},
+	}, buildDefinition.ResolvedDependencies)
+	assert.Equal(t, "build-Oct17_10-00-00", statement.Predicate.RunDetails.Metadata.InvocationID)
+
+	verification, err := Verify(imagePath, nil, "")
+	require.NoError(t, err)
+	assert.False(t, verification.Signed)
+	assert.Len(t, verification.Subjects, 3)
+}
+
+func TestAttest_Signed(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	keys := map[string]crypto.Signer{
+		"ECDSA":   ecdsaKey,
+		"Ed25519": ed25519Key,
+		"RSA":     rsaKey,
+	}
+
+	for name, key := range keys {
+		t.Run(name, func(t *testing.T) {
+			ctx, r, companions := setupBuild(t)
+			publicKey := writePrivateKey(t, ctx.ImageConfigDir, key)
+
+			outputs, err := Attest(ctx, r, companions...)
+			require.NoError(t, err)
+			assert.Len(t, outputs.Signatures, 4)
+
+			imagePath := filepath.Join(ctx.ImageConfigDir, "eib-image.iso")
+			definitionPath := filepath.Join(ctx.ImageConfigDir, "definition.yaml")
+
+			verification, err := Verify(imagePath, publicKey, definitionPath)
+			require.NoError(t, err)
+			assert.True(t, verification.Signed)
+		})
+	}
+}
+
+func TestVerify_Failures(t *testing.T) {
+	otherKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		tamper        func(t *testing.T, configDir string)
+		key           func(publicKey crypto.PublicKey) crypto.PublicKey
+		definition    string
+		expectedError string
+	}{
+		"Modified image": {
+			tamper: func(t *testing.T, configDir string) {
+				require.NoError(t, os.WriteFile(filepath.Join(configDir, "eib-image.iso"), []byte("modified"), 0o600))
+			},
+			expectedError: "verifying 'eib-image.iso': digest mismatch",
+		},
+		"Modified checksums": {
+			tamper: func(t *testing.T, configDir string) {
+				path := filepath.Join(configDir, "eib-image.iso"+ChecksumsSuffix)
+				require.NoError(t, os.WriteFile(path, []byte(testContentsSHA256+"  eib-image.iso\n"), 0o600))
+			},
+			expectedError: "checksum file lists 1 file(s) while provenance lists 3",
+		},
+		"Traversing checksums": {
+			tamper: func(t *testing.T, configDir string) {
+				path := filepath.Join(configDir, "eib-image.iso"+ChecksumsSuffix)
+				require.NoError(t, os.WriteFile(path, []byte(testContentsSHA256+"  ../../etc/shadow\n"), 0o600))
+			},
+			expectedError: "invalid file name in checksum file: ../../etc/shadow",
+		},
+		"Absolute path in checksums": {
+			tamper: func(t *testing.T, configDir string) {
+				path := filepath.Join(configDir, "eib-image.iso"+ChecksumsSuffix)
+				require.NoError(t, os.WriteFile(path, []byte(testContentsSHA256+"  */etc/shadow\n"), 0o600))
+			},
+			expectedError: "invalid file name in checksum file: /etc/shadow",
+		},
+		"Modified provenance": {
+			tamper: func(t *testing.T, configDir string) {
+				path := filepath.Join(configDir, "eib-image.iso"+ProvenanceSuffix)
+				statement, err := readStatement(path)
+				require.NoError(t, err)
+				statement.Predicate.RunDetails.Builder.Version["eib"] = "v0.0.1"
+				require.NoError(t, writeStatement(path, statement))
+			},
+			expectedError: "verifying signature of 'eib-image.iso.provenance.json': invalid signature",
+		},
+		"Missing signature": {
+			tamper: func(t *testing.T, configDir string) {
+				require.NoError(t, os.Remove(filepath.Join(configDir, report.Filename+SignatureSuffix)))
+			},
+			expectedError: "verifying signature of 'build-report.json': reading signature",
+		},
+		"Different key": {
+			key: func(crypto.PublicKey) crypto.PublicKey {
+				return otherKey
+			},
+			expectedError: "verifying signature of 'eib-image.iso': invalid signature",
+		},
+		"Different definition": {
+			definition:    "definition-other.yaml",
+			expectedError: "verifying definition file: digest mismatch",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, r, companions := setupBuild(t)
+
+			_, key, err := ed25519.GenerateKey(rand.Reader)
+			require.NoError(t, err)
+			publicKey := writePrivateKey(t, ctx.ImageConfigDir, key)
+
+			_, err = Attest(ctx, r, companions...)
+			require.NoError(t, err)
+
+			if test.tamper != nil {
+				test.tamper(t, ctx.ImageConfigDir)
+			}
+			if test.key != nil {
+				publicKey = test.key(publicKey)
+			}
+
+			var definitionPath string
+			if test.definition != "" {
+				definitionPath = filepath.Join(ctx.ImageConfigDir, test.definition)
+				require.NoError(t, os.WriteFile(definitionPath, []byte("other"), 0o600))
+			}
+
+			_, err = Verify(filepath.Join(ctx.ImageConfigDir, "eib-image.iso"), publicKey, definitionPath)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.expectedError)
+		})
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	sec1, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	privatePath := filepath.Join(dir, "ec.key")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: sec1}), 0o600))
+
+	pkix, err := x509.MarshalPKIXPublicKey(key.Public())
+	require.NoError(t, err)
+	publicPath := filepath.Join(dir, "ec.pub")
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}), 0o600))
+
+	signer, err := LoadPrivateKey(privatePath)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(signer))
+
+	publicKey, err := LoadPublicKey(publicPath)
+	require.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(publicKey))
+
+	_, err = LoadPrivateKey(publicPath)
+	assert.EqualError(t, err, "unsupported PEM block type 'PUBLIC KEY'")
+
+	_, err = LoadPublicKey(writeTestFile(t, filepath.Join(dir, "invalid.pub")))
+	assert.ErrorContains(t, err, "no PEM data found")
+}
@@ -0,0 +1,104 @@
+package attestation
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+)
+
+// ChecksumsSuffix is appended to the name of the output image to form the name of its checksum file.
+const ChecksumsSuffix = ".sha256"
+
+// Subject is a file covered by the attestations of a build, identified by its name relative
+// to the directory of the output image.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+func newSubject(name, sha256Digest string) Subject {
+	return Subject{Name: name, Digest: map[string]string{"sha256": sha256Digest}}
+}
+
+func (s Subject) sha256() string {
+	return s.Digest["sha256"]
+}
+
+// writeChecksums stores the digests of the subjects in the format used by sha256sum,
+// so that they can also be checked with 'sha256sum --check'.
+func writeChecksums(path string, subjects []Subject) error {
+	var sb strings.Builder
+	for _, s := range subjects {
+		sb.WriteString(fmt.Sprintf("%s  %s\n", s.sha256(), s.Name))
+	}
+
+	if err := os.WriteFile(path, []byte(sb.String()), fileio.NonExecutablePerms); err != nil {
+		return fmt.Errorf("writing checksum file: %w", err)
+	}
+
+	return nil
+}
+
+func readChecksums(path string) ([]Subject, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening checksum file: %w", err)
+	}
+	defer file.Close()
+
+	var subjects []Subject
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		digest, name, found := strings.Cut(line, "  ")
+		if !found || len(digest) != sha256.Size*2 || name == "" {
+			return nil, fmt.Errorf("invalid checksum line: %s", line)
+		}
+
+		name = strings.TrimPrefix(name, "*")
+
+		// Subjects are located next to the image, so their names must not point elsewhere
+		if !filepath.IsLocal(name) || strings.ContainsAny(name, `/\`) {
+			return nil, fmt.Errorf("invalid file name in checksum file: %s", name)
+		}
+
+		subjects = append(subjects, newSubject(name, digest))
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading checksum file: %w", err)
+	}
+
+	if len(subjects) == 0 {
+		return nil, fmt.Errorf("checksum file '%s' is empty", path)
+	}
+
+	return subjects, nil
+}
+
+func fileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("reading file: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
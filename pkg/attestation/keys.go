@@ -0,0 +1,130 @@
+package attestation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+const (
+	// KeyDir is the directory under the image configuration directory holding the signing key.
+	KeyDir = "signing"
+	// PrivateKeyFilename is the name of the PEM encoded private key used to sign the build outputs.
+	PrivateKeyFilename = "signing.key"
+)
+
+// LoadPrivateKey reads a PEM encoded, unencrypted Ed25519, ECDSA or RSA private key.
+func LoadPrivateKey(path string) (crypto.Signer, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var key any
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type '%s'", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing private key: %w", err)
+	}
+
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ecdsa.PrivateKey:
+		return k, nil
+	case *rsa.PrivateKey:
+		return k, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// LoadPublicKey reads a PEM encoded Ed25519, ECDSA or RSA public key.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("unsupported PEM block type '%s'", block.Type)
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing public key: %w", err)
+	}
+
+	switch key.(type) {
+	case ed25519.PublicKey, *ecdsa.PublicKey, *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading key file: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in '%s'", path)
+	}
+
+	return block, nil
+}
+
+// signDigest signs the given SHA-256 digest. Ed25519 keys sign the digest itself as the message,
+// which avoids reading potentially large files into memory.
+func signDigest(signer crypto.Signer, digest []byte) ([]byte, error) {
+	opts := crypto.SignerOpts(crypto.SHA256)
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		opts = crypto.Hash(0)
+	}
+
+	signature, err := signer.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return nil, fmt.Errorf("signing digest: %w", err)
+	}
+
+	return signature, nil
+}
+
+func verifyDigest(key crypto.PublicKey, digest, signature []byte) error {
+	var valid bool
+
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(k, digest, signature)
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(k, digest, signature)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, signature) == nil
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+
+	if !valid {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
@@ -0,0 +1,178 @@
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
+)
+
+// ProvenanceSuffix is appended to the name of the output image to form the name of its provenance document.
+const ProvenanceSuffix = ".provenance.json"
+
+const (
+	StatementType           = "https://in-toto.io/Statement/v1"
+	ProvenancePredicateType = "https://slsa.dev/provenance/v1"
+	BuildType               = "https://github.com/suse-edge/edge-image-builder/build/v1"
+	BuilderID               = "https://github.com/suse-edge/edge-image-builder"
+)
+
+// Statement is an in-toto attestation statement carrying a SLSA provenance predicate.
+type Statement struct {
+	Type          string     `json:"_type"`
+	Subject       []Subject  `json:"subject"`
+	PredicateType string     `json:"predicateType"`
+	Predicate     Provenance `json:"predicate"`
+}
+
+type Provenance struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   ExternalParameters   `json:"externalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies"`
+}
+
+type ExternalParameters struct {
+	Definition    ResourceDescriptor `json:"definition"`
+	SchemaVersion string             `json:"schemaVersion"`
+	ImageType     string             `json:"imageType"`
+	Arch          string             `json:"arch"`
+}
+
+type ResourceDescriptor struct {
+	Name   string            `json:"name,omitempty"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+type RunDetails struct {
+	Builder  Builder       `json:"builder"`
+	Metadata BuildMetadata `json:"metadata"`
+}
+
+type Builder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version"`
+}
+
+type BuildMetadata struct {
+	InvocationID string    `json:"invocationId"`
+	StartedOn    time.Time `json:"startedOn"`
+	FinishedOn   time.Time `json:"finishedOn"`
+}
+
+// EIBVersion returns the version of Edge Image Builder which produced the subjects.
+func (s *Statement) EIBVersion() string {
+	return s.Predicate.RunDetails.Builder.Version["eib"]
+}
+
+// DefinitionDigest returns the SHA-256 digest of the image definition file the subjects were built from.
+func (s *Statement) DefinitionDigest() string {
+	return s.Predicate.BuildDefinition.ExternalParameters.Definition.Digest["sha256"]
+}
+
+func newStatement(ctx *image.Context, buildReport *report.Report, subjects []Subject) *Statement {
+	definition := ResourceDescriptor{Name: filepath.Base(buildReport.DefinitionFile)}
+	if buildReport.DefinitionDigest != "" {
+		definition.Digest = map[string]string{"sha256": buildReport.DefinitionDigest}
+	}
+
+	dependencies := []ResourceDescriptor{}
+
+	// Includes and overlays are named relative to the definition, as they are referenced by it
+	for _, source := range buildReport.DefinitionSources {
+		name, err := filepath.Rel(filepath.Dir(buildReport.DefinitionFile), source.Path)
+		if err != nil {
+			name = filepath.Base(source.Path)
+		}
+
+		dependencies = append(dependencies, ResourceDescriptor{
+			Name:   name,
+			Digest: map[string]string{"sha256": source.SHA256},
+		})
+	}
+
+	if baseImage := buildReport.FindBaseImage(ctx.BaseImagePath()); baseImage != nil {
+		dependencies = append(dependencies, ResourceDescriptor{
+			Name:   ctx.ImageDefinition.Image.BaseImage,
+			Digest: map[string]string{"sha256": baseImage.SHA256},
+		})
+	}
+
+	for _, artefact := range buildReport.Artefacts {
+		dependencies = append(dependencies, ResourceDescriptor{
+			Name:   filepath.Base(artefact.Path),
+			URI:    artefact.URL,
+			Digest: map[string]string{"sha256": artefact.SHA256},
+		})
+	}
+
+	return &Statement{
+		Type:          StatementType,
+		Subject:       subjects,
+		PredicateType: ProvenancePredicateType,
+		Predicate: Provenance{
+			BuildDefinition: BuildDefinition{
+				BuildType: BuildType,
+				ExternalParameters: ExternalParameters{
+					Definition:    definition,
+					SchemaVersion: buildReport.SchemaVersion,
+					ImageType:     ctx.ImageDefinition.Image.ImageType,
+					Arch:          string(ctx.ImageDefinition.Image.Arch),
+				},
+				ResolvedDependencies: dependencies,
+			},
+			RunDetails: RunDetails{
+				Builder: Builder{
+					ID:      BuilderID,
+					Version: map[string]string{"eib": buildReport.EIBVersion},
+				},
+				Metadata: BuildMetadata{
+					InvocationID: filepath.Base(ctx.BuildDir),
+					StartedOn:    buildReport.Started,
+					FinishedOn:   buildReport.Finished,
+				},
+			},
+		},
+	}
+}
+
+func writeStatement(path string, statement *Statement) error {
+	data, err := json.MarshalIndent(statement, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling provenance: %w", err)
+	}
+
+	if err = os.WriteFile(path, append(data, '\n'), fileio.NonExecutablePerms); err != nil {
+		return fmt.Errorf("writing provenance: %w", err)
+	}
+
+	return nil
+}
+
+func readStatement(path string) (*Statement, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading provenance: %w", err)
+	}
+
+	var statement Statement
+	if err = json.Unmarshal(data, &statement); err != nil {
+		return nil, fmt.Errorf("parsing provenance: %w", err)
+	}
+
+	if statement.Type != StatementType || statement.PredicateType != ProvenancePredicateType {
+		return nil, fmt.Errorf("unsupported provenance type '%s' with predicate '%s'", statement.Type, statement.PredicateType)
+	}
+
+	return &statement, nil
+}
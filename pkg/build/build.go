@@ -1,12 +1,16 @@
 package build
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/suse-edge/edge-image-builder/pkg/image"
 	"github.com/suse-edge/edge-image-builder/pkg/log"
+	"go.uber.org/zap"
 )
 
 type imageConfigurator interface {
@@ -26,13 +30,77 @@ func NewBuilder(ctx *image.Context, imageConfigurator imageConfigurator) *Builde
 }
 
 func (b *Builder) Build() error {
-	log.Audit("Generating image customization components...")
+	if err := b.Configure(); err != nil {
+		return err
+	}
+
+	return b.BuildImage()
+}
+
+// Configure generates the customization components. These are shared by all images of the same architecture.
+func (b *Builder) Configure() error {
+	return b.runStage("customization components", func() error {
+		log.Audit("Generating image customization components...")
+
+		if err := b.imageConfigurator.Configure(b.context); err != nil {
+			log.Audit("Error configuring customization components.")
+			return fmt.Errorf("configuring image: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// BuildImage builds the image described by the context from the previously generated customization components.
+// A partially written image is removed if the build fails or is cancelled.
+func (b *Builder) BuildImage() error {
+	stage := fmt.Sprintf("image '%s'", b.context.ImageDefinition.Image.OutputImageName)
+
+	return b.runStage(stage, func() error {
+		if err := b.buildImage(); err != nil {
+			if removeErr := b.deleteExistingOutputImage(); removeErr != nil {
+				zap.S().Warnf("Removing incomplete output image failed: %s", removeErr)
+			}
+
+			return err
+		}
+
+		return nil
+	})
+}
 
-	if err := b.imageConfigurator.Configure(b.context); err != nil {
-		log.Audit("Error configuring customization components.")
-		return fmt.Errorf("configuring image: %w", err)
+// runStage runs the given build stage, cancelling it once it exceeds the stage timeout of the context.
+func (b *Builder) runStage(stage string, run func() error) error {
+	parent := b.context
+	defer func() {
+		b.context = parent
+	}()
+
+	if err := parent.Context().Err(); err != nil {
+		return fmt.Errorf("building %s: %w", stage, context.Cause(parent.Context()))
+	}
+
+	if parent.StageTimeout > 0 {
+		timeoutErr := fmt.Errorf("building %s exceeded the stage timeout of %s: %w", stage, parent.StageTimeout, context.DeadlineExceeded)
+
+		ctx, cancel := context.WithTimeoutCause(parent.Context(), parent.StageTimeout, timeoutErr)
+		defer cancel()
+
+		b.context = parent.WithContext(ctx)
+	}
+
+	err := run()
+	if err != nil && b.context.Context().Err() != nil {
+		// The errors of interrupted operations do not necessarily tell why they were interrupted
+		if cause := context.Cause(b.context.Context()); !errors.Is(err, cause) {
+			return fmt.Errorf("%w: %w", cause, err)
+		}
 	}
 
+	return err
+}
+
+func (b *Builder) buildImage() error {
 	switch b.context.ImageDefinition.Image.ImageType {
 	case image.TypeISO:
 		log.Audit("Building ISO image...")
@@ -46,9 +114,21 @@ func (b *Builder) Build() error {
 			log.Audit("Error building RAW image.")
 			return err
 		}
+	case image.TypeQCOW2, image.TypeVMDK, image.TypeVHDX:
+		imageType := strings.ToUpper(b.context.ImageDefinition.Image.ImageType)
+		log.Auditf("Building %s image...", imageType)
+		if err := b.buildConvertedImage(); err != nil {
+			log.Auditf("Error building %s image.", imageType)
+			return err
+		}
 	default:
-		return fmt.Errorf("invalid imageType value specified, must be either \"%s\" or \"%s\"",
-			image.TypeISO, image.TypeRAW)
+		return fmt.Errorf("invalid imageType value specified, must be one of: %s",
+			strings.Join([]string{image.TypeISO, image.TypeRAW, image.TypeQCOW2, image.TypeVMDK, image.TypeVHDX}, ", "))
+	}
+
+	imageDefinition := b.context.ImageDefinition
+	if err := b.context.Recorder().AddImage(b.generateOutputImageFilename(), imageDefinition.Image.ImageType, string(imageDefinition.Image.Arch)); err != nil {
+		return fmt.Errorf("recording image in build report: %w", err)
 	}
 
 	log.Auditf("Build complete, the image can be found at: %s",
@@ -1,13 +1,17 @@
 package build
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/process"
 )
 
 func TestGenerateBuildDirFilename(t *testing.T) {
@@ -88,3 +92,65 @@ func TestDeleteExistingImage(t *testing.T) {
 	require.Error(t, err)
 	require.True(t, os.IsNotExist(err))
 }
+
+func TestRunStageTimeout(t *testing.T) {
+	builder := Builder{
+		context: &image.Context{
+			StageTimeout: 100 * time.Millisecond,
+		},
+	}
+
+	start := time.Now()
+	err := builder.runStage("image 'eib.iso'", func() error {
+		return process.Command(builder.context.Context(), "sleep", "10").Run()
+	})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "building image 'eib.iso' exceeded the stage timeout of 100ms")
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// The timeout only applies to the stage
+	require.NoError(t, builder.context.Context().Err())
+}
+
+func TestRunStageCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errors.New("build interrupted"))
+
+	builder := Builder{
+		context: (&image.Context{}).WithContext(ctx),
+	}
+
+	var run bool
+	err := builder.runStage("customization components", func() error {
+		run = true
+		return nil
+	})
+
+	require.Error(t, err)
+	assert.EqualError(t, err, "building customization components: build interrupted")
+	assert.False(t, run)
+}
+
+func TestBuildImageRemovesIncompleteImage(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	outputImage := filepath.Join(tmpDir, "eib.img")
+	require.NoError(t, os.WriteFile(outputImage, []byte("partial"), 0o600))
+
+	builder := Builder{
+		context: &image.Context{
+			ImageConfigDir: tmpDir,
+			ImageDefinition: &image.Definition{
+				Image: image.Image{
+					ImageType:       "invalid",
+					OutputImageName: "eib.img",
+				},
+			},
+		},
+	}
+
+	require.Error(t, builder.BuildImage())
+	assert.NoFileExists(t, outputImage)
+}
@@ -0,0 +1,88 @@
+package build
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/process"
+	"go.uber.org/zap"
+)
+
+const (
+	qemuImgExec         = "/usr/bin/qemu-img"
+	intermediateRawName = "intermediate-image.raw"
+	convertLogFile      = "convert-image.log"
+)
+
+// buildConvertedImage builds a RAW image in the build directory and converts it
+// to the virtual disk format requested in the image definition.
+func (b *Builder) buildConvertedImage() error {
+	rawImagePath := b.generateBuildDirFilename(intermediateRawName)
+
+	if err := b.createRawImage(rawImagePath); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := os.Remove(rawImagePath); err != nil {
+			zap.S().Warnf("Failed to remove intermediate raw image: %s", err)
+		}
+	}()
+
+	return b.convertImage(rawImagePath)
+}
+
+func (b *Builder) convertImage(rawImagePath string) error {
+	logFilename := filepath.Join(b.context.BuildDir, convertLogFile)
+	logFile, err := os.Create(logFilename)
+	if err != nil {
+		return fmt.Errorf("creating log file: %w", err)
+	}
+
+	defer func() {
+		if err = logFile.Close(); err != nil {
+			zap.S().Warnf("Failed to close image conversion log file properly: %s", err)
+		}
+	}()
+
+	cmd := b.createConvertCommand(rawImagePath, logFile)
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("converting the raw image to %s: %w", b.context.ImageDefinition.Image.ImageType, err)
+	}
+
+	return nil
+}
+
+func (b *Builder) createConvertCommand(rawImagePath string, writer io.Writer) *exec.Cmd {
+	definition := b.context.ImageDefinition
+
+	args := []string{"convert", "-f", "raw", "-O", definition.Image.ImageType}
+	args = append(args, conversionOptions(definition.Image.ImageType, definition.OperatingSystem.RawConfiguration.Compress)...)
+	args = append(args, rawImagePath, b.generateOutputImageFilename())
+
+	cmd := process.Command(b.context.Context(), qemuImgExec, args...)
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	return cmd
+}
+
+func conversionOptions(imageType string, compress bool) []string {
+	if !compress {
+		return nil
+	}
+
+	switch imageType {
+	case image.TypeQCOW2:
+		return []string{"-c"}
+	case image.TypeVMDK:
+		// Stream optimized VMDK images are compressed and are the format expected by OVF/OVA based deployments
+		return []string{"-o", "subformat=streamOptimized"}
+	default:
+		return nil
+	}
+}
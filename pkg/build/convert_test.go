@@ -0,0 +1,72 @@
+package build
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+)
+
+func TestCreateConvertCommand(t *testing.T) {
+	tests := map[string]struct {
+		imageType    string
+		compress     bool
+		expectedArgs []string
+	}{
+		"qcow2": {
+			imageType:    image.TypeQCOW2,
+			expectedArgs: []string{"convert", "-f", "raw", "-O", "qcow2"},
+		},
+		"compressed qcow2": {
+			imageType:    image.TypeQCOW2,
+			compress:     true,
+			expectedArgs: []string{"convert", "-f", "raw", "-O", "qcow2", "-c"},
+		},
+		"vmdk": {
+			imageType:    image.TypeVMDK,
+			expectedArgs: []string{"convert", "-f", "raw", "-O", "vmdk"},
+		},
+		"compressed vmdk": {
+			imageType:    image.TypeVMDK,
+			compress:     true,
+			expectedArgs: []string{"convert", "-f", "raw", "-O", "vmdk", "-o", "subformat=streamOptimized"},
+		},
+		"vhdx": {
+			imageType:    image.TypeVHDX,
+			expectedArgs: []string{"convert", "-f", "raw", "-O", "vhdx"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			builder := Builder{
+				context: &image.Context{
+					ImageConfigDir: "config-dir",
+					ImageDefinition: &image.Definition{
+						Image: image.Image{
+							ImageType:       test.imageType,
+							OutputImageName: "build-image",
+						},
+						OperatingSystem: image.OperatingSystem{
+							RawConfiguration: image.RawConfiguration{
+								Compress: test.compress,
+							},
+						},
+					},
+				},
+			}
+
+			var writer bytes.Buffer
+			cmd := builder.createConvertCommand("intermediate.raw", &writer)
+
+			expectedArgs := append([]string{qemuImgExec}, test.expectedArgs...)
+			expectedArgs = append(expectedArgs, "intermediate.raw", builder.generateOutputImageFilename())
+
+			assert.Equal(t, qemuImgExec, cmd.Path)
+			assert.Equal(t, expectedArgs, cmd.Args)
+			assert.Equal(t, &writer, cmd.Stdout)
+			assert.Equal(t, &writer, cmd.Stderr)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/process"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 	"go.uber.org/zap"
 )
@@ -141,7 +142,7 @@ func (b *Builder) createIsoCommand(logFilename, scriptName string) (*exec.Cmd, *
 	}
 
 	scriptFilename := filepath.Join(b.context.BuildDir, scriptName)
-	cmd := exec.Command(scriptFilename)
+	cmd := process.Command(b.context.Context(), scriptFilename)
 	cmd.Stdout = logFile
 	cmd.Stderr = logFile
 
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/process"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 	"go.uber.org/zap"
 )
@@ -24,6 +25,11 @@ const (
 var modifyRawImageTemplate string
 
 func (b *Builder) buildRawImage() error {
+	return b.createRawImage(b.generateOutputImageFilename())
+}
+
+// createRawImage copies the base image to the given path and applies the customizations to it.
+func (b *Builder) createRawImage(imagePath string) error {
 	requiredSpace, err := b.calculateMinimumRequiredSpace()
 	if err != nil {
 		return fmt.Errorf("calculating minimum required space: %w", err)
@@ -46,13 +52,13 @@ func (b *Builder) buildRawImage() error {
 		return fmt.Errorf("deleting existing RAW image: %w", err)
 	}
 
-	cmd := b.createRawImageCopyCommand()
+	cmd := b.createRawImageCopyCommand(imagePath)
 	if err = cmd.Run(); err != nil {
-		return fmt.Errorf("copying the base image %s to the output image location %s: %w",
-			b.context.ImageDefinition.Image.BaseImage, b.generateOutputImageFilename(), err)
+		return fmt.Errorf("copying the base image %s to the image location %s: %w",
+			b.context.ImageDefinition.Image.BaseImage, imagePath, err)
 	}
 
-	return b.modifyRawImage(b.generateOutputImageFilename(), true, true)
+	return b.modifyRawImage(imagePath, true, true)
 }
 
 func (b *Builder) modifyRawImage(imagePath string, includeCombustion, renameFilesystem bool) error {
@@ -81,11 +87,10 @@ func (b *Builder) modifyRawImage(imagePath string, includeCombustion, renameFile
 	return nil
 }
 
-func (b *Builder) createRawImageCopyCommand() *exec.Cmd {
+func (b *Builder) createRawImageCopyCommand(imagePath string) *exec.Cmd {
 	baseImagePath := b.generateBaseImageFilename()
-	outputImagePath := b.generateOutputImageFilename()
 
-	cmd := exec.Command(copyExec, baseImagePath, outputImagePath)
+	cmd := process.Command(b.context.Context(), copyExec, baseImagePath, imagePath)
 	return cmd
 }
 
@@ -134,7 +139,7 @@ func (b *Builder) writeModifyScript(imageFilename string, includeCombustion, ren
 func (b *Builder) createModifyCommand(writer io.Writer) *exec.Cmd {
 	scriptPath := filepath.Join(b.context.BuildDir, modifyScriptName)
 
-	cmd := exec.Command(scriptPath)
+	cmd := process.Command(b.context.Context(), scriptPath)
 	cmd.Stdout = writer
 	cmd.Stderr = writer
 
@@ -28,7 +28,7 @@ func TestCreateRawImageCopyCommand(t *testing.T) {
 	}
 
 	// Test
-	cmd := builder.createRawImageCopyCommand()
+	cmd := builder.createRawImageCopyCommand(builder.generateOutputImageFilename())
 
 	// Verify
 	require.NotNil(t, cmd)
@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// Export writes all cache entries as a tar archive consisting of
+// the cache index followed by the files referenced by it.
+func (cache *Cache) Export(writer io.Writer) error {
+	return cache.withSharedLock(func(idx *index) error {
+		tw := tar.NewWriter(writer)
+
+		b, err := yaml.Marshal(idx)
+		if err != nil {
+			return fmt.Errorf("encoding index: %w", err)
+		}
+
+		if err = writeTarEntry(tw, indexFilename, int64(len(b)), strings.NewReader(string(b))); err != nil {
+			return fmt.Errorf("writing index: %w", err)
+		}
+
+		written := map[string]bool{}
+		for _, entry := range idx.list() {
+			if written[entry.Digest] {
+				continue
+			}
+
+			if err = cache.exportBlob(tw, entry); err != nil {
+				return fmt.Errorf("exporting entry '%s': %w", entry.Identifier, err)
+			}
+
+			written[entry.Digest] = true
+		}
+
+		if err = tw.Close(); err != nil {
+			return fmt.Errorf("closing archive: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (cache *Cache) exportBlob(tw *tar.Writer, entry *Entry) error {
+	file, err := os.Open(cache.blobPath(entry.Digest))
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	return writeTarEntry(tw, path.Join(blobsDir, entry.Digest), entry.Size, file)
+}
+
+func writeTarEntry(tw *tar.Writer, name string, size int64, reader io.Reader) error {
+	header := &tar.Header{
+		Name: name,
+		Mode: int64(fileio.NonExecutablePerms),
+		Size: size,
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	if _, err := io.Copy(tw, reader); err != nil {
+		return fmt.Errorf("writing contents: %w", err)
+	}
+
+	return nil
+}
+
+// Import reads a tar archive produced by Export and adds its entries to the cache.
+// Every file is verified against its digest before being stored; entries with
+// identifiers already present in the cache are left untouched.
+// Returns the newly imported entries.
+func (cache *Cache) Import(reader io.Reader) ([]*Entry, error) {
+	tr := tar.NewReader(reader)
+
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("reading archive: %w", err)
+	}
+
+	if header.Name != indexFilename {
+		return nil, fmt.Errorf("invalid archive: expected '%s' as first entry, found '%s'", indexFilename, header.Name)
+	}
+
+	imported := &index{}
+	if err = yaml.NewDecoder(tr).Decode(imported); err != nil {
+		return nil, fmt.Errorf("decoding index: %w", err)
+	}
+
+	byDigest := map[string][]*Entry{}
+	for _, entry := range imported.Entries {
+		byDigest[entry.Digest] = append(byDigest[entry.Digest], entry)
+	}
+
+	var added []*Entry
+
+	for {
+		header, err = tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, fmt.Errorf("reading archive: %w", err)
+		}
+
+		digest := path.Base(header.Name)
+		entries, ok := byDigest[digest]
+		if !ok || header.Name != path.Join(blobsDir, digest) {
+			zap.S().Warnf("Skipping unexpected archive entry '%s'", header.Name)
+			continue
+		}
+
+		var blobEntries []*Entry
+		blobEntries, err = cache.importBlob(tr, digest, entries)
+		if err != nil {
+			return nil, fmt.Errorf("importing file '%s': %w", digest, err)
+		}
+
+		added = append(added, blobEntries...)
+	}
+
+	sortEntries(added)
+	return added, nil
+}
+
+func (cache *Cache) importBlob(reader io.Reader, digest string, entries []*Entry) ([]*Entry, error) {
+	tmpPath, actualDigest, _, err := cache.writeTemporary(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+
+	if actualDigest != digest {
+		return nil, fmt.Errorf("%w: expected digest %s, got %s", ErrCorrupted, digest, actualDigest)
+	}
+
+	var added []*Entry
+
+	err = cache.withLock(func(idx *index) (bool, error) {
+		for _, entry := range entries {
+			if _, ok := idx.Entries[entry.Identifier]; ok {
+				zap.S().Infof("Skipping import of existing cache entry '%s'", entry.Identifier)
+				continue
+			}
+
+			if len(added) == 0 {
+				if err = os.Rename(tmpPath, cache.blobPath(digest)); err != nil {
+					return false, fmt.Errorf("moving file to cache: %w", err)
+				}
+			}
+
+			idx.Entries[entry.Identifier] = entry
+			added = append(added, entry)
+		}
+
+		for _, entry := range added {
+			cache.evict(idx, entry.Identifier)
+		}
+
+		return len(added) != 0, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return added, nil
+}
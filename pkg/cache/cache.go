@@ -1,95 +1,369 @@
 package cache
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"hash/fnv"
 	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"strconv"
+	"sort"
+	"time"
 
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"go.uber.org/zap"
 )
 
+const (
+	blobsDir     = "blobs"
+	tmpDir       = "tmp"
+	lockFilename = ".lock"
+)
+
+// ErrCorrupted is returned when the contents of a cache entry no longer match the digest it was stored with.
+var ErrCorrupted = errors.New("cache entry is corrupted")
+
+// Cache stores files by their SHA-256 content digest and keeps an index mapping
+// user-provided identifiers (e.g. "v1.30.3+rke2r1/rke2.linux-amd64.tar.gz") to those digests.
+//
+// All index mutations are guarded by a file lock, so that multiple builds
+// sharing the same cache directory do not corrupt each other.
 type Cache struct {
 	cacheDir string
+	// maxSize is the upper limit (in bytes) of the stored blobs; zero means unlimited.
+	maxSize int64
 }
 
-func New(cacheDir string) (*Cache, error) {
-	return &Cache{cacheDir: cacheDir}, nil
+// New creates a cache under the given directory. Least recently used entries are
+// evicted once the total size of the stored files exceeds maxSize.
+// A maxSize of zero disables eviction.
+func New(cacheDir string, maxSize int64) (*Cache, error) {
+	if maxSize < 0 {
+		return nil, fmt.Errorf("invalid maximum cache size: %d", maxSize)
+	}
+
+	for _, dir := range []string{cacheDir, filepath.Join(cacheDir, blobsDir), filepath.Join(cacheDir, tmpDir)} {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return nil, fmt.Errorf("creating cache directory '%s': %w", dir, err)
+		}
+	}
+
+	return &Cache{cacheDir: cacheDir, maxSize: maxSize}, nil
 }
 
-func (cache *Cache) Get(fileIdentifier string) (path string, err error) {
-	path, err = cache.identifierPath(fileIdentifier)
+// CopyTo copies the file stored under the given identifier to dest, creating it with the given permissions.
+//
+// The file is copied while holding a shared lock, so it cannot be evicted or pruned by another build
+// in the meantime. Its contents are verified against the digest recorded on insertion as they are copied;
+// entries which fail verification are removed from the cache and ErrCorrupted is returned.
+func (cache *Cache) CopyTo(fileIdentifier, dest string, perms os.FileMode) error {
+	var digest string
+
+	err := cache.withSharedLock(func(idx *index) error {
+		entry, ok := idx.Entries[fileIdentifier]
+		if !ok {
+			return fs.ErrNotExist
+		}
+
+		digest = entry.Digest
+		return copyVerified(cache.blobPath(digest), dest, digest, perms)
+	})
 	if err != nil {
-		return "", fmt.Errorf("searching for identifier '%s' in cache: %w", fileIdentifier, err)
+		if errors.Is(err, ErrCorrupted) {
+			zap.S().Warnf("Cache entry with identifier '%s' failed verification: %v", fileIdentifier, err)
+			cache.removeCorrupted(fileIdentifier, digest)
+			return fmt.Errorf("identifier '%s': %w", fileIdentifier, err)
+		}
+
+		return err
 	}
 
-	if !exists(path) {
-		return "", fs.ErrNotExist
+	cache.touch(fileIdentifier)
+	return nil
+}
+
+// Contains reports whether a file is stored under the given identifier.
+func (cache *Cache) Contains(fileIdentifier string) bool {
+	var found bool
+
+	err := cache.withSharedLock(func(idx *index) error {
+		entry, ok := idx.Entries[fileIdentifier]
+		if !ok {
+			return nil
+		}
+
+		_, err := os.Stat(cache.blobPath(entry.Digest))
+		found = err == nil
+		return nil
+	})
+	if err != nil {
+		zap.S().Warnf("Querying cache for identifier '%s' failed: %v", fileIdentifier, err)
+		return false
 	}
 
-	return path, nil
+	return found
+}
+
+// touch records the access of the given identifier, which determines the order of eviction.
+func (cache *Cache) touch(fileIdentifier string) {
+	err := cache.withLock(func(idx *index) (bool, error) {
+		entry, ok := idx.Entries[fileIdentifier]
+		if !ok {
+			return false, nil
+		}
+
+		entry.LastAccessed = time.Now().UTC()
+		return true, nil
+	})
+	if err != nil {
+		zap.S().Warnf("Recording access of cache entry '%s' failed: %v", fileIdentifier, err)
+	}
 }
 
-func (cache *Cache) Put(fileIdentifier string, reader io.Reader) error {
-	path, err := cache.identifierPath(fileIdentifier)
+// removeCorrupted removes the identifier from the cache unless it has been stored again with different contents.
+func (cache *Cache) removeCorrupted(fileIdentifier, digest string) {
+	err := cache.withLock(func(idx *index) (bool, error) {
+		entry, ok := idx.Entries[fileIdentifier]
+		if !ok || entry.Digest != digest {
+			return false, nil
+		}
+
+		cache.removeEntry(idx, fileIdentifier)
+		return true, nil
+	})
 	if err != nil {
-		return fmt.Errorf("searching for identifier '%s' in cache: %w", fileIdentifier, err)
+		zap.S().Warnf("Removing corrupted cache entry '%s' failed: %v", fileIdentifier, err)
 	}
+}
 
-	if exists(path) {
+// Put stores the contents of the reader under the given identifier.
+// The source (e.g. a download URL) is only recorded as metadata and may be empty.
+//
+// The contents are written to a temporary location first and only become
+// visible to Get once they have been fully received.
+func (cache *Cache) Put(fileIdentifier, source string, reader io.Reader) error {
+	if cache.Contains(fileIdentifier) {
 		zap.S().Warnf("File with identifier '%s' already exists in cache", fileIdentifier)
 		return fs.ErrExist
 	}
 
 	zap.S().Infof("Storing file with identifier '%s' in cache", fileIdentifier)
 
-	file, err := os.Create(path)
+	tmpPath, digest, size, err := cache.writeTemporary(reader)
 	if err != nil {
-		return fmt.Errorf("creating file: %w", err)
+		return fmt.Errorf("storing file: %w", err)
+	}
+
+	defer func() {
+		if err = os.Remove(tmpPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			zap.S().Warnf("Removing temporary cache file '%s' failed: %v", tmpPath, err)
+		}
+	}()
+
+	return cache.withLock(func(idx *index) (bool, error) {
+		if _, ok := idx.Entries[fileIdentifier]; ok {
+			zap.S().Warnf("File with identifier '%s' already exists in cache", fileIdentifier)
+			return false, fs.ErrExist
+		}
+
+		blobPath := cache.blobPath(digest)
+		if verifyFile(blobPath, digest) != nil {
+			if err = os.Rename(tmpPath, blobPath); err != nil {
+				return false, fmt.Errorf("moving file to cache: %w", err)
+			}
+		}
+
+		now := time.Now().UTC()
+		idx.Entries[fileIdentifier] = &Entry{
+			Identifier:   fileIdentifier,
+			Digest:       digest,
+			Size:         size,
+			Source:       source,
+			Inserted:     now,
+			LastAccessed: now,
+		}
+
+		cache.evict(idx, fileIdentifier)
+		return true, nil
+	})
+}
+
+func (cache *Cache) writeTemporary(reader io.Reader) (path, digest string, size int64, err error) {
+	file, err := os.CreateTemp(filepath.Join(cache.cacheDir, tmpDir), "entry-")
+	if err != nil {
+		return "", "", 0, fmt.Errorf("creating temporary file: %w", err)
 	}
 	defer file.Close()
 
-	if _, err = io.Copy(file, reader); err != nil {
-		return fmt.Errorf("storing file: %w", err)
+	hash := sha256.New()
+
+	size, err = io.Copy(io.MultiWriter(file, hash), reader)
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", "", 0, fmt.Errorf("writing temporary file: %w", err)
 	}
 
-	return nil
+	return file.Name(), hex.EncodeToString(hash.Sum(nil)), size, nil
 }
 
-func (cache *Cache) identifierPath(fileIdentifier string) (string, error) {
-	identifier, err := identifierHash(fileIdentifier)
-	if err != nil {
-		return "", err
+// evict removes the least recently used entries until the total cache size
+// fits within the configured limit. The entry identified by keep is never evicted.
+func (cache *Cache) evict(idx *index, keep string) {
+	if cache.maxSize == 0 {
+		return
+	}
+
+	entries := idx.list()
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastAccessed.Before(entries[j].LastAccessed)
+	})
+
+	for _, entry := range entries {
+		if idx.size() <= cache.maxSize {
+			return
+		}
+
+		if entry.Identifier == keep {
+			continue
+		}
+
+		zap.S().Infof("Evicting file with identifier '%s' from cache", entry.Identifier)
+		cache.removeEntry(idx, entry.Identifier)
+	}
+}
+
+// removeEntry deletes the identifier from the index and removes
+// the underlying file if no other identifier references it.
+func (cache *Cache) removeEntry(idx *index, fileIdentifier string) {
+	entry, ok := idx.Entries[fileIdentifier]
+	if !ok {
+		return
+	}
+
+	delete(idx.Entries, fileIdentifier)
+
+	for _, e := range idx.Entries {
+		if e.Digest == entry.Digest {
+			return
+		}
 	}
 
-	return filepath.Join(cache.cacheDir, identifier), nil
+	if err := os.Remove(cache.blobPath(entry.Digest)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		zap.S().Warnf("Removing cached file '%s' failed: %v", entry.Digest, err)
+	}
 }
 
-func identifierHash(identifier string) (string, error) {
-	h := fnv.New64()
+func (cache *Cache) blobPath(digest string) string {
+	return filepath.Join(cache.cacheDir, blobsDir, digest)
+}
 
-	if _, err := h.Write([]byte(identifier)); err != nil {
-		return "", err
+func (cache *Cache) indexPath() string {
+	return filepath.Join(cache.cacheDir, indexFilename)
+}
+
+func (cache *Cache) lockPath() string {
+	return filepath.Join(cache.cacheDir, lockFilename)
+}
+
+// withLock acquires the cache lock, loads the index and passes it to the given function.
+// The index is persisted afterwards if the function reports that it has been modified.
+func (cache *Cache) withLock(f func(idx *index) (modified bool, err error)) error {
+	l, err := fileio.AcquireLock(cache.lockPath())
+	if err != nil {
+		return fmt.Errorf("locking cache: %w", err)
 	}
 
-	hash := strconv.FormatUint(h.Sum64(), 10)
+	defer func() {
+		if err = l.Release(); err != nil {
+			zap.S().Warnf("Releasing cache lock failed: %v", err)
+		}
+	}()
 
-	zap.S().Debugf("Generated hash '%s' from identifier '%s'", hash, identifier)
-	return hash, nil
+	idx, err := readIndex(cache.indexPath())
+	if err != nil {
+		return fmt.Errorf("reading cache index: %w", err)
+	}
+
+	modified, fErr := f(idx)
+	if modified {
+		if err = writeIndex(cache.indexPath(), idx); err != nil {
+			return fmt.Errorf("writing cache index: %w", err)
+		}
+	}
+
+	return fErr
 }
 
-func exists(path string) bool {
-	if _, err := os.Stat(path); err != nil {
-		if !errors.Is(err, fs.ErrNotExist) {
-			zap.S().Warnf("Looking for file with identifier failed: %v", err)
+// withSharedLock acquires a shared cache lock and passes the index to the given function, which must not modify it.
+func (cache *Cache) withSharedLock(f func(idx *index) error) error {
+	l, err := fileio.AcquireSharedLock(cache.lockPath())
+	if err != nil {
+		return fmt.Errorf("locking cache: %w", err)
+	}
+
+	defer func() {
+		if err = l.Release(); err != nil {
+			zap.S().Warnf("Releasing cache lock failed: %v", err)
 		}
+	}()
 
-		return false
+	idx, err := readIndex(cache.indexPath())
+	if err != nil {
+		return fmt.Errorf("reading cache index: %w", err)
+	}
+
+	return f(idx)
+}
+
+// copyVerified copies the file to dest while verifying its contents against the digest.
+// The destination is removed if the contents do not match.
+func copyVerified(path, dest, digest string, perms os.FileMode) error {
+	source, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%w: opening file: %w", ErrCorrupted, err)
+	}
+	defer source.Close()
+
+	destFile, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perms)
+	if err != nil {
+		return fmt.Errorf("creating destination file: %w", err)
+	}
+	defer destFile.Close()
+
+	if err = os.Chmod(dest, perms); err != nil {
+		return fmt.Errorf("adjusting destination file permissions: %w", err)
 	}
 
-	return true
+	hash := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(destFile, hash), source); err != nil {
+		return fmt.Errorf("copying file: %w", err)
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != digest {
+		_ = os.Remove(dest)
+		return fmt.Errorf("%w: digest mismatch: expected %s, got %s", ErrCorrupted, digest, actual)
+	}
+
+	return nil
+}
+
+func verifyFile(path, digest string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return fmt.Errorf("reading file: %w", err)
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != digest {
+		return fmt.Errorf("digest mismatch: expected %s, got %s", digest, actual)
+	}
+
+	return nil
 }
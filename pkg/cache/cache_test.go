@@ -1,20 +1,26 @@
 package cache
 
 import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func setup(t *testing.T) (cache *Cache, teardown func()) {
+func setup(t *testing.T, maxSize int64) (cache *Cache, teardown func()) {
 	cacheDir := "test-cache"
-	assert.NoError(t, os.MkdirAll(cacheDir, os.ModePerm))
 
-	cache, err := New(cacheDir)
+	cache, err := New(cacheDir, maxSize)
 	require.NoError(t, err)
 
 	return cache, func() {
@@ -22,44 +28,401 @@ func setup(t *testing.T) (cache *Cache, teardown func()) {
 	}
 }
 
+// storedPath returns the path of the blob stored under the given identifier.
+func storedPath(t *testing.T, cache *Cache, fileIdentifier string) string {
+	idx, err := readIndex(cache.indexPath())
+	require.NoError(t, err)
+
+	entry, ok := idx.Entries[fileIdentifier]
+	require.True(t, ok, "missing entry %s", fileIdentifier)
+
+	return cache.blobPath(entry.Digest)
+}
+
+// copyFromCache copies the file stored under the given identifier and returns its contents.
+func copyFromCache(t *testing.T, cache *Cache, fileIdentifier string) (string, error) {
+	dest := filepath.Join(t.TempDir(), "copy")
+	if err := cache.CopyTo(fileIdentifier, dest, 0o600); err != nil {
+		assert.NoFileExists(t, dest)
+		return "", err
+	}
+
+	b, err := os.ReadFile(dest)
+	require.NoError(t, err)
+
+	return string(b), nil
+}
+
+func TestNew_InvalidMaxSize(t *testing.T) {
+	_, err := New("test-cache", -1)
+	assert.EqualError(t, err, "invalid maximum cache size: -1")
+}
+
 func TestCache(t *testing.T) {
-	cache, teardown := setup(t)
+	cache, teardown := setup(t, 0)
 	defer teardown()
 
 	fileIdentifier := "some-cool-filename"
 	fileContents := "some-data"
 
-	require.NoError(t, cache.Put(fileIdentifier, strings.NewReader(fileContents)))
+	require.NoError(t, cache.Put(fileIdentifier, "", strings.NewReader(fileContents)))
+
+	contents, err := copyFromCache(t, cache, fileIdentifier)
+	require.NoError(t, err)
+	assert.Equal(t, fileContents, contents)
+	assert.True(t, cache.Contains(fileIdentifier))
+
+	assert.Equal(t, "test-cache/blobs/9332d94d5ee69ad17d310e62cd101d70f578024fd5e8d1647f8073f886c894e1", storedPath(t, cache, fileIdentifier))
+}
+
+func TestCache_CopyToPermissions(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	require.NoError(t, cache.Put("script", "", strings.NewReader("#!/bin/bash")))
+
+	dest := filepath.Join(t.TempDir(), "script.sh")
+	require.NoError(t, cache.CopyTo("script", dest, 0o744))
 
-	path, err := cache.Get(fileIdentifier)
+	info, err := os.Stat(dest)
 	require.NoError(t, err)
-	require.FileExists(t, path)
+	assert.Equal(t, os.FileMode(0o744), info.Mode())
 
-	b, err := os.ReadFile(path)
+	// The copy is independent of the stored file
+	require.NoError(t, os.WriteFile(dest, []byte("modified"), 0o744))
+	contents, err := copyFromCache(t, cache, "script")
 	require.NoError(t, err)
-	assert.Equal(t, fileContents, string(b))
+	assert.Equal(t, "#!/bin/bash", contents)
 }
 
 func TestCache_MissingEntry(t *testing.T) {
-	cache, teardown := setup(t)
+	cache, teardown := setup(t, 0)
 	defer teardown()
 
 	fileIdentifier := "some-cool-filename"
 
-	path, err := cache.Get(fileIdentifier)
+	_, err := copyFromCache(t, cache, fileIdentifier)
 	require.Error(t, err)
 
 	assert.ErrorIs(t, err, fs.ErrNotExist)
-	assert.NoFileExists(t, path)
+	assert.False(t, cache.Contains(fileIdentifier))
 }
 
 func TestCache_DoubleInsert(t *testing.T) {
-	cache, teardown := setup(t)
+	cache, teardown := setup(t, 0)
 	defer teardown()
 
 	fileIdentifier := "https://raw.githubusercontent.com/suse-edge/edge-image-builder/main/README.md"
 	fileContents := "some-data"
 
-	require.NoError(t, cache.Put(fileIdentifier, strings.NewReader(fileContents)))
-	assert.ErrorIs(t, cache.Put(fileIdentifier, strings.NewReader(fileContents)), fs.ErrExist)
+	require.NoError(t, cache.Put(fileIdentifier, "", strings.NewReader(fileContents)))
+	assert.ErrorIs(t, cache.Put(fileIdentifier, "", strings.NewReader(fileContents)), fs.ErrExist)
+}
+
+func TestCache_InterruptedInsert(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	fileIdentifier := "v1.30.3+rke2r1/rke2.linux-amd64.tar.gz"
+	reader := iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader("some-data")))
+
+	err := cache.Put(fileIdentifier, "", reader)
+	require.Error(t, err)
+
+	_, err = copyFromCache(t, cache, fileIdentifier)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	tmpEntries, err := os.ReadDir("test-cache/tmp")
+	require.NoError(t, err)
+	assert.Empty(t, tmpEntries)
+}
+
+func TestCache_CorruptedEntry(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	fileIdentifier := "v1.30.3+rke2r1/rke2.linux-amd64.tar.gz"
+
+	require.NoError(t, cache.Put(fileIdentifier, "", strings.NewReader("some-data")))
+
+	path := storedPath(t, cache, fileIdentifier)
+	require.NoError(t, os.WriteFile(path, []byte("some-"), 0o600))
+
+	_, err := copyFromCache(t, cache, fileIdentifier)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrCorrupted)
+	assert.NoFileExists(t, path)
+
+	_, err = copyFromCache(t, cache, fileIdentifier)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestCache_SharedContents(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	require.NoError(t, cache.Put("first", "https://example.com/first", strings.NewReader("some-data")))
+	require.NoError(t, cache.Put("second", "https://example.com/second", strings.NewReader("some-data")))
+
+	assert.Equal(t, storedPath(t, cache, "first"), storedPath(t, cache, "second"))
+
+	idx, err := readIndex(cache.indexPath())
+	require.NoError(t, err)
+
+	require.Len(t, idx.Entries, 2)
+	assert.Equal(t, "https://example.com/first", idx.Entries["first"].Source)
+	assert.Equal(t, "https://example.com/second", idx.Entries["second"].Source)
+	assert.EqualValues(t, 9, idx.size())
+
+	entries, err := cache.List()
+	require.NoError(t, err)
+	assert.EqualValues(t, 9, TotalSize(entries))
+}
+
+func TestCache_Eviction(t *testing.T) {
+	cache, teardown := setup(t, 10)
+	defer teardown()
+
+	require.NoError(t, cache.Put("first", "", strings.NewReader("12345")))
+	require.NoError(t, cache.Put("second", "", strings.NewReader("67890")))
+
+	// Access the first entry so that the second one becomes the least recently used
+	_, err := copyFromCache(t, cache, "first")
+	require.NoError(t, err)
+
+	require.NoError(t, cache.Put("third", "", strings.NewReader("abc")))
+
+	_, err = copyFromCache(t, cache, "first")
+	assert.NoError(t, err)
+
+	_, err = copyFromCache(t, cache, "second")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	_, err = copyFromCache(t, cache, "third")
+	assert.NoError(t, err)
+}
+
+func TestCache_EvictionKeepsOversizedEntry(t *testing.T) {
+	cache, teardown := setup(t, 4)
+	defer teardown()
+
+	require.NoError(t, cache.Put("small", "", strings.NewReader("123")))
+	require.NoError(t, cache.Put("large", "", strings.NewReader("some-data")))
+
+	assert.False(t, cache.Contains("small"))
+	assert.True(t, cache.Contains("large"))
+}
+
+func TestCache_ConcurrentInserts(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, cache.Put(fmt.Sprintf("entry-%d", i), "", strings.NewReader(fmt.Sprintf("data-%d", i))))
+		}()
+	}
+	wg.Wait()
+
+	idx, err := readIndex(cache.indexPath())
+	require.NoError(t, err)
+	assert.Len(t, idx.Entries, 20)
+}
+
+func TestCache_ConcurrentCopyAndRemove(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	contents := strings.Repeat("some-data", 100000)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for range 20 {
+			assert.NoError(t, cache.Put("entry", "", strings.NewReader(contents)))
+			assert.NoError(t, cache.Remove("entry"))
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for range 100 {
+			// Copies either see the complete contents or no entry at all
+			copied, err := copyFromCache(t, cache, "entry")
+			if err != nil {
+				assert.ErrorIs(t, err, fs.ErrNotExist)
+				continue
+			}
+			assert.Equal(t, contents, copied)
+		}
+	}()
+
+	wg.Wait()
+}
+
+func TestCache_List(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	entries, err := cache.List()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, cache.Put("b", "https://example.com/b", strings.NewReader("bbb")))
+	require.NoError(t, cache.Put("a", "https://example.com/a", strings.NewReader("a")))
+
+	entries, err = cache.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "a", entries[0].Identifier)
+	assert.Equal(t, "https://example.com/a", entries[0].Source)
+	assert.EqualValues(t, 1, entries[0].Size)
+	assert.Equal(t, "b", entries[1].Identifier)
+	assert.EqualValues(t, 3, entries[1].Size)
+
+	size, err := cache.Size()
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, size)
+}
+
+func TestCache_Prune(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	require.NoError(t, cache.Put("v1.28.9+rke2r1/rke2.linux-amd64.tar.gz", "", strings.NewReader("old")))
+	require.NoError(t, cache.Put("v1.30.3+rke2r1/rke2.linux-amd64.tar.gz", "", strings.NewReader("new")))
+
+	path := storedPath(t, cache, "v1.28.9+rke2r1/rke2.linux-amd64.tar.gz")
+
+	pruned, err := cache.Prune(func(entry *Entry) bool {
+		return strings.HasPrefix(entry.Identifier, "v1.28.9+rke2r1/")
+	})
+	require.NoError(t, err)
+	require.Len(t, pruned, 1)
+	assert.Equal(t, "v1.28.9+rke2r1/rke2.linux-amd64.tar.gz", pruned[0].Identifier)
+	assert.NoFileExists(t, path)
+
+	entries, err := cache.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "v1.30.3+rke2r1/rke2.linux-amd64.tar.gz", entries[0].Identifier)
+}
+
+func TestCache_Verify(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	require.NoError(t, cache.Put("valid", "", strings.NewReader("valid-data")))
+	require.NoError(t, cache.Put("corrupted", "", strings.NewReader("some-data")))
+
+	require.NoError(t, os.WriteFile(storedPath(t, cache, "corrupted"), []byte("some"), 0o600))
+
+	corrupted, err := cache.Verify()
+	require.NoError(t, err)
+	require.Len(t, corrupted, 1)
+	assert.Equal(t, "corrupted", corrupted[0].Identifier)
+
+	corrupted, err = cache.Verify()
+	require.NoError(t, err)
+	assert.Empty(t, corrupted)
+
+	entries, err := cache.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "valid", entries[0].Identifier)
+}
+
+func TestCache_ExportImport(t *testing.T) {
+	source, teardown := setup(t, 0)
+	defer teardown()
+
+	require.NoError(t, source.Put("first", "https://example.com/first", strings.NewReader("some-data")))
+	require.NoError(t, source.Put("second", "https://example.com/second", strings.NewReader("some-data")))
+	require.NoError(t, source.Put("third", "", strings.NewReader("other-data")))
+
+	var archive bytes.Buffer
+	require.NoError(t, source.Export(&archive))
+
+	destination, err := New("test-cache-import", 0)
+	require.NoError(t, err)
+	defer func() {
+		assert.NoError(t, os.RemoveAll("test-cache-import"))
+	}()
+
+	require.NoError(t, destination.Put("third", "", strings.NewReader("other-data")))
+
+	imported, err := destination.Import(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, imported, 2)
+	assert.Equal(t, "first", imported[0].Identifier)
+	assert.Equal(t, "second", imported[1].Identifier)
+
+	contents, err := copyFromCache(t, destination, "second")
+	require.NoError(t, err)
+	assert.Equal(t, "some-data", contents)
+
+	entries, err := destination.List()
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, "https://example.com/first", entries[0].Source)
+}
+
+func TestCache_ImportInvalidArchive(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	require.NoError(t, writeTarEntry(tw, "something.txt", 4, strings.NewReader("data")))
+	require.NoError(t, tw.Close())
+
+	_, err := cache.Import(&archive)
+	assert.EqualError(t, err, "invalid archive: expected 'index.yaml' as first entry, found 'something.txt'")
+}
+
+func TestCache_ImportCorruptedFile(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	digest := "9332d94d5ee69ad17d310e62cd101d70f578024fd5e8d1647f8073f886c894e1"
+	idx := fmt.Sprintf("entries:\n  first:\n    identifier: first\n    digest: %s\n    size: 9\n", digest)
+
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	require.NoError(t, writeTarEntry(tw, indexFilename, int64(len(idx)), strings.NewReader(idx)))
+	require.NoError(t, writeTarEntry(tw, "blobs/"+digest, 9, strings.NewReader("some-dat@")))
+	require.NoError(t, tw.Close())
+
+	_, err := cache.Import(&archive)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrCorrupted)
+
+	assert.False(t, cache.Contains("first"))
+}
+
+func TestCache_Remove(t *testing.T) {
+	cache, teardown := setup(t, 0)
+	defer teardown()
+
+	require.NoError(t, cache.Put("first", "", strings.NewReader("some-data")))
+	require.NoError(t, cache.Put("second", "", strings.NewReader("some-data")))
+
+	path := storedPath(t, cache, "first")
+
+	require.NoError(t, cache.Remove("first"))
+	require.NoError(t, cache.Remove("missing"))
+
+	assert.False(t, cache.Contains("first"))
+
+	// The contents are still referenced by the second entry
+	assert.FileExists(t, path)
+
+	require.NoError(t, cache.Remove("second"))
+	assert.NoFileExists(t, path)
 }
@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"gopkg.in/yaml.v3"
+)
+
+const indexFilename = "index.yaml"
+
+// Entry describes a single file stored in the cache.
+type Entry struct {
+	Identifier   string    `yaml:"identifier"`
+	Digest       string    `yaml:"digest"`
+	Size         int64     `yaml:"size"`
+	Source       string    `yaml:"source,omitempty"`
+	Inserted     time.Time `yaml:"inserted"`
+	LastAccessed time.Time `yaml:"lastAccessed"`
+}
+
+type index struct {
+	Entries map[string]*Entry `yaml:"entries"`
+}
+
+func readIndex(path string) (*index, error) {
+	idx := &index{Entries: map[string]*Entry{}}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return idx, nil
+		}
+
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	if err = yaml.Unmarshal(b, idx); err != nil {
+		return nil, fmt.Errorf("decoding index: %w", err)
+	}
+
+	if idx.Entries == nil {
+		idx.Entries = map[string]*Entry{}
+	}
+
+	return idx, nil
+}
+
+// writeIndex atomically replaces the index file so that readers
+// which do not hold the lock never observe a partially written index.
+func writeIndex(path string, idx *index) error {
+	b, err := yaml.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err = os.WriteFile(tmpPath, b, fileio.NonExecutablePerms); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+
+	if err = os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replacing index: %w", err)
+	}
+
+	return nil
+}
+
+func (idx *index) list() []*Entry {
+	entries := make([]*Entry, 0, len(idx.Entries))
+	for _, entry := range idx.Entries {
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// size returns the total size of the stored files, accounting for
+// identifiers sharing the same content only once.
+func (idx *index) size() int64 {
+	return TotalSize(idx.list())
+}
+
+// TotalSize returns the size of the files stored for the given entries,
+// accounting for entries sharing the same content only once.
+func TotalSize(entries []*Entry) int64 {
+	var total int64
+
+	seen := map[string]bool{}
+	for _, entry := range entries {
+		if seen[entry.Digest] {
+			continue
+		}
+
+		seen[entry.Digest] = true
+		total += entry.Size
+	}
+
+	return total
+}
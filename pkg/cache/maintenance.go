@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+)
+
+// List returns all entries stored in the cache ordered by identifier.
+func (cache *Cache) List() ([]*Entry, error) {
+	var entries []*Entry
+
+	err := cache.withSharedLock(func(idx *index) error {
+		entries = idx.list()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortEntries(entries)
+	return entries, nil
+}
+
+// Prune removes all entries matching the given predicate and returns them.
+func (cache *Cache) Prune(match func(entry *Entry) bool) ([]*Entry, error) {
+	var pruned []*Entry
+
+	err := cache.withLock(func(idx *index) (bool, error) {
+		for _, entry := range idx.list() {
+			if match(entry) {
+				cache.removeEntry(idx, entry.Identifier)
+				pruned = append(pruned, entry)
+			}
+		}
+
+		return len(pruned) != 0, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortEntries(pruned)
+	return pruned, nil
+}
+
+// Remove deletes the entry stored under the given identifier.
+// Removing an identifier which is not present in the cache is not an error.
+func (cache *Cache) Remove(fileIdentifier string) error {
+	return cache.withLock(func(idx *index) (bool, error) {
+		if _, ok := idx.Entries[fileIdentifier]; !ok {
+			return false, nil
+		}
+
+		cache.removeEntry(idx, fileIdentifier)
+		return true, nil
+	})
+}
+
+// Verify checks the contents of all cache entries against their recorded digests.
+// Corrupted entries are removed from the cache and returned.
+func (cache *Cache) Verify() ([]*Entry, error) {
+	var corrupted []*Entry
+
+	err := cache.withLock(func(idx *index) (bool, error) {
+		for _, entry := range idx.list() {
+			if err := verifyFile(cache.blobPath(entry.Digest), entry.Digest); err != nil {
+				cache.removeEntry(idx, entry.Identifier)
+				corrupted = append(corrupted, entry)
+			}
+		}
+
+		return len(corrupted) != 0, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("verifying cache: %w", err)
+	}
+
+	sortEntries(corrupted)
+	return corrupted, nil
+}
+
+// Size returns the total size (in bytes) of the files stored in the cache.
+func (cache *Cache) Size() (int64, error) {
+	var size int64
+
+	err := cache.withSharedLock(func(idx *index) error {
+		size = idx.size()
+		return nil
+	})
+
+	return size, err
+}
+
+func sortEntries(entries []*Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Identifier < entries[j].Identifier
+	})
+}
@@ -1,17 +1,26 @@
 package build
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 
+	"github.com/suse-edge/edge-image-builder/pkg/attestation"
 	"github.com/suse-edge/edge-image-builder/pkg/cli/cmd"
 	"github.com/suse-edge/edge-image-builder/pkg/eib"
+	"github.com/suse-edge/edge-image-builder/pkg/http"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/image/overlay"
+	"github.com/suse-edge/edge-image-builder/pkg/image/variables"
 	"github.com/suse-edge/edge-image-builder/pkg/log"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
+	"github.com/suse-edge/edge-image-builder/pkg/sbom"
 	"github.com/suse-edge/edge-image-builder/pkg/version"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
@@ -21,16 +30,14 @@ import (
 const (
 	buildLogFilename     = "eib-build.log"
 	checkBuildLogMessage = "Please check the eib-build.log file under the build directory for more information."
+	bytesInMiB           = 1024 * 1024
 )
 
 func Run(_ *cli.Context) error {
 	args := &cmd.BuildArgs
 
-	rootBuildDir := args.RootBuildDir
-	if rootBuildDir == "" {
-		const defaultBuildDir = "_build"
-
-		rootBuildDir = filepath.Join(args.ConfigDir, defaultBuildDir)
+	rootBuildDir := rootBuildDirectory(args.ConfigDir, args.RootBuildDir)
+	if args.RootBuildDir == "" {
 		if err := os.MkdirAll(rootBuildDir, os.ModePerm); err != nil {
 			log.Auditf("The root build directory could not be set up under the configuration directory '%s'.", args.ConfigDir)
 			return err
@@ -46,51 +53,131 @@ func Run(_ *cli.Context) error {
 	// This needs to occur as early as possible so that the subsequent calls can use the log
 	log.ConfigureGlobalLogger(filepath.Join(buildDir, buildLogFilename))
 
+	buildReport := report.New(version.GetEibVersion())
+	log.SetComponentStatusObserver(buildReport.RecordComponentStatus)
+
 	if cmdErr := imageConfigDirExists(args.ConfigDir); cmdErr != nil {
 		cmd.LogError(cmdErr, checkBuildLogMessage)
 		os.Exit(1)
 	}
 
-	imageDefinition, cmdErr := parseImageDefinition(args.ConfigDir, args.DefinitionFile)
+	imageDefinition, cmdErr := parseImageDefinition(args.ConfigDir, args.DefinitionFile, args.ValuesFile, args.Overlays.Value())
 	if cmdErr != nil {
 		cmd.LogError(cmdErr, checkBuildLogMessage)
 		os.Exit(1)
 	}
 
+	definitionFilePath := filepath.Join(args.ConfigDir, args.DefinitionFile)
+	if err = buildReport.SetDefinition(definitionFilePath, imageDefinition.APIVersion, imageDefinition.Sources()); err != nil {
+		zap.S().Warnf("Recording the image definition in the build report failed: %v", err)
+	}
+
 	combustionDir, artefactsDir, err := eib.SetupCombustionDirectory(buildDir)
 	if err != nil {
 		log.Auditf("Setting up the combustion directory failed. %s", checkBuildLogMessage)
+		log.AuditResult(err)
 		zap.S().Fatalf("Failed to create combustion directories: %s", err)
 	}
 
 	artifactSources, err := parseArtifactSources()
 	if err != nil {
 		log.Auditf("Loading artifact sources metadata failed. %s", checkBuildLogMessage)
+		log.AuditResult(err)
 		zap.S().Fatalf("Parsing artifact sources failed: %v", err)
 	}
 
+	if err = configureDownloads(artifactSources, args.Offline, buildReport); err != nil {
+		log.Auditf("Configuring file downloads failed. %s", checkBuildLogMessage)
+		log.AuditResult(err)
+		zap.S().Fatalf("Configuring downloads failed: %v", err)
+	}
+
 	ctx := buildContext(buildDir, combustionDir, artefactsDir, args.ConfigDir, imageDefinition, artifactSources)
+	ctx.CacheMaxSize = args.CacheMaxSize * bytesInMiB
+	ctx.Parallelism = args.Parallelism
+	ctx.StageTimeout = args.StageTimeout
+	ctx.ReuseComponents = !args.NoReuse
+	ctx = ctx.WithRecorder(buildReport)
 
-	if cmdErr = validateImageDefinition(ctx); cmdErr != nil {
+	if cmdErr = validateImageDefinition(ctx, args.Strict); cmdErr != nil {
 		cmd.LogError(cmdErr, checkBuildLogMessage)
 		os.Exit(1)
 	}
 
 	defer func() {
 		if r := recover(); r != nil {
+			redactLogFiles(buildDir)
 			log.Auditf("Build failed unexpectedly. %s", checkBuildLogMessage)
+			log.AuditResult(fmt.Errorf("unexpected error: %v", r))
 			zap.S().Fatalf("Unexpected error occurred: %s", r)
 		}
 	}()
 
-	if err = eib.Run(ctx, rootBuildDir); err != nil {
+	for _, target := range imageDefinition.Image.Targets() {
+		if err = buildReport.AddBaseImage(ctx.ForImage(target).BaseImagePath()); err != nil {
+			zap.S().Warnf("Recording the base image in the build report failed: %v", err)
+		}
+	}
+
+	buildCtx, stop := interruptContext()
+	defer stop()
+
+	err = eib.Run(ctx.WithContext(buildCtx), rootBuildDir, buildReport)
+	err = finalizeBuild(ctx, buildReport, err)
+
+	redactLogFiles(buildDir)
+
+	if err != nil {
 		log.Audit(checkBuildLogMessage)
+		log.AuditResult(err)
 		zap.S().Fatalf("An error occurred building the image: %s", err)
 	}
 
+	log.AuditResult(nil)
 	return nil
 }
 
+var errBuildInterrupted = errors.New("build interrupted")
+
+// interruptContext returns a context which is cancelled once SIGINT or SIGTERM is received,
+// allowing the operations in progress to stop and clean up after themselves.
+// Receiving a subsequent signal terminates the process immediately.
+func interruptContext() (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case sig := <-signals:
+			// Restores the default handling, so that the next signal terminates the process
+			signal.Stop(signals)
+
+			log.Auditf("Received %s, stopping the build and cleaning up. Repeat to exit immediately.", sig)
+			cancel(errBuildInterrupted)
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel(nil)
+	}
+}
+
+func rootBuildDirectory(configDir, rootBuildDir string) string {
+	if rootBuildDir != "" {
+		return rootBuildDir
+	}
+
+	const defaultBuildDir = "_build"
+	return filepath.Join(configDir, defaultBuildDir)
+}
+
 func imageConfigDirExists(configDir string) *cmd.Error {
 	_, err := os.Stat(configDir)
 	if err == nil {
@@ -109,7 +196,18 @@ func imageConfigDirExists(configDir string) *cmd.Error {
 	}
 }
 
-func parseImageDefinition(configDir, definitionFile string) (*image.Definition, *cmd.Error) {
+func parseImageDefinition(configDir, definitionFile, valuesFile string, overlays []string) (*image.Definition, *cmd.Error) {
+	document, cmdErr := loadImageDefinition(configDir, definitionFile, valuesFile, overlays)
+	if cmdErr != nil {
+		return nil, cmdErr
+	}
+
+	return decodeImageDefinition(filepath.Join(configDir, definitionFile), document)
+}
+
+// loadImageDefinition reads the image definition, combines it with its includes and overlays and
+// resolves the variables and secrets it references.
+func loadImageDefinition(configDir, definitionFile, valuesFile string, overlays []string) (*image.Document, *cmd.Error) {
 	definitionFilePath := filepath.Join(configDir, definitionFile)
 
 	configData, err := os.ReadFile(definitionFilePath)
@@ -126,7 +224,40 @@ func parseImageDefinition(configDir, definitionFile string) (*image.Definition,
 		}
 	}
 
-	imageDefinition, err := image.ParseDefinition(configData)
+	// Relative overlay paths are resolved next to the definition, i.e. in the image configuration directory
+	document, err := overlay.Resolve(definitionFilePath, configData, overlays)
+	if err != nil {
+		return nil, &cmd.Error{
+			UserMessage: fmt.Sprintf("The image definition file '%s' could not be combined with its includes and overlays: %v", definitionFilePath, err),
+			LogMessage:  fmt.Sprintf("Resolving definition includes and overlays failed: %v", err),
+		}
+	}
+
+	var values map[string]string
+	if valuesFile != "" {
+		if values, err = variables.LoadValues(valuesFile); err != nil {
+			return nil, &cmd.Error{
+				UserMessage: fmt.Sprintf("The specified values file '%s' could not be loaded.", valuesFile),
+				LogMessage:  fmt.Sprintf("Loading values file failed: %v", err),
+			}
+		}
+	}
+
+	resolver := variables.NewResolver(configDir, values, variables.SOPS{})
+	if err = resolver.Interpolate(document); err != nil {
+		return nil, &cmd.Error{
+			UserMessage: fmt.Sprintf("The image definition file '%s' references variables or secrets which could not be resolved: %v", definitionFilePath, err),
+			LogMessage:  fmt.Sprintf("Interpolating definition file failed: %v", err),
+		}
+	}
+
+	log.RegisterSecrets(resolver.Secrets()...)
+
+	return document, nil
+}
+
+func decodeImageDefinition(definitionFilePath string, document *image.Document) (*image.Definition, *cmd.Error) {
+	imageDefinition, err := image.ParseDocument(document)
 	if err != nil {
 		if errors.Is(err, image.ErrorInvalidSchemaVersion) {
 			m := "Invalid schema version specified. This version of Edge Image Builder supports the following schema versions: %s"
@@ -143,6 +274,12 @@ func parseImageDefinition(configDir, definitionFile string) (*image.Definition,
 		}
 	}
 
+	log.RegisterSecrets(imageDefinition.SensitiveValues()...)
+
+	for _, deprecation := range imageDefinition.Deprecations() {
+		log.AuditWarningf("%s Run the 'definition upgrade' command to update the image definition.", deprecation)
+	}
+
 	return imageDefinition, nil
 }
 
@@ -158,14 +295,139 @@ func parseArtifactSources() (*image.ArtifactSources, error) {
 		return nil, fmt.Errorf("reading artifact sources file: %w", err)
 	}
 
+	return decodeArtifactSources(b)
+}
+
+func decodeArtifactSources(b []byte) (*image.ArtifactSources, error) {
 	var sources image.ArtifactSources
-	if err = yaml.Unmarshal(b, &sources); err != nil {
+	if err := yaml.Unmarshal(b, &sources); err != nil {
 		return nil, fmt.Errorf("decoding artifacts sources: %w", err)
 	}
 
 	return &sources, nil
 }
 
+func configureDownloads(sources *image.ArtifactSources, offline bool, recorder http.DownloadRecorder) error {
+	config := http.Config{
+		Retries:  sources.Downloads.Retries,
+		Proxy:    sources.Downloads.Proxy,
+		Offline:  offline,
+		Recorder: recorder,
+	}
+
+	for _, m := range sources.Downloads.Mirrors {
+		config.Mirrors = append(config.Mirrors, http.Mirror{
+			Source: m.Source,
+			Mirror: m.Mirror,
+		})
+	}
+
+	return http.Configure(config)
+}
+
+// finalizeBuild produces the files accompanying the output images. The build report is written
+// regardless of the build result, while the SBOMs and the attestations are only produced for built images.
+func finalizeBuild(ctx *image.Context, buildReport *report.Report, buildErr error) error {
+	buildReport.Finish(buildErr)
+
+	targets := ctx.ImageDefinition.Image.Targets()
+
+	if buildErr == nil {
+		for _, target := range targets {
+			if buildErr = writeSBOM(ctx.ForImage(target), buildReport); buildErr != nil {
+				buildReport.Finish(buildErr)
+				break
+			}
+		}
+	}
+
+	writeBuildReport(ctx, buildReport)
+
+	if buildErr != nil {
+		return buildErr
+	}
+
+	for _, target := range targets {
+		if err := attest(ctx.ForImage(target), buildReport, buildReportPath(ctx)); err != nil {
+			// The report has already been written as successful, so it needs to be corrected
+			buildReport.Finish(err)
+			writeBuildReport(ctx, buildReport)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeBuildReport stores the build report next to the output image.
+// Failing to do so is not fatal since the image itself has already been built.
+func writeBuildReport(ctx *image.Context, buildReport *report.Report) {
+	reportPath := buildReportPath(ctx)
+	if err := buildReport.Write(reportPath); err != nil {
+		log.Auditf("Writing the build report failed. %s", checkBuildLogMessage)
+		zap.S().Errorf("Writing build report failed: %v", err)
+		return
+	}
+
+	zap.S().Infof("Build report written to '%s'", reportPath)
+}
+
+// redactLogFiles scrubs secrets from the log files written by the tools run during the build.
+// Failing to do so is not fatal, but the user needs to be made aware of it.
+func redactLogFiles(buildDir string) {
+	if err := log.RedactLogFiles(buildDir); err != nil {
+		log.AuditWarning("Secrets could not be removed from all log files under the build directory.")
+		zap.S().Errorf("Redacting log files failed: %v", err)
+	}
+}
+
+func writeSBOM(ctx *image.Context, buildReport *report.Report) error {
+	log.Audit("Generating the software bill of materials...")
+
+	doc, err := sbom.Generate(ctx, buildReport)
+	if err != nil {
+		return fmt.Errorf("generating SBOM: %w", err)
+	}
+
+	sbomPath := sbomPath(ctx)
+	if err = doc.Write(sbomPath); err != nil {
+		return err
+	}
+
+	log.Auditf("The software bill of materials can be found at: %s", sbomPath)
+	return nil
+}
+
+func attest(ctx *image.Context, buildReport *report.Report, reportPath string) error {
+	log.Audit("Generating checksums and provenance...")
+
+	outputs, err := attestation.Attest(ctx, buildReport, sbomPath(ctx), reportPath)
+	if err != nil {
+		return fmt.Errorf("attesting build outputs: %w", err)
+	}
+
+	log.Auditf("The checksums can be found at: %s", outputs.Checksums)
+	log.Auditf("The provenance can be found at: %s", outputs.Provenance)
+
+	if len(outputs.Signatures) > 0 {
+		log.Audit("The output image, SBOM, build report and provenance have been signed.")
+		zap.S().Infof("Signatures written: %s", strings.Join(outputs.Signatures, ", "))
+	}
+
+	return nil
+}
+
+func sbomPath(ctx *image.Context) string {
+	return ctx.OutputImagePath() + sbom.FileSuffix
+}
+
+// buildReportPath returns the location of the build report, which is shared by all output images
+// and stored next to the first of them.
+func buildReportPath(ctx *image.Context) string {
+	target := ctx.ImageDefinition.Image.Targets()[0]
+	return filepath.Join(filepath.Dir(ctx.ForImage(target).OutputImagePath()), report.Filename)
+}
+
 // Assembles the image build context with user-provided values and implementation defaults.
 func buildContext(buildDir, combustionDir, artefactsDir, configDir string, imageDefinition *image.Definition, artifactSources *image.ArtifactSources) *image.Context {
 	ctx := &image.Context{
@@ -0,0 +1,102 @@
+package build
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDecodeArtifactSources_ShippedConfig(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("..", "..", "..", "config", "artifacts.yaml"))
+	require.NoError(t, err)
+
+	sources, err := decodeArtifactSources(b)
+	require.NoError(t, err)
+
+	// Unknown fields indicate keys nested under the wrong parent
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+	require.NoError(t, decoder.Decode(&image.ArtifactSources{}))
+
+	assert.NotEmpty(t, sources.MetalLB.Chart)
+	assert.NotEmpty(t, sources.EndpointCopierOperator.Chart)
+	assert.Equal(t, "https://rpm.rancher.io/k3s/stable/common/slemicro/noarch", sources.Kubernetes.K3s.SELinuxRepository)
+	assert.Equal(t, "https://rpm.rancher.io/rke2/stable/common/slemicro/noarch", sources.Kubernetes.Rke2.SELinuxRepository)
+	assert.Equal(t, 3, sources.Downloads.Retries)
+}
+
+func TestParseImageDefinition_Overlays(t *testing.T) {
+	// The configuration directory is specified relative to the working directory
+	workDir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(workDir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+
+	configDir := filepath.Join(".", "cfg")
+	require.NoError(t, os.MkdirAll(filepath.Join(configDir, "sites"), os.ModePerm))
+
+	definition := `apiVersion: "1.1"
+image:
+  imageType: iso
+  arch: x86_64
+  baseImage: base.iso
+  outputImageName: eib-image.iso
+`
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "definition.yaml"), []byte(definition), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "sites", "site-a.yaml"), []byte("image:\n  outputImageName: site-a.iso\n"), 0o600))
+
+	absoluteOverlay := filepath.Join(t.TempDir(), "keymap.yaml")
+	require.NoError(t, os.WriteFile(absoluteOverlay, []byte("operatingSystem:\n  keymap: de\n"), 0o600))
+
+	imageDefinition, cmdErr := parseImageDefinition(configDir, "definition.yaml", "", []string{filepath.Join("sites", "site-a.yaml"), absoluteOverlay})
+	require.Nil(t, cmdErr)
+
+	assert.Equal(t, "site-a.iso", imageDefinition.Image.OutputImageName)
+	assert.Equal(t, "de", imageDefinition.OperatingSystem.Keymap)
+}
+
+func TestLoadImageDefinition_Positions(t *testing.T) {
+	configDir := t.TempDir()
+
+	definition := `apiVersion: "1.2"
+image:
+  imageType: iso
+  arch: ${ARCH}
+  baseImage: base.iso
+  outputImageName: eib-image.iso
+`
+	definitionPath := filepath.Join(configDir, "definition.yaml")
+	require.NoError(t, os.WriteFile(definitionPath, []byte(definition), 0o600))
+
+	overlayPath := filepath.Join(configDir, "site-a.yaml")
+	require.NoError(t, os.WriteFile(overlayPath, []byte("kubernetes:\n  nodes:\n    - hostname: node1.suse.com\n      type: master\n"), 0o600))
+
+	valuesPath := filepath.Join(configDir, "values.yaml")
+	require.NoError(t, os.WriteFile(valuesPath, []byte("ARCH: x86_64\n"), 0o600))
+
+	document, cmdErr := loadImageDefinition(configDir, "definition.yaml", valuesPath, []string{"site-a.yaml"})
+	require.Nil(t, cmdErr)
+
+	// Violations in overlays are located in the overlay file
+	cmdErr = validateDefinitionSchema(document)
+	require.NotNil(t, cmdErr)
+	assert.Contains(t, cmdErr.UserMessage, fmt.Sprintf("kubernetes.nodes[0].type (%s, line 4, column 13): must be one of: server, agent", overlayPath))
+
+	imageDefinition, cmdErr := decodeImageDefinition(definitionPath, document)
+	require.Nil(t, cmdErr)
+
+	// Interpolated values retain the position of their reference
+	assert.Equal(t, image.ArchTypeX86, imageDefinition.Image.Arch)
+	assert.Equal(t, image.Position{File: definitionPath, Line: 4, Column: 9}, imageDefinition.Locate("image.arch"))
+	assert.Equal(t, image.Position{File: overlayPath, Line: 3, Column: 7}, imageDefinition.Locate("kubernetes.nodes[0]"))
+}
@@ -0,0 +1,210 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/suse-edge/edge-image-builder/pkg/cache"
+	"github.com/suse-edge/edge-image-builder/pkg/cli/cmd"
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/log"
+	"github.com/urfave/cli/v2"
+)
+
+const cacheDirName = "cache"
+
+var CacheActions = &cmd.CacheActions{
+	List:   CacheList,
+	Prune:  CachePrune,
+	Verify: CacheVerify,
+	Export: CacheExport,
+	Import: CacheImport,
+}
+
+func CacheList(_ *cli.Context) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	entries, err := c.List()
+	if err != nil {
+		log.Audit("Listing the cache contents failed.")
+		return err
+	}
+
+	if len(entries) == 0 {
+		log.Audit("The cache is empty.")
+		return nil
+	}
+
+	log.Audit(formatEntries(entries))
+	log.Auditf("%d artefact(s), %s in total.", len(entries), formatSize(cache.TotalSize(entries)))
+
+	return nil
+}
+
+func CachePrune(_ *cli.Context) error {
+	args := &cmd.CacheArgs
+
+	if !args.All && args.Prefix == "" && args.UnusedFor == 0 {
+		log.Audit("At least one of '--all', '--prefix' or '--unused-for' must be specified.")
+		return fmt.Errorf("no prune criteria specified")
+	}
+
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().UTC().Add(-args.UnusedFor)
+
+	pruned, err := c.Prune(func(entry *cache.Entry) bool {
+		if args.All {
+			return true
+		}
+
+		if args.Prefix != "" && !strings.HasPrefix(entry.Identifier, args.Prefix) {
+			return false
+		}
+
+		if args.UnusedFor != 0 && entry.LastAccessed.After(cutoff) {
+			return false
+		}
+
+		return true
+	})
+	if err != nil {
+		log.Audit("Pruning the cache failed.")
+		return err
+	}
+
+	for _, entry := range pruned {
+		log.Auditf("Removed %s", entry.Identifier)
+	}
+	log.Auditf("%d artefact(s) removed from the cache.", len(pruned))
+
+	return nil
+}
+
+func CacheVerify(_ *cli.Context) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	corrupted, err := c.Verify()
+	if err != nil {
+		log.Audit("Verifying the cache failed.")
+		return err
+	}
+
+	if len(corrupted) == 0 {
+		log.Audit("All cached artefacts are valid.")
+		return nil
+	}
+
+	for _, entry := range corrupted {
+		log.Auditf("Removed corrupted artefact %s", entry.Identifier)
+	}
+
+	return fmt.Errorf("%d corrupted artefact(s) found", len(corrupted))
+}
+
+func CacheExport(_ *cli.Context) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	archivePath := cmd.CacheArgs.ArchiveFile
+
+	file, err := os.OpenFile(archivePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fileio.NonExecutablePerms)
+	if err != nil {
+		log.Auditf("The archive file '%s' could not be created.", archivePath)
+		return err
+	}
+	defer file.Close()
+
+	if err = c.Export(file); err != nil {
+		log.Audit("Exporting the cache failed.")
+		return err
+	}
+
+	log.Auditf("Cache exported to '%s'.", archivePath)
+	return nil
+}
+
+func CacheImport(_ *cli.Context) error {
+	c, err := openCache()
+	if err != nil {
+		return err
+	}
+
+	archivePath := cmd.CacheArgs.ArchiveFile
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		log.Auditf("The archive file '%s' could not be opened.", archivePath)
+		return err
+	}
+	defer file.Close()
+
+	imported, err := c.Import(file)
+	if err != nil {
+		log.Audit("Importing the cache failed.")
+		return err
+	}
+
+	for _, entry := range imported {
+		log.Auditf("Imported %s", entry.Identifier)
+	}
+	log.Auditf("%d artefact(s) imported into the cache.", len(imported))
+
+	return nil
+}
+
+func openCache() (*cache.Cache, error) {
+	cacheDir := filepath.Join(rootBuildDirectory(cmd.BuildArgs.ConfigDir, cmd.CacheArgs.RootBuildDir), cacheDirName)
+
+	c, err := cache.New(cacheDir, 0)
+	if err != nil {
+		log.Auditf("The cache directory '%s' could not be opened.", cacheDir)
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func formatEntries(entries []*cache.Entry) string {
+	var sb strings.Builder
+
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "IDENTIFIER\tSIZE\tLAST USED\tSOURCE")
+	for _, entry := range entries {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			entry.Identifier, formatSize(entry.Size), entry.LastAccessed.Format(time.DateTime), entry.Source)
+	}
+	_ = w.Flush()
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
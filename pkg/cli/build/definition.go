@@ -0,0 +1,67 @@
+package build
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/suse-edge/edge-image-builder/pkg/cli/cmd"
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/log"
+	"github.com/suse-edge/edge-image-builder/pkg/version"
+	"github.com/urfave/cli/v2"
+)
+
+var DefinitionActions = &cmd.DefinitionActions{
+	Upgrade: DefinitionUpgrade,
+}
+
+func DefinitionUpgrade(_ *cli.Context) error {
+	definitionPath := filepath.Join(cmd.BuildArgs.ConfigDir, cmd.BuildArgs.DefinitionFile)
+
+	data, err := os.ReadFile(definitionPath)
+	if err != nil {
+		log.Auditf("The definition file '%s' could not be read.", definitionPath)
+		return err
+	}
+
+	upgraded, changes, err := image.UpgradeDefinition(data)
+	if err != nil {
+		if errors.Is(err, image.ErrorInvalidSchemaVersion) {
+			log.Auditf("The definition file '%s' does not specify a supported schema version.", definitionPath)
+		} else {
+			log.Auditf("The definition file '%s' could not be upgraded.", definitionPath)
+		}
+		return err
+	}
+
+	if len(changes) == 0 {
+		log.Auditf("The definition file '%s' already uses schema version %s.", definitionPath, version.LatestSchemaVersion)
+		return nil
+	}
+
+	// Ensure the result is a valid definition before anything is overwritten
+	if _, err = image.ParseDefinition(upgraded); err != nil {
+		log.Audit("The upgraded definition could not be parsed.")
+		return fmt.Errorf("parsing upgraded definition: %w", err)
+	}
+
+	outputPath := cmd.DefinitionArgs.OutputFile
+	if outputPath == "" {
+		outputPath = definitionPath
+	}
+
+	if err = os.WriteFile(outputPath, upgraded, fileio.NonExecutablePerms); err != nil {
+		log.Auditf("The upgraded definition could not be written to '%s'.", outputPath)
+		return err
+	}
+
+	for _, change := range changes {
+		log.Audit(change)
+	}
+	log.Auditf("Definition upgraded to schema version %s and written to '%s'.", version.LatestSchemaVersion, outputPath)
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package build
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/cli/cmd"
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/image/schema"
+	"github.com/suse-edge/edge-image-builder/pkg/log"
+	"github.com/suse-edge/edge-image-builder/pkg/version"
+	"github.com/urfave/cli/v2"
+)
+
+var SchemaActions = &cmd.SchemaActions{
+	Export: SchemaExport,
+}
+
+func SchemaExport(_ *cli.Context) error {
+	args := &cmd.SchemaArgs
+
+	data, err := schema.Export(args.Version)
+	if err != nil {
+		if errors.Is(err, image.ErrorInvalidSchemaVersion) {
+			log.Auditf("Schema version '%s' is not supported. Supported schema versions: %s",
+				args.Version, strings.Join(version.SupportedSchemaVersions, ", "))
+		} else {
+			log.Audit("The schema could not be generated.")
+		}
+		return err
+	}
+
+	if args.OutputFile == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	if err = os.WriteFile(args.OutputFile, data, fileio.NonExecutablePerms); err != nil {
+		log.Auditf("The schema could not be written to '%s'.", args.OutputFile)
+		return err
+	}
+
+	log.Auditf("Schema version %s exported to '%s'.", args.Version, args.OutputFile)
+	return nil
+}
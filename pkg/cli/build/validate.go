@@ -10,13 +10,16 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/cli/cmd"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/image/schema"
 	"github.com/suse-edge/edge-image-builder/pkg/image/validation"
 	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/urfave/cli/v2"
+	"go.uber.org/zap"
 )
 
 const (
 	checkValidationLogMessage = "Please check the log file under the validation directory for more information."
+	schemaComponent           = "Schema"
 )
 
 func Validate(_ *cli.Context) error {
@@ -42,7 +45,18 @@ func Validate(_ *cli.Context) error {
 
 	log.AuditInfo("Parsing image definition...")
 
-	imageDefinition, err := parseImageDefinition(args.ConfigDir, args.DefinitionFile)
+	document, err := loadImageDefinition(args.ConfigDir, args.DefinitionFile, args.ValuesFile, args.Overlays.Value())
+	if err != nil {
+		cmd.LogError(err, checkValidationLogMessage)
+		os.Exit(1)
+	}
+
+	if err = validateDefinitionSchema(document); err != nil {
+		cmd.LogError(err, checkValidationLogMessage)
+		os.Exit(1)
+	}
+
+	imageDefinition, err := decodeImageDefinition(filepath.Join(args.ConfigDir, args.DefinitionFile), document)
 	if err != nil {
 		cmd.LogError(err, checkValidationLogMessage)
 		os.Exit(1)
@@ -55,19 +69,76 @@ func Validate(_ *cli.Context) error {
 
 	log.AuditInfo("Validating image definition...")
 
-	if err = validateImageDefinition(ctx); err != nil {
+	if err = validateImageDefinition(ctx, args.Strict); err != nil {
 		cmd.LogError(err, checkValidationLogMessage)
 		os.Exit(1)
 	}
 
 	log.AuditInfo("The specified image definition is valid.")
+	log.AuditResult(nil)
 
 	return nil
 }
 
-func validateImageDefinition(ctx *image.Context) *cmd.Error {
+// validateDefinitionSchema checks the structure of the image definition against the schema of its version,
+// locating every mismatching field before the definition is parsed.
+func validateDefinitionSchema(document *image.Document) *cmd.Error {
+	violations, err := schema.Validate(document)
+	if err != nil {
+		// The definition could not be decoded, which is reported in detail once it is parsed
+		zap.S().Warnf("Validating the image definition against the schema failed: %v", err)
+		return nil
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	logMessageBuilder := strings.Builder{}
+	userMessageBuilder := strings.Builder{}
+
+	userMessageBuilder.WriteString("Image definition does not match the schema:\n")
+	logMessageBuilder.WriteString("Image definition schema violations:\n")
+
+	for _, v := range violations {
+		userMessageBuilder.WriteString("  " + v.String() + "\n")
+		logMessageBuilder.WriteString("  " + v.String() + "\n")
+
+		log.AuditValidationFailure(schemaComponent, v.Message, validation.SeverityError.String(), &log.Location{Path: v.Path, File: v.File, Line: v.Line, Column: v.Column})
+	}
+
+	return &cmd.Error{
+		UserMessage: userMessageBuilder.String(),
+		LogMessage:  logMessageBuilder.String(),
+	}
+}
+
+// validateImageDefinition reports all warnings found in the image definition and returns an error
+// listing the failed validations, which include the warnings in strict mode.
+func validateImageDefinition(ctx *image.Context, strict bool) *cmd.Error {
 	failedValidations := validation.ValidateDefinition(ctx)
-	if len(failedValidations) == 0 {
+
+	failures := map[string][]validation.FailedValidation{}
+	warnings := map[string][]validation.FailedValidation{}
+
+	for componentName, componentFailures := range failedValidations {
+		for _, cf := range componentFailures {
+			if cf.Severity == validation.SeverityWarning && !strict {
+				warnings[componentName] = append(warnings[componentName], cf)
+			} else {
+				failures[componentName] = append(failures[componentName], cf)
+			}
+		}
+	}
+
+	for _, componentName := range sortedComponentNames(warnings) {
+		for _, cf := range warnings[componentName] {
+			log.AuditWarningf("%s: %s", componentName, formatFailedValidation(cf))
+			log.AuditValidationFailure(componentName, cf.UserMessage, cf.Severity.String(), failureLocation(cf))
+		}
+	}
+
+	if len(failures) == 0 {
 		return nil
 	}
 
@@ -77,21 +148,22 @@ func validateImageDefinition(ctx *image.Context) *cmd.Error {
 	userMessageBuilder.WriteString("Image definition validation found the following errors:\n")
 	logMessageBuilder.WriteString("Image definition validation failures:\n")
 
-	orderedComponentNames := make([]string, 0, len(failedValidations))
-	for c := range failedValidations {
-		orderedComponentNames = append(orderedComponentNames, c)
-	}
-	slices.Sort(orderedComponentNames)
-
-	for _, componentName := range orderedComponentNames {
+	for _, componentName := range sortedComponentNames(failures) {
 		userMessageBuilder.WriteString("  " + componentName + "\n")
 
-		for _, cf := range failedValidations[componentName] {
-			userMessageBuilder.WriteString("    " + cf.UserMessage + "\n")
-			logMessageBuilder.WriteString("  " + cf.UserMessage + "\n")
+		for _, cf := range failures[componentName] {
+			message := formatFailedValidation(cf)
+			if cf.Severity == validation.SeverityWarning {
+				message = "(strict) " + message
+			}
+
+			userMessageBuilder.WriteString("    " + message + "\n")
+			logMessageBuilder.WriteString("  " + message + "\n")
 			if cf.Error != nil {
 				logMessageBuilder.WriteString("    " + cf.Error.Error() + "\n")
 			}
+
+			log.AuditValidationFailure(componentName, cf.UserMessage, cf.Severity.String(), failureLocation(cf))
 		}
 	}
 
@@ -100,3 +172,34 @@ func validateImageDefinition(ctx *image.Context) *cmd.Error {
 		LogMessage:  logMessageBuilder.String(),
 	}
 }
+
+func sortedComponentNames(failedValidations map[string][]validation.FailedValidation) []string {
+	names := make([]string, 0, len(failedValidations))
+	for c := range failedValidations {
+		names = append(names, c)
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+func formatFailedValidation(failure validation.FailedValidation) string {
+	if !failure.Position.IsKnown() {
+		return failure.UserMessage
+	}
+
+	return fmt.Sprintf("%s (%s)", failure.UserMessage, failure.Position)
+}
+
+func failureLocation(failure validation.FailedValidation) *log.Location {
+	if failure.Path == "" {
+		return nil
+	}
+
+	return &log.Location{
+		Path:   failure.Path,
+		File:   failure.Position.File,
+		Line:   failure.Position.Line,
+		Column: failure.Position.Column,
+	}
+}
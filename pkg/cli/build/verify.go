@@ -0,0 +1,52 @@
+package build
+
+import (
+	"crypto"
+
+	"github.com/suse-edge/edge-image-builder/pkg/attestation"
+	"github.com/suse-edge/edge-image-builder/pkg/cli/cmd"
+	"github.com/suse-edge/edge-image-builder/pkg/log"
+	"github.com/urfave/cli/v2"
+)
+
+func Verify(_ *cli.Context) error {
+	args := &cmd.VerifyArgs
+
+	var key crypto.PublicKey
+	if args.PublicKey != "" {
+		var err error
+		if key, err = attestation.LoadPublicKey(args.PublicKey); err != nil {
+			log.Auditf("The public key '%s' could not be loaded.", args.PublicKey)
+			log.AuditResult(err)
+			return err
+		}
+	}
+
+	verification, err := attestation.Verify(args.Image, key, args.DefinitionFile)
+	if err != nil {
+		log.Auditf("Verification of the image '%s' failed.", args.Image)
+		log.AuditResult(err)
+		return err
+	}
+
+	for _, s := range verification.Subjects {
+		log.Auditf("Verified %s", s.Name)
+	}
+
+	provenance := verification.Provenance
+	log.Auditf("Built by Edge Image Builder %s from a definition file with SHA-256 digest %s.",
+		provenance.EIBVersion(), provenance.DefinitionDigest())
+
+	if args.DefinitionFile != "" {
+		log.Auditf("The definition file '%s' matches the provenance.", args.DefinitionFile)
+	}
+
+	if verification.Signed {
+		log.Audit("All signatures are valid.")
+	} else {
+		log.AuditWarning("Signatures have not been verified since no public key was specified.")
+	}
+
+	log.AuditResult(nil)
+	return nil
+}
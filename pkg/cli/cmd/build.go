@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/urfave/cli/v2"
 )
@@ -9,7 +10,15 @@ import (
 type BuildFlags struct {
 	DefinitionFile string
 	ConfigDir      string
+	ValuesFile     string
+	Overlays       cli.StringSlice
 	RootBuildDir   string
+	CacheMaxSize   int64
+	Offline        bool
+	Strict         bool
+	Parallelism    int
+	StageTimeout   time.Duration
+	NoReuse        bool
 }
 
 var BuildArgs BuildFlags
@@ -23,11 +32,40 @@ func NewBuildCommand(action func(*cli.Context) error) *cli.Command {
 		Flags: []cli.Flag{
 			DefinitionFileFlag,
 			ConfigDirFlag,
+			ValuesFileFlag,
+			OverlayFlag,
+			StrictFlag,
 			&cli.StringFlag{
 				Name:        "build-dir",
 				Usage:       "Full path to the directory to store build artifacts",
 				Destination: &BuildArgs.RootBuildDir,
 			},
+			&cli.Int64Flag{
+				Name:        "cache-max-size",
+				Usage:       "Maximum size of the artefact cache in MiB, least recently used entries are evicted when exceeded (0 for unlimited)",
+				Destination: &BuildArgs.CacheMaxSize,
+			},
+			&cli.BoolFlag{
+				Name:        "offline",
+				Usage:       "Disable all network access and retrieve external inputs from the artefact cache only",
+				Destination: &BuildArgs.Offline,
+			},
+			&cli.IntFlag{
+				Name:        "parallelism",
+				Usage:       "Maximum number of Combustion components configured concurrently (1 to configure them sequentially)",
+				Value:       4,
+				Destination: &BuildArgs.Parallelism,
+			},
+			&cli.DurationFlag{
+				Name:        "stage-timeout",
+				Usage:       "Maximum duration of each build stage, e.g. the generation of the customization components or the build of an image (0 for unlimited)",
+				Destination: &BuildArgs.StageTimeout,
+			},
+			&cli.BoolFlag{
+				Name:        "no-reuse",
+				Usage:       "Configure all customization components instead of reusing the unchanged ones from previous builds",
+				Destination: &BuildArgs.NoReuse,
+			},
 		},
 	}
 }
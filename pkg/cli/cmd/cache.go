@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+type CacheFlags struct {
+	RootBuildDir string
+	ArchiveFile  string
+	Prefix       string
+	UnusedFor    time.Duration
+	All          bool
+}
+
+var CacheArgs CacheFlags
+
+type CacheActions struct {
+	List   func(*cli.Context) error
+	Prune  func(*cli.Context) error
+	Verify func(*cli.Context) error
+	Export func(*cli.Context) error
+	Import func(*cli.Context) error
+}
+
+func NewCacheCommand(actions *CacheActions) *cli.Command {
+	buildDirFlag := &cli.StringFlag{
+		Name:        "build-dir",
+		Usage:       "Full path to the build directory containing the cache (defaults to '_build' under the configuration directory)",
+		Destination: &CacheArgs.RootBuildDir,
+	}
+
+	archiveFlag := &cli.StringFlag{
+		Name:        "file",
+		Usage:       "Full path to the cache archive",
+		Required:    true,
+		Destination: &CacheArgs.ArchiveFile,
+	}
+
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "Manage the artefact cache",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "list",
+				Usage:     "List cached artefacts",
+				UsageText: fmt.Sprintf("%s cache list [OPTIONS]", appName),
+				Action:    actions.List,
+				Flags:     []cli.Flag{ConfigDirFlag, buildDirFlag},
+			},
+			{
+				Name:      "prune",
+				Usage:     "Remove cached artefacts",
+				UsageText: fmt.Sprintf("%s cache prune [OPTIONS]", appName),
+				Action:    actions.Prune,
+				Flags: []cli.Flag{
+					ConfigDirFlag,
+					buildDirFlag,
+					&cli.StringFlag{
+						Name:        "prefix",
+						Usage:       "Remove artefacts whose identifier starts with the given prefix (e.g. 'v1.28.9+rke2r1/')",
+						Destination: &CacheArgs.Prefix,
+					},
+					&cli.DurationFlag{
+						Name:        "unused-for",
+						Usage:       "Remove artefacts which have not been used for the given duration (e.g. '720h')",
+						Destination: &CacheArgs.UnusedFor,
+					},
+					&cli.BoolFlag{
+						Name:        "all",
+						Usage:       "Remove all artefacts",
+						Destination: &CacheArgs.All,
+					},
+				},
+			},
+			{
+				Name:      "verify",
+				Usage:     "Verify the integrity of cached artefacts, removing corrupted ones",
+				UsageText: fmt.Sprintf("%s cache verify [OPTIONS]", appName),
+				Action:    actions.Verify,
+				Flags:     []cli.Flag{ConfigDirFlag, buildDirFlag},
+			},
+			{
+				Name:      "export",
+				Usage:     "Export cached artefacts to an archive",
+				UsageText: fmt.Sprintf("%s cache export --file <archive> [OPTIONS]", appName),
+				Action:    actions.Export,
+				Flags:     []cli.Flag{ConfigDirFlag, buildDirFlag, archiveFlag},
+			},
+			{
+				Name:      "import",
+				Usage:     "Import cached artefacts from an archive",
+				UsageText: fmt.Sprintf("%s cache import --file <archive> [OPTIONS]", appName),
+				Action:    actions.Import,
+				Flags:     []cli.Flag{ConfigDirFlag, buildDirFlag, archiveFlag},
+			},
+		},
+	}
+}
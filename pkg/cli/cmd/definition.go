@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+type DefinitionFlags struct {
+	OutputFile string
+}
+
+var DefinitionArgs DefinitionFlags
+
+type DefinitionActions struct {
+	Upgrade func(*cli.Context) error
+}
+
+func NewDefinitionCommand(actions *DefinitionActions) *cli.Command {
+	return &cli.Command{
+		Name:  "definition",
+		Usage: "Manage image definition files",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "upgrade",
+				Usage:     "Rewrite the image definition to the latest schema version",
+				UsageText: fmt.Sprintf("%s definition upgrade --definition-file <file> [OPTIONS]", appName),
+				Action:    actions.Upgrade,
+				Flags: []cli.Flag{
+					DefinitionFileFlag,
+					ConfigDirFlag,
+					&cli.StringFlag{
+						Name:        "output",
+						Usage:       "Full path to the file the upgraded definition is written to (defaults to overwriting the definition file)",
+						Destination: &DefinitionArgs.OutputFile,
+					},
+				},
+			},
+		},
+	}
+}
@@ -11,7 +11,10 @@ type Error struct {
 	LogMessage  string
 }
 
+// LogError reports an error which terminates the command.
 func LogError(err *Error, checkLogMessage string) {
+	defer log.AuditResult(err)
+
 	if err.LogMessage == "" {
 		log.AuditError(err.UserMessage)
 		return
@@ -21,3 +24,7 @@ func LogError(err *Error, checkLogMessage string) {
 	log.Audit(checkLogMessage)
 	zap.S().Error(err.LogMessage)
 }
+
+func (e *Error) Error() string {
+	return e.UserMessage
+}
@@ -14,4 +14,19 @@ var (
 		Value:       "/eib",
 		Destination: &BuildArgs.ConfigDir,
 	}
+	ValuesFileFlag = &cli.StringFlag{
+		Name:        "values",
+		Usage:       "Path to a YAML file of variables interpolated into the image definition",
+		Destination: &BuildArgs.ValuesFile,
+	}
+	OverlayFlag = &cli.StringSliceFlag{
+		Name:        "overlay",
+		Usage:       "Name of an overlay file merged into the image definition, may be repeated to apply multiple overlays in order",
+		Destination: &BuildArgs.Overlays,
+	}
+	StrictFlag = &cli.BoolFlag{
+		Name:        "strict",
+		Usage:       "Treat image definition validation warnings as errors",
+		Destination: &BuildArgs.Strict,
+	}
 )
@@ -1,18 +1,37 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/urfave/cli/v2"
 )
 
 var appName = filepath.Base(os.Args[0])
 
+type GlobalFlags struct {
+	Output string
+}
+
+var GlobalArgs GlobalFlags
+
 func NewApp() *cli.App {
 	app := cli.NewApp()
 	app.Name = appName
 	app.Usage = "Edge Image Builder"
+	app.Flags = []cli.Flag{
+		&cli.StringFlag{
+			Name:        "output",
+			Usage:       fmt.Sprintf("Format of the messages displayed to the user, either '%s' or '%s'", log.OutputFormatHuman, log.OutputFormatJSON),
+			Value:       log.OutputFormatHuman,
+			Destination: &GlobalArgs.Output,
+		},
+	}
+	app.Before = func(_ *cli.Context) error {
+		return log.SetOutputFormat(GlobalArgs.Output)
+	}
 
 	return app
 }
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/suse-edge/edge-image-builder/pkg/version"
+	"github.com/urfave/cli/v2"
+)
+
+type SchemaFlags struct {
+	Version    string
+	OutputFile string
+}
+
+var SchemaArgs SchemaFlags
+
+type SchemaActions struct {
+	Export func(*cli.Context) error
+}
+
+func NewSchemaCommand(actions *SchemaActions) *cli.Command {
+	return &cli.Command{
+		Name:  "schema",
+		Usage: "Inspect the image definition schema",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "export",
+				Usage:     "Export the JSON Schema of the image definition",
+				UsageText: fmt.Sprintf("%s schema export [OPTIONS]", appName),
+				Action:    actions.Export,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        "version",
+						Usage:       "Schema version of the image definition to describe",
+						Value:       version.LatestSchemaVersion,
+						Destination: &SchemaArgs.Version,
+					},
+					&cli.StringFlag{
+						Name:        "file",
+						Usage:       "Full path to the file the schema is written to (defaults to the standard output)",
+						Destination: &SchemaArgs.OutputFile,
+					},
+				},
+			},
+		},
+	}
+}
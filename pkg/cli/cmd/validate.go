@@ -15,6 +15,9 @@ func NewValidateCommand(action func(*cli.Context) error) *cli.Command {
 		Flags: []cli.Flag{
 			DefinitionFileFlag,
 			ConfigDirFlag,
+			ValuesFileFlag,
+			OverlayFlag,
+			StrictFlag,
 		},
 	}
 }
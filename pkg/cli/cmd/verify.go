@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+)
+
+type VerifyFlags struct {
+	Image          string
+	PublicKey      string
+	DefinitionFile string
+}
+
+var VerifyArgs VerifyFlags
+
+func NewVerifyCommand(action func(*cli.Context) error) *cli.Command {
+	return &cli.Command{
+		Name:      "verify",
+		Usage:     "Verify the checksums, provenance and signatures of a built image",
+		UsageText: fmt.Sprintf("%s verify --image <path> [OPTIONS]", appName),
+		Action:    action,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "image",
+				Usage:       "Full path to the built image",
+				Required:    true,
+				Destination: &VerifyArgs.Image,
+			},
+			&cli.StringFlag{
+				Name:        "public-key",
+				Usage:       "Full path to the PEM encoded public key matching the signing key; signatures are not verified if unspecified",
+				Destination: &VerifyArgs.PublicKey,
+			},
+			&cli.StringFlag{
+				Name:        "definition-file",
+				Usage:       "Full path to the image definition file expected to have produced the image",
+				Destination: &VerifyArgs.DefinitionFile,
+			},
+		},
+	}
+}
@@ -8,7 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 	"go.uber.org/zap"
 )
@@ -24,22 +23,22 @@ var certsScriptTemplate string
 
 func configureCertificates(ctx *image.Context) ([]string, error) {
 	if !isComponentConfigured(ctx, certsConfigDir) {
-		log.AuditComponentSkipped(certsComponentName)
+		ctx.Output.AuditComponentSkipped(certsComponentName)
 		zap.S().Info("skipping certificate configuration, no certificates provided")
 		return nil, nil
 	}
 
 	if err := copyCertificates(ctx); err != nil {
-		log.AuditComponentFailed(certsComponentName)
+		ctx.Output.AuditComponentFailed(certsComponentName)
 		return nil, err
 	}
 
 	if err := writeCertificatesScript(ctx); err != nil {
-		log.AuditComponentFailed(certsComponentName)
+		ctx.Output.AuditComponentFailed(certsComponentName)
 		return nil, err
 	}
 
-	log.AuditComponentSuccessful(certsComponentName)
+	ctx.Output.AuditComponentSuccessful(certsComponentName)
 	return []string{certsScriptName}, nil
 }
 
@@ -8,7 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"go.uber.org/zap"
 )
 
@@ -21,9 +20,13 @@ const (
 var cleanupScript string
 
 func configureCleanup(ctx *image.Context) ([]string, error) {
-	if ctx.ImageDefinition.Image.ImageType != image.TypeRAW {
-		log.AuditComponentSkipped(cleanupComponentName)
-		zap.S().Info("skipping cleanup component, image type is not raw")
+	isDiskImage := func(target image.Image) bool {
+		return target.IsDiskImage()
+	}
+
+	if !ctx.ImageDefinition.Image.HasTarget(isDiskImage) {
+		ctx.Output.AuditComponentSkipped(cleanupComponentName)
+		zap.S().Info("skipping cleanup component, no image is built from a raw image")
 		return nil, nil
 	}
 
@@ -32,6 +35,6 @@ func configureCleanup(ctx *image.Context) ([]string, error) {
 		return nil, fmt.Errorf("writing cleanup files script %s: %w", cleanupScriptName, err)
 	}
 
-	log.AuditComponentSuccessful(cleanupComponentName)
+	ctx.Output.AuditComponentSuccessful(cleanupComponentName)
 	return []string{cleanupScriptName}, nil
 }
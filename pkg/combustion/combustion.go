@@ -1,6 +1,7 @@
 package combustion
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -10,21 +11,20 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/registry"
 	"go.uber.org/zap"
 )
 
-// configureComponent defines the combustion component contract.
+// ConfigureComponent defines the combustion component contract.
 // Each component (e.g. "users") receives the necessary dir structure and
 // additional values it should be operating with through a Context object.
 //
-// configureComponent returns a slice of scripts which should be executed as part of the Combustion script.
+// ConfigureComponent returns a slice of scripts which should be executed as part of the Combustion script.
 // Result can also be an empty slice or nil if this is not necessary.
-type configureComponent func(context *image.Context) ([]string, error)
+type ConfigureComponent func(context *image.Context) ([]string, error)
 
 type networkConfigGenerator interface {
-	GenerateNetworkConfig(configDir, outputDir string, outputWriter io.Writer) error
+	GenerateNetworkConfig(ctx context.Context, configDir, outputDir string, outputWriter io.Writer) error
 }
 
 type networkConfiguratorInstaller interface {
@@ -32,28 +32,35 @@ type networkConfiguratorInstaller interface {
 }
 
 type kubernetesScriptDownloader interface {
-	DownloadInstallScript(distribution, destinationPath string) (string, error)
+	DownloadInstallScript(ctx context.Context, distribution, destinationPath string) (string, error)
 }
 
 type kubernetesArtefactDownloader interface {
-	DownloadRKE2Artefacts(arch image.Arch, version, cni string, multusEnabled bool, installPath, imagesPath string) error
-	DownloadK3sArtefacts(arch image.Arch, version, installPath, imagesPath string) error
+	DownloadRKE2Artefacts(ctx context.Context, arch image.Arch, version, cni string, multusEnabled bool, installPath, imagesPath string) error
+	DownloadK3sArtefacts(ctx context.Context, arch image.Arch, version, installPath, imagesPath string) error
 }
 
 type rpmResolver interface {
-	Resolve(packages *image.Packages, localRPMConfig *image.LocalRPMConfig, outputDir string) (rpmDirPath string, pkgList []string, err error)
+	Resolve(ctx context.Context, packages *image.Packages, localRPMConfig *image.LocalRPMConfig, outputDir string) (rpmDirPath string, pkgList []string, err error)
 }
 
 type rpmRepoCreator interface {
-	Create(path string) error
+	Create(ctx context.Context, path string) error
 }
 
 type embeddedRegistry interface {
 	ManifestsPath() string
-	ContainerImages() ([]string, error)
+	ContainerImages(ctx context.Context) ([]string, error)
 	HelmCharts() ([]*registry.HelmCRD, error)
 }
 
+type artefactCache interface {
+	CopyTo(identifier, dest string, perms os.FileMode) error
+	Contains(identifier string) bool
+	Put(identifier, source string, reader io.Reader) error
+	Remove(identifier string) error
+}
+
 type Combustion struct {
 	NetworkConfigGenerator       networkConfigGenerator
 	NetworkConfiguratorInstaller networkConfiguratorInstaller
@@ -62,134 +69,209 @@ type Combustion struct {
 	RPMResolver                  rpmResolver
 	RPMRepoCreator               rpmRepoCreator
 	Registry                     embeddedRegistry
+	Cache                        artefactCache
+	// Store is optional and keeps the outputs of the components for subsequent builds.
+	Store *ComponentStore
 }
 
-// Configure iterates over all separate Combustion components and configures them independently.
-// If all of those are successful, the Combustion script is assembled and written to the file system.
+// Configure iterates over all separate Combustion components and configures them independently,
+// concurrently up to the parallelism of the context. If all of those are successful, the Combustion script is assembled and written to the file system.
 func (c *Combustion) Configure(ctx *image.Context) error {
-	var combustionScripts []string
-
-	// EIB Combustion script prefix ranges:
-	// 00-09 -- Networking
-	// 10-19 -- Operating System
-	// 20-24 -- Kubernetes
-	// 25-29 -- User Workloads
-	// 30-39 -- SUSE Product Integration
-	// 40-49 -- Miscellaneous
-
-	// Component order rationale:
-	// - Message has no effect on the system, so this can go anywhere
-	// - Custom scripts should be early to allow the most flexibility in the user
-	//   being able to override/preempt the built-in behavior
-	// - Elemental & SUMA must come after RPMs since the user must provide the
-	//   elemental and venv-salt-minion RPMs manually
-	type componentWrapper struct {
-		name     string
-		runnable configureComponent
+	graph, err := newComponentGraph(c.components())
+	if err != nil {
+		return fmt.Errorf("registering components: %w", err)
+	}
+
+	components, err := graph.order()
+	if err != nil {
+		return fmt.Errorf("ordering components: %w", err)
+	}
+
+	componentScripts, err := configureComponents(ctx, components, c.Store)
+	if err != nil {
+		return err
+	}
+
+	var networkScript string
+	if isComponentConfigured(ctx, networkConfigDir) {
+		networkScript = networkConfigScriptName
+	}
+
+	script, err := assembleScript(graph.orderScripts(componentScripts), networkScript)
+	if err != nil {
+		return fmt.Errorf("assembling script: %w", err)
+	}
+
+	filename := filepath.Join(ctx.CombustionDir, "script")
+	if err = os.WriteFile(filename, []byte(script), fileio.ExecutablePerms); err != nil {
+		return fmt.Errorf("writing script: %w", err)
 	}
-	combustionComponents := []componentWrapper{
+
+	return nil
+}
+
+// components lists the built-in components followed by the registered extension components.
+//
+// EIB Combustion script prefix ranges:
+// 00-09 -- Networking
+// 10-19 -- Operating System
+// 20-24 -- Kubernetes
+// 25-29 -- User Workloads
+// 30-39 -- SUSE Product Integration
+// 40-49 -- Miscellaneous
+//
+// Component order rationale:
+//   - Message has no effect on the system, so this can go anywhere
+//   - Custom scripts should be early to allow the most flexibility in the user
+//     being able to override/preempt the built-in behavior
+//   - Groups must exist before the users which are assigned to them
+//   - Elemental, SUMA, FIPS and Kubernetes must come after RPMs since they rely on
+//     packages installed by it (e.g. the elemental and venv-salt-minion RPMs provided by the user)
+//   - Cleanup removes the Combustion files, so it always comes last
+func (c *Combustion) components() []Component {
+	components := []Component{
 		{
-			name:     messageComponentName,
-			runnable: configureMessage,
+			Name:      messageComponentName,
+			Configure: configureMessage,
+			Inputs:    &ComponentInputs{},
 		},
 		{
-			name:     customComponentName,
-			runnable: configureCustomFiles,
+			Name:      customComponentName,
+			Configure: configureCustomFiles,
+			Inputs: &ComponentInputs{
+				Definition:  func(d *image.Definition) any { return d.NodeOverrides },
+				ConfigPaths: []string{customDir, nodeOverridesDir},
+			},
 		},
 		{
-			name:     timeComponentName,
-			runnable: configureTime,
+			Name:      timeComponentName,
+			Configure: configureTime,
+			Inputs: &ComponentInputs{
+				Definition: func(d *image.Definition) any { return d.OperatingSystem.Time },
+			},
 		},
 		{
-			name:     networkComponentName,
-			runnable: c.configureNetwork,
+			Name:      networkComponentName,
+			Configure: c.configureNetwork,
+			Inputs:    &ComponentInputs{ConfigPaths: []string{networkConfigDir}},
 		},
 		{
-			name:     groupsComponentName,
-			runnable: configureGroups,
+			Name:      groupsComponentName,
+			Configure: configureGroups,
+			Before:    []string{usersComponentName},
+			Inputs: &ComponentInputs{
+				Definition: func(d *image.Definition) any { return d.OperatingSystem.Groups },
+			},
 		},
 		{
-			name:     usersComponentName,
-			runnable: configureUsers,
+			Name:      usersComponentName,
+			Configure: configureUsers,
+			Inputs: &ComponentInputs{
+				Definition: func(d *image.Definition) any {
+					return []any{d.OperatingSystem.Users, d.NodeOverrides}
+				},
+			},
 		},
 		{
-			name:     proxyComponentName,
-			runnable: configureProxy,
+			Name:      proxyComponentName,
+			Configure: configureProxy,
+			Inputs: &ComponentInputs{
+				Definition: func(d *image.Definition) any { return d.OperatingSystem.Proxy },
+			},
 		},
 		{
-			name:     rpmComponentName,
-			runnable: c.configureRPMs,
+			Name:      rpmComponentName,
+			Configure: c.configureRPMs,
+			Inputs: &ComponentInputs{
+				Definition:  func(d *image.Definition) any { return d.OperatingSystem.Packages },
+				ConfigPaths: []string{rpmDir},
+				BaseImage:   true,
+			},
 		},
 		{
-			name:     osFilesComponentName,
-			runnable: configureOSFiles,
+			Name:      osFilesComponentName,
+			Configure: configureOSFiles,
+			Inputs: &ComponentInputs{
+				Definition:  func(d *image.Definition) any { return d.NodeOverrides },
+				ConfigPaths: []string{osFilesConfigDir, nodeOverridesDir},
+			},
 		},
 		{
-			name:     systemdComponentName,
-			runnable: configureSystemd,
+			Name:      systemdComponentName,
+			Configure: configureSystemd,
+			Inputs: &ComponentInputs{
+				Definition: func(d *image.Definition) any {
+					return []any{d.OperatingSystem.Systemd, d.NodeOverrides}
+				},
+			},
 		},
 		{
-			name:     fipsComponentName,
-			runnable: configureFips,
+			Name:      fipsComponentName,
+			Configure: configureFips,
+			After:     []string{rpmComponentName},
+			Inputs: &ComponentInputs{
+				Definition: func(d *image.Definition) any { return d.OperatingSystem.EnableFips },
+			},
 		},
 		{
-			name:     elementalComponentName,
-			runnable: configureElemental,
+			Name:      elementalComponentName,
+			Configure: configureElemental,
+			After:     []string{rpmComponentName},
+			Inputs:    &ComponentInputs{ConfigPaths: []string{elementalConfigDir}},
 		},
 		{
-			name:     sumaComponentName,
-			runnable: configureSuma,
+			Name:      sumaComponentName,
+			Configure: configureSuma,
+			After:     []string{rpmComponentName},
+			Inputs: &ComponentInputs{
+				Definition: func(d *image.Definition) any { return d.OperatingSystem.Suma },
+			},
 		},
 		{
-			name:     registryComponentName,
-			runnable: c.configureRegistry,
+			Name:      registryComponentName,
+			Configure: c.configureRegistry,
+			Inputs: &ComponentInputs{
+				Definition: func(d *image.Definition) any {
+					return []any{d.EmbeddedArtifactRegistry, d.Kubernetes}
+				},
+				ConfigPaths: []string{k8sDir},
+			},
 		},
 		{
-			name:     keymapComponentName,
-			runnable: configureKeymap,
+			Name:      keymapComponentName,
+			Configure: configureKeymap,
+			Inputs: &ComponentInputs{
+				Definition: func(d *image.Definition) any { return d.OperatingSystem },
+			},
 		},
 		{
-			name:     k8sComponentName,
-			runnable: c.configureKubernetes,
+			Name:      k8sComponentName,
+			Configure: c.configureKubernetes,
+			After:     []string{rpmComponentName},
+			Inputs: &ComponentInputs{
+				Definition:      func(d *image.Definition) any { return d.Kubernetes },
+				ConfigPaths:     []string{k8sDir},
+				ArtifactSources: true,
+			},
 		},
 		{
-			name:     certsComponentName,
-			runnable: configureCertificates,
+			Name:      certsComponentName,
+			Configure: configureCertificates,
+			Inputs:    &ComponentInputs{ConfigPaths: []string{certsConfigDir}},
 		},
 	}
 
-	for _, component := range combustionComponents {
-		scripts, err := component.runnable(ctx)
-		if err != nil {
-			return fmt.Errorf("configuring component %q: %w", component.name, err)
-		}
+	components = append(components, extensionComponents()...)
 
-		combustionScripts = append(combustionScripts, scripts...)
+	cleanup := Component{
+		Name:      cleanupComponentName,
+		Configure: configureCleanup,
 	}
-
-	// We manually add the cleanup component as to always make sure it is last
-	s, err := configureCleanup(ctx)
-	if err != nil {
-		return fmt.Errorf("configuring cleanup component %q: %w", cleanupComponentName, err)
+	for _, component := range components {
+		cleanup.After = append(cleanup.After, component.Name)
 	}
-	combustionScripts = append(combustionScripts, s...)
 
-	var networkScript string
-	if isComponentConfigured(ctx, networkConfigDir) {
-		networkScript = networkConfigScriptName
-	}
-
-	script, err := assembleScript(combustionScripts, networkScript)
-	if err != nil {
-		return fmt.Errorf("assembling script: %w", err)
-	}
-
-	filename := filepath.Join(ctx.CombustionDir, "script")
-	if err = os.WriteFile(filename, []byte(script), fileio.ExecutablePerms); err != nil {
-		return fmt.Errorf("writing script: %w", err)
-	}
-
-	return nil
+	return append(components, cleanup)
 }
 
 func generateComponentPath(ctx *image.Context, componentDir string) string {
@@ -217,11 +299,11 @@ func isComponentConfigured(ctx *image.Context, componentDir string) bool {
 	return false
 }
 
-func logComponentStatus(component string, err error) {
+func logComponentStatus(ctx *image.Context, component string, err error) {
 	if err != nil {
-		log.AuditComponentFailed(component)
+		ctx.Output.AuditComponentFailed(component)
 	} else {
-		log.AuditComponentSuccessful(component)
+		ctx.Output.AuditComponentSuccessful(component)
 		zap.S().Infof("Successfully configured %s component", component)
 	}
 }
@@ -0,0 +1,427 @@
+package combustion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/log"
+)
+
+// Component is a unit of the Combustion configuration, e.g. "users" or "kubernetes".
+//
+// The scripts produced by the components are executed in dependency order. Scripts of
+// components without a dependency between them are executed alphabetically, which retains
+// the ordering by script name prefix.
+//
+// Components may be configured concurrently, so they must only write to their own
+// locations in the build directory and must not depend on the output of other components.
+type Component struct {
+	// Name identifies the component in dependencies, the audit output and the build report.
+	Name string
+	// Configure sets up the component and returns the scripts it adds to the Combustion script.
+	Configure ConfigureComponent
+	// After lists the components whose scripts must be executed before the scripts of this one.
+	After []string
+	// Before lists the components whose scripts must be executed after the scripts of this one.
+	Before []string
+	// Inputs declares what the outputs of the component are derived from, so that these can be reused
+	// by subsequent builds. Components without declared inputs are configured in every build.
+	Inputs *ComponentInputs
+}
+
+var (
+	registeredComponentsMutex sync.Mutex
+	registeredComponents      []Component
+)
+
+// RegisterComponent adds a component to all subsequent Combustion configurations
+// alongside the built-in components. It is intended to be called from the init functions
+// of packages extending the builder. Invalid names and dependencies are reported when
+// the components are ordered during the configuration.
+func RegisterComponent(component Component) {
+	registeredComponentsMutex.Lock()
+	defer registeredComponentsMutex.Unlock()
+
+	registeredComponents = append(registeredComponents, component)
+}
+
+func extensionComponents() []Component {
+	registeredComponentsMutex.Lock()
+	defer registeredComponentsMutex.Unlock()
+
+	return slices.Clone(registeredComponents)
+}
+
+// componentGraph holds the "runs before" relations between components, identified by their index.
+type componentGraph struct {
+	components []Component
+	successors [][]int
+}
+
+func newComponentGraph(components []Component) (*componentGraph, error) {
+	indices := map[string]int{}
+
+	for i, component := range components {
+		if component.Name == "" {
+			return nil, errors.New("component name must be defined")
+		}
+
+		if component.Configure == nil {
+			return nil, fmt.Errorf("component %q does not define a configure function", component.Name)
+		}
+
+		if _, ok := indices[component.Name]; ok {
+			return nil, fmt.Errorf("component %q is registered multiple times", component.Name)
+		}
+
+		indices[component.Name] = i
+	}
+
+	g := &componentGraph{
+		components: components,
+		successors: make([][]int, len(components)),
+	}
+
+	lookup := func(component, dependency string) (int, error) {
+		index, ok := indices[dependency]
+		if !ok {
+			return 0, fmt.Errorf("component %q depends on unknown component %q", component, dependency)
+		}
+
+		return index, nil
+	}
+
+	for i, component := range components {
+		for _, after := range component.After {
+			index, err := lookup(component.Name, after)
+			if err != nil {
+				return nil, err
+			}
+
+			g.successors[index] = append(g.successors[index], i)
+		}
+
+		for _, before := range component.Before {
+			index, err := lookup(component.Name, before)
+			if err != nil {
+				return nil, err
+			}
+
+			g.successors[i] = append(g.successors[i], index)
+		}
+	}
+
+	return g, nil
+}
+
+// order sorts the components topologically. Components without a dependency
+// between them retain their registration order.
+func (g *componentGraph) order() ([]Component, error) {
+	inDegree := make([]int, len(g.components))
+	for _, successors := range g.successors {
+		for _, s := range successors {
+			inDegree[s]++
+		}
+	}
+
+	var ordered []Component
+	var ready []int
+
+	for i := range g.components {
+		if inDegree[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	for len(ready) > 0 {
+		slices.Sort(ready)
+
+		next := ready[0]
+		ready = ready[1:]
+		ordered = append(ordered, g.components[next])
+
+		for _, s := range g.successors[next] {
+			if inDegree[s]--; inDegree[s] == 0 {
+				ready = append(ready, s)
+			}
+		}
+	}
+
+	if len(ordered) != len(g.components) {
+		return nil, fmt.Errorf("dependency cycle detected between components: %s", strings.Join(g.cycle(), " -> "))
+	}
+
+	return ordered, nil
+}
+
+// cycle returns the names of the components forming a dependency cycle, starting and ending with the same component.
+func (g *componentGraph) cycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make([]int, len(g.components))
+	var path []int
+
+	var visit func(i int) []string
+	visit = func(i int) []string {
+		state[i] = visiting
+		path = append(path, i)
+
+		for _, s := range g.successors[i] {
+			switch state[s] {
+			case visiting:
+				start := slices.Index(path, s)
+
+				var names []string
+				for _, p := range path[start:] {
+					names = append(names, g.components[p].Name)
+				}
+
+				return append(names, g.components[s].Name)
+			case unvisited:
+				if names := visit(s); names != nil {
+					return names
+				}
+			}
+		}
+
+		state[i] = visited
+		path = path[:len(path)-1]
+
+		return nil
+	}
+
+	for i := range g.components {
+		if state[i] == unvisited {
+			if names := visit(i); names != nil {
+				return names
+			}
+		}
+	}
+
+	return nil
+}
+
+// reachability returns whether the component at the first index must run before the one at the second index,
+// either directly or through other components.
+func (g *componentGraph) reachability() [][]bool {
+	precedes := make([][]bool, len(g.components))
+
+	for from := range g.components {
+		precedes[from] = make([]bool, len(g.components))
+		pending := []int{from}
+
+		for len(pending) > 0 {
+			current := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+
+			for _, s := range g.successors[current] {
+				if !precedes[from][s] {
+					precedes[from][s] = true
+					pending = append(pending, s)
+				}
+			}
+		}
+	}
+
+	return precedes
+}
+
+// orderScripts sorts the scripts produced by the components, keyed by component name, so that
+// the scripts of a component are executed after the scripts of all components it depends on,
+// including indirectly through components which did not produce any scripts.
+// Otherwise, the scripts are executed alphabetically.
+func (g *componentGraph) orderScripts(componentScripts map[string][]string) []string {
+	type script struct {
+		name      string
+		component int
+	}
+
+	var scripts []script
+	for i, component := range g.components {
+		for _, s := range componentScripts[component.Name] {
+			scripts = append(scripts, script{name: s, component: i})
+		}
+	}
+
+	slices.SortStableFunc(scripts, func(a, b script) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	precedes := g.reachability()
+
+	isBlocked := func(candidate script, selected []bool) bool {
+		for i, other := range scripts {
+			if !selected[i] && precedes[other.component][candidate.component] {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	// Repeatedly select the first script in alphabetical order whose predecessors have all been selected
+	ordered := make([]string, 0, len(scripts))
+	selected := make([]bool, len(scripts))
+
+	for len(ordered) < len(scripts) {
+		for i, candidate := range scripts {
+			if !selected[i] && !isBlocked(candidate, selected) {
+				selected[i] = true
+				ordered = append(ordered, candidate.name)
+				break
+			}
+		}
+	}
+
+	return ordered
+}
+
+type componentResult struct {
+	scripts  []string
+	err      error
+	duration time.Duration
+	// started is false if the component was not configured because another one failed.
+	started bool
+	output  *log.Buffer
+	done    chan struct{}
+}
+
+// configureComponents configures the components, concurrently up to the parallelism of the context,
+// and returns the scripts they produced keyed by component name. The audit output and the build report
+// entries of the components are emitted in the given order, regardless of the order in which they complete.
+// Once a component fails or the context is cancelled, the running components are cancelled through their
+// context, the components which have not been started yet are skipped and the error of the first failed
+// component, or the cancellation cause, is returned.
+//
+// The store is optional. If provided, the outputs of unchanged components are reused from it.
+func configureComponents(ctx *image.Context, components []Component, store *ComponentStore) (map[string][]string, error) {
+	if ctx.Parallelism < 2 {
+		return configureComponentsSequentially(ctx, components, store)
+	}
+
+	results := make([]componentResult, len(components))
+	for i := range results {
+		results[i].done = make(chan struct{})
+	}
+
+	// The slots are acquired in component order, so that only the components
+	// which have not been started by the time of a failure are skipped
+	slots := make(chan struct{}, ctx.Parallelism)
+
+	// The first failure cancels the group, which interrupts the running components
+	groupCtx, cancel := context.WithCancelCause(ctx.Context())
+	defer cancel(nil)
+
+	go func() {
+		for i, component := range components {
+			result := &results[i]
+
+			slots <- struct{}{}
+			if groupCtx.Err() != nil {
+				<-slots
+				close(result.done)
+				continue
+			}
+
+			// Operations run with the context of the component, such as downloads, share its output
+			output := log.NewBuffer()
+			componentCtx := ctx.WithContext(log.WithOutput(groupCtx, output))
+			componentCtx.Output = output
+
+			result.started = true
+			result.output = componentCtx.Output
+
+			go func() {
+				defer close(result.done)
+				defer func() { <-slots }()
+
+				result.scripts, result.duration, result.err = configureComponent(componentCtx, component, store)
+				if result.err != nil {
+					cancel(fmt.Errorf("configuring component %q: %w", component.Name, result.err))
+				}
+			}()
+		}
+	}()
+
+	componentScripts := map[string][]string{}
+	var firstErr error
+
+	for i, component := range components {
+		result := &results[i]
+		<-result.done
+
+		if !result.started {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("configuring component %q: %w", component.Name, context.Cause(groupCtx))
+			}
+			continue
+		}
+
+		result.output.Flush()
+		ctx.Recorder().AddComponent(component.Name, string(ctx.ImageDefinition.Image.Arch), result.duration, result.scripts, result.err)
+
+		if result.err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("configuring component %q: %w", component.Name, result.err)
+		}
+
+		componentScripts[component.Name] = result.scripts
+	}
+
+	// Components interrupted by the failure of another one are not the cause of the failure
+	if cause := context.Cause(groupCtx); cause != nil && ctx.Context().Err() == nil {
+		return nil, cause
+	}
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return componentScripts, nil
+}
+
+func configureComponentsSequentially(ctx *image.Context, components []Component, store *ComponentStore) (map[string][]string, error) {
+	componentScripts := map[string][]string{}
+
+	for _, component := range components {
+		if ctx.Context().Err() != nil {
+			return nil, fmt.Errorf("configuring component %q: %w", component.Name, context.Cause(ctx.Context()))
+		}
+
+		scripts, duration, err := configureComponent(ctx, component, store)
+		ctx.Recorder().AddComponent(component.Name, string(ctx.ImageDefinition.Image.Arch), duration, scripts, err)
+		if err != nil {
+			return nil, fmt.Errorf("configuring component %q: %w", component.Name, err)
+		}
+
+		componentScripts[component.Name] = scripts
+	}
+
+	return componentScripts, nil
+}
+
+func configureComponent(ctx *image.Context, component Component, store *ComponentStore) ([]string, time.Duration, error) {
+	ctx.Output.AuditComponentStarted(component.Name)
+
+	start := time.Now()
+
+	var scripts []string
+	var err error
+
+	if store == nil || component.Inputs == nil {
+		scripts, err = component.Configure(ctx)
+	} else {
+		scripts, err = store.configure(ctx, component)
+	}
+
+	return scripts, time.Since(start), err
+}
@@ -0,0 +1,433 @@
+package combustion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
+)
+
+func noopComponent(name string, after, before []string) Component {
+	return Component{
+		Name: name,
+		Configure: func(*image.Context) ([]string, error) {
+			return nil, nil
+		},
+		After:  after,
+		Before: before,
+	}
+}
+
+func componentNames(components []Component) []string {
+	var names []string
+	for _, c := range components {
+		names = append(names, c.Name)
+	}
+
+	return names
+}
+
+func TestComponentGraphOrder(t *testing.T) {
+	tests := map[string]struct {
+		components    []Component
+		expectedOrder []string
+		expectedError string
+	}{
+		"registration order": {
+			components: []Component{
+				noopComponent("a", nil, nil),
+				noopComponent("b", nil, nil),
+				noopComponent("c", nil, nil),
+			},
+			expectedOrder: []string{"a", "b", "c"},
+		},
+		"dependencies": {
+			components: []Component{
+				noopComponent("a", []string{"c"}, nil),
+				noopComponent("b", nil, []string{"c"}),
+				noopComponent("c", nil, nil),
+				noopComponent("d", nil, nil),
+			},
+			expectedOrder: []string{"b", "c", "a", "d"},
+		},
+		"cycle": {
+			components: []Component{
+				noopComponent("a", nil, nil),
+				noopComponent("b", []string{"d"}, nil),
+				noopComponent("c", []string{"b"}, nil),
+				noopComponent("d", []string{"c"}, nil),
+			},
+			expectedError: "dependency cycle detected between components: b -> c -> d -> b",
+		},
+		"self dependency": {
+			components: []Component{
+				noopComponent("a", []string{"a"}, nil),
+			},
+			expectedError: "dependency cycle detected between components: a -> a",
+		},
+		"unknown dependency": {
+			components: []Component{
+				noopComponent("a", nil, []string{"missing"}),
+			},
+			expectedError: `component "a" depends on unknown component "missing"`,
+		},
+		"duplicate component": {
+			components: []Component{
+				noopComponent("a", nil, nil),
+				noopComponent("a", nil, nil),
+			},
+			expectedError: `component "a" is registered multiple times`,
+		},
+		"unnamed component": {
+			components: []Component{
+				noopComponent("", nil, nil),
+			},
+			expectedError: "component name must be defined",
+		},
+		"missing configure function": {
+			components: []Component{
+				{Name: "a"},
+			},
+			expectedError: `component "a" does not define a configure function`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			graph, err := newComponentGraph(test.components)
+
+			var ordered []Component
+			if err == nil {
+				ordered, err = graph.order()
+			}
+
+			if test.expectedError != "" {
+				require.Error(t, err)
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedOrder, componentNames(ordered))
+		})
+	}
+}
+
+func TestComponentGraphOrderScripts(t *testing.T) {
+	graph, err := newComponentGraph([]Component{
+		noopComponent("rpm", nil, nil),
+		noopComponent("custom", nil, nil),
+		noopComponent("no-scripts", []string{"rpm"}, nil),
+		noopComponent("extension", []string{"no-scripts"}, []string{"message"}),
+		noopComponent("message", nil, nil),
+	})
+	require.NoError(t, err)
+
+	scripts := graph.orderScripts(map[string][]string{
+		"rpm":       {"10-rpm-install.sh"},
+		"custom":    {"60-custom.sh", "01-custom.sh"},
+		"extension": {"extension.sh"},
+		"message":   {"48-message.sh"},
+	})
+
+	assert.Equal(t, []string{
+		"01-custom.sh",
+		"10-rpm-install.sh",
+		"60-custom.sh",
+		"extension.sh",
+		"48-message.sh",
+	}, scripts)
+}
+
+func TestBuiltInComponentsRetainScriptNameOrder(t *testing.T) {
+	c := &Combustion{}
+
+	graph, err := newComponentGraph(c.components())
+	require.NoError(t, err)
+
+	ordered, err := graph.order()
+	require.NoError(t, err)
+
+	names := componentNames(ordered)
+	assert.Equal(t, componentNames(c.components()), names)
+	assert.Equal(t, cleanupComponentName, names[len(names)-1])
+
+	scripts := graph.orderScripts(map[string][]string{
+		rpmComponentName:       {installRPMsScriptName},
+		customComponentName:    {"05-early.sh", "zz-late.sh"},
+		groupsComponentName:    {groupsScriptName},
+		usersComponentName:     {usersScriptName},
+		elementalComponentName: {elementalScriptName},
+		k8sComponentName:       {k8sInstallScript},
+		messageComponentName:   {messageScriptName},
+		cleanupComponentName:   {cleanupScriptName},
+	})
+
+	assert.Equal(t, []string{
+		"05-early.sh",
+		installRPMsScriptName,
+		groupsScriptName,
+		usersScriptName,
+		k8sInstallScript,
+		elementalScriptName,
+		messageScriptName,
+		"zz-late.sh",
+		cleanupScriptName,
+	}, scripts)
+}
+
+func TestConfigureRegisteredComponent(t *testing.T) {
+	ctx, teardown := setupContext(t)
+	defer teardown()
+
+	ctx.ImageDefinition.Image.ImageType = image.TypeISO
+
+	defer func() {
+		registeredComponents = nil
+	}()
+
+	RegisterComponent(Component{
+		Name: "extension",
+		Configure: func(ctx *image.Context) ([]string, error) {
+			return []string{"extension.sh"}, os.WriteFile(filepath.Join(ctx.CombustionDir, "extension.sh"), nil, 0o700)
+		},
+		After:  []string{messageComponentName},
+		Before: []string{certsComponentName},
+	})
+
+	c := &Combustion{}
+	require.NoError(t, c.Configure(ctx))
+
+	script, err := os.ReadFile(filepath.Join(ctx.CombustionDir, "script"))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(script), `echo "Running 48-message.sh"
+./48-message.sh
+
+echo "Running extension.sh"
+./extension.sh
+`)
+}
+
+func reportedComponentNames(r *report.Report) []string {
+	var names []string
+	for _, c := range r.Components {
+		names = append(names, c.Name)
+	}
+
+	return names
+}
+
+func TestConfigureComponentsConcurrently(t *testing.T) {
+	buildReport := report.New("test")
+	ctx := (&image.Context{
+		ImageDefinition: &image.Definition{},
+		Parallelism:     3,
+	}).WithRecorder(buildReport)
+
+	// Each component waits until all of them are running, which only succeeds when they are configured concurrently
+	var running atomic.Int32
+	waitForAll := func(name string, delay time.Duration) Component {
+		return Component{
+			Name: name,
+			Configure: func(componentCtx *image.Context) ([]string, error) {
+				assert.NotNil(t, componentCtx.Output)
+
+				running.Add(1)
+				deadline := time.Now().Add(5 * time.Second)
+				for running.Load() < 3 {
+					if time.Now().After(deadline) {
+						return nil, errors.New("components are not configured concurrently")
+					}
+					time.Sleep(time.Millisecond)
+				}
+
+				time.Sleep(delay)
+				return []string{name + ".sh"}, nil
+			},
+		}
+	}
+
+	components := []Component{
+		waitForAll("slow", 50*time.Millisecond),
+		waitForAll("medium", 20*time.Millisecond),
+		waitForAll("fast", 0),
+	}
+
+	scripts, err := configureComponents(ctx, components, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string][]string{
+		"slow":   {"slow.sh"},
+		"medium": {"medium.sh"},
+		"fast":   {"fast.sh"},
+	}, scripts)
+	assert.Equal(t, []string{"slow", "medium", "fast"}, reportedComponentNames(buildReport))
+	assert.Nil(t, ctx.Output)
+}
+
+func TestConfigureComponentsFailure(t *testing.T) {
+	tests := map[string]struct {
+		parallelism int
+	}{
+		"sequential": {
+			parallelism: 1,
+		},
+		"concurrent": {
+			parallelism: 2,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			buildReport := report.New("test")
+			ctx := (&image.Context{
+				ImageDefinition: &image.Definition{},
+				Parallelism:     test.parallelism,
+			}).WithRecorder(buildReport)
+
+			var skippedConfigured atomic.Bool
+			components := []Component{
+				noopComponent("first", nil, nil),
+				{
+					Name: "failing",
+					Configure: func(*image.Context) ([]string, error) {
+						return nil, errors.New("resolution failed")
+					},
+				},
+				{
+					Name: "second-failing",
+					Configure: func(*image.Context) ([]string, error) {
+						time.Sleep(10 * time.Millisecond)
+						return nil, errors.New("download failed")
+					},
+				},
+			}
+
+			// Components queued after the failures are never started
+			for i := 0; i < 10; i++ {
+				components = append(components, Component{
+					Name: fmt.Sprintf("skipped-%d", i),
+					Configure: func(*image.Context) ([]string, error) {
+						time.Sleep(20 * time.Millisecond)
+						skippedConfigured.Store(true)
+						return nil, nil
+					},
+				})
+			}
+
+			scripts, err := configureComponents(ctx, components, nil)
+			require.Error(t, err)
+			assert.EqualError(t, err, `configuring component "failing": resolution failed`)
+			assert.Nil(t, scripts)
+
+			assert.False(t, skippedConfigured.Load())
+			assert.Equal(t, "first", buildReport.Components[0].Name)
+			assert.Equal(t, "failing", buildReport.Components[1].Name)
+			assert.NotContains(t, reportedComponentNames(buildReport), "skipped-9")
+		})
+	}
+}
+
+func TestConfigureComponentsFailureCancelsRunning(t *testing.T) {
+	buildReport := report.New("test")
+	ctx := (&image.Context{
+		ImageDefinition: &image.Definition{},
+		Parallelism:     2,
+	}).WithRecorder(buildReport)
+
+	started := make(chan struct{})
+
+	components := []Component{
+		{
+			Name: "download",
+			Configure: func(componentCtx *image.Context) ([]string, error) {
+				close(started)
+
+				select {
+				case <-componentCtx.Context().Done():
+					return nil, fmt.Errorf("downloading: %w", context.Cause(componentCtx.Context()))
+				case <-time.After(5 * time.Second):
+					return []string{"download.sh"}, nil
+				}
+			},
+		},
+		{
+			Name: "failing",
+			Configure: func(*image.Context) ([]string, error) {
+				<-started
+				return nil, errors.New("resolution failed")
+			},
+		},
+	}
+
+	start := time.Now()
+
+	_, err := configureComponents(ctx, components, nil)
+	require.Error(t, err)
+	assert.EqualError(t, err, `configuring component "failing": resolution failed`)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	require.Len(t, buildReport.Components, 2)
+	assert.Equal(t, "download", buildReport.Components[0].Name)
+	assert.Contains(t, buildReport.Components[0].Error, `configuring component "failing": resolution failed`)
+}
+
+func TestConfigureComponentsCancelled(t *testing.T) {
+	for _, parallelism := range []int{1, 2} {
+		t.Run(fmt.Sprintf("parallelism %d", parallelism), func(t *testing.T) {
+			buildCtx, cancel := context.WithCancelCause(context.Background())
+			defer cancel(nil)
+
+			ctx := (&image.Context{
+				ImageDefinition: &image.Definition{},
+				Parallelism:     parallelism,
+			}).WithContext(buildCtx)
+
+			var configured []string
+			components := []Component{
+				{
+					Name: "interrupted",
+					Configure: func(*image.Context) ([]string, error) {
+						configured = append(configured, "interrupted")
+						cancel(errors.New("build interrupted"))
+						return nil, nil
+					},
+				},
+				{
+					Name: "skipped",
+					Configure: func(*image.Context) ([]string, error) {
+						configured = append(configured, "skipped")
+						return nil, nil
+					},
+				},
+			}
+
+			if parallelism > 1 {
+				// Occupies the second slot until the cancellation, so that the last component starts afterwards
+				components = slices.Insert(components, 1, Component{
+					Name: "running",
+					Configure: func(componentCtx *image.Context) ([]string, error) {
+						<-componentCtx.Context().Done()
+						return nil, nil
+					},
+				})
+			}
+
+			_, err := configureComponents(ctx, components, nil)
+			require.Error(t, err)
+			assert.EqualError(t, err, `configuring component "skipped": build interrupted`)
+			assert.Equal(t, []string{"interrupted"}, configured)
+		})
+	}
+}
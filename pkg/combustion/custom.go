@@ -1,13 +1,15 @@
 package combustion
 
 import (
+	_ "embed"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 )
 
 const (
@@ -17,28 +19,78 @@ const (
 	customComponentName = "custom files"
 )
 
+//go:embed templates/node-script.sh.tpl
+var nodeScriptTemplate string
+
 func configureCustomFiles(ctx *image.Context) ([]string, error) {
-	if !isComponentConfigured(ctx, customDir) {
-		log.AuditComponentSkipped(customComponentName)
+	nodes := nodeOverrides(ctx, func(override image.NodeOverride) bool {
+		return isComponentConfigured(ctx, filepath.Join(nodeOverridesDir, override.ID(), nodeScriptsDir))
+	})
+
+	if !isComponentConfigured(ctx, customDir) && len(nodes) == 0 {
+		ctx.Output.AuditComponentSkipped(customComponentName)
 		return nil, nil
 	}
 
 	err := handleCustomFiles(ctx)
 	if err != nil {
-		log.AuditComponentFailed(customComponentName)
+		ctx.Output.AuditComponentFailed(customComponentName)
 		return nil, err
 	}
 
 	scripts, err := handleCustomScripts(ctx)
 	if err != nil {
-		log.AuditComponentFailed(customComponentName)
+		ctx.Output.AuditComponentFailed(customComponentName)
+		return nil, err
+	}
+
+	nodeScripts, err := handleNodeScripts(ctx, nodes, scripts)
+	if err != nil {
+		ctx.Output.AuditComponentFailed(customComponentName)
 		return nil, err
 	}
+	scripts = append(scripts, nodeScripts...)
 
-	log.AuditComponentSuccessful(customComponentName)
+	if err = recordCustomFiles(ctx, nodes); err != nil {
+		ctx.Output.AuditComponentFailed(customComponentName)
+		return nil, fmt.Errorf("recording custom files: %w", err)
+	}
+
+	ctx.Output.AuditComponentSuccessful(customComponentName)
 	return scripts, nil
 }
 
+func recordCustomFiles(ctx *image.Context, nodes []image.NodeOverride) error {
+	dirs := []string{generateComponentPath(ctx, customDir)}
+	for _, node := range nodes {
+		dirs = append(dirs, NodeScriptsPath(ctx, node))
+	}
+
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			name, err := filepath.Rel(ctx.ImageConfigDir, path)
+			if err != nil {
+				return err
+			}
+
+			return ctx.Recorder().AddSideLoadedFile(name, path)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func handleCustomFiles(ctx *image.Context) error {
 	fullFilesDir := generateComponentPath(ctx, filepath.Join(customDir, customFilesDir))
 	err := copyCustomFiles(fullFilesDir, ctx.CombustionDir)
@@ -46,11 +98,75 @@ func handleCustomFiles(ctx *image.Context) error {
 }
 
 func handleCustomScripts(ctx *image.Context) ([]string, error) {
-	fullScriptsDir := generateComponentPath(ctx, filepath.Join(customDir, customScriptsDir))
-	scripts, err := copyCustomScripts(fullScriptsDir, ctx.CombustionDir, &fileio.ExecutablePerms)
+	scripts, err := copyCustomScripts(CustomScriptsPath(ctx), ctx.CombustionDir, &fileio.ExecutablePerms)
 	return scripts, err
 }
 
+// CustomScriptsPath returns the directory of the image configuration holding the custom scripts run on all nodes.
+func CustomScriptsPath(ctx *image.Context) string {
+	return generateComponentPath(ctx, filepath.Join(customDir, customScriptsDir))
+}
+
+// handleNodeScripts copies the scripts specific to each node and generates a script for each of
+// their names, which runs the matching node's copy when executed on that node.
+func handleNodeScripts(ctx *image.Context, nodes []image.NodeOverride, sharedScripts []string) ([]string, error) {
+	var scripts []string
+	scriptNodes := map[string][]string{}
+
+	for _, node := range nodes {
+		destDir := filepath.Join(ctx.CombustionDir, nodeOverridesDir, node.ID(), nodeScriptsDir)
+		if err := os.MkdirAll(destDir, os.ModePerm); err != nil {
+			return nil, fmt.Errorf("creating node scripts directory %s: %w", destDir, err)
+		}
+
+		copied, err := copyCustomScripts(NodeScriptsPath(ctx, node), destDir, &fileio.ExecutablePerms)
+		if err != nil {
+			return nil, fmt.Errorf("copying scripts for node %s: %w", node.ID(), err)
+		}
+
+		for _, script := range copied {
+			if _, ok := scriptNodes[script]; !ok {
+				scripts = append(scripts, script)
+			}
+			scriptNodes[script] = append(scriptNodes[script], node.ID())
+		}
+	}
+
+	for _, script := range scripts {
+		if slices.Contains(sharedScripts, script) {
+			return nil, fmt.Errorf("node script %s conflicts with the custom script of the same name", script)
+		}
+
+		if err := writeNodeScript(ctx, script, scriptNodes[script]); err != nil {
+			return nil, err
+		}
+	}
+
+	return scripts, nil
+}
+
+func writeNodeScript(ctx *image.Context, script string, nodes []string) error {
+	values := struct {
+		Script string
+		Nodes  []string
+	}{
+		Script: script,
+		Nodes:  nodes,
+	}
+
+	data, err := parseNodeTemplate(script, nodeScriptTemplate, values)
+	if err != nil {
+		return fmt.Errorf("applying node script template for %s: %w", script, err)
+	}
+
+	filename := filepath.Join(ctx.CombustionDir, script)
+	if err = os.WriteFile(filename, []byte(data), fileio.ExecutablePerms); err != nil {
+		return fmt.Errorf("writing node script %s: %w", filename, err)
+	}
+
+	return nil
+}
+
 func copyCustomFiles(fromDir, toDir string) error {
 	if _, err := os.Stat(fromDir); os.IsNotExist(err) {
 		return nil
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
 )
 
 func TestConfigureCustomFiles(t *testing.T) {
@@ -132,3 +133,72 @@ func TestCopyCustomFiles_EmptyFromDir(t *testing.T) {
 	assert.ErrorContains(t, err, "no scripts found in directory")
 	assert.Nil(t, scripts)
 }
+
+func TestConfigureCustomFiles_NodeScripts(t *testing.T) {
+	// Setup
+	ctx, teardown := setupContext(t)
+	defer teardown()
+
+	node1 := image.NodeOverride{Hostname: "node1.example.com"}
+	node2 := image.NodeOverride{MACAddress: "34:8a:b1:4b:16:e2"}
+	ctx.ImageDefinition = &image.Definition{
+		NodeOverrides: []image.NodeOverride{node1, node2},
+	}
+
+	sharedScriptsDir := filepath.Join(ctx.ImageConfigDir, customDir, customScriptsDir)
+	require.NoError(t, os.MkdirAll(sharedScriptsDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(sharedScriptsDir, "foo.sh"), nil, 0o744))
+
+	for _, node := range []image.NodeOverride{node1, node2} {
+		require.NoError(t, os.MkdirAll(NodeScriptsPath(ctx, node), os.ModePerm))
+		require.NoError(t, os.WriteFile(filepath.Join(NodeScriptsPath(ctx, node), "50-node.sh"), nil, 0o744))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(NodeScriptsPath(ctx, node1), "60-node1.sh"), nil, 0o744))
+
+	// Test
+	scripts, err := configureCustomFiles(ctx)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, []string{"foo.sh", "50-node.sh", "60-node1.sh"}, scripts)
+
+	// - node scripts are copied per node
+	assert.FileExists(t, filepath.Join(ctx.CombustionDir, nodeOverridesDir, node1.ID(), nodeScriptsDir, "50-node.sh"))
+	assert.FileExists(t, filepath.Join(ctx.CombustionDir, nodeOverridesDir, node2.ID(), nodeScriptsDir, "50-node.sh"))
+
+	// - a script under the node script name dispatches to the matching node's copy
+	contents, err := os.ReadFile(filepath.Join(ctx.CombustionDir, "50-node.sh"))
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "if is_node 'node1.example.com'; then\n  './node-overrides/node1.example.com/scripts/50-node.sh'\nfi")
+	assert.Contains(t, string(contents), "if is_node '34:8a:b1:4b:16:e2'; then\n  './node-overrides/34:8a:b1:4b:16:e2/scripts/50-node.sh'\nfi")
+
+	contents, err = os.ReadFile(filepath.Join(ctx.CombustionDir, "60-node1.sh"))
+	require.NoError(t, err)
+	assert.Contains(t, string(contents), "node1.example.com")
+	assert.NotContains(t, string(contents), node2.MACAddress)
+}
+
+func TestConfigureCustomFiles_NodeScriptConflict(t *testing.T) {
+	// Setup
+	ctx, teardown := setupContext(t)
+	defer teardown()
+
+	node := image.NodeOverride{Hostname: "node1.example.com"}
+	ctx.ImageDefinition = &image.Definition{
+		NodeOverrides: []image.NodeOverride{node},
+	}
+
+	sharedScriptsDir := filepath.Join(ctx.ImageConfigDir, customDir, customScriptsDir)
+	require.NoError(t, os.MkdirAll(sharedScriptsDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(sharedScriptsDir, "foo.sh"), nil, 0o744))
+
+	require.NoError(t, os.MkdirAll(NodeScriptsPath(ctx, node), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(NodeScriptsPath(ctx, node), "foo.sh"), nil, 0o744))
+
+	// Test
+	scripts, err := configureCustomFiles(ctx)
+
+	// Verify
+	assert.Nil(t, scripts)
+	assert.EqualError(t, err, "node script foo.sh conflicts with the custom script of the same name")
+}
@@ -8,7 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 	"go.uber.org/zap"
 )
@@ -29,22 +28,22 @@ var (
 
 func configureElemental(ctx *image.Context) ([]string, error) {
 	if !isComponentConfigured(ctx, elementalConfigDir) {
-		log.AuditComponentSkipped(elementalComponentName)
+		ctx.Output.AuditComponentSkipped(elementalComponentName)
 		zap.S().Info("Skipping elemental registration component, configuration is not provided")
 		return nil, nil
 	}
 
 	if err := copyElementalConfigFile(ctx); err != nil {
-		log.AuditComponentFailed(elementalComponentName)
+		ctx.Output.AuditComponentFailed(elementalComponentName)
 		return nil, err
 	}
 
 	if err := writeElementalCombustionScript(ctx); err != nil {
-		log.AuditComponentFailed(elementalComponentName)
+		ctx.Output.AuditComponentFailed(elementalComponentName)
 		return nil, err
 	}
 
-	log.AuditComponentSuccessful(elementalComponentName)
+	ctx.Output.AuditComponentSuccessful(elementalComponentName)
 	return []string{elementalScriptName}, nil
 }
 
@@ -8,7 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 )
 
 const (
@@ -26,16 +25,16 @@ var (
 func configureFips(ctx *image.Context) ([]string, error) {
 	fips := ctx.ImageDefinition.OperatingSystem.EnableFips
 	if !fips {
-		log.AuditComponentSkipped(fipsComponentName)
+		ctx.Output.AuditComponentSkipped(fipsComponentName)
 		return nil, nil
 	}
 
 	if err := writeFipsCombustionScript(ctx); err != nil {
-		log.AuditComponentFailed(fipsComponentName)
+		ctx.Output.AuditComponentFailed(fipsComponentName)
 		return nil, err
 	}
 
-	log.AuditComponentSuccessful(fipsComponentName)
+	ctx.Output.AuditComponentSuccessful(fipsComponentName)
 	return []string{fipsScriptName}, nil
 }
 
@@ -8,7 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 )
 
@@ -23,23 +22,23 @@ var groupsScript string
 func configureGroups(ctx *image.Context) ([]string, error) {
 	// Punch out early if there are no groups
 	if len(ctx.ImageDefinition.OperatingSystem.Groups) == 0 {
-		log.AuditComponentSkipped(groupsComponentName)
+		ctx.Output.AuditComponentSkipped(groupsComponentName)
 		return nil, nil
 	}
 
 	data, err := template.Parse(groupsScriptName, groupsScript, ctx.ImageDefinition.OperatingSystem.Groups)
 	if err != nil {
-		log.AuditComponentFailed(groupsComponentName)
+		ctx.Output.AuditComponentFailed(groupsComponentName)
 		return nil, fmt.Errorf("parsing the group script template: %w", err)
 	}
 
 	filename := filepath.Join(ctx.CombustionDir, groupsScriptName)
 	err = os.WriteFile(filename, []byte(data), fileio.ExecutablePerms)
 	if err != nil {
-		log.AuditComponentFailed(groupsComponentName)
+		ctx.Output.AuditComponentFailed(groupsComponentName)
 		return nil, fmt.Errorf("writing %s to the combustion directory: %w", groupsScriptName, err)
 	}
 
-	log.AuditComponentSuccessful(groupsComponentName)
+	ctx.Output.AuditComponentSuccessful(groupsComponentName)
 	return []string{groupsScriptName}, nil
 }
@@ -8,7 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 )
 
@@ -22,11 +21,11 @@ var keymapScript string
 
 func configureKeymap(ctx *image.Context) ([]string, error) {
 	if err := writeKeymapCombustionScript(ctx); err != nil {
-		log.AuditComponentFailed(keymapComponentName)
+		ctx.Output.AuditComponentFailed(keymapComponentName)
 		return nil, err
 	}
 
-	log.AuditComponentSuccessful(keymapComponentName)
+	ctx.Output.AuditComponentSuccessful(keymapComponentName)
 	return []string{keymapScriptName}, nil
 }
 
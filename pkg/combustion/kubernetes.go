@@ -10,7 +10,6 @@ import (
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
 	"github.com/suse-edge/edge-image-builder/pkg/kubernetes"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
@@ -57,31 +56,26 @@ func (c *Combustion) configureKubernetes(ctx *image.Context) ([]string, error) {
 	version := ctx.ImageDefinition.Kubernetes.Version
 
 	if version == "" {
-		log.AuditComponentSkipped(k8sComponentName)
+		ctx.Output.AuditComponentSkipped(k8sComponentName)
 		return nil, nil
 	}
 
 	configureFunc := c.kubernetesConfigurator(version)
 	if configureFunc == nil {
-		log.AuditComponentFailed(k8sComponentName)
+		ctx.Output.AuditComponentFailed(k8sComponentName)
 		return nil, fmt.Errorf("cannot configure kubernetes version: %s", version)
 	}
 
 	// Show a message to the user to indicate that the Kubernetes component
 	// is usually taking longer to complete due to downloading files
-	log.Audit("Configuring Kubernetes component...")
-
-	if kubernetes.ServersCount(ctx.ImageDefinition.Kubernetes.Nodes) == 2 {
-		log.Audit("WARNING: Kubernetes clusters consisting of two server nodes cannot form a highly available architecture")
-		zap.S().Warn("Kubernetes cluster of two server nodes has been requested")
-	}
+	ctx.Output.Audit("Configuring Kubernetes component...")
 
 	configDir := generateComponentPath(ctx, k8sDir)
 	configPath := filepath.Join(configDir, k8sConfigDir)
 
-	cluster, err := kubernetes.NewCluster(&ctx.ImageDefinition.Kubernetes, configPath)
+	cluster, err := kubernetes.NewCluster(&ctx.ImageDefinition.Kubernetes, configPath, ctx.Output)
 	if err != nil {
-		log.AuditComponentFailed(k8sComponentName)
+		ctx.Output.AuditComponentFailed(k8sComponentName)
 		return nil, fmt.Errorf("initialising cluster config: %w", err)
 	}
 
@@ -91,17 +85,17 @@ func (c *Combustion) configureKubernetes(ctx *image.Context) ([]string, error) {
 	}
 
 	if err = storeKubernetesClusterConfig(cluster, artefactsPath); err != nil {
-		log.AuditComponentFailed(k8sComponentName)
+		ctx.Output.AuditComponentFailed(k8sComponentName)
 		return nil, fmt.Errorf("storing cluster config: %w", err)
 	}
 
 	script, err := configureFunc(ctx, cluster)
 	if err != nil {
-		log.AuditComponentFailed(k8sComponentName)
+		ctx.Output.AuditComponentFailed(k8sComponentName)
 		return nil, fmt.Errorf("configuring kubernetes components: %w", err)
 	}
 
-	log.AuditComponentSuccessful(k8sComponentName)
+	ctx.Output.AuditComponentSuccessful(k8sComponentName)
 	return []string{script}, nil
 }
 
@@ -119,7 +113,7 @@ func (c *Combustion) kubernetesConfigurator(version string) func(*image.Context,
 func (c *Combustion) downloadKubernetesInstallScript(ctx *image.Context, distribution string) (string, error) {
 	path := kubernetesArtefactsPath(ctx)
 
-	installScript, err := c.KubernetesScriptDownloader.DownloadInstallScript(distribution, path)
+	installScript, err := c.KubernetesScriptDownloader.DownloadInstallScript(ctx.Context(), distribution, path)
 	if err != nil {
 		return "", fmt.Errorf("downloading install script: %w", err)
 	}
@@ -160,10 +154,6 @@ func (c *Combustion) configureK3S(ctx *image.Context, cluster *kubernetes.Cluste
 	if singleNode {
 		if ctx.ImageDefinition.Kubernetes.Network.APIVIP == "" {
 			zap.S().Info("Virtual IP address for k3s cluster is not provided and will not be configured")
-		} else {
-			log.Audit("WARNING: A Virtual IP address for the k3s cluster has been provided. " +
-				"An external IP address for the Ingress Controller (Traefik) must be manually configured.")
-			zap.S().Warn("Virtual IP address for k3s cluster is requested and will invalidate Traefik configuration")
 		}
 
 		templateValues["configFile"] = k8sServerConfigFile
@@ -171,9 +161,6 @@ func (c *Combustion) configureK3S(ctx *image.Context, cluster *kubernetes.Cluste
 		return storeKubernetesInstaller(ctx, "single-node-k3s", k3sSingleNodeInstaller, templateValues)
 	}
 
-	log.Audit("WARNING: An external IP address for the Ingress Controller (Traefik) must be manually configured in multi-node clusters.")
-	zap.S().Warn("Virtual IP address for k3s cluster is necessary for multi node clusters and will invalidate Traefik configuration")
-
 	templateValues["nodes"] = ctx.ImageDefinition.Kubernetes.Nodes
 	templateValues["initialiser"] = cluster.InitialiserName
 	templateValues["initialiserConfigFile"] = k8sInitServerConfigFile
@@ -194,7 +181,7 @@ func (c *Combustion) downloadK3sArtefacts(ctx *image.Context) (binaryPath, image
 		return "", "", fmt.Errorf("creating kubernetes install dir: %w", err)
 	}
 
-	if err = c.KubernetesArtefactDownloader.DownloadK3sArtefacts(
+	if err = c.KubernetesArtefactDownloader.DownloadK3sArtefacts(ctx.Context(),
 		ctx.ImageDefinition.Image.Arch,
 		ctx.ImageDefinition.Kubernetes.Version,
 		installDestination,
@@ -301,7 +288,7 @@ func (c *Combustion) downloadRKE2Artefacts(ctx *image.Context, cluster *kubernet
 		return "", "", fmt.Errorf("creating kubernetes install dir: %w", err)
 	}
 
-	if err = c.KubernetesArtefactDownloader.DownloadRKE2Artefacts(
+	if err = c.KubernetesArtefactDownloader.DownloadRKE2Artefacts(ctx.Context(),
 		ctx.ImageDefinition.Image.Arch,
 		ctx.ImageDefinition.Kubernetes.Version,
 		cni,
@@ -431,6 +418,10 @@ func KubernetesConfigPath(ctx *image.Context) string {
 	return filepath.Join(ctx.ImageConfigDir, k8sDir, k8sConfigDir, k8sServerConfigFile)
 }
 
+func KubernetesAgentConfigPath(ctx *image.Context) string {
+	return filepath.Join(ctx.ImageConfigDir, k8sDir, k8sConfigDir, k8sAgentConfigFile)
+}
+
 func localKubernetesManifestsPath() string {
 	return filepath.Join(k8sDir, k8sManifestsDir)
 }
@@ -1,6 +1,7 @@
 package combustion
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -20,7 +21,7 @@ type mockKubernetesScriptDownloader struct {
 	downloadScript func(distribution, destPath string) (string, error)
 }
 
-func (m mockKubernetesScriptDownloader) DownloadInstallScript(distribution, destPath string) (string, error) {
+func (m mockKubernetesScriptDownloader) DownloadInstallScript(_ context.Context, distribution, destPath string) (string, error) {
 	if m.downloadScript != nil {
 		return m.downloadScript(distribution, destPath)
 	}
@@ -34,6 +35,7 @@ type mockKubernetesArtefactDownloader struct {
 }
 
 func (m mockKubernetesArtefactDownloader) DownloadRKE2Artefacts(
+	_ context.Context,
 	arch image.Arch,
 	version string,
 	cni string,
@@ -48,7 +50,7 @@ func (m mockKubernetesArtefactDownloader) DownloadRKE2Artefacts(
 	panic("not implemented")
 }
 
-func (m mockKubernetesArtefactDownloader) DownloadK3sArtefacts(arch image.Arch, version, installPath, imagesPath string) error {
+func (m mockKubernetesArtefactDownloader) DownloadK3sArtefacts(_ context.Context, arch image.Arch, version, installPath, imagesPath string) error {
 	if m.downloadK3sArtefacts != nil {
 		return m.downloadK3sArtefacts(arch, version, installPath, imagesPath)
 	}
@@ -70,7 +72,7 @@ func (m mockEmbeddedRegistry) HelmCharts() ([]*registry.HelmCRD, error) {
 	panic("not implemented")
 }
 
-func (m mockEmbeddedRegistry) ContainerImages() ([]string, error) {
+func (m mockEmbeddedRegistry) ContainerImages(context.Context) ([]string, error) {
 	if m.containerImagesFunc != nil {
 		return m.containerImagesFunc()
 	}
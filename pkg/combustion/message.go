@@ -8,7 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 	"github.com/suse-edge/edge-image-builder/pkg/version"
 )
@@ -36,10 +35,10 @@ func configureMessage(ctx *image.Context) ([]string, error) {
 	filename := filepath.Join(ctx.CombustionDir, messageScriptName)
 	err = os.WriteFile(filename, []byte(data), fileio.ExecutablePerms)
 	if err != nil {
-		log.AuditComponentFailed(messageComponentName)
+		ctx.Output.AuditComponentFailed(messageComponentName)
 		return nil, fmt.Errorf("writing message script: %w", err)
 	}
 
-	log.AuditComponentSuccessful(messageComponentName)
+	ctx.Output.AuditComponentSuccessful(messageComponentName)
 	return []string{messageScriptName}, nil
 }
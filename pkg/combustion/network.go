@@ -10,7 +10,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 	"go.uber.org/zap"
 )
@@ -53,13 +52,13 @@ func (c *Combustion) configureNetwork(ctx *image.Context) (scripts []string, err
 	zap.S().Info("Configuring network component...")
 
 	if !isComponentConfigured(ctx, networkConfigDir) {
-		log.AuditComponentSkipped(networkComponentName)
+		ctx.Output.AuditComponentSkipped(networkComponentName)
 		zap.S().Info("Skipping network component, configuration is not provided")
 		return nil, nil
 	}
 
 	defer func() {
-		logComponentStatus(networkComponentName, err)
+		logComponentStatus(ctx, networkComponentName, err)
 	}()
 
 	networkPath := generateComponentPath(ctx, networkConfigDir)
@@ -117,7 +116,7 @@ func (c *Combustion) generateNetworkConfig(ctx *image.Context) error {
 	configDir := generateComponentPath(ctx, networkConfigDir)
 	outputDir := filepath.Join(ctx.CombustionDir, networkConfigDir)
 
-	return c.NetworkConfigGenerator.GenerateNetworkConfig(configDir, outputDir, logFile)
+	return c.NetworkConfigGenerator.GenerateNetworkConfig(ctx.Context(), configDir, outputDir, logFile)
 }
 
 func (c *Combustion) installNetworkConfigurator(ctx *image.Context) error {
@@ -145,3 +144,11 @@ func writeNetworkConfigurationScript(scriptPath string) error {
 
 	return nil
 }
+
+func NetworkConfigPath(ctx *image.Context) string {
+	return generateComponentPath(ctx, networkConfigDir)
+}
+
+func NetworkCustomScriptPath(ctx *image.Context) string {
+	return filepath.Join(NetworkConfigPath(ctx), networkCustomScriptName)
+}
@@ -1,6 +1,7 @@
 package combustion
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -16,7 +17,7 @@ type mockNetworkConfigGenerator struct {
 	generateNetworkConfigFunc func(configDir, outputDir string, outputWriter io.Writer) error
 }
 
-func (m mockNetworkConfigGenerator) GenerateNetworkConfig(configDir, outputDir string, outputWriter io.Writer) error {
+func (m mockNetworkConfigGenerator) GenerateNetworkConfig(_ context.Context, configDir, outputDir string, outputWriter io.Writer) error {
 	if m.generateNetworkConfigFunc != nil {
 		return m.generateNetworkConfigFunc(configDir, outputDir, outputWriter)
 	}
@@ -0,0 +1,53 @@
+package combustion
+
+import (
+	_ "embed"
+	"path/filepath"
+	"slices"
+
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/template"
+)
+
+const (
+	nodeOverridesDir = "node-overrides"
+	nodeScriptsDir   = "scripts"
+)
+
+// nodeMatchTemplate defines the 'nodeMatch' template, which declares the 'is_node' shell function
+// identifying the node a script is executed on by its hostname or MAC address.
+//
+//go:embed templates/node-match.sh.tpl
+var nodeMatchTemplate string
+
+// parseNodeTemplate applies a script template which may branch per node through the 'nodeMatch' template.
+func parseNodeTemplate(name, contents string, templateData any) (string, error) {
+	return template.Parse(name, nodeMatchTemplate+contents, templateData)
+}
+
+// NodeOverridesPath returns the directory of the image configuration holding the files specific to each node.
+func NodeOverridesPath(ctx *image.Context) string {
+	return filepath.Join(ctx.ImageConfigDir, nodeOverridesDir)
+}
+
+// NodeOverridePath returns the directory of the image configuration holding the files specific to the given node.
+func NodeOverridePath(ctx *image.Context, override image.NodeOverride) string {
+	return filepath.Join(NodeOverridesPath(ctx), override.ID())
+}
+
+// NodeOSFilesPath returns the directory of the image configuration holding the os-files specific to the given node.
+func NodeOSFilesPath(ctx *image.Context, override image.NodeOverride) string {
+	return filepath.Join(NodeOverridePath(ctx, override), osFilesConfigDir)
+}
+
+// NodeScriptsPath returns the directory of the image configuration holding the scripts specific to the given node.
+func NodeScriptsPath(ctx *image.Context, override image.NodeOverride) string {
+	return filepath.Join(NodeOverridePath(ctx, override), nodeScriptsDir)
+}
+
+// nodeOverrides lists the node overrides matching the given condition.
+func nodeOverrides(ctx *image.Context, matches func(override image.NodeOverride) bool) []image.NodeOverride {
+	return slices.DeleteFunc(slices.Clone(ctx.ImageDefinition.NodeOverrides), func(override image.NodeOverride) bool {
+		return !matches(override)
+	})
+}
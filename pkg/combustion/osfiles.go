@@ -8,7 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"go.uber.org/zap"
 )
 
@@ -20,28 +19,42 @@ const (
 )
 
 var (
-	//go:embed templates/19-copy-os-files.sh
+	//go:embed templates/19-copy-os-files.sh.tpl
 	osFilesScript string
 )
 
 func configureOSFiles(ctx *image.Context) ([]string, error) {
-	if !isComponentConfigured(ctx, osFilesConfigDir) {
-		log.AuditComponentSkipped(osFilesComponentName)
+	shared := isComponentConfigured(ctx, osFilesConfigDir)
+	nodes := nodeOverrides(ctx, func(override image.NodeOverride) bool {
+		return isComponentConfigured(ctx, filepath.Join(nodeOverridesDir, override.ID(), osFilesConfigDir))
+	})
+
+	if !shared && len(nodes) == 0 {
+		ctx.Output.AuditComponentSkipped(osFilesComponentName)
 		zap.S().Info("skipping os files component, no files provided")
 		return nil, nil
 	}
 
-	if err := copyOSFiles(ctx); err != nil {
-		log.AuditComponentFailed(osFilesComponentName)
-		return nil, err
+	if shared {
+		if err := copyOSFiles(ctx); err != nil {
+			ctx.Output.AuditComponentFailed(osFilesComponentName)
+			return nil, err
+		}
+	}
+
+	for _, node := range nodes {
+		if err := copyNodeOSFiles(ctx, node); err != nil {
+			ctx.Output.AuditComponentFailed(osFilesComponentName)
+			return nil, err
+		}
 	}
 
-	if err := writeOSFilesScript(ctx); err != nil {
-		log.AuditComponentFailed(osFilesComponentName)
+	if err := writeOSFilesScript(ctx, shared, nodes); err != nil {
+		ctx.Output.AuditComponentFailed(osFilesComponentName)
 		return nil, err
 	}
 
-	log.AuditComponentSuccessful(osFilesComponentName)
+	ctx.Output.AuditComponentSuccessful(osFilesComponentName)
 	return []string{osFilesScriptName}, nil
 }
 
@@ -49,6 +62,17 @@ func copyOSFiles(ctx *image.Context) error {
 	srcDirectory := filepath.Join(ctx.ImageConfigDir, osFilesConfigDir)
 	destDirectory := filepath.Join(ctx.CombustionDir, osFilesConfigDir)
 
+	return copyOSFilesDir(srcDirectory, destDirectory)
+}
+
+func copyNodeOSFiles(ctx *image.Context, node image.NodeOverride) error {
+	srcDirectory := NodeOSFilesPath(ctx, node)
+	destDirectory := filepath.Join(ctx.CombustionDir, nodeOverridesDir, node.ID(), osFilesConfigDir)
+
+	return copyOSFilesDir(srcDirectory, destDirectory)
+}
+
+func copyOSFilesDir(srcDirectory, destDirectory string) error {
 	dirEntries, err := os.ReadDir(srcDirectory)
 	if err != nil {
 		return fmt.Errorf("reading the os files directory at %s: %w", srcDirectory, err)
@@ -59,17 +83,30 @@ func copyOSFiles(ctx *image.Context) error {
 		return fmt.Errorf("no files found in directory %s", srcDirectory)
 	}
 
-	if err := fileio.CopyFiles(srcDirectory, destDirectory, "", true, nil); err != nil {
+	if err = fileio.CopyFiles(srcDirectory, destDirectory, "", true, nil); err != nil {
 		return fmt.Errorf("copying os-files: %w", err)
 	}
 
 	return nil
 }
 
-func writeOSFilesScript(ctx *image.Context) error {
+func writeOSFilesScript(ctx *image.Context, shared bool, nodes []image.NodeOverride) error {
+	values := struct {
+		Shared bool
+		Nodes  []image.NodeOverride
+	}{
+		Shared: shared,
+		Nodes:  nodes,
+	}
+
+	data, err := parseNodeTemplate(osFilesScriptName, osFilesScript, values)
+	if err != nil {
+		return fmt.Errorf("applying os files script template: %w", err)
+	}
+
 	osFilesScriptFilename := filepath.Join(ctx.CombustionDir, osFilesScriptName)
 
-	if err := os.WriteFile(osFilesScriptFilename, []byte(osFilesScript), fileio.ExecutablePerms); err != nil {
+	if err = os.WriteFile(osFilesScriptFilename, []byte(data), fileio.ExecutablePerms); err != nil {
 		return fmt.Errorf("writing os files script %s: %w", osFilesScriptFilename, err)
 	}
 
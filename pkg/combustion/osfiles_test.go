@@ -69,3 +69,36 @@ func TestConfigureOSFiles_EmptyDirectory(t *testing.T) {
 	srcDirectory := filepath.Join(ctx.ImageConfigDir, osFilesConfigDir)
 	assert.EqualError(t, err, fmt.Sprintf("no files found in directory %s", srcDirectory))
 }
+
+func TestConfigureOSFiles_NodeOverrides(t *testing.T) {
+	// Setup
+	ctx, teardown := setupContext(t)
+	defer teardown()
+
+	node1 := image.NodeOverride{Hostname: "node1.example.com"}
+	node2 := image.NodeOverride{MACAddress: "34:8a:b1:4b:16:e2"}
+	ctx.ImageDefinition = &image.Definition{
+		NodeOverrides: []image.NodeOverride{node1, node2},
+	}
+
+	nodeOSFilesDir := filepath.Join(NodeOSFilesPath(ctx, node1), "etc")
+	require.NoError(t, os.MkdirAll(nodeOSFilesDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(nodeOSFilesDir, "motd"), []byte("node1"), 0o600))
+
+	// Test
+	scriptNames, err := configureOSFiles(ctx)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, []string{osFilesScriptName}, scriptNames)
+
+	// -- Combustion Script
+	contents, err := os.ReadFile(filepath.Join(ctx.CombustionDir, osFilesScriptName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(contents), "cp -R ./os-files/* /")
+	assert.Contains(t, string(contents), "if is_node 'node1.example.com'; then\n  cp -R './node-overrides/node1.example.com/os-files/'* /\nfi")
+	assert.NotContains(t, string(contents), node2.MACAddress)
+
+	// -- Files
+	assert.FileExists(t, filepath.Join(ctx.CombustionDir, nodeOverridesDir, node1.ID(), osFilesConfigDir, "etc", "motd"))
+}
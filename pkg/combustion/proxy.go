@@ -9,7 +9,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 )
 
@@ -24,16 +23,16 @@ var proxyScript string
 func configureProxy(ctx *image.Context) ([]string, error) {
 	proxy := ctx.ImageDefinition.OperatingSystem.Proxy
 	if proxy.HTTPProxy == "" && proxy.HTTPSProxy == "" {
-		log.AuditComponentSkipped(proxyComponentName)
+		ctx.Output.AuditComponentSkipped(proxyComponentName)
 		return nil, nil
 	}
 
 	if err := writeProxyCombustionScript(ctx); err != nil {
-		log.AuditComponentFailed(proxyComponentName)
+		ctx.Output.AuditComponentFailed(proxyComponentName)
 		return nil, err
 	}
 
-	log.AuditComponentSuccessful(proxyComponentName)
+	ctx.Output.AuditComponentSuccessful(proxyComponentName)
 	return []string{proxyScriptName}, nil
 }
 
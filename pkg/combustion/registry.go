@@ -1,19 +1,26 @@
 package combustion
 
 import (
+	"archive/tar"
+	"context"
 	_ "embed"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
-	"os/exec"
+	"path"
 	"path/filepath"
 	"slices"
 	"strings"
 
-	"github.com/schollz/progressbar/v3"
+	"github.com/klauspost/compress/zstd"
+	"github.com/suse-edge/edge-image-builder/pkg/cache"
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/http"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
+	"github.com/suse-edge/edge-image-builder/pkg/process"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 	"go.uber.org/zap"
 )
@@ -26,6 +33,11 @@ const (
 	registryDir             = "registry"
 	registryPort            = "6545"
 	registryMirrorsFileName = "registries.yaml"
+	// registryIndexFile is the OCI image index listing the contents of the registry archive.
+	registryIndexFile = "index.json"
+	// haulerKindAnnotation distinguishes the images stored by hauler from their signatures and attestations.
+	haulerKindAnnotation = "kind"
+	haulerKindImage      = "dev.cosignproject.cosign/image"
 )
 
 var (
@@ -38,46 +50,46 @@ var (
 
 func (c *Combustion) configureRegistry(ctx *image.Context) ([]string, error) {
 	if !IsEmbeddedArtifactRegistryConfigured(ctx) {
-		log.AuditComponentSkipped(registryComponentName)
+		ctx.Output.AuditComponentSkipped(registryComponentName)
 		return nil, nil
 	}
 
-	images, err := c.Registry.ContainerImages()
+	images, err := c.Registry.ContainerImages(ctx.Context())
 	if err != nil {
-		log.AuditComponentFailed(registryComponentName)
+		ctx.Output.AuditComponentFailed(registryComponentName)
 		return nil, fmt.Errorf("extracting container images: %w", err)
 	}
 
 	if len(images) == 0 {
-		log.AuditComponentSkipped(registryComponentName)
+		ctx.Output.AuditComponentSkipped(registryComponentName)
 		zap.S().Info("Skipping embedded artifact registry since the provided manifests/helm charts contain no images")
 		return nil, nil
 	}
 
 	script, err := c.configureEmbeddedArtifactRegistry(ctx, images)
 	if err != nil {
-		log.AuditComponentFailed(registryComponentName)
+		ctx.Output.AuditComponentFailed(registryComponentName)
 		return nil, fmt.Errorf("configuring embedded artifact registry: %w", err)
 	}
 
-	log.AuditComponentSuccessful(registryComponentName)
+	ctx.Output.AuditComponentSuccessful(registryComponentName)
 	return []string{script}, nil
 }
 
-func storeImage(containerImage, arch string, outputWriter io.Writer) error {
+func storeImage(ctx context.Context, containerImage, arch string, outputWriter io.Writer) error {
 	args := []string{"store", "add", "image", containerImage, "-p", fmt.Sprintf("linux/%s", arch)}
 
-	cmd := exec.Command(hauler, args...)
+	cmd := process.Command(ctx, hauler, args...)
 	cmd.Stdout = outputWriter
 	cmd.Stderr = outputWriter
 
 	return cmd.Run()
 }
 
-func generateRegistryTar(imageTarDest string, outputWriter io.Writer) error {
+func generateRegistryTar(ctx context.Context, imageTarDest string, outputWriter io.Writer) error {
 	args := []string{"store", "save", "--filename", imageTarDest}
 
-	cmd := exec.Command(hauler, args...)
+	cmd := process.Command(ctx, hauler, args...)
 	cmd.Stdout = outputWriter
 	cmd.Stderr = outputWriter
 
@@ -184,7 +196,7 @@ func (c *Combustion) configureEmbeddedArtifactRegistry(ctx *image.Context, conta
 		return "", fmt.Errorf("creating registry dir: %w", err)
 	}
 
-	if err := populateRegistry(ctx, containerImages); err != nil {
+	if err := c.populateRegistry(ctx, containerImages); err != nil {
 		return "", fmt.Errorf("populating registry: %w", err)
 	}
 
@@ -206,13 +218,8 @@ func registryArtefactsPath(ctx *image.Context) string {
 	return filepath.Join(ctx.ArtefactsDir, registryDir)
 }
 
-func populateRegistry(ctx *image.Context, images []string) error {
-	imageCacheDir := filepath.Join(ctx.CacheDir, "images")
-	if err := os.MkdirAll(imageCacheDir, os.ModePerm); err != nil {
-		return fmt.Errorf("creating container image cache dir: %w", err)
-	}
-
-	bar := progressbar.Default(int64(len(images)), "Populating Embedded Artifact Registry...")
+func (c *Combustion) populateRegistry(ctx *image.Context, images []string) error {
+	bar := ctx.Output.NewProgressBar(int64(len(images)), "Populating Embedded Artifact Registry...")
 	zap.S().Infof("Adding the following images to the embedded artifact registry:\n%s", images)
 
 	const registryLogFileName = "embedded-registry.log"
@@ -231,30 +238,45 @@ func populateRegistry(ctx *image.Context, images []string) error {
 
 	arch := ctx.ImageDefinition.Image.Arch.Short()
 
+	if http.IsOffline() {
+		if missing := c.uncachedImages(arch, images); len(missing) != 0 {
+			return fmt.Errorf("container images not available in cache: %s: %w", strings.Join(missing, ", "), http.ErrOffline)
+		}
+	}
+
 	for _, img := range images {
-		convertedImage := strings.ReplaceAll(img, "/", "_")
-		convertedImageName := fmt.Sprintf("%s-%s", convertedImage, registryTarSuffix)
+		cacheKey := ContainerImageCacheIdentifier(arch, img)
+		imageTarDest := filepath.Join(registryArtefactsPath(ctx), containerImageTarName(img))
 
-		imageCacheLocation := filepath.Join(imageCacheDir, convertedImageName)
-		imageTarDest := filepath.Join(registryArtefactsPath(ctx), convertedImageName)
+		var copied bool
+		copied, err = c.copyImageFromCache(img, cacheKey, imageTarDest)
+		if err != nil {
+			return fmt.Errorf("retrieving cached container image: %w", err)
+		}
 
-		if fileio.FileExists(imageCacheLocation) {
-			if err = fileio.CopyFile(imageCacheLocation, imageTarDest, fileio.NonExecutablePerms); err != nil {
-				return fmt.Errorf("copying cached container image: %w", err)
-			}
-		} else {
-			if err = storeImage(img, arch, logFile); err != nil {
+		if !copied {
+			if err = storeImage(ctx.Context(), img, arch, logFile); err != nil {
 				return fmt.Errorf("adding image to registry store: %w", err)
 			}
 
-			if err = generateRegistryTar(imageTarDest, logFile); err != nil {
+			if err = generateRegistryTar(ctx.Context(), imageTarDest, logFile); err != nil {
 				return fmt.Errorf("generating registry store tarball: %w", err)
 			}
 
-			if err = fileio.CopyFile(imageTarDest, imageCacheLocation, fileio.NonExecutablePerms); err != nil {
-				return fmt.Errorf("copying container image to cache: %w", err)
+			if err = c.cacheImage(cacheKey, img, imageTarDest); err != nil {
+				return fmt.Errorf("storing container image in cache: %w", err)
 			}
 		}
+
+		var digest string
+		if digest, err = registryImageDigest(imageTarDest); err != nil {
+			return fmt.Errorf("reading digest of container image %s: %w", img, err)
+		}
+
+		if err = ctx.Recorder().AddContainerImage(img, string(ctx.ImageDefinition.Image.Arch), digest, imageTarDest); err != nil {
+			return fmt.Errorf("recording container image: %w", err)
+		}
+
 		if err = bar.Add(1); err != nil {
 			zap.S().Debugf("Error incrementing the progress bar: %s", err)
 		}
@@ -262,3 +284,132 @@ func populateRegistry(ctx *image.Context, images []string) error {
 
 	return nil
 }
+
+// copyImageFromCache reports whether the image was copied from the cache. Images which are not
+// cached are pulled instead, unless network access is disabled.
+func (c *Combustion) copyImageFromCache(img, cacheKey, destPath string) (bool, error) {
+	if c.Cache == nil {
+		return false, nil
+	}
+
+	if err := c.Cache.CopyTo(cacheKey, destPath, fileio.NonExecutablePerms); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			if http.IsOffline() {
+				return false, fmt.Errorf("container image %s not available in cache: %w", img, http.ErrOffline)
+			}
+
+			return false, nil
+		}
+
+		if errors.Is(err, cache.ErrCorrupted) {
+			if http.IsOffline() {
+				return false, fmt.Errorf("cached container image %s is corrupted: %w", img, http.ErrOffline)
+			}
+
+			zap.S().Warnf("Cached container image with identifier '%s' is corrupted and will be pulled again: %v", cacheKey, err)
+			return false, nil
+		}
+
+		return false, fmt.Errorf("copying from cache: %w", err)
+	}
+
+	return true, nil
+}
+
+func (c *Combustion) cacheImage(cacheKey, containerImage, imageTarPath string) error {
+	if c.Cache == nil {
+		return nil
+	}
+
+	file, err := os.Open(imageTarPath)
+	if err != nil {
+		return fmt.Errorf("opening image tarball: %w", err)
+	}
+	defer file.Close()
+
+	if err = c.Cache.Put(cacheKey, containerImage, file); err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("caching image tarball: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Combustion) uncachedImages(arch string, images []string) []string {
+	var missing []string
+
+	for _, img := range images {
+		if c.Cache == nil {
+			missing = append(missing, img)
+			continue
+		}
+
+		if !c.Cache.Contains(ContainerImageCacheIdentifier(arch, img)) {
+			missing = append(missing, img)
+		}
+	}
+
+	slices.Sort(missing)
+	return missing
+}
+
+// ContainerImageCacheIdentifier returns the cache identifier of the registry tarball for the given image and architecture.
+func ContainerImageCacheIdentifier(arch, containerImage string) string {
+	return fmt.Sprintf("images/%s/%s", arch, containerImageTarName(containerImage))
+}
+
+// registryImageDigest returns the digest of the image manifest hauler resolved when adding the image to the
+// registry archive, as listed in the index of the archive.
+func registryImageDigest(archivePath string) (string, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("opening registry archive: %w", err)
+	}
+	defer file.Close()
+
+	decoder, err := zstd.NewReader(file)
+	if err != nil {
+		return "", fmt.Errorf("decompressing registry archive: %w", err)
+	}
+	defer decoder.Close()
+
+	reader := tar.NewReader(decoder)
+	for {
+		var header *tar.Header
+
+		header, err = reader.Next()
+		if errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("registry archive does not contain '%s'", registryIndexFile)
+		}
+		if err != nil {
+			return "", fmt.Errorf("reading registry archive: %w", err)
+		}
+
+		if path.Base(header.Name) != registryIndexFile {
+			continue
+		}
+
+		var index struct {
+			Manifests []struct {
+				Digest      string            `json:"digest"`
+				Annotations map[string]string `json:"annotations"`
+			} `json:"manifests"`
+		}
+
+		if err = json.NewDecoder(reader).Decode(&index); err != nil {
+			return "", fmt.Errorf("decoding registry index: %w", err)
+		}
+
+		for _, manifest := range index.Manifests {
+			if kind, ok := manifest.Annotations[haulerKindAnnotation]; !ok || kind == haulerKindImage {
+				return manifest.Digest, nil
+			}
+		}
+
+		return "", fmt.Errorf("registry index does not list an image")
+	}
+}
+
+func containerImageTarName(containerImage string) string {
+	convertedImage := strings.ReplaceAll(containerImage, "/", "_")
+	return fmt.Sprintf("%s-%s", convertedImage, registryTarSuffix)
+}
@@ -1,15 +1,39 @@
 package combustion
 
 import (
+	"archive/tar"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/cache"
+	"github.com/suse-edge/edge-image-builder/pkg/http"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
 )
 
+type corruptedCache struct{}
+
+func (corruptedCache) CopyTo(identifier, _ string, _ os.FileMode) error {
+	return fmt.Errorf("%w: digest mismatch for %s", cache.ErrCorrupted, identifier)
+}
+
+func (corruptedCache) Contains(string) bool {
+	return true
+}
+
+func (corruptedCache) Put(string, string, io.Reader) error {
+	return nil
+}
+
+func (corruptedCache) Remove(string) error {
+	return nil
+}
+
 func TestWriteRegistryScript(t *testing.T) {
 	// Setup
 	ctx, teardown := setupContext(t)
@@ -180,3 +204,100 @@ func TestGetImageHostnames(t *testing.T) {
 	// Verify
 	assert.Equal(t, expectedHostnames, hostnames)
 }
+
+func TestPopulateRegistry_CorruptedCacheOffline(t *testing.T) {
+	ctx, teardown := setupContext(t)
+	defer teardown()
+
+	ctx.ImageDefinition.Image.Arch = image.ArchTypeX86
+	require.NoError(t, os.Mkdir(registryArtefactsPath(ctx), os.ModePerm))
+
+	require.NoError(t, http.Configure(http.Config{Offline: true}))
+	t.Cleanup(func() {
+		require.NoError(t, http.Configure(http.Config{}))
+	})
+
+	c := Combustion{Cache: corruptedCache{}}
+
+	// The image would otherwise be pulled again, which requires network access
+	err := c.populateRegistry(ctx, []string{"hello-world:latest"})
+	require.ErrorIs(t, err, http.ErrOffline)
+	assert.EqualError(t, err, "retrieving cached container image: cached container image hello-world:latest is corrupted: "+
+		"network access is disabled in offline mode")
+	assert.NoFileExists(t, filepath.Join(registryArtefactsPath(ctx), containerImageTarName("hello-world:latest")))
+}
+
+func writeRegistryArchive(t *testing.T, files map[string]string) string {
+	archivePath := filepath.Join(t.TempDir(), containerImageTarName("hello-world:latest"))
+
+	file, err := os.Create(archivePath)
+	require.NoError(t, err)
+	defer file.Close()
+
+	encoder, err := zstd.NewWriter(file)
+	require.NoError(t, err)
+
+	writer := tar.NewWriter(encoder)
+	for name, contents := range files {
+		require.NoError(t, writer.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(contents))}))
+		_, err = writer.Write([]byte(contents))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, writer.Close())
+	require.NoError(t, encoder.Close())
+
+	return archivePath
+}
+
+func TestRegistryImageDigest(t *testing.T) {
+	tests := map[string]struct {
+		files          map[string]string
+		expectedDigest string
+		expectedErr    string
+	}{
+		"image and signature": {
+			files: map[string]string{
+				"oci-layout": `{"imageLayoutVersion": "1.0.0"}`,
+				"index.json": `{"schemaVersion": 2, "manifests": [
+  {"digest": "sha256:5b3c", "annotations": {"kind": "dev.cosignproject.cosign/sigs"}},
+  {"digest": "sha256:4c0f", "annotations": {"kind": "dev.cosignproject.cosign/image",
+    "io.containerd.image.name": "docker.io/library/hello-world:latest"}}
+]}`,
+			},
+			expectedDigest: "sha256:4c0f",
+		},
+		"image without annotations": {
+			files: map[string]string{
+				"index.json": `{"schemaVersion": 2, "manifests": [{"digest": "sha256:4c0f"}]}`,
+			},
+			expectedDigest: "sha256:4c0f",
+		},
+		"no image": {
+			files: map[string]string{
+				"index.json": `{"schemaVersion": 2, "manifests": []}`,
+			},
+			expectedErr: "registry index does not list an image",
+		},
+		"no index": {
+			files: map[string]string{
+				"oci-layout": `{"imageLayoutVersion": "1.0.0"}`,
+			},
+			expectedErr: "registry archive does not contain 'index.json'",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			digest, err := registryImageDigest(writeRegistryArchive(t, test.files))
+
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedDigest, digest)
+		})
+	}
+}
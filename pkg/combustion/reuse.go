@@ -0,0 +1,486 @@
+package combustion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/http"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
+	"github.com/suse-edge/edge-image-builder/pkg/version"
+	"go.uber.org/zap"
+)
+
+const (
+	storedCombustionDir = "combustion"
+	storedArtefactsDir  = "artefacts"
+	storedComponentFile = "component.json"
+
+	// storeDirPerms restrict the access to the stored outputs, which may contain secrets
+	// such as encrypted passwords and registration keys, to the user running the builds.
+	storeDirPerms = os.FileMode(0o700)
+)
+
+// ComponentInputs declares everything the outputs of a component are derived from,
+// so that they can be reused by subsequent builds for as long as these inputs are unchanged.
+type ComponentInputs struct {
+	// Definition returns the part of the image definition used by the component.
+	Definition func(definition *image.Definition) any
+	// ConfigPaths lists the files and directories of the image configuration directory used by the component.
+	ConfigPaths []string
+	// ArtifactSources indicates that the component uses the artifact sources (e.g. the Kubernetes release URLs).
+	ArtifactSources bool
+	// BaseImage indicates that the component uses the base image (e.g. to resolve the RPM packages against).
+	// Base images are identified by their path, size and modification time rather than their contents.
+	BaseImage bool
+}
+
+// ComponentStore keeps the outputs of the components configured by previous builds of an architecture.
+type ComponentStore struct {
+	dir string
+	// reuse is disabled to configure all components while still storing their outputs for subsequent builds.
+	reuse bool
+	// report collects the details recorded while configuring the components, whether they are reused or not.
+	report *report.Report
+}
+
+func NewComponentStore(dir string, reuse bool, buildReport *report.Report) *ComponentStore {
+	return &ComponentStore{
+		dir:    dir,
+		reuse:  reuse,
+		report: buildReport,
+	}
+}
+
+type storedComponent struct {
+	Fingerprint string   `json:"fingerprint"`
+	Scripts     []string `json:"scripts"`
+	// Report holds the artefacts, packages and other details recorded while configuring the component.
+	// The paths of the artefacts stored alongside the component are relative to the stored component.
+	Report *report.Report `json:"report"`
+}
+
+// configure restores the outputs of the component if its inputs are unchanged since the outputs were stored.
+// Otherwise, the component is configured and its outputs are stored for subsequent builds.
+func (s *ComponentStore) configure(ctx *image.Context, component Component) ([]string, error) {
+	fingerprint, err := fingerprintComponent(ctx, component.Name, component.Inputs)
+	if err != nil {
+		zap.S().Warnf("Fingerprinting the inputs of component '%s' failed, its outputs will not be stored: %v", component.Name, err)
+		return component.Configure(ctx)
+	}
+
+	if s.reuse {
+		scripts, reused, reuseErr := s.reuseStored(ctx, component.Name, fingerprint)
+		if reuseErr != nil {
+			return nil, fmt.Errorf("restoring outputs of a previous build: %w", reuseErr)
+		}
+
+		if reused {
+			zap.S().Infof("Reusing the outputs of component '%s' with fingerprint %s", component.Name, fingerprint)
+			ctx.Output.AuditComponentReused(component.Name)
+
+			return scripts, nil
+		}
+	}
+
+	return s.configureAndStore(ctx, component, fingerprint)
+}
+
+// reuseStored restores the stored outputs of the component if they match the given fingerprint.
+// The outputs are locked against concurrent builds replacing them in the meantime.
+func (s *ComponentStore) reuseStored(ctx *image.Context, name, fingerprint string) (scripts []string, reused bool, err error) {
+	l, err := s.lock(name, fileio.AcquireSharedLock)
+	if err != nil {
+		zap.S().Warnf("Locking the stored outputs of component '%s' failed, they will not be reused: %v", name, err)
+		return nil, false, nil
+	}
+	defer s.unlock(name, l)
+
+	stored := s.lookup(name, fingerprint)
+	if stored == nil {
+		return nil, false, nil
+	}
+
+	if err = s.restore(ctx, name, stored); err != nil {
+		return nil, false, err
+	}
+
+	return stored.Scripts, true, nil
+}
+
+// lock acquires the lock guarding the stored outputs of the component through the given function.
+func (s *ComponentStore) lock(name string, acquire func(path string) (*fileio.Lock, error)) (*fileio.Lock, error) {
+	if err := os.MkdirAll(s.dir, storeDirPerms); err != nil {
+		return nil, fmt.Errorf("creating store directory: %w", err)
+	}
+
+	// Directories created by previous versions may be accessible to other users
+	if err := os.Chmod(s.dir, storeDirPerms); err != nil {
+		return nil, fmt.Errorf("restricting store directory permissions: %w", err)
+	}
+
+	return acquire(filepath.Join(s.dir, name+".lock"))
+}
+
+func (s *ComponentStore) unlock(name string, l *fileio.Lock) {
+	if err := l.Release(); err != nil {
+		zap.S().Warnf("Releasing the lock of component '%s' failed: %v", name, err)
+	}
+}
+
+// configureAndStore configures the component against a staging directory, which exactly captures its outputs.
+// The outputs are then moved to the build directory and stored for subsequent builds.
+func (s *ComponentStore) configureAndStore(ctx *image.Context, component Component, fingerprint string) ([]string, error) {
+	stagingDir, err := os.MkdirTemp(ctx.BuildDir, fmt.Sprintf("component-%s-", component.Name))
+	if err != nil {
+		return nil, fmt.Errorf("creating staging directory: %w", err)
+	}
+	defer func() {
+		if removeErr := os.RemoveAll(stagingDir); removeErr != nil {
+			zap.S().Warnf("Removing staging directory '%s' failed: %v", stagingDir, removeErr)
+		}
+	}()
+
+	componentReport := report.New(version.GetEibVersion())
+
+	componentCtx := ctx.WithContext(http.WithRecorder(ctx.Context(), componentReport.ForArch(string(ctx.ImageDefinition.Image.Arch))))
+	componentCtx = componentCtx.WithRecorder(componentReport)
+	componentCtx.CombustionDir = filepath.Join(stagingDir, storedCombustionDir)
+	componentCtx.ArtefactsDir = filepath.Join(stagingDir, storedArtefactsDir)
+
+	for _, dir := range []string{componentCtx.CombustionDir, componentCtx.ArtefactsDir} {
+		if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+			return nil, fmt.Errorf("creating staging directory: %w", err)
+		}
+	}
+
+	scripts, err := component.Configure(componentCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	relativizeArtefacts(componentReport, stagingDir)
+
+	// Components which did not produce any scripts have been skipped and are cheap to configure again
+	if len(scripts) != 0 {
+		stored := &storedComponent{
+			Fingerprint: fingerprint,
+			Scripts:     scripts,
+			Report:      componentReport,
+		}
+
+		if err = s.store(component.Name, stagingDir, stored); err != nil {
+			zap.S().Warnf("Storing the outputs of component '%s' failed: %v", component.Name, err)
+		}
+	}
+
+	if err = s.restoreOutputs(ctx, stagingDir, componentReport); err != nil {
+		return nil, fmt.Errorf("moving outputs to build directory: %w", err)
+	}
+
+	return scripts, nil
+}
+
+// lookup returns the stored outputs of the component if they match the given fingerprint.
+func (s *ComponentStore) lookup(name, fingerprint string) *storedComponent {
+	data, err := os.ReadFile(filepath.Join(s.dir, name, storedComponentFile))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			zap.S().Warnf("Reading the stored outputs of component '%s' failed: %v", name, err)
+		}
+
+		return nil
+	}
+
+	var stored storedComponent
+	if err = json.Unmarshal(data, &stored); err != nil {
+		zap.S().Warnf("Parsing the stored outputs of component '%s' failed: %v", name, err)
+		return nil
+	}
+
+	if stored.Fingerprint != fingerprint {
+		zap.S().Infof("Inputs of component '%s' changed since its outputs were stored", name)
+		return nil
+	}
+
+	return &stored
+}
+
+func (s *ComponentStore) restore(ctx *image.Context, name string, stored *storedComponent) error {
+	return s.restoreOutputs(ctx, filepath.Join(s.dir, name), stored.Report)
+}
+
+// store replaces the stored outputs of the component with the ones in the given directory.
+func (s *ComponentStore) store(name, outputDir string, stored *storedComponent) error {
+	l, err := s.lock(name, fileio.AcquireLock)
+	if err != nil {
+		return fmt.Errorf("locking stored outputs: %w", err)
+	}
+	defer s.unlock(name, l)
+
+	// The outputs are assembled aside, so that an interrupted build does not leave incomplete outputs behind
+	tmpDir, err := os.MkdirTemp(s.dir, fmt.Sprintf(".%s-", name))
+	if err != nil {
+		return fmt.Errorf("creating temporary directory: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	for _, dir := range []string{storedCombustionDir, storedArtefactsDir} {
+		if err = linkTree(filepath.Join(outputDir, dir), filepath.Join(tmpDir, dir)); err != nil {
+			return fmt.Errorf("storing %s files: %w", dir, err)
+		}
+	}
+
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("marshalling component: %w", err)
+	}
+
+	if err = os.WriteFile(filepath.Join(tmpDir, storedComponentFile), data, fileio.NonExecutablePerms); err != nil {
+		return fmt.Errorf("writing component: %w", err)
+	}
+
+	componentDir := filepath.Join(s.dir, name)
+	if err = os.RemoveAll(componentDir); err != nil {
+		return fmt.Errorf("removing previous outputs: %w", err)
+	}
+
+	if err = os.Rename(tmpDir, componentDir); err != nil {
+		return fmt.Errorf("moving outputs: %w", err)
+	}
+
+	return nil
+}
+
+// restoreOutputs places the outputs of a component found in the given directory in the build directory
+// and records the details collected while configuring the component in the build report.
+// The paths of the artefacts recorded in the component report are updated accordingly.
+func (s *ComponentStore) restoreOutputs(ctx *image.Context, outputDir string, componentReport *report.Report) error {
+	if err := linkTree(filepath.Join(outputDir, storedCombustionDir), ctx.CombustionDir); err != nil {
+		return fmt.Errorf("restoring combustion files: %w", err)
+	}
+
+	if err := linkTree(filepath.Join(outputDir, storedArtefactsDir), ctx.ArtefactsDir); err != nil {
+		return fmt.Errorf("restoring artefacts: %w", err)
+	}
+
+	if componentReport == nil {
+		return nil
+	}
+
+	// Locate the artefacts stored alongside the component in the build directory
+	for i := range componentReport.Artefacts {
+		artefact := &componentReport.Artefacts[i]
+		if filepath.IsAbs(artefact.Path) {
+			continue
+		}
+
+		switch dir, path, _ := strings.Cut(artefact.Path, string(filepath.Separator)); dir {
+		case storedCombustionDir:
+			artefact.Path = filepath.Join(ctx.CombustionDir, path)
+		case storedArtefactsDir:
+			artefact.Path = filepath.Join(ctx.ArtefactsDir, path)
+		}
+	}
+
+	s.report.Merge(componentReport)
+
+	return nil
+}
+
+// relativizeArtefacts records the paths of the artefacts retrieved into the given directory relative to it,
+// so that the artefacts can be located in the build directory of any build reusing them.
+func relativizeArtefacts(componentReport *report.Report, dir string) {
+	for i := range componentReport.Artefacts {
+		path, err := filepath.Rel(dir, componentReport.Artefacts[i].Path)
+		if err == nil && filepath.IsLocal(path) {
+			componentReport.Artefacts[i].Path = path
+		}
+	}
+}
+
+// linkTree hard links all files under the source directory to the same locations under the destination directory,
+// replacing existing ones. Files are copied instead if they cannot be linked, e.g. across file systems.
+// A missing source directory is ignored.
+func linkTree(src, dest string) error {
+	if _, err := os.Stat(src); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(dest, relPath)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return os.MkdirAll(destPath, info.Mode().Perm())
+		}
+
+		if err = os.Remove(destPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+
+		if d.Type()&fs.ModeSymlink != 0 {
+			var target string
+			if target, err = os.Readlink(path); err != nil {
+				return err
+			}
+
+			return os.Symlink(target, destPath)
+		}
+
+		if err = os.Link(path, destPath); err != nil {
+			return fileio.CopyFile(path, destPath, info.Mode().Perm())
+		}
+
+		return nil
+	})
+}
+
+type componentFingerprint struct {
+	Version         string                 `json:"version"`
+	Component       string                 `json:"component"`
+	Arch            image.Arch             `json:"arch"`
+	Definition      any                    `json:"definition,omitempty"`
+	ArtifactSources *image.ArtifactSources `json:"artifactSources,omitempty"`
+	ConfigFiles     []configFile           `json:"configFiles,omitempty"`
+	BaseImage       *baseImageFile         `json:"baseImage,omitempty"`
+}
+
+type configFile struct {
+	Path   string      `json:"path"`
+	Mode   fs.FileMode `json:"mode"`
+	SHA256 string      `json:"sha256,omitempty"`
+}
+
+type baseImageFile struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// fingerprintComponent computes a digest of the declared inputs of the component.
+// The version of EIB is included, since changes to the components affect their outputs as well.
+func fingerprintComponent(ctx *image.Context, name string, inputs *ComponentInputs) (string, error) {
+	fingerprint := componentFingerprint{
+		Version:   version.GetEibVersion(),
+		Component: name,
+		Arch:      ctx.ImageDefinition.Image.Arch,
+	}
+
+	if inputs.Definition != nil {
+		fingerprint.Definition = inputs.Definition(ctx.ImageDefinition)
+	}
+
+	if inputs.ArtifactSources {
+		fingerprint.ArtifactSources = ctx.ArtifactSources
+	}
+
+	for _, configPath := range inputs.ConfigPaths {
+		files, err := fingerprintConfigPath(ctx.ImageConfigDir, configPath)
+		if err != nil {
+			return "", fmt.Errorf("fingerprinting '%s': %w", configPath, err)
+		}
+
+		fingerprint.ConfigFiles = append(fingerprint.ConfigFiles, files...)
+	}
+
+	if inputs.BaseImage {
+		baseImagePath := filepath.Join(ctx.ImageConfigDir, "base-images", ctx.ImageDefinition.Image.Targets()[0].BaseImage)
+
+		info, err := os.Stat(baseImagePath)
+		if err != nil {
+			return "", fmt.Errorf("describing base image: %w", err)
+		}
+
+		fingerprint.BaseImage = &baseImageFile{Path: baseImagePath, Size: info.Size(), Modified: info.ModTime().UTC()}
+	}
+
+	data, err := json.Marshal(fingerprint)
+	if err != nil {
+		return "", fmt.Errorf("marshalling inputs: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:]), nil
+}
+
+// fingerprintConfigPath describes the files and directories found at the given path relative to the configuration directory.
+// Nothing is returned for a missing path.
+func fingerprintConfigPath(configDir, configPath string) ([]configFile, error) {
+	var files []configFile
+
+	root := filepath.Join(configDir, configPath)
+	if _, err := os.Stat(root); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(configDir, path)
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		file := configFile{Path: relPath, Mode: info.Mode()}
+
+		if !info.IsDir() {
+			if file.SHA256, err = fileDigest(path); err != nil {
+				return err
+			}
+		}
+
+		files = append(files, file)
+		return nil
+	})
+
+	return files, err
+}
+
+func fileDigest(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("reading file: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,260 @@
+package combustion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/log"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
+)
+
+func TestFingerprintComponent(t *testing.T) {
+	setupConfigDir := func(t *testing.T) string {
+		configDir := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(configDir, "os-files", "etc"), os.ModePerm))
+		require.NoError(t, os.WriteFile(filepath.Join(configDir, "os-files", "etc", "motd"), []byte("welcome"), 0o600))
+
+		return configDir
+	}
+
+	definition := func() *image.Definition {
+		return &image.Definition{
+			Image: image.Image{Arch: image.ArchTypeX86},
+			OperatingSystem: image.OperatingSystem{
+				Systemd: image.Systemd{Enable: []string{"sshd"}},
+				Proxy:   image.Proxy{HTTPProxy: "http://proxy:3128"},
+			},
+		}
+	}
+
+	inputs := &ComponentInputs{
+		Definition:  func(d *image.Definition) any { return d.OperatingSystem.Systemd },
+		ConfigPaths: []string{"os-files", "missing"},
+	}
+
+	fingerprint := func(t *testing.T, ctx *image.Context) string {
+		f, err := fingerprintComponent(ctx, "systemd", inputs)
+		require.NoError(t, err)
+
+		return f
+	}
+
+	ctx := &image.Context{ImageConfigDir: setupConfigDir(t), ImageDefinition: definition()}
+	initial := fingerprint(t, ctx)
+	assert.Len(t, initial, 64)
+
+	tests := map[string]struct {
+		modify  func(ctx *image.Context)
+		changed bool
+	}{
+		"Unchanged": {
+			modify:  func(*image.Context) {},
+			changed: false,
+		},
+		"Unrelated definition change": {
+			modify: func(ctx *image.Context) {
+				ctx.ImageDefinition.OperatingSystem.Proxy.HTTPProxy = "http://other-proxy:3128"
+			},
+			changed: false,
+		},
+		"Definition subtree change": {
+			modify: func(ctx *image.Context) {
+				ctx.ImageDefinition.OperatingSystem.Systemd.Disable = []string{"cups"}
+			},
+			changed: true,
+		},
+		"Architecture change": {
+			modify: func(ctx *image.Context) {
+				ctx.ImageDefinition.Image.Arch = image.ArchTypeARM
+			},
+			changed: true,
+		},
+		"Config file change": {
+			modify: func(ctx *image.Context) {
+				require.NoError(t, os.WriteFile(filepath.Join(ctx.ImageConfigDir, "os-files", "etc", "motd"), []byte("hello"), 0o600))
+			},
+			changed: true,
+		},
+		"Config file added": {
+			modify: func(ctx *image.Context) {
+				require.NoError(t, os.WriteFile(filepath.Join(ctx.ImageConfigDir, "missing"), []byte("present"), 0o600))
+			},
+			changed: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			testCtx := &image.Context{ImageConfigDir: setupConfigDir(t), ImageDefinition: definition()}
+			test.modify(testCtx)
+
+			if test.changed {
+				assert.NotEqual(t, initial, fingerprint(t, testCtx))
+			} else {
+				assert.Equal(t, initial, fingerprint(t, testCtx))
+			}
+		})
+	}
+}
+
+func TestComponentStore(t *testing.T) {
+	configDir := t.TempDir()
+	storeDir := t.TempDir()
+
+	var configured int
+	component := Component{
+		Name: "systemd",
+		Configure: func(ctx *image.Context) ([]string, error) {
+			configured++
+
+			if err := os.WriteFile(filepath.Join(ctx.CombustionDir, "14-systemd.sh"), []byte("#!/bin/bash"), 0o700); err != nil {
+				return nil, err
+			}
+
+			if err := os.MkdirAll(filepath.Join(ctx.ArtefactsDir, "units"), os.ModePerm); err != nil {
+				return nil, err
+			}
+
+			if err := os.WriteFile(filepath.Join(ctx.ArtefactsDir, "units", "sshd.service"), []byte("[Unit]"), 0o600); err != nil {
+				return nil, err
+			}
+
+			ctx.Recorder().AddPackage("openssh", "", "", "")
+			ctx.Output.AuditComponentSuccessful("systemd")
+
+			return []string{"14-systemd.sh"}, nil
+		},
+		Inputs: &ComponentInputs{
+			Definition: func(d *image.Definition) any { return d.OperatingSystem.Systemd },
+		},
+	}
+
+	build := func(t *testing.T, enable []string, reuse bool) (*image.Context, *report.Report) {
+		buildDir := t.TempDir()
+		buildReport := report.New("test")
+		ctx := (&image.Context{
+			ImageConfigDir:  configDir,
+			BuildDir:        buildDir,
+			CombustionDir:   filepath.Join(buildDir, "combustion"),
+			ArtefactsDir:    filepath.Join(buildDir, "artefacts"),
+			ImageDefinition: &image.Definition{OperatingSystem: image.OperatingSystem{Systemd: image.Systemd{Enable: enable}}},
+		}).WithRecorder(buildReport)
+		require.NoError(t, os.MkdirAll(ctx.CombustionDir, os.ModePerm))
+		require.NoError(t, os.MkdirAll(ctx.ArtefactsDir, os.ModePerm))
+
+		log.SetComponentStatusObserver(buildReport.RecordComponentStatus)
+		defer log.SetComponentStatusObserver(nil)
+
+		scripts, err := configureComponents(ctx, []Component{component}, NewComponentStore(storeDir, reuse, buildReport))
+		require.NoError(t, err)
+		assert.Equal(t, map[string][]string{"systemd": {"14-systemd.sh"}}, scripts)
+
+		assert.FileExists(t, filepath.Join(ctx.CombustionDir, "14-systemd.sh"))
+		assert.FileExists(t, filepath.Join(ctx.ArtefactsDir, "units", "sshd.service"))
+		assert.Equal(t, []report.Package{{Name: "openssh"}}, buildReport.Packages)
+
+		return ctx, buildReport
+	}
+
+	_, buildReport := build(t, []string{"sshd"}, true)
+	assert.Equal(t, 1, configured)
+	assert.Equal(t, report.StatusSuccessful, buildReport.Components[0].Status)
+	assert.FileExists(t, filepath.Join(storeDir, "systemd", storedComponentFile))
+
+	ctx, buildReport := build(t, []string{"sshd"}, true)
+	assert.Equal(t, 1, configured, "unchanged component must be reused")
+	assert.Equal(t, report.StatusReused, buildReport.Components[0].Status)
+
+	build(t, []string{"sshd", "cups"}, true)
+	assert.Equal(t, 2, configured, "changed component must be configured")
+
+	build(t, []string{"sshd", "cups"}, false)
+	assert.Equal(t, 3, configured, "component must be configured when reuse is disabled")
+
+	// Staging directories are removed once the outputs are moved to the build directory
+	entries, err := os.ReadDir(ctx.BuildDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestComponentStore_SkippedComponent(t *testing.T) {
+	storeDir := t.TempDir()
+	buildDir := t.TempDir()
+
+	ctx := &image.Context{
+		BuildDir:        buildDir,
+		CombustionDir:   filepath.Join(buildDir, "combustion"),
+		ArtefactsDir:    filepath.Join(buildDir, "artefacts"),
+		ImageDefinition: &image.Definition{},
+	}
+
+	component := noopComponent("proxy", nil, nil)
+	component.Inputs = &ComponentInputs{}
+
+	_, err := configureComponents(ctx, []Component{component}, NewComponentStore(storeDir, true, nil))
+	require.NoError(t, err)
+
+	assert.NoDirExists(t, filepath.Join(storeDir, "proxy"))
+}
+
+func TestComponentStore_Locking(t *testing.T) {
+	// Directories created by previous versions are accessible to all users
+	storeDir := filepath.Join(t.TempDir(), "components", "x86_64")
+	require.NoError(t, os.MkdirAll(storeDir, 0o755))
+
+	store := NewComponentStore(storeDir, true, nil)
+
+	// Another build is replacing the stored outputs of the component
+	l, err := store.lock("systemd", fileio.AcquireLock)
+	require.NoError(t, err)
+
+	info, err := os.Stat(storeDir)
+	require.NoError(t, err)
+	assert.Equal(t, storeDirPerms, info.Mode().Perm())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		_, reused, reuseErr := store.reuseStored(&image.Context{}, "systemd", "fingerprint")
+		assert.NoError(t, reuseErr)
+		assert.False(t, reused)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("stored outputs must not be looked up while they are replaced")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, l.Release())
+	<-done
+}
+
+func TestRelativizeArtefacts(t *testing.T) {
+	componentReport := report.New("test")
+	componentReport.RecordDownload("https://get.k3s.io", filepath.Join("/staging", "artefacts", "kubernetes", "install.sh"), false)
+	componentReport.RecordDownload("https://example.com/cached", filepath.Join("/cache", "file"), true)
+
+	relativizeArtefacts(componentReport, "/staging")
+
+	assert.Equal(t, filepath.Join("artefacts", "kubernetes", "install.sh"), componentReport.Artefacts[0].Path)
+	assert.Equal(t, filepath.Join("/cache", "file"), componentReport.Artefacts[1].Path)
+
+	ctx := &image.Context{
+		CombustionDir: t.TempDir(),
+		ArtefactsDir:  t.TempDir(),
+	}
+	buildReport := report.New("test")
+	store := NewComponentStore(t.TempDir(), true, buildReport)
+	require.NoError(t, store.restoreOutputs(ctx, t.TempDir(), componentReport))
+
+	assert.Equal(t, filepath.Join(ctx.ArtefactsDir, "kubernetes", "install.sh"), buildReport.Artefacts[0].Path)
+	assert.Equal(t, filepath.Join("/cache", "file"), buildReport.Artefacts[1].Path)
+}
@@ -10,8 +10,9 @@ import (
 	"strings"
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/http"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 	"go.uber.org/zap"
 )
@@ -28,59 +29,176 @@ var installRPMsScript string
 
 func (c *Combustion) configureRPMs(ctx *image.Context) ([]string, error) {
 	if SkipRPMComponent(ctx) {
-		log.AuditComponentSkipped(rpmComponentName)
+		ctx.Output.AuditComponentSkipped(rpmComponentName)
 		zap.L().Info("Skipping RPM component. Configuration is not provided")
 		return nil, nil
 	}
 
 	zap.L().Info("Configuring RPM component...")
 
-	packages := &ctx.ImageDefinition.OperatingSystem.Packages
-	if packages.NoGPGCheck {
-		log.Audit("WARNING: Running EIB with disabled GPG validation is intended for development purposes only")
-		zap.S().Warn("Disabling GPG validation for the EIB RPM resolver")
-	}
-
-	// package list specified without either a sccRegistrationCode or an additionalRepos entry
-	if len(packages.PKGList) > 0 && (packages.RegCode == "" && len(packages.AdditionalRepos) == 0) {
-		log.Audit("WARNING: No SUSE registration code or additional repositories provided, package resolution may fail if you're using SLE Micro as the base image")
-		zap.S().Warn("Detected packages for installation with no sccRegistrationCode or additionalRepos provided")
-	}
-
 	localRPMConfig, err := fetchLocalRPMConfig(ctx)
 	if err != nil {
-		log.AuditComponentFailed(rpmComponentName)
+		ctx.Output.AuditComponentFailed(rpmComponentName)
 		return nil, fmt.Errorf("fetching local RPM config: %w", err)
 	}
 
 	artefactsPath := filepath.Join(ctx.ArtefactsDir, rpmDir)
 	if err = os.MkdirAll(artefactsPath, os.ModePerm); err != nil {
-		log.AuditComponentFailed(rpmComponentName)
+		ctx.Output.AuditComponentFailed(rpmComponentName)
 		return nil, fmt.Errorf("creating rpm artefacts path: %w", err)
 	}
 
-	log.Audit("Resolving package dependencies...")
-	repoPath, pkgsList, err := c.RPMResolver.Resolve(packages, localRPMConfig, artefactsPath)
+	repoPath, pkgsList, err := c.resolveRPMRepository(ctx, localRPMConfig, artefactsPath)
 	if err != nil {
-		log.AuditComponentFailed(rpmComponentName)
-		return nil, fmt.Errorf("resolving rpm/package dependencies: %w", err)
-	}
-
-	if err = c.RPMRepoCreator.Create(repoPath); err != nil {
-		log.AuditComponentFailed(rpmComponentName)
-		return nil, fmt.Errorf("creating resolved rpm repository: %w", err)
+		ctx.Output.AuditComponentFailed(rpmComponentName)
+		return nil, err
 	}
 
 	script, err := writeRPMScript(ctx, repoPath, pkgsList)
 	if err != nil {
-		log.AuditComponentFailed(rpmComponentName)
+		ctx.Output.AuditComponentFailed(rpmComponentName)
 		return nil, fmt.Errorf("writing the RPM install script %s: %w", installRPMsScriptName, err)
 	}
 
-	log.AuditComponentSuccessful(rpmComponentName)
+	if resolvedPackages, listErr := listResolvedPackages(repoPath); listErr != nil {
+		zap.S().Warnf("Listing resolved packages failed: %v", listErr)
+	} else {
+		for _, pkg := range resolvedPackages {
+			ctx.Recorder().AddPackage(pkg.Name, pkg.Version, pkg.Arch, pkg.Repository)
+		}
+	}
+
+	ctx.Output.AuditComponentSuccessful(rpmComponentName)
 	return []string{script}, nil
 }
 
+// resolveRPMRepository creates a repository containing the configured packages and their dependencies.
+// Repositories resolved from remote repositories are cached, so that offline builds can restore them instead.
+func (c *Combustion) resolveRPMRepository(ctx *image.Context, localRPMConfig *image.LocalRPMConfig, artefactsPath string) (string, []string, error) {
+	packages := &ctx.ImageDefinition.OperatingSystem.Packages
+
+	var identifier string
+	if RequiresRemotePackageResolution(packages) {
+		var err error
+		if identifier, err = RPMRepositoryCacheIdentifier(ctx); err != nil {
+			if http.IsOffline() {
+				return "", nil, fmt.Errorf("identifying cached rpm repository: %w", err)
+			}
+
+			zap.S().Warnf("Identifying the cached RPM repository failed, the resolved repository will not be cached: %v", err)
+		}
+
+		if http.IsOffline() {
+			ctx.Output.Audit("Restoring resolved packages from the cache...")
+
+			repoPath, pkgsList, restoreErr := c.restoreRPMRepository(ctx, identifier, artefactsPath)
+			if restoreErr != nil {
+				if errors.Is(restoreErr, fs.ErrNotExist) {
+					return "", nil, fmt.Errorf("resolved rpm repository not available in cache: %w", http.ErrOffline)
+				}
+
+				return "", nil, fmt.Errorf("restoring cached rpm repository: %w", restoreErr)
+			}
+
+			return repoPath, pkgsList, nil
+		}
+	}
+
+	ctx.Output.Audit("Resolving package dependencies...")
+	repoPath, pkgsList, err := c.RPMResolver.Resolve(ctx.Context(), packages, localRPMConfig, artefactsPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("resolving rpm/package dependencies: %w", err)
+	}
+
+	if err = c.RPMRepoCreator.Create(ctx.Context(), repoPath); err != nil {
+		return "", nil, fmt.Errorf("creating resolved rpm repository: %w", err)
+	}
+
+	if identifier != "" && repoPath != "" && len(pkgsList) != 0 {
+		if err = c.cacheRPMRepository(ctx, identifier, repoPath, pkgsList); err != nil {
+			zap.S().Warnf("Caching the resolved RPM repository failed: %v", err)
+		}
+	}
+
+	return repoPath, pkgsList, nil
+}
+
+// listResolvedPackages describes all RPM files (including dependencies) in the resolved repository.
+//
+// The resolver stores the packages in per-repository subdirectories named after the repository alias
+// (e.g. "rpm-repo/addrepo0/x86_64/vim-9.1.0836-1.1.x86_64.rpm").
+func listResolvedPackages(repoPath string) ([]report.Package, error) {
+	var packages []report.Package
+
+	err := filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || filepath.Ext(path) != ".rpm" {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return err
+		}
+
+		var repository string
+		if dir, _, found := strings.Cut(relativePath, string(filepath.Separator)); found {
+			repository = dir
+		}
+
+		pkg := parseRPMFilename(d.Name())
+		pkg.Repository = repository
+		packages = append(packages, pkg)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return packages, nil
+}
+
+// parseRPMFilename extracts the package details from a file name
+// following the "<name>-<version>-<release>.<arch>.rpm" convention.
+func parseRPMFilename(filename string) report.Package {
+	nvra := strings.TrimSuffix(filename, ".rpm")
+
+	var pkg report.Package
+
+	if i := strings.LastIndex(nvra, "."); i != -1 {
+		pkg.Arch = nvra[i+1:]
+		nvra = nvra[:i]
+	}
+
+	// The release and version are the last two dash separated fields, the name may contain dashes itself
+	i := strings.LastIndex(nvra, "-")
+	if i == -1 {
+		pkg.Name = nvra
+		return pkg
+	}
+
+	j := strings.LastIndex(nvra[:i], "-")
+	if j == -1 {
+		pkg.Name = nvra[:i]
+		pkg.Version = nvra[i+1:]
+		return pkg
+	}
+
+	pkg.Name = nvra[:j]
+	pkg.Version = nvra[j+1:]
+	return pkg
+}
+
+// RequiresRemotePackageResolution determines whether resolving the configured
+// packages requires access to remote repositories.
+func RequiresRemotePackageResolution(packages *image.Packages) bool {
+	return len(packages.PKGList) != 0 || len(packages.AdditionalRepos) != 0 || packages.RegCode != ""
+}
+
 // SkipRPMComponent determines whether RPM configuration is needed
 func SkipRPMComponent(ctx *image.Context) bool {
 	pkg := ctx.ImageDefinition.OperatingSystem.Packages
@@ -0,0 +1,258 @@
+package combustion
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/cache"
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"go.uber.org/zap"
+)
+
+// rpmRepositoryManifest is the archive entry listing the packages installed from the archived repository.
+const rpmRepositoryManifest = "packages.json"
+
+type rpmRepositoryInputs struct {
+	Packages  image.Packages `json:"packages"`
+	BaseImage string         `json:"baseImage"`
+}
+
+// RPMRepositoryCacheIdentifier returns the cache identifier of the RPM repository resolved for the packages
+// configured in the context, including the side-loaded RPMs and GPG keys.
+//
+// Unlike the fingerprints of stored components, the identifier only depends on the name of the base image,
+// so that repositories resolved on another host can be used by offline builds after importing its cache.
+func RPMRepositoryCacheIdentifier(ctx *image.Context) (string, error) {
+	fingerprint, err := fingerprintComponent(ctx, rpmDir, &ComponentInputs{
+		Definition: func(definition *image.Definition) any {
+			return rpmRepositoryInputs{
+				Packages:  definition.OperatingSystem.Packages,
+				BaseImage: definition.Image.Targets()[0].BaseImage,
+			}
+		},
+		ConfigPaths: []string{rpmDir},
+	})
+	if err != nil {
+		return "", fmt.Errorf("fingerprinting packages: %w", err)
+	}
+
+	return fmt.Sprintf("rpm-repositories/%s/%s", ctx.ImageDefinition.Image.Arch, fingerprint), nil
+}
+
+// cacheRPMRepository stores the resolved repository along with the packages installed from it.
+// Repositories previously stored under the same identifier are replaced, since the remote
+// repositories the packages are resolved from may have been updated in the meantime.
+func (c *Combustion) cacheRPMRepository(ctx *image.Context, identifier, repoPath string, packages []string) error {
+	if c.Cache == nil {
+		return nil
+	}
+
+	archive, err := os.CreateTemp(ctx.BuildDir, "rpm-repository-*.tar")
+	if err != nil {
+		return fmt.Errorf("creating repository archive: %w", err)
+	}
+	defer func() {
+		_ = archive.Close()
+		_ = os.Remove(archive.Name())
+	}()
+
+	if err = archiveRPMRepository(archive, repoPath, packages); err != nil {
+		return fmt.Errorf("archiving repository: %w", err)
+	}
+
+	if _, err = archive.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewinding repository archive: %w", err)
+	}
+
+	if err = c.Cache.Remove(identifier); err != nil {
+		return fmt.Errorf("removing previous repository: %w", err)
+	}
+
+	if err = c.Cache.Put(identifier, "", archive); err != nil && !errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("caching repository archive: %w", err)
+	}
+
+	return nil
+}
+
+// restoreRPMRepository extracts the cached repository into the given directory.
+// It returns the path of the repository and the packages installed from it,
+// or fs.ErrNotExist if no usable repository is cached.
+func (c *Combustion) restoreRPMRepository(ctx *image.Context, identifier, dir string) (string, []string, error) {
+	if c.Cache == nil {
+		return "", nil, fs.ErrNotExist
+	}
+
+	archivePath := filepath.Join(ctx.BuildDir, "rpm-repository.tar")
+	defer func() {
+		_ = os.Remove(archivePath)
+	}()
+
+	if err := c.Cache.CopyTo(identifier, archivePath, fileio.NonExecutablePerms); err != nil {
+		if errors.Is(err, cache.ErrCorrupted) {
+			zap.S().Warnf("Cached RPM repository with identifier '%s' is corrupted: %v", identifier, err)
+			return "", nil, fs.ErrNotExist
+		}
+
+		return "", nil, err
+	}
+
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return "", nil, fmt.Errorf("opening repository archive: %w", err)
+	}
+	defer archive.Close()
+
+	repoName, packages, err := extractRPMRepository(archive, dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("extracting repository archive: %w", err)
+	}
+
+	return filepath.Join(dir, repoName), packages, nil
+}
+
+// archiveRPMRepository writes the repository directory and the manifest of the packages installed from it as a tarball.
+func archiveRPMRepository(writer io.Writer, repoPath string, packages []string) error {
+	tw := tar.NewWriter(writer)
+
+	manifest, err := json.Marshal(packages)
+	if err != nil {
+		return fmt.Errorf("marshalling package list: %w", err)
+	}
+
+	if err = tw.WriteHeader(&tar.Header{Name: rpmRepositoryManifest, Mode: int64(fileio.NonExecutablePerms), Size: int64(len(manifest))}); err != nil {
+		return fmt.Errorf("writing package list header: %w", err)
+	}
+
+	if _, err = tw.Write(manifest); err != nil {
+		return fmt.Errorf("writing package list: %w", err)
+	}
+
+	parent := filepath.Dir(repoPath)
+
+	err = filepath.WalkDir(repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return fmt.Errorf("unsupported file type: %s", path)
+		}
+
+		name, err := filepath.Rel(parent, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+
+		if err = tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = io.Copy(tw, file)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("archiving repository directory: %w", err)
+	}
+
+	return tw.Close()
+}
+
+// extractRPMRepository extracts an archive written by archiveRPMRepository into the given directory.
+// It returns the name of the repository directory and the packages installed from it.
+func extractRPMRepository(reader io.Reader, dir string) (string, []string, error) {
+	tr := tar.NewReader(reader)
+
+	var repoName string
+	var packages []string
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("reading archive: %w", err)
+		}
+
+		if header.Name == rpmRepositoryManifest {
+			if err = json.NewDecoder(tr).Decode(&packages); err != nil {
+				return "", nil, fmt.Errorf("decoding package list: %w", err)
+			}
+			continue
+		}
+
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return "", nil, fmt.Errorf("invalid archive entry: %s", header.Name)
+		}
+
+		if repoName == "" {
+			repoName, _, _ = strings.Cut(name, string(filepath.Separator))
+		}
+
+		path := filepath.Join(dir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(path, os.ModePerm); err != nil {
+				return "", nil, fmt.Errorf("creating directory: %w", err)
+			}
+		case tar.TypeReg:
+			if err = extractFile(tr, path, header.FileInfo().Mode().Perm()); err != nil {
+				return "", nil, err
+			}
+		default:
+			return "", nil, fmt.Errorf("unsupported archive entry: %s", header.Name)
+		}
+	}
+
+	if repoName == "" || len(packages) == 0 {
+		return "", nil, errors.New("archive does not contain a repository")
+	}
+
+	return repoName, packages, nil
+}
+
+func extractFile(reader io.Reader, path string, perms os.FileMode) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perms)
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err = io.Copy(file, reader); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package combustion
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/cache"
+	"github.com/suse-edge/edge-image-builder/pkg/http"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+)
+
+func TestConfigureRPMs_CachedRepository(t *testing.T) {
+	ctx, teardown := setupContext(t)
+	defer teardown()
+
+	ctx.ImageDefinition.Image = image.Image{Arch: image.ArchTypeX86, BaseImage: "SL-Micro.x86_64-6.0-Base-GM2.raw"}
+	ctx.ImageDefinition.OperatingSystem.Packages = image.Packages{PKGList: []string{"vim"}}
+
+	c, err := cache.New(filepath.Join(t.TempDir(), "cache"), 0)
+	require.NoError(t, err)
+
+	online := Combustion{
+		RPMResolver: mockRPMResolver{
+			resolveFunc: func(_ *image.Packages, _ *image.LocalRPMConfig, outputDir string) (string, []string, error) {
+				repoPath := filepath.Join(outputDir, "rpm-repo")
+				require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "addrepo0", "x86_64"), os.ModePerm))
+				require.NoError(t, os.WriteFile(filepath.Join(repoPath, "addrepo0", "x86_64", "vim-9.1.0836-1.1.x86_64.rpm"), []byte("rpm"), 0o600))
+
+				return repoPath, []string{"vim"}, nil
+			},
+		},
+		RPMRepoCreator: mockRPMRepoCreator{
+			createFunc: func(path string) error {
+				require.NoError(t, os.MkdirAll(filepath.Join(path, "repodata"), os.ModePerm))
+				return os.WriteFile(filepath.Join(path, "repodata", "repomd.xml"), []byte("<repomd/>"), 0o600)
+			},
+		},
+		Cache: c,
+	}
+
+	_, err = online.configureRPMs(ctx)
+	require.NoError(t, err)
+
+	identifier, err := RPMRepositoryCacheIdentifier(ctx)
+	require.NoError(t, err)
+	assert.True(t, c.Contains(identifier))
+
+	require.NoError(t, http.Configure(http.Config{Offline: true}))
+	t.Cleanup(func() {
+		require.NoError(t, http.Configure(http.Config{}))
+	})
+
+	// Packages are never resolved offline
+	offline := Combustion{Cache: c}
+
+	ctx.ArtefactsDir = t.TempDir()
+	ctx.CombustionDir = t.TempDir()
+
+	scripts, err := offline.configureRPMs(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{installRPMsScriptName}, scripts)
+
+	repoPath := filepath.Join(ctx.ArtefactsDir, rpmDir, "rpm-repo")
+	assert.FileExists(t, filepath.Join(repoPath, "addrepo0", "x86_64", "vim-9.1.0836-1.1.x86_64.rpm"))
+	assert.FileExists(t, filepath.Join(repoPath, "repodata", "repomd.xml"))
+
+	script, err := os.ReadFile(filepath.Join(ctx.CombustionDir, installRPMsScriptName))
+	require.NoError(t, err)
+	assert.Contains(t, string(script), "vim")
+
+	// The repository resolved for other packages is not cached
+	ctx.ImageDefinition.OperatingSystem.Packages.PKGList = []string{"vim", "git"}
+
+	_, err = offline.configureRPMs(ctx)
+	require.ErrorIs(t, err, http.ErrOffline)
+	assert.EqualError(t, err, "resolved rpm repository not available in cache: network access is disabled in offline mode")
+}
+
+func TestExtractRPMRepository_InvalidEntry(t *testing.T) {
+	var buf bytes.Buffer
+
+	tw := tar.NewWriter(&buf)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "../rpm-repo/vim.rpm", Mode: 0o644, Size: 3, Typeflag: tar.TypeReg}))
+	_, err := tw.Write([]byte("rpm"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	dir := t.TempDir()
+
+	_, _, err = extractRPMRepository(&buf, dir)
+	require.EqualError(t, err, "invalid archive entry: ../rpm-repo/vim.rpm")
+	assert.NoFileExists(t, filepath.Join(filepath.Dir(dir), "rpm-repo", "vim.rpm"))
+}
@@ -1,6 +1,7 @@
 package combustion
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,13 +12,14 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
 )
 
 type mockRPMResolver struct {
 	resolveFunc func(packages *image.Packages, localRPMConfig *image.LocalRPMConfig, outputDir string) (rpmDir string, pkgList []string, err error)
 }
 
-func (m mockRPMResolver) Resolve(packages *image.Packages, localRPMConfig *image.LocalRPMConfig, outputDir string) (rpmDir string, pkgList []string, err error) {
+func (m mockRPMResolver) Resolve(_ context.Context, packages *image.Packages, localRPMConfig *image.LocalRPMConfig, outputDir string) (rpmDir string, pkgList []string, err error) {
 	if m.resolveFunc != nil {
 		return m.resolveFunc(packages, localRPMConfig, outputDir)
 	}
@@ -29,7 +31,7 @@ type mockRPMRepoCreator struct {
 	createFunc func(path string) error
 }
 
-func (mr mockRPMRepoCreator) Create(path string) error {
+func (mr mockRPMRepoCreator) Create(_ context.Context, path string) error {
 	if mr.createFunc != nil {
 		return mr.createFunc(path)
 	}
@@ -357,3 +359,51 @@ func TestConfigureRPMs_SuccessfulConfig(t *testing.T) {
 	assert.Contains(t, foundContents, zypperInstall)
 	assert.Contains(t, foundContents, zypperRR)
 }
+
+func TestParseRPMFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		expected report.Package
+	}{
+		{
+			filename: "vim-9.1.0836-1.1.x86_64.rpm",
+			expected: report.Package{Name: "vim", Version: "9.1.0836-1.1", Arch: "x86_64"},
+		},
+		{
+			filename: "rke2-selinux-0.18-1.slemicro.noarch.rpm",
+			expected: report.Package{Name: "rke2-selinux", Version: "0.18-1.slemicro", Arch: "noarch"},
+		},
+		{
+			filename: "custom.rpm",
+			expected: report.Package{Name: "custom"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.filename, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseRPMFilename(test.filename))
+		})
+	}
+}
+
+func TestListResolvedPackages(t *testing.T) {
+	repoPath := t.TempDir()
+
+	for _, path := range []string{
+		"addrepo0/x86_64/vim-9.1.0836-1.1.x86_64.rpm",
+		"SL-Micro-6.0-Pool/noarch/rke2-selinux-0.18-1.slemicro.noarch.rpm",
+		"repodata/repomd.xml",
+	} {
+		fullPath := filepath.Join(repoPath, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0o755))
+		require.NoError(t, os.WriteFile(fullPath, nil, 0o600))
+	}
+
+	packages, err := listResolvedPackages(repoPath)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []report.Package{
+		{Name: "vim", Version: "9.1.0836-1.1", Arch: "x86_64", Repository: "addrepo0"},
+		{Name: "rke2-selinux", Version: "0.18-1.slemicro", Arch: "noarch", Repository: "SL-Micro-6.0-Pool"},
+	}, packages)
+}
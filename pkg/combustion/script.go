@@ -3,7 +3,6 @@ package combustion
 import (
 	_ "embed"
 	"fmt"
-	"slices"
 
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 )
@@ -11,9 +10,8 @@ import (
 //go:embed templates/script-base.sh.tpl
 var combustionScriptBase string
 
+// assembleScript generates the Combustion script executing the given scripts in order.
 func assembleScript(scripts []string, networkScript string) (string, error) {
-	slices.Sort(scripts)
-
 	values := struct {
 		NetworkScript string
 		Scripts       []string
@@ -8,7 +8,7 @@ import (
 )
 
 func TestAssembleScript_DynamicNetwork(t *testing.T) {
-	script, err := assembleScript([]string{"foo.sh", "bar.sh", "baz.sh"}, "")
+	script, err := assembleScript([]string{"bar.sh", "baz.sh", "foo.sh"}, "")
 	require.NoError(t, err)
 
 	assert.Contains(t, script, "# combustion: network")
@@ -17,7 +17,7 @@ func TestAssembleScript_DynamicNetwork(t *testing.T) {
 	assert.NotContains(t, script, `if [ "${1-}" = "--prepare" ]; then`)
 	assert.NotContains(t, script, "./configure-network.sh")
 
-	// alphabetic ordering
+	// given ordering
 	assert.Contains(t, script, `
 echo "Running bar.sh"
 ./bar.sh
@@ -31,7 +31,7 @@ echo "Running foo.sh"
 }
 
 func TestAssembleScript_StaticNetwork(t *testing.T) {
-	script, err := assembleScript([]string{"foo.sh", "bar.sh", "baz.sh"}, "configure-network.sh")
+	script, err := assembleScript([]string{"bar.sh", "baz.sh", "foo.sh"}, "configure-network.sh")
 	require.NoError(t, err)
 
 	assert.Contains(t, script, "# combustion: prepare network")
@@ -40,7 +40,7 @@ func TestAssembleScript_StaticNetwork(t *testing.T) {
 	assert.Contains(t, script, `if [ "${1-}" = "--prepare" ]; then`)
 	assert.Contains(t, script, "./configure-network.sh")
 
-	// alphabetic ordering
+	// given ordering
 	assert.Contains(t, script, `
 echo "Running bar.sh"
 ./bar.sh
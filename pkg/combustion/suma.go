@@ -8,7 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 )
 
@@ -23,16 +22,16 @@ var sumaScript string
 func configureSuma(ctx *image.Context) ([]string, error) {
 	suma := ctx.ImageDefinition.OperatingSystem.Suma
 	if suma.Host == "" {
-		log.AuditComponentSkipped(sumaComponentName)
+		ctx.Output.AuditComponentSkipped(sumaComponentName)
 		return nil, nil
 	}
 
 	if err := writeSumaCombustionScript(ctx); err != nil {
-		log.AuditComponentFailed(sumaComponentName)
+		ctx.Output.AuditComponentFailed(sumaComponentName)
 		return nil, err
 	}
 
-	log.AuditComponentSuccessful(sumaComponentName)
+	ctx.Output.AuditComponentSuccessful(sumaComponentName)
 	return []string{sumaScriptName}, nil
 }
 
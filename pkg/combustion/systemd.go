@@ -8,8 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
-	"github.com/suse-edge/edge-image-builder/pkg/template"
 )
 
 const (
@@ -21,26 +19,42 @@ const (
 var systemdTemplate string
 
 func configureSystemd(ctx *image.Context) ([]string, error) {
-	// Nothing to do if both lists are empty
+	nodes := nodeOverrides(ctx, func(override image.NodeOverride) bool {
+		return hasSystemdUnits(override.Systemd)
+	})
+
+	// Nothing to do if both lists are empty for all nodes
 	systemd := ctx.ImageDefinition.OperatingSystem.Systemd
-	if len(systemd.Enable) == 0 && len(systemd.Disable) == 0 {
-		log.AuditComponentSkipped(systemdComponentName)
+	if !hasSystemdUnits(systemd) && len(nodes) == 0 {
+		ctx.Output.AuditComponentSkipped(systemdComponentName)
 		return nil, nil
 	}
 
-	data, err := template.Parse(systemdScriptName, systemdTemplate, ctx.ImageDefinition.OperatingSystem.Systemd)
+	values := struct {
+		Systemd image.Systemd
+		Nodes   []image.NodeOverride
+	}{
+		Systemd: systemd,
+		Nodes:   nodes,
+	}
+
+	data, err := parseNodeTemplate(systemdScriptName, systemdTemplate, values)
 	if err != nil {
-		log.AuditComponentFailed(systemdComponentName)
+		ctx.Output.AuditComponentFailed(systemdComponentName)
 		return nil, fmt.Errorf("applying systemd script template: %w", err)
 	}
 
 	filename := filepath.Join(ctx.CombustionDir, systemdScriptName)
 	err = os.WriteFile(filename, []byte(data), fileio.ExecutablePerms)
 	if err != nil {
-		log.AuditComponentFailed(systemdComponentName)
+		ctx.Output.AuditComponentFailed(systemdComponentName)
 		return nil, fmt.Errorf("writing systemd combustion file: %w", err)
 	}
 
-	log.AuditComponentSuccessful(systemdComponentName)
+	ctx.Output.AuditComponentSuccessful(systemdComponentName)
 	return []string{systemdScriptName}, nil
 }
+
+func hasSystemdUnits(systemd image.Systemd) bool {
+	return len(systemd.Enable) != 0 || len(systemd.Disable) != 0
+}
@@ -72,3 +72,41 @@ func TestConfigureSystemd_BothServiceTypes(t *testing.T) {
 	assert.Contains(t, foundContents, "systemctl disable disable1")
 	assert.Contains(t, foundContents, "systemctl mask disable1")
 }
+
+func TestConfigureSystemd_NodeOverrides(t *testing.T) {
+	// Setup
+	ctx, teardown := setupContext(t)
+	defer teardown()
+
+	ctx.ImageDefinition = &image.Definition{
+		NodeOverrides: []image.NodeOverride{
+			{
+				Hostname: "node1.example.com",
+				Systemd:  image.Systemd{Enable: []string{"enable-node1"}},
+			},
+			{
+				MACAddress: "34:8a:b1:4b:16:e2",
+				Systemd:    image.Systemd{Disable: []string{"disable-node2"}},
+			},
+			{
+				Hostname: "node3.example.com",
+			},
+		},
+	}
+
+	// Test
+	scripts, err := configureSystemd(ctx)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, []string{systemdScriptName}, scripts)
+
+	foundBytes, err := os.ReadFile(filepath.Join(ctx.CombustionDir, systemdScriptName))
+	require.NoError(t, err)
+
+	foundContents := string(foundBytes)
+	assert.Contains(t, foundContents, "is_node() {")
+	assert.Contains(t, foundContents, "if is_node 'node1.example.com'; then\n  systemctl enable enable-node1\nfi")
+	assert.Contains(t, foundContents, "if is_node '34:8a:b1:4b:16:e2'; then\n  systemctl disable disable-node2\n  systemctl mask disable-node2\nfi")
+	assert.NotContains(t, foundContents, "node3.example.com")
+}
@@ -1,11 +1,5 @@
-#!/bin/bash
-set -euo pipefail
-
-# Without this, the script will run successfully during combustion, but when /home
-# is mounted it will hide the /home used during these user creations.
-mount /home
-#---
-{{- range $user := . }}
+{{- define "user" }}
+{{- $user := . }}
 {{- /* Non-root users */}}
 {{- if (ne $user.Username "root") }}
 {{- $create_home := ""}}
@@ -49,7 +43,25 @@ echo '{{.}}' >> /{{$user.Username}}/.ssh/authorized_keys
 {{- end }}
 # ---
 {{- end }}
+{{- end -}}
+#!/bin/bash
+set -euo pipefail
+{{ if .Nodes }}{{ template "nodeMatch" }}
+{{ end }}
+# Without this, the script will run successfully during combustion, but when /home
+# is mounted it will hide the /home used during these user creations.
+mount /home
+#---
+{{- range .Users }}
+{{- template "user" . }}
+{{- end }}
 
+{{- range .Nodes }}
+if is_node '{{ .ID }}'; then
+{{- range .Users }}
+{{- template "user" . }}
+{{- end }}
+fi
 {{- end }}
 
 umount /home
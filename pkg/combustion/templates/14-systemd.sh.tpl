@@ -1,11 +1,25 @@
 #!/bin/bash
 set -euo pipefail
+{{- if .Nodes }}
+{{ template "nodeMatch" }}
+{{- end }}
 
-{{ range .Disable }}
+{{ range .Systemd.Disable }}
   systemctl disable {{ . }}
   systemctl mask {{ . }}
 {{ end }}
 
-{{ range .Enable }}
+{{ range .Systemd.Enable }}
   systemctl enable {{ . }}
+{{ end }}
+{{- range .Nodes }}
+if is_node '{{ .ID }}'; then
+{{- range .Systemd.Disable }}
+  systemctl disable {{ . }}
+  systemctl mask {{ . }}
+{{- end }}
+{{- range .Systemd.Enable }}
+  systemctl enable {{ . }}
+{{- end }}
+fi
 {{ end }}
\ No newline at end of file
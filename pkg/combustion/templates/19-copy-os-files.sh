@@ -1,4 +0,0 @@
-#!/bin/bash
-set -euo pipefail
-
-cp -R ./os-files/* /
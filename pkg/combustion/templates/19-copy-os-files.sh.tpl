@@ -0,0 +1,15 @@
+#!/bin/bash
+set -euo pipefail
+{{- if .Nodes }}
+{{ template "nodeMatch" }}
+{{- end }}
+{{- if .Shared }}
+
+cp -R ./os-files/* /
+{{- end }}
+{{- range .Nodes }}
+
+if is_node '{{ .ID }}'; then
+  cp -R './node-overrides/{{ .ID }}/os-files/'* /
+fi
+{{- end }}
@@ -1,7 +1,10 @@
 #!/bin/bash
 set -euo pipefail
 
-rm -r /combustion
+# The same configuration may be shared with ISO images, which do not copy it into the system
+if test -d /combustion; then
+  rm -r /combustion
+fi
 
 if test -d /artefacts; then
   rm -r /artefacts
@@ -0,0 +1,12 @@
+{{- define "nodeMatch" }}
+# Checks whether the node is identified by the given hostname or the MAC address of one of its interfaces
+is_node() {
+    local hostname
+    hostname=$(cat /etc/hostname 2>/dev/null || true)
+    if [ ! "$hostname" ]; then
+        hostname=$(cat /proc/sys/kernel/hostname)
+    fi
+
+    [ "$hostname" = "$1" ] || grep -qixF "$1" /sys/class/net/*/address
+}
+{{- end -}}
@@ -0,0 +1,9 @@
+#!/bin/bash
+set -euo pipefail
+{{ template "nodeMatch" }}
+{{- range .Nodes }}
+
+if is_node '{{ . }}'; then
+  './node-overrides/{{ . }}/scripts/{{ $.Script }}'
+fi
+{{- end }}
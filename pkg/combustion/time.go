@@ -8,7 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 )
 
@@ -23,16 +22,16 @@ var timeScript string
 func configureTime(ctx *image.Context) ([]string, error) {
 	time := ctx.ImageDefinition.OperatingSystem.Time
 	if time.Timezone == "" {
-		log.AuditComponentSkipped(timeComponentName)
+		ctx.Output.AuditComponentSkipped(timeComponentName)
 		return nil, nil
 	}
 
 	if err := writeTimeCombustionScript(ctx); err != nil {
-		log.AuditComponentFailed(timeComponentName)
+		ctx.Output.AuditComponentFailed(timeComponentName)
 		return nil, err
 	}
 
-	log.AuditComponentSuccessful(timeComponentName)
+	ctx.Output.AuditComponentSuccessful(timeComponentName)
 	return []string{timeScriptName}, nil
 }
 
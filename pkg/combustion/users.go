@@ -8,8 +8,6 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
-	"github.com/suse-edge/edge-image-builder/pkg/log"
-	"github.com/suse-edge/edge-image-builder/pkg/template"
 )
 
 const (
@@ -21,25 +19,37 @@ const (
 var usersScript string
 
 func configureUsers(ctx *image.Context) ([]string, error) {
+	nodes := nodeOverrides(ctx, func(override image.NodeOverride) bool {
+		return len(override.Users) != 0
+	})
+
 	// Punch out early if there are no users
-	if len(ctx.ImageDefinition.OperatingSystem.Users) == 0 {
-		log.AuditComponentSkipped(usersComponentName)
+	if len(ctx.ImageDefinition.OperatingSystem.Users) == 0 && len(nodes) == 0 {
+		ctx.Output.AuditComponentSkipped(usersComponentName)
 		return nil, nil
 	}
 
-	data, err := template.Parse(usersScriptName, usersScript, ctx.ImageDefinition.OperatingSystem.Users)
+	values := struct {
+		Users []image.OperatingSystemUser
+		Nodes []image.NodeOverride
+	}{
+		Users: ctx.ImageDefinition.OperatingSystem.Users,
+		Nodes: nodes,
+	}
+
+	data, err := parseNodeTemplate(usersScriptName, usersScript, values)
 	if err != nil {
-		log.AuditComponentFailed(usersComponentName)
+		ctx.Output.AuditComponentFailed(usersComponentName)
 		return nil, fmt.Errorf("parsing users script template: %w", err)
 	}
 
 	filename := filepath.Join(ctx.CombustionDir, usersScriptName)
 	err = os.WriteFile(filename, []byte(data), fileio.ExecutablePerms)
 	if err != nil {
-		log.AuditComponentFailed(usersComponentName)
+		ctx.Output.AuditComponentFailed(usersComponentName)
 		return nil, fmt.Errorf("writing %s to the combustion directory: %w", usersScriptName, err)
 	}
 
-	log.AuditComponentSuccessful(usersComponentName)
+	ctx.Output.AuditComponentSuccessful(usersComponentName)
 	return []string{usersScriptName}, nil
 }
@@ -3,6 +3,7 @@ package combustion
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -114,3 +115,51 @@ func TestConfigureUsers_NoUsers(t *testing.T) {
 	_, err = os.ReadFile(expectedFilename)
 	require.ErrorIs(t, err, os.ErrNotExist)
 }
+
+func TestConfigureUsers_NodeOverrides(t *testing.T) {
+	// Setup
+	ctx, teardown := setupContext(t)
+	defer teardown()
+
+	ctx.ImageDefinition = &image.Definition{
+		OperatingSystem: image.OperatingSystem{
+			Users: []image.OperatingSystemUser{
+				{
+					Username:          "alpha",
+					EncryptedPassword: "alpha123",
+				},
+			},
+		},
+		NodeOverrides: []image.NodeOverride{
+			{
+				Hostname: "node1.example.com",
+				Users: []image.OperatingSystemUser{
+					{
+						Username:          "beta",
+						EncryptedPassword: "beta123",
+					},
+				},
+			},
+			{
+				Hostname: "node2.example.com",
+			},
+		},
+	}
+
+	// Test
+	scripts, err := configureUsers(ctx)
+
+	// Verify
+	require.NoError(t, err)
+	assert.Equal(t, []string{usersScriptName}, scripts)
+
+	foundBytes, err := os.ReadFile(filepath.Join(ctx.CombustionDir, usersScriptName))
+	require.NoError(t, err)
+
+	foundContents := string(foundBytes)
+	assert.Contains(t, foundContents, "is_node() {")
+	assert.Contains(t, foundContents, "useradd alpha")
+	assert.Contains(t, foundContents, "if is_node 'node1.example.com'; then\nuseradd beta\necho 'beta:beta123' | chpasswd -e")
+	assert.Less(t, strings.Index(foundContents, "useradd alpha"), strings.Index(foundContents, "if is_node"))
+	assert.NotContains(t, foundContents, "node2.example.com")
+}
@@ -14,49 +14,156 @@ import (
 	"github.com/suse-edge/edge-image-builder/pkg/cache"
 	"github.com/suse-edge/edge-image-builder/pkg/combustion"
 	"github.com/suse-edge/edge-image-builder/pkg/helm"
+	"github.com/suse-edge/edge-image-builder/pkg/http"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
 	"github.com/suse-edge/edge-image-builder/pkg/kubernetes"
 	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"github.com/suse-edge/edge-image-builder/pkg/network"
 	"github.com/suse-edge/edge-image-builder/pkg/podman"
 	"github.com/suse-edge/edge-image-builder/pkg/registry"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
 	"github.com/suse-edge/edge-image-builder/pkg/rpm"
 	"github.com/suse-edge/edge-image-builder/pkg/rpm/resolver"
 	"go.uber.org/zap"
 )
 
-func Run(ctx *image.Context, rootBuildDir string) error {
-	if err := appendKubernetesSELinuxRPMs(ctx); err != nil {
-		log.Auditf("Bootstrapping dependency services failed.")
-		return fmt.Errorf("configuring kubernetes selinux policy: %w", err)
+func Run(ctx *image.Context, rootBuildDir string, buildReport *report.Report) error {
+	artefactCache, err := setupCache(ctx, rootBuildDir)
+	if err != nil {
+		log.Audit("Bootstrapping dependency services failed.")
+		return fmt.Errorf("setting up cache: %w", err)
 	}
 
+	ctx.ComponentsDir = filepath.Join(rootBuildDir, "components")
+
 	appendElementalRPMs(ctx)
 	appendFips(ctx)
 	appendHelm(ctx)
 
-	c, err := buildCombustion(ctx, rootBuildDir)
+	archs := ctx.ImageDefinition.Image.Architectures()
+
+	if err = appendKubernetesSELinuxRPMs(ctx, artefactCache); err != nil {
+		log.Auditf("Bootstrapping dependency services failed.")
+		return fmt.Errorf("configuring kubernetes selinux policy: %w", err)
+	}
+
+	// The SELinux packages are part of the package set the cached RPM repositories are identified by
+	if http.IsOffline() {
+		for _, arch := range archs {
+			if err = verifyOfflineInputs(ctx.ForImage(architectureImage(ctx.ImageDefinition.Image, arch)), artefactCache); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The Podman API listener is shared by the package resolution of all architectures
+	var p *podman.Podman
+	if !combustion.SkipRPMComponent(ctx) {
+		if p, err = podman.New(ctx.Context(), ctx.BuildDir); err != nil {
+			log.Audit("Bootstrapping dependency services failed.")
+			return fmt.Errorf("setting up Podman instance: %w", err)
+		}
+	}
+
+	for _, arch := range archs {
+		if err = buildArchitecture(ctx, buildReport, arch, len(archs) > 1, artefactCache, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildArchitecture generates the customization components for the given architecture once
+// and builds all images of that architecture from them.
+func buildArchitecture(ctx *image.Context, buildReport *report.Report, arch image.Arch, multiArch bool, c *cache.Cache, p *podman.Podman) error {
+	archCtx := ctx.ForImage(architectureImage(ctx.ImageDefinition.Image, arch))
+	archCtx = archCtx.WithContext(http.WithRecorder(archCtx.Context(), buildReport.ForArch(string(arch))))
+
+	if multiArch {
+		log.Auditf("Building %s images...", arch)
+
+		archCtx.BuildDir = filepath.Join(ctx.BuildDir, string(arch))
+
+		combustionDir, artefactsDir, err := SetupCombustionDirectory(archCtx.BuildDir)
+		if err != nil {
+			log.Auditf("Setting up the %s build directory failed.", arch)
+			return fmt.Errorf("setting up %s build directory: %w", arch, err)
+		}
+
+		archCtx.CombustionDir = combustionDir
+		archCtx.ArtefactsDir = artefactsDir
+	}
+
+	combustionHandler, err := buildCombustion(archCtx, buildReport, c, p)
 	if err != nil {
 		log.Audit("Bootstrapping dependency services failed.")
 		return fmt.Errorf("building combustion: %w", err)
 	}
 
-	builder := build.NewBuilder(ctx, c)
-	return builder.Build()
+	if err = build.NewBuilder(archCtx, combustionHandler).Configure(); err != nil {
+		return err
+	}
+
+	targets := archCtx.ImageDefinition.Image.Targets()
+	for _, target := range targets {
+		targetCtx := archCtx.ForImage(target)
+
+		// Intermediate files of different images must not clash
+		if len(targets) > 1 {
+			targetCtx.BuildDir = filepath.Join(archCtx.BuildDir, "images", target.OutputImageName)
+			if err = os.MkdirAll(targetCtx.BuildDir, os.ModePerm); err != nil {
+				return fmt.Errorf("creating build directory for image '%s': %w", target.OutputImageName, err)
+			}
+		}
+
+		if err = build.NewBuilder(targetCtx, combustionHandler).BuildImage(); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
-func appendKubernetesSELinuxRPMs(ctx *image.Context) error {
+// architectureImage narrows the image section down to the images of the given architecture.
+// When multiple images are built, these are listed as outputs while the architecture is set as well,
+// since some of the customization components rely on it.
+func architectureImage(img image.Image, arch image.Arch) image.Image {
+	if len(img.Outputs) == 0 {
+		return img
+	}
+
+	archImage := image.Image{Arch: arch}
+	for _, output := range img.Outputs {
+		if output.Arch == arch {
+			archImage.Outputs = append(archImage.Outputs, output)
+		}
+	}
+
+	return archImage
+}
+
+func kubernetesSELinuxEnabled(ctx *image.Context) (bool, error) {
 	if ctx.ImageDefinition.Kubernetes.Version == "" {
-		return nil
+		return false, nil
 	}
 
 	configPath := combustion.KubernetesConfigPath(ctx)
 	config, err := kubernetes.ParseKubernetesConfig(configPath)
 	if err != nil {
-		return fmt.Errorf("parsing kubernetes server config: %w", err)
+		return false, fmt.Errorf("parsing kubernetes server config: %w", err)
 	}
 
 	selinuxEnabled, _ := config["selinux"].(bool)
+	return selinuxEnabled, nil
+}
+
+func appendKubernetesSELinuxRPMs(ctx *image.Context, artefactCache *cache.Cache) error {
+	selinuxEnabled, err := kubernetesSELinuxEnabled(ctx)
+	if err != nil {
+		return err
+	}
+
 	if !selinuxEnabled {
 		return nil
 	}
@@ -81,7 +188,7 @@ func appendKubernetesSELinuxRPMs(ctx *image.Context) error {
 		return fmt.Errorf("creating directory '%s': %w", gpgKeysDir, err)
 	}
 
-	if err = kubernetes.DownloadSELinuxRPMsSigningKey(gpgKeysDir); err != nil {
+	if err = kubernetes.DownloadSELinuxRPMsSigningKey(ctx.Context(), gpgKeysDir, artefactCache); err != nil {
 		return fmt.Errorf("downloading signing key: %w", err)
 	}
 
@@ -145,27 +252,38 @@ func appendKernelArgs(ctx *image.Context, kernelArgs ...string) {
 	ctx.ImageDefinition.OperatingSystem.KernelArgs = kernelArgList
 }
 
-func buildCombustion(ctx *image.Context, rootDir string) (*combustion.Combustion, error) {
+func setupCache(ctx *image.Context, rootDir string) (*cache.Cache, error) {
 	cacheDir := filepath.Join(rootDir, "cache")
 	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
 		return nil, fmt.Errorf("creating a cache directory: %w", err)
 	}
 	ctx.CacheDir = cacheDir
 
+	c, err := cache.New(cacheDir, ctx.CacheMaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("initialising cache instance: %w", err)
+	}
+
+	return c, nil
+}
+
+func buildCombustion(ctx *image.Context, buildReport *report.Report, c *cache.Cache, p *podman.Podman) (*combustion.Combustion, error) {
 	combustionHandler := &combustion.Combustion{
 		NetworkConfigGenerator:       network.ConfigGenerator{},
 		NetworkConfiguratorInstaller: network.ConfiguratorInstaller{},
+		Cache:                        c,
 	}
 
-	if !combustion.SkipRPMComponent(ctx) {
-		p, err := podman.New(ctx.BuildDir)
-		if err != nil {
-			return nil, fmt.Errorf("setting up Podman instance: %w", err)
-		}
+	if ctx.ComponentsDir != "" {
+		storeDir := filepath.Join(ctx.ComponentsDir, string(ctx.ImageDefinition.Image.Arch))
+		combustionHandler.Store = combustion.NewComponentStore(storeDir, ctx.ReuseComponents, buildReport)
+	}
 
-		imgPath := filepath.Join(ctx.ImageConfigDir, "base-images", ctx.ImageDefinition.Image.BaseImage)
-		imgType := ctx.ImageDefinition.Image.ImageType
-		baseBuilder := resolver.NewTarballBuilder(ctx.BuildDir, imgPath, imgType, string(ctx.ImageDefinition.Image.Arch), p)
+	if !combustion.SkipRPMComponent(ctx) {
+		// Packages are resolved once per architecture, so any of its base images can serve as the resolution base
+		baseImage := ctx.ImageDefinition.Image.Targets()[0]
+		imgPath := filepath.Join(ctx.ImageConfigDir, "base-images", baseImage.BaseImage)
+		baseBuilder := resolver.NewTarballBuilder(ctx.BuildDir, imgPath, baseImage.ImageType, string(ctx.ImageDefinition.Image.Arch), p)
 
 		combustionHandler.RPMResolver = resolver.New(ctx.BuildDir, p, baseBuilder, "", string(ctx.ImageDefinition.Image.Arch))
 		combustionHandler.RPMRepoCreator = rpm.NewRepoCreator(ctx.BuildDir)
@@ -174,7 +292,7 @@ func buildCombustion(ctx *image.Context, rootDir string) (*combustion.Combustion
 	if combustion.IsEmbeddedArtifactRegistryConfigured(ctx) {
 		helmClient := helm.New(ctx.BuildDir, combustion.HelmCertsPath(ctx))
 
-		r, err := registry.New(ctx, combustion.KubernetesManifestsPath(ctx), helmClient, combustion.HelmValuesPath(ctx))
+		r, err := registry.New(ctx, combustion.KubernetesManifestsPath(ctx), helmClient, combustion.HelmValuesPath(ctx), c)
 		if err != nil {
 			return nil, fmt.Errorf("initialising embedded artifact registry: %w", err)
 		}
@@ -183,12 +301,9 @@ func buildCombustion(ctx *image.Context, rootDir string) (*combustion.Combustion
 	}
 
 	if ctx.ImageDefinition.Kubernetes.Version != "" {
-		c, err := cache.New(cacheDir)
-		if err != nil {
-			return nil, fmt.Errorf("initialising cache instance: %w", err)
+		combustionHandler.KubernetesScriptDownloader = kubernetes.ScriptDownloader{
+			Cache: c,
 		}
-
-		combustionHandler.KubernetesScriptDownloader = kubernetes.ScriptDownloader{}
 		combustionHandler.KubernetesArtefactDownloader = kubernetes.ArtefactDownloader{
 			Cache: c,
 		}
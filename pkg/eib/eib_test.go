@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
 )
 
 func TestSetupBuildDirectory_EmptyRootDir(t *testing.T) {
@@ -55,3 +56,31 @@ func TestSetupBuildDir_NonEmptyRootDir(t *testing.T) {
 		})
 	}
 }
+
+func TestArchitectureImage(t *testing.T) {
+	single := image.Image{ImageType: image.TypeISO, Arch: image.ArchTypeX86, OutputImageName: "eib.iso"}
+	assert.Equal(t, single, architectureImage(single, image.ArchTypeX86))
+
+	multiple := image.Image{
+		Outputs: []image.ImageOutput{
+			{ImageType: image.TypeISO, Arch: image.ArchTypeX86, OutputImageName: "eib-x86.iso"},
+			{ImageType: image.TypeRAW, Arch: image.ArchTypeARM, OutputImageName: "eib-arm.raw"},
+			{ImageType: image.TypeQCOW2, Arch: image.ArchTypeX86, OutputImageName: "eib-x86.qcow2"},
+		},
+	}
+
+	assert.Equal(t, image.Image{
+		Arch: image.ArchTypeX86,
+		Outputs: []image.ImageOutput{
+			{ImageType: image.TypeISO, Arch: image.ArchTypeX86, OutputImageName: "eib-x86.iso"},
+			{ImageType: image.TypeQCOW2, Arch: image.ArchTypeX86, OutputImageName: "eib-x86.qcow2"},
+		},
+	}, architectureImage(multiple, image.ArchTypeX86))
+
+	assert.Equal(t, image.Image{
+		Arch: image.ArchTypeARM,
+		Outputs: []image.ImageOutput{
+			{ImageType: image.TypeRAW, Arch: image.ArchTypeARM, OutputImageName: "eib-arm.raw"},
+		},
+	}, architectureImage(multiple, image.ArchTypeARM))
+}
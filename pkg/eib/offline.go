@@ -0,0 +1,122 @@
+package eib
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/cache"
+	"github.com/suse-edge/edge-image-builder/pkg/combustion"
+	"github.com/suse-edge/edge-image-builder/pkg/http"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/kubernetes"
+	"github.com/suse-edge/edge-image-builder/pkg/log"
+	"github.com/suse-edge/edge-image-builder/pkg/registry"
+)
+
+// missingOfflineInputs lists all external inputs required by the build which are not available in the cache.
+//
+// Container images referenced by Helm charts and manifests are only known once those are rendered,
+// so their availability is verified when populating the embedded artifact registry instead.
+func missingOfflineInputs(ctx *image.Context, c *cache.Cache) ([]string, error) {
+	var missing []string
+
+	check := func(identifier, description string) {
+		if !c.Contains(identifier) {
+			missing = append(missing, description)
+		}
+	}
+
+	k8s := &ctx.ImageDefinition.Kubernetes
+
+	if k8s.Version != "" {
+		identifiers, err := kubernetesIdentifiers(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, identifier := range identifiers {
+			check(identifier, fmt.Sprintf("Kubernetes artefact '%s'", identifier))
+		}
+
+		distribution := image.KubernetesDistroK3S
+		if strings.Contains(k8s.Version, image.KubernetesDistroRKE2) {
+			distribution = image.KubernetesDistroRKE2
+		}
+		check(kubernetes.InstallScriptCacheIdentifier(distribution), fmt.Sprintf("%s install script", distribution))
+	}
+
+	for _, manifestURL := range k8s.Manifests.URLs {
+		check(registry.ManifestCacheIdentifier(manifestURL), fmt.Sprintf("Kubernetes manifest '%s'", manifestURL))
+	}
+
+	for _, chart := range k8s.Helm.Charts {
+		for _, repo := range k8s.Helm.Repositories {
+			if repo.Name == chart.RepositoryName {
+				check(registry.ChartCacheIdentifier(repo.URL, chart.Name, chart.Version),
+					fmt.Sprintf("Helm chart '%s' version '%s'", chart.Name, chart.Version))
+			}
+		}
+	}
+
+	arch := ctx.ImageDefinition.Image.Arch.Short()
+	for _, img := range ctx.ImageDefinition.EmbeddedArtifactRegistry.ContainerImages {
+		check(combustion.ContainerImageCacheIdentifier(arch, img.Name), fmt.Sprintf("Container image '%s'", img.Name))
+	}
+
+	packages := &ctx.ImageDefinition.OperatingSystem.Packages
+	if !combustion.SkipRPMComponent(ctx) && combustion.RequiresRemotePackageResolution(packages) {
+		identifier, err := combustion.RPMRepositoryCacheIdentifier(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		check(identifier, fmt.Sprintf("RPM repository resolved for packages %v", packages.PKGList))
+	}
+
+	return missing, nil
+}
+
+func kubernetesIdentifiers(ctx *image.Context) ([]string, error) {
+	version := ctx.ImageDefinition.Kubernetes.Version
+	arch := ctx.ImageDefinition.Image.Arch
+
+	if !strings.Contains(version, image.KubernetesDistroRKE2) {
+		return kubernetes.K3sCacheIdentifiers(arch, version), nil
+	}
+
+	config, err := kubernetes.ParseKubernetesConfig(combustion.KubernetesConfigPath(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("parsing kubernetes server config: %w", err)
+	}
+
+	cni, multusEnabled, err := kubernetes.ConfiguredCNI(config)
+	if err != nil {
+		return nil, fmt.Errorf("extracting CNI from server config: %w", err)
+	}
+
+	identifiers, err := kubernetes.RKE2CacheIdentifiers(arch, version, cni, multusEnabled)
+	if err != nil {
+		return nil, fmt.Errorf("identifying RKE2 artefacts: %w", err)
+	}
+
+	return identifiers, nil
+}
+
+func verifyOfflineInputs(ctx *image.Context, c *cache.Cache) error {
+	missing, err := missingOfflineInputs(ctx, c)
+	if err != nil {
+		log.Audit("Verifying offline build inputs failed.")
+		return fmt.Errorf("verifying offline build inputs: %w", err)
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	log.AuditError("The following inputs are not available in the cache and cannot be retrieved in offline mode:")
+	for _, m := range missing {
+		log.AuditErrorf("  - %s", m)
+	}
+
+	return fmt.Errorf("%d build input(s) not available in cache: %w", len(missing), http.ErrOffline)
+}
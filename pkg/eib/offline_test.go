@@ -0,0 +1,94 @@
+package eib
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/cache"
+	"github.com/suse-edge/edge-image-builder/pkg/combustion"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/registry"
+)
+
+func TestMissingOfflineInputs(t *testing.T) {
+	c, err := cache.New(filepath.Join(t.TempDir(), "cache"), 0)
+	require.NoError(t, err)
+
+	const repoURL = "https://charts.example.com"
+	require.NoError(t, c.Put(registry.ChartCacheIdentifier(repoURL, "cached-chart", "1.0.0"), "", strings.NewReader("chart")))
+	require.NoError(t, c.Put(combustion.ContainerImageCacheIdentifier(image.ArchTypeX86.Short(), "nginx:1.25"), "", strings.NewReader("image")))
+
+	ctx := &image.Context{
+		ImageConfigDir: t.TempDir(),
+		ImageDefinition: &image.Definition{
+			Image: image.Image{
+				Arch: image.ArchTypeX86,
+			},
+			OperatingSystem: image.OperatingSystem{
+				Packages: image.Packages{
+					PKGList: []string{"vim"},
+				},
+			},
+			Kubernetes: image.Kubernetes{
+				Manifests: image.Manifests{
+					URLs: []string{"https://example.com/manifest.yaml"},
+				},
+				Helm: image.Helm{
+					Charts: []image.HelmChart{
+						{Name: "cached-chart", RepositoryName: "repo", Version: "1.0.0"},
+						{Name: "missing-chart", RepositoryName: "repo", Version: "2.0.0"},
+					},
+					Repositories: []image.HelmRepository{
+						{Name: "repo", URL: repoURL},
+					},
+				},
+			},
+			EmbeddedArtifactRegistry: image.EmbeddedArtifactRegistry{
+				ContainerImages: []image.ContainerImage{
+					{Name: "nginx:1.25"},
+					{Name: "busybox:1.36"},
+				},
+			},
+		},
+	}
+
+	missing, err := missingOfflineInputs(ctx, c)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"Kubernetes manifest 'https://example.com/manifest.yaml'",
+		"Helm chart 'missing-chart' version '2.0.0'",
+		"Container image 'busybox:1.36'",
+		"RPM repository resolved for packages [vim]",
+	}, missing)
+}
+
+func TestMissingOfflineInputs_AllCached(t *testing.T) {
+	c, err := cache.New(filepath.Join(t.TempDir(), "cache"), 0)
+	require.NoError(t, err)
+
+	ctx := &image.Context{
+		ImageConfigDir: t.TempDir(),
+		ImageDefinition: &image.Definition{
+			Image: image.Image{
+				Arch: image.ArchTypeX86,
+			},
+			OperatingSystem: image.OperatingSystem{
+				Packages: image.Packages{
+					PKGList: []string{"vim"},
+				},
+			},
+		},
+	}
+
+	identifier, err := combustion.RPMRepositoryCacheIdentifier(ctx)
+	require.NoError(t, err)
+	require.NoError(t, c.Put(identifier, "", strings.NewReader("repository")))
+
+	missing, err := missingOfflineInputs(ctx, c)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+}
@@ -0,0 +1,48 @@
+package fileio
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// Lock is an advisory lock held on a file.
+type Lock struct {
+	file *os.File
+}
+
+// AcquireLock blocks until an exclusive advisory lock on the given path is obtained.
+func AcquireLock(path string) (*Lock, error) {
+	return flock(path, syscall.LOCK_EX)
+}
+
+// AcquireSharedLock blocks until a shared advisory lock on the given path is obtained.
+// Shared locks may be held by multiple readers at once, but exclude any exclusive lock.
+func AcquireSharedLock(path string) (*Lock, error) {
+	return flock(path, syscall.LOCK_SH)
+}
+
+func flock(path string, how int) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, NonExecutablePerms)
+	if err != nil {
+		return nil, fmt.Errorf("opening lock file: %w", err)
+	}
+
+	if err = syscall.Flock(int(file.Fd()), how); err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("acquiring lock: %w", err)
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// Release releases the lock and closes the underlying file.
+func (l *Lock) Release() error {
+	defer l.file.Close()
+
+	if err := syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN); err != nil {
+		return fmt.Errorf("releasing lock: %w", err)
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package helm
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/url"
@@ -11,6 +12,8 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/log"
+	"github.com/suse-edge/edge-image-builder/pkg/process"
 	"go.uber.org/zap"
 	"gopkg.in/yaml.v3"
 )
@@ -45,7 +48,7 @@ func chartPath(repoName, repoURL, chart string) string {
 	return path
 }
 
-func (h *Helm) AddRepo(repo *image.HelmRepository) error {
+func (h *Helm) AddRepo(ctx context.Context, repo *image.HelmRepository) error {
 	logFile := filepath.Join(h.outputDir, repoAddLogFileName)
 
 	file, err := os.OpenFile(logFile, outputFileFlags, fileio.NonExecutablePerms)
@@ -58,16 +61,16 @@ func (h *Helm) AddRepo(repo *image.HelmRepository) error {
 		}
 	}()
 
-	cmd := addRepoCommand(repo, h.certsDir, file)
+	cmd := addRepoCommand(ctx, repo, h.certsDir, file)
 
-	if _, err = fmt.Fprintf(file, "command: %s\n", cmd); err != nil {
+	if _, err = fmt.Fprintf(file, "command: %s\n", log.Redact(cmd.String())); err != nil {
 		return fmt.Errorf("writing command prefix to log file: %w", err)
 	}
 
 	return cmd.Run()
 }
 
-func addRepoCommand(repo *image.HelmRepository, certsDir string, output io.Writer) *exec.Cmd {
+func addRepoCommand(ctx context.Context, repo *image.HelmRepository, certsDir string, output io.Writer) *exec.Cmd {
 	var args []string
 	args = append(args, "repo", "add", repo.Name, repo.URL)
 
@@ -82,14 +85,14 @@ func addRepoCommand(repo *image.HelmRepository, certsDir string, output io.Write
 		args = append(args, "--ca-file", caFilePath)
 	}
 
-	cmd := exec.Command("helm", args...)
+	cmd := process.Command(ctx, "helm", args...)
 	cmd.Stdout = output
 	cmd.Stderr = output
 
 	return cmd
 }
 
-func (h *Helm) RegistryLogin(repo *image.HelmRepository) error {
+func (h *Helm) RegistryLogin(ctx context.Context, repo *image.HelmRepository) error {
 	logFile := filepath.Join(h.outputDir, registryLoginFileName)
 
 	file, err := os.OpenFile(logFile, outputFileFlags, fileio.NonExecutablePerms)
@@ -107,16 +110,16 @@ func (h *Helm) RegistryLogin(repo *image.HelmRepository) error {
 		return fmt.Errorf("getting host url: %w", err)
 	}
 
-	cmd := registryLoginCommand(host, repo, h.certsDir, file)
+	cmd := registryLoginCommand(ctx, host, repo, h.certsDir, file)
 
-	if _, err = fmt.Fprintf(file, "command: %s\n", cmd); err != nil {
+	if _, err = fmt.Fprintf(file, "command: %s\n", log.Redact(cmd.String())); err != nil {
 		return fmt.Errorf("writing command prefix to log file: %w", err)
 	}
 
 	return cmd.Run()
 }
 
-func registryLoginCommand(host string, repo *image.HelmRepository, certsDir string, output io.Writer) *exec.Cmd {
+func registryLoginCommand(ctx context.Context, host string, repo *image.HelmRepository, certsDir string, output io.Writer) *exec.Cmd {
 	var args []string
 	args = append(args, "registry", "login", host)
 
@@ -131,14 +134,14 @@ func registryLoginCommand(host string, repo *image.HelmRepository, certsDir stri
 		args = append(args, "--ca-file", caFilePath)
 	}
 
-	cmd := exec.Command("helm", args...)
+	cmd := process.Command(ctx, "helm", args...)
 	cmd.Stdout = output
 	cmd.Stderr = output
 
 	return cmd
 }
 
-func (h *Helm) Pull(chart string, repo *image.HelmRepository, version, destDir string) (string, error) {
+func (h *Helm) Pull(ctx context.Context, chart string, repo *image.HelmRepository, version, destDir string) (string, error) {
 	logFile := filepath.Join(h.outputDir, pullLogFileName)
 
 	file, err := os.OpenFile(logFile, outputFileFlags, fileio.NonExecutablePerms)
@@ -156,9 +159,9 @@ func (h *Helm) Pull(chart string, repo *image.HelmRepository, version, destDir s
 		return "", fmt.Errorf("creating chart dir %q: %w", chartDir, err)
 	}
 
-	cmd := pullCommand(chart, repo, version, chartDir, h.certsDir, file)
+	cmd := pullCommand(ctx, chart, repo, version, chartDir, h.certsDir, file)
 
-	if _, err = fmt.Fprintf(file, "command: %s\n", cmd); err != nil {
+	if _, err = fmt.Fprintf(file, "command: %s\n", log.Redact(cmd.String())); err != nil {
 		return "", fmt.Errorf("writing command prefix to log file: %w", err)
 	}
 
@@ -179,7 +182,7 @@ func (h *Helm) Pull(chart string, repo *image.HelmRepository, version, destDir s
 	return chartPath, nil
 }
 
-func pullCommand(chart string, repo *image.HelmRepository, version, destDir, certsDir string, output io.Writer) *exec.Cmd {
+func pullCommand(ctx context.Context, chart string, repo *image.HelmRepository, version, destDir, certsDir string, output io.Writer) *exec.Cmd {
 	path := chartPath(repo.Name, repo.URL, chart)
 
 	var args []string
@@ -202,7 +205,7 @@ func pullCommand(chart string, repo *image.HelmRepository, version, destDir, cer
 		args = append(args, "--ca-file", caFilePath)
 	}
 
-	cmd := exec.Command("helm", args...)
+	cmd := process.Command(ctx, "helm", args...)
 
 	cmd.Stdout = output
 	cmd.Stderr = output
@@ -210,7 +213,7 @@ func pullCommand(chart string, repo *image.HelmRepository, version, destDir, cer
 	return cmd
 }
 
-func (h *Helm) Template(chart, repository, version, valuesFilePath, kubeVersion, targetNamespace string, apiVersions []string) ([]map[string]any, error) {
+func (h *Helm) Template(ctx context.Context, chart, repository, version, valuesFilePath, kubeVersion, targetNamespace string, apiVersions []string) ([]map[string]any, error) {
 	logFile := filepath.Join(h.outputDir, templateLogFileName)
 
 	file, err := os.OpenFile(logFile, outputFileFlags, fileio.NonExecutablePerms)
@@ -224,9 +227,9 @@ func (h *Helm) Template(chart, repository, version, valuesFilePath, kubeVersion,
 	}()
 
 	chartContentsBuffer := new(strings.Builder)
-	cmd := templateCommand(chart, repository, version, valuesFilePath, kubeVersion, targetNamespace, apiVersions, io.MultiWriter(file, chartContentsBuffer), file)
+	cmd := templateCommand(ctx, chart, repository, version, valuesFilePath, kubeVersion, targetNamespace, apiVersions, io.MultiWriter(file, chartContentsBuffer), file)
 
-	if _, err = fmt.Fprintf(file, "command: %s\n", cmd); err != nil {
+	if _, err = fmt.Fprintf(file, "command: %s\n", log.Redact(cmd.String())); err != nil {
 		return nil, fmt.Errorf("writing command prefix to log file: %w", err)
 	}
 
@@ -243,7 +246,7 @@ func (h *Helm) Template(chart, repository, version, valuesFilePath, kubeVersion,
 	return resources, nil
 }
 
-func templateCommand(chart, repository, version, valuesFilePath, kubeVersion, targetNamespace string, apiVersions []string, stdout, stderr io.Writer) *exec.Cmd {
+func templateCommand(ctx context.Context, chart, repository, version, valuesFilePath, kubeVersion, targetNamespace string, apiVersions []string, stdout, stderr io.Writer) *exec.Cmd {
 	var args []string
 	args = append(args, "template", "--skip-crds", chart, repository)
 
@@ -265,7 +268,7 @@ func templateCommand(chart, repository, version, valuesFilePath, kubeVersion, ta
 
 	args = append(args, "--kube-version", kubeVersion)
 
-	cmd := exec.Command("helm", args...)
+	cmd := process.Command(ctx, "helm", args...)
 	cmd.Stdout = stdout
 	cmd.Stderr = stderr
 
@@ -2,6 +2,7 @@ package helm
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -165,7 +166,7 @@ func TestAddRepoCommand(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			cmd := addRepoCommand(test.repo, certsDir, &buf)
+			cmd := addRepoCommand(context.Background(), test.repo, certsDir, &buf)
 
 			assert.Equal(t, test.expectedArgs, cmd.Args)
 			assert.Equal(t, &buf, cmd.Stdout)
@@ -283,7 +284,7 @@ func TestRegistryLoginCommand(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			cmd := registryLoginCommand(test.host, test.repo, certsDir, &buf)
+			cmd := registryLoginCommand(context.Background(), test.host, test.repo, certsDir, &buf)
 
 			assert.Equal(t, test.expectedArgs, cmd.Args)
 			assert.Equal(t, &buf, cmd.Stdout)
@@ -487,7 +488,7 @@ func TestPullCommand(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			cmd := pullCommand(test.chart, test.repo, test.version, test.destDir, certsDir, &buf)
+			cmd := pullCommand(context.Background(), test.chart, test.repo, test.version, test.destDir, certsDir, &buf)
 
 			assert.Equal(t, test.expectedArgs, cmd.Args)
 			assert.Equal(t, &buf, cmd.Stdout)
@@ -557,7 +558,7 @@ func TestTemplateCommand(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			cmd := templateCommand(test.chart, test.repo, test.version, test.valuesPath, test.kubeVersion, test.targetNamespace, test.apiVersions, &stdout, &stderr)
+			cmd := templateCommand(context.Background(), test.chart, test.repo, test.version, test.valuesPath, test.kubeVersion, test.targetNamespace, test.apiVersions, &stdout, &stderr)
 
 			assert.Equal(t, test.expectedArgs, cmd.Args)
 			assert.Equal(t, &stdout, cmd.Stdout)
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"go.uber.org/zap"
+)
+
+// ErrOffline is returned for any download attempted while offline mode is enabled.
+var ErrOffline = errors.New("network access is disabled in offline mode")
+
+type FileCache interface {
+	CopyTo(identifier, dest string, perms os.FileMode) error
+	Put(identifier, source string, reader io.Reader) error
+	Remove(identifier string) error
+}
+
+// DownloadCachedFile downloads a file which may change between builds (e.g. an install script)
+// and refreshes its copy in the cache under the given identifier.
+// The cached copy is used instead when offline mode is enabled.
+//
+// A nil cache is allowed, in which case the file is only downloaded.
+func DownloadCachedFile(ctx context.Context, url, path string, cache FileCache, identifier string) error {
+	if cache == nil {
+		return DownloadFile(ctx, url, path, nil)
+	}
+
+	if IsOffline() {
+		zap.S().Infof("Copying file with identifier '%s' from cache", identifier)
+
+		if err := cache.CopyTo(identifier, path, fileio.NonExecutablePerms); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fmt.Errorf("file '%s' is not cached: %w", url, ErrOffline)
+			}
+
+			return fmt.Errorf("copying from cache: %w", err)
+		}
+
+		RecordCachedFile(ctx, url, path)
+		return nil
+	}
+
+	if err := DownloadFile(ctx, url, path, nil); err != nil {
+		return err
+	}
+
+	refreshCache(cache, identifier, url, path)
+	return nil
+}
+
+// refreshCache replaces the cached copy of the file. Failures are not fatal
+// since the file has already been downloaded to its destination.
+func refreshCache(cache FileCache, identifier, url, path string) {
+	if err := cache.Remove(identifier); err != nil {
+		zap.S().Warnf("Removing stale cache entry '%s' failed: %v", identifier, err)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		zap.S().Warnf("Opening downloaded file '%s' failed: %v", path, err)
+		return
+	}
+	defer file.Close()
+
+	if err = cache.Put(identifier, url, file); err != nil {
+		zap.S().Warnf("Caching file with identifier '%s' failed: %v", identifier, err)
+	}
+}
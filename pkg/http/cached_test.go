@@ -0,0 +1,160 @@
+package http
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+)
+
+type mockFileCache struct {
+	files map[string]string
+	dir   string
+}
+
+func newMockFileCache(t *testing.T) *mockFileCache {
+	return &mockFileCache{
+		files: map[string]string{},
+		dir:   t.TempDir(),
+	}
+}
+
+func (c *mockFileCache) CopyTo(identifier, dest string, perms os.FileMode) error {
+	path, ok := c.files[identifier]
+	if !ok {
+		return fs.ErrNotExist
+	}
+
+	return fileio.CopyFile(path, dest, perms)
+}
+
+func (c *mockFileCache) Put(identifier, _ string, reader io.Reader) error {
+	b, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(c.dir, filepath.Base(identifier))
+	if err = os.WriteFile(path, b, 0o600); err != nil {
+		return err
+	}
+
+	c.files[identifier] = path
+	return nil
+}
+
+func (c *mockFileCache) Remove(identifier string) error {
+	delete(c.files, identifier)
+	return nil
+}
+
+func TestDownloadCachedFile_RefreshesCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testContents))
+	}))
+	defer server.Close()
+
+	configureTestClient(t, Config{})
+
+	cache := newMockFileCache(t)
+	require.NoError(t, cache.Put("scripts/install.sh", "", strings.NewReader("stale")))
+
+	path := filepath.Join(t.TempDir(), "install.sh")
+	require.NoError(t, DownloadCachedFile(context.Background(), server.URL, path, cache, "scripts/install.sh"))
+
+	b, err := os.ReadFile(cache.files["scripts/install.sh"])
+	require.NoError(t, err)
+	assert.Equal(t, testContents, string(b))
+}
+
+func TestDownloadCachedFile_Offline(t *testing.T) {
+	configureTestClient(t, Config{Offline: true})
+
+	cache := newMockFileCache(t)
+	require.NoError(t, cache.Put("scripts/install.sh", "", strings.NewReader(testContents)))
+
+	path := filepath.Join(t.TempDir(), "install.sh")
+	require.NoError(t, DownloadCachedFile(context.Background(), "https://get.rke2.io", path, cache, "scripts/install.sh"))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, testContents, string(b))
+}
+
+func TestDownloadCachedFile_OfflineNotCached(t *testing.T) {
+	configureTestClient(t, Config{Offline: true})
+
+	path := filepath.Join(t.TempDir(), "install.sh")
+
+	err := DownloadCachedFile(context.Background(), "https://get.rke2.io", path, newMockFileCache(t), "scripts/install.sh")
+	require.ErrorIs(t, err, ErrOffline)
+	assert.EqualError(t, err, "file 'https://get.rke2.io' is not cached: network access is disabled in offline mode")
+	assert.NoFileExists(t, path)
+}
+
+func TestDownloadFile_Offline(t *testing.T) {
+	configureTestClient(t, Config{Offline: true})
+
+	err := DownloadFile(context.Background(), "https://get.rke2.io", filepath.Join(t.TempDir(), "install.sh"), nil)
+	require.ErrorIs(t, err, ErrOffline)
+	assert.EqualError(t, err, "downloading 'https://get.rke2.io': network access is disabled in offline mode")
+}
+
+type mockRecorder struct {
+	downloads []string
+}
+
+func (r *mockRecorder) RecordDownload(url, _ string, cached bool) {
+	if cached {
+		url += " (cached)"
+	}
+
+	r.downloads = append(r.downloads, url)
+}
+
+func TestDownloadCachedFile_Recorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testContents))
+	}))
+	defer server.Close()
+
+	recorder := &mockRecorder{}
+	configureTestClient(t, Config{Recorder: recorder})
+
+	cache := newMockFileCache(t)
+	dir := t.TempDir()
+
+	require.NoError(t, DownloadCachedFile(context.Background(), server.URL, filepath.Join(dir, "first"), cache, "file"))
+
+	configureTestClient(t, Config{Recorder: recorder, Offline: true})
+	require.NoError(t, DownloadCachedFile(context.Background(), server.URL, filepath.Join(dir, "second"), cache, "file"))
+
+	assert.Equal(t, []string{server.URL, server.URL + " (cached)"}, recorder.downloads)
+}
+
+func TestDownloadCachedFile_ContextRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testContents))
+	}))
+	defer server.Close()
+
+	recorder := &mockRecorder{}
+	configureTestClient(t, Config{Recorder: recorder})
+
+	contextRecorder := &mockRecorder{}
+	ctx := WithRecorder(context.Background(), contextRecorder)
+
+	require.NoError(t, DownloadCachedFile(ctx, server.URL, filepath.Join(t.TempDir(), "file"), nil, "file"))
+
+	assert.Empty(t, recorder.downloads)
+	assert.Equal(t, []string{server.URL}, contextRecorder.downloads)
+}
@@ -0,0 +1,210 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRetries      = 3
+	defaultRetryBackoff = 2 * time.Second
+	maxRetryBackoff     = 30 * time.Second
+
+	dialTimeout           = 30 * time.Second
+	tlsHandshakeTimeout   = 30 * time.Second
+	responseHeaderTimeout = 60 * time.Second
+)
+
+// Mirror describes the rewriting of download URLs starting with Source to the
+// same path under the Mirror location (e.g. GitHub releases to an internal Artifactory instance).
+type Mirror struct {
+	Source string
+	Mirror string
+}
+
+// DownloadRecorder is notified of every file retrieved through this package,
+// either downloaded or copied from a cache.
+type DownloadRecorder interface {
+	RecordDownload(url, path string, cached bool)
+}
+
+type Config struct {
+	// Retries is the number of additional attempts made after a failed download.
+	// A negative value disables retrying entirely, while zero applies the default.
+	Retries int
+	// RetryBackoff is the initial delay between attempts which is doubled after each retry.
+	RetryBackoff time.Duration
+	// Proxy is the URL of a proxy used for all downloads.
+	// The standard proxy environment variables are honoured when empty.
+	Proxy string
+	// Mirrors are matched against the download URLs in order; the first matching one is used.
+	Mirrors []Mirror
+	// Offline rejects all downloads with ErrOffline.
+	Offline bool
+	// Recorder is optional and is notified of every retrieved file.
+	Recorder DownloadRecorder
+}
+
+type client struct {
+	httpClient   *http.Client
+	retries      int
+	retryBackoff time.Duration
+	mirrors      []Mirror
+	offline      bool
+	recorder     DownloadRecorder
+}
+
+var (
+	defaultClientMu sync.RWMutex
+	defaultClient   = mustNewClient(Config{})
+)
+
+// Configure replaces the settings used by all subsequent downloads.
+func Configure(config Config) error {
+	c, err := newClient(config)
+	if err != nil {
+		return err
+	}
+
+	defaultClientMu.Lock()
+	defaultClient = c
+	defaultClientMu.Unlock()
+
+	return nil
+}
+
+func currentClient() *client {
+	defaultClientMu.RLock()
+	defer defaultClientMu.RUnlock()
+
+	return defaultClient
+}
+
+// IsOffline reports whether downloads are currently disabled.
+func IsOffline() bool {
+	return currentClient().offline
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a copy of the context through which the files retrieved by this package
+// are recorded by the given recorder instead of the configured one.
+func WithRecorder(ctx context.Context, recorder DownloadRecorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, recorder)
+}
+
+// RecordCachedFile notifies the recorder of a file which was
+// copied from a cache instead of being downloaded from the given URL.
+func RecordCachedFile(ctx context.Context, url, path string) {
+	currentClient().record(ctx, url, path, true)
+}
+
+func (c *client) record(ctx context.Context, url, path string, cached bool) {
+	recorder := c.recorder
+	if r, ok := ctx.Value(recorderKey{}).(DownloadRecorder); ok {
+		recorder = r
+	}
+
+	if recorder != nil {
+		recorder.RecordDownload(url, path, cached)
+	}
+}
+
+func mustNewClient(config Config) *client {
+	c, err := newClient(config)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+func newClient(config Config) (*client, error) {
+	proxy := http.ProxyFromEnvironment
+	if config.Proxy != "" {
+		proxyURL, err := url.Parse(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy URL: %w", err)
+		}
+
+		if proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL: %s", config.Proxy)
+		}
+
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	for _, m := range config.Mirrors {
+		if m.Source == "" || m.Mirror == "" {
+			return nil, fmt.Errorf("mirror source and destination must both be specified")
+		}
+
+		if _, err := url.Parse(m.Mirror); err != nil {
+			return nil, fmt.Errorf("parsing mirror URL: %w", err)
+		}
+	}
+
+	retries := config.Retries
+	switch {
+	case retries == 0:
+		retries = defaultRetries
+	case retries < 0:
+		retries = 0
+	}
+
+	backoff := config.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	transport := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   tlsHandshakeTimeout,
+		ResponseHeaderTimeout: responseHeaderTimeout,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	return &client{
+		httpClient:   &http.Client{Transport: transport},
+		retries:      retries,
+		retryBackoff: backoff,
+		mirrors:      config.Mirrors,
+		offline:      config.Offline,
+		recorder:     config.Recorder,
+	}, nil
+}
+
+// resolveURL applies the first matching mirror to the given URL.
+func (c *client) resolveURL(rawURL string) string {
+	for _, m := range c.mirrors {
+		if strings.HasPrefix(rawURL, m.Source) {
+			mirrored := strings.TrimSuffix(m.Mirror, "/") + "/" + strings.TrimPrefix(strings.TrimPrefix(rawURL, m.Source), "/")
+			zap.S().Infof("Using mirror '%s' for '%s'", mirrored, rawURL)
+			return mirrored
+		}
+	}
+
+	return rawURL
+}
+
+func (c *client) backoff(attempt int) time.Duration {
+	delay := c.retryBackoff << attempt
+	if delay <= 0 || delay > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+
+	return delay
+}
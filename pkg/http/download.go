@@ -2,62 +2,154 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"syscall"
+	"time"
 
-	"github.com/schollz/progressbar/v3"
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"go.uber.org/zap"
 )
 
+// partialSuffix is appended to the destination path while a download is in progress.
+// Interrupted downloads are resumed from this file on the next attempt.
+const partialSuffix = ".part"
+
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.code)
+}
+
 // DownloadFile downloads a file from the specified URL and stores it to the given path.
 //
+// Failed downloads are retried with an exponential backoff, resuming from the already
+// received data if the server supports range requests. The URL is rewritten according
+// to the configured mirrors prior to downloading.
+//
 // Optionally provide an additional cache writer in cases where the pending download
-// must be stored to other locations alongside the given path.
+// must be stored to other locations alongside the given path. The cache writer only
+// receives the contents once the download has fully completed.
 func DownloadFile(ctx context.Context, url, path string, cache io.Writer) error {
+	c := currentClient()
+	if c.offline {
+		return fmt.Errorf("downloading '%s': %w", url, ErrOffline)
+	}
+
 	filename := filepath.Base(path)
+	sourceURL := url
+	url = c.resolveURL(url)
 
 	zap.S().Infof("Downloading file '%s' from '%s' to '%s'...", filename, url, filepath.Dir(path))
 
+	partialPath := path + partialSuffix
+
+	for attempt := 0; ; attempt++ {
+		err := c.download(ctx, url, partialPath, filename)
+		if err == nil {
+			break
+		}
+
+		if attempt >= c.retries || !isRetryable(ctx, err) {
+			return err
+		}
+
+		delay := c.backoff(attempt)
+		zap.S().Warnf("Downloading file '%s' failed (attempt %d of %d), retrying in %s: %v",
+			filename, attempt+1, c.retries+1, delay, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting to retry: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+
+	if err := os.Rename(partialPath, path); err != nil {
+		return fmt.Errorf("moving downloaded file: %w", err)
+	}
+
+	if cache != nil {
+		if err := copyToCache(path, cache); err != nil {
+			return fmt.Errorf("writing to cache: %w", err)
+		}
+	}
+
+	c.record(ctx, sourceURL, path, false)
+	zap.S().Infof("Downloading file '%s' completed", filename)
+
+	return nil
+}
+
+func (c *client) download(ctx context.Context, url, path, filename string) error {
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
 	if err != nil {
 		return fmt.Errorf("creating request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	flags := os.O_CREATE | os.O_WRONLY
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		// The server either does not support range requests or this is the first attempt
+		flags |= os.O_TRUNC
+		offset = 0
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		zap.S().Infof("Resuming download of '%s' from byte %d", filename, offset)
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The partial file is no longer consistent with the remote one, start over on the next attempt
+		if err = os.Remove(path); err != nil {
+			return fmt.Errorf("removing partial file: %w", err)
+		}
+		return &statusError{code: resp.StatusCode}
+	default:
+		return &statusError{code: resp.StatusCode}
 	}
 
-	file, err := os.Create(path)
+	file, err := os.OpenFile(path, flags, fileio.NonExecutablePerms)
 	if err != nil {
 		return fmt.Errorf("creating file: %w", err)
 	}
 	defer file.Close()
 
-	var writers []io.Writer
-	writers = append(writers, file)
-
-	if cache != nil {
-		writers = append(writers, cache)
-	}
+	writers := []io.Writer{file}
 
 	message := fmt.Sprintf("Downloading file: %s", filename)
+	output := log.Output(ctx)
 
 	if resp.ContentLength == -1 {
 		// Only audit the message since progress bars of unknown length
 		// (i.e. spinners) are not properly rendered.
-		log.Audit(message)
+		output.Audit(message)
 	} else {
-		bar := progressbar.DefaultBytes(resp.ContentLength, message)
+		bar := output.NewBytesProgressBar(offset+resp.ContentLength, message)
+		if offset > 0 {
+			_ = bar.Add64(offset)
+		}
 		writers = append(writers, bar)
 	}
 
@@ -65,7 +157,51 @@ func DownloadFile(ctx context.Context, url, path string, cache io.Writer) error
 		return fmt.Errorf("storing response: %w", err)
 	}
 
-	zap.S().Infof("Downloading file '%s' completed", filename)
-
 	return nil
 }
+
+func copyToCache(path string, cache io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	_, err = io.Copy(cache, file)
+	return err
+}
+
+// isRetryable reports whether the error is likely to be transient.
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx != nil && ctx.Err() != nil {
+		return false
+	}
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= http.StatusInternalServerError ||
+			statusErr.code == http.StatusTooManyRequests ||
+			statusErr.code == http.StatusRequestTimeout ||
+			statusErr.code == http.StatusRequestedRangeNotSatisfiable
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return dnsErr.IsTemporary || dnsErr.IsTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
@@ -2,13 +2,46 @@ package http
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+const testContents = "some-data-which-is-long-enough-to-be-split"
+
+func configureTestClient(t *testing.T, config Config) {
+	if config.RetryBackoff == 0 {
+		config.RetryBackoff = time.Millisecond
+	}
+
+	require.NoError(t, Configure(config))
+	t.Cleanup(func() {
+		require.NoError(t, Configure(Config{}))
+	})
+}
+
 func TestDownloadFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(testContents))
+	}))
+	defer server.Close()
+
+	configureTestClient(t, Config{})
+
 	tests := []struct {
 		name        string
 		ctx         context.Context
@@ -29,15 +62,15 @@ func TestDownloadFile(t *testing.T) {
 		{
 			name:        "Unexpected status",
 			ctx:         context.Background(),
-			url:         "https://github.com/suse-edge/eib",
+			url:         server.URL + "/missing",
 			expectedErr: "unexpected status code: 404",
 		},
 		{
 			name:        "Error creating file",
 			ctx:         context.Background(),
-			url:         "https://github.com/suse-edge/edge-image-builder",
+			url:         server.URL + "/file",
 			path:        "downloads/abc",
-			expectedErr: "creating file: open downloads/abc: no such file or directory",
+			expectedErr: "creating file: open downloads/abc.part: no such file or directory",
 		},
 	}
 
@@ -49,3 +82,244 @@ func TestDownloadFile(t *testing.T) {
 		})
 	}
 }
+
+func TestDownloadFile_Successful(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(testContents))
+	}))
+	defer server.Close()
+
+	configureTestClient(t, Config{})
+
+	path := filepath.Join(t.TempDir(), "file")
+
+	var sb strings.Builder
+	require.NoError(t, DownloadFile(context.Background(), server.URL, path, &sb))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, testContents, string(b))
+	assert.Equal(t, testContents, sb.String())
+	assert.NoFileExists(t, path+partialSuffix)
+}
+
+func TestDownloadFile_RetryServerError(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write([]byte(testContents))
+	}))
+	defer server.Close()
+
+	configureTestClient(t, Config{Retries: 2})
+
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, DownloadFile(context.Background(), server.URL, path, nil))
+
+	assert.EqualValues(t, 3, requests.Load())
+	assert.FileExists(t, path)
+}
+
+func TestDownloadFile_RetriesExhausted(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	configureTestClient(t, Config{Retries: 2})
+
+	err := DownloadFile(context.Background(), server.URL, filepath.Join(t.TempDir(), "file"), nil)
+	assert.EqualError(t, err, "unexpected status code: 502")
+	assert.EqualValues(t, 3, requests.Load())
+}
+
+func TestDownloadFile_NoRetryClientError(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	configureTestClient(t, Config{Retries: 5})
+
+	err := DownloadFile(context.Background(), server.URL, filepath.Join(t.TempDir(), "file"), nil)
+	assert.EqualError(t, err, "unexpected status code: 403")
+	assert.EqualValues(t, 1, requests.Load())
+}
+
+func TestDownloadFile_ResumeInterrupted(t *testing.T) {
+	var requests atomic.Int32
+	var rangeHeader atomic.Value
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			// Announce the full length but only send half of the contents
+			w.Header().Set("Content-Length", fmt.Sprint(len(testContents)))
+			_, _ = w.Write([]byte(testContents[:len(testContents)/2]))
+			return
+		}
+
+		rangeHeader.Store(r.Header.Get("Range"))
+
+		var start int
+		_, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(testContents)-1, len(testContents)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write([]byte(testContents[start:]))
+	}))
+	defer server.Close()
+
+	configureTestClient(t, Config{Retries: 1})
+
+	path := filepath.Join(t.TempDir(), "file")
+
+	var sb strings.Builder
+	require.NoError(t, DownloadFile(context.Background(), server.URL, path, &sb))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, testContents, string(b))
+	assert.Equal(t, testContents, sb.String())
+	assert.EqualValues(t, 2, requests.Load())
+	assert.Equal(t, fmt.Sprintf("bytes=%d-", len(testContents)/2), rangeHeader.Load())
+}
+
+func TestDownloadFile_ResumeUnsupported(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Content-Length", fmt.Sprint(len(testContents)))
+			_, _ = w.Write([]byte(testContents[:5]))
+			return
+		}
+
+		// Ignore the range header and return the full contents
+		_, _ = w.Write([]byte(testContents))
+	}))
+	defer server.Close()
+
+	configureTestClient(t, Config{Retries: 1})
+
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, DownloadFile(context.Background(), server.URL, path, nil))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, testContents, string(b))
+}
+
+func TestDownloadFile_Mirror(t *testing.T) {
+	var requestedPath atomic.Value
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath.Store(r.URL.Path)
+		_, _ = w.Write([]byte(testContents))
+	}))
+	defer server.Close()
+
+	configureTestClient(t, Config{
+		Mirrors: []Mirror{
+			{
+				Source: "https://github.com/rancher/rke2/releases/download",
+				Mirror: server.URL + "/artifactory/rke2/",
+			},
+		},
+	})
+
+	path := filepath.Join(t.TempDir(), "rke2.linux-amd64.tar.gz")
+	url := "https://github.com/rancher/rke2/releases/download/v1.30.3+rke2r1/rke2.linux-amd64.tar.gz"
+
+	require.NoError(t, DownloadFile(context.Background(), url, path, nil))
+	assert.Equal(t, "/artifactory/rke2/v1.30.3+rke2r1/rke2.linux-amd64.tar.gz", requestedPath.Load())
+}
+
+func TestDownloadFile_Proxy(t *testing.T) {
+	var proxiedURL atomic.Value
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedURL.Store(r.URL.String())
+		_, _ = w.Write([]byte(testContents))
+	}))
+	defer proxy.Close()
+
+	configureTestClient(t, Config{Proxy: proxy.URL})
+
+	path := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, DownloadFile(context.Background(), "http://example.com/file", path, nil))
+
+	assert.Equal(t, "http://example.com/file", proxiedURL.Load())
+}
+
+func TestDownloadFile_CancelledDuringBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	configureTestClient(t, Config{Retries: 5, RetryBackoff: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := DownloadFile(ctx, server.URL, filepath.Join(t.TempDir(), "file"), nil)
+	assert.EqualError(t, err, "waiting to retry: context deadline exceeded")
+}
+
+func TestConfigure_Invalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		expectedErr string
+	}{
+		{
+			name:        "Invalid proxy",
+			config:      Config{Proxy: "proxy.example.com"},
+			expectedErr: "invalid proxy URL: proxy.example.com",
+		},
+		{
+			name:        "Incomplete mirror",
+			config:      Config{Mirrors: []Mirror{{Source: "https://github.com"}}},
+			expectedErr: "mirror source and destination must both be specified",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualError(t, Configure(test.config), test.expectedErr)
+		})
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	c, err := newClient(Config{
+		Mirrors: []Mirror{
+			{Source: "https://github.com/rancher/rke2/releases/download/", Mirror: "https://mirror.example.com/rke2"},
+			{Source: "https://github.com", Mirror: "https://mirror.example.com/github"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://mirror.example.com/rke2/v1.30.3+rke2r1/rke2.linux-amd64.tar.gz",
+		c.resolveURL("https://github.com/rancher/rke2/releases/download/v1.30.3+rke2r1/rke2.linux-amd64.tar.gz"))
+	assert.Equal(t, "https://mirror.example.com/github/k3s-io/k3s/releases/download/v1.30.3+k3s1/k3s",
+		c.resolveURL("https://github.com/k3s-io/k3s/releases/download/v1.30.3+k3s1/k3s"))
+	assert.Equal(t, "https://get.rke2.io", c.resolveURL("https://get.rke2.io"))
+}
@@ -1,5 +1,13 @@
 package image
 
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/suse-edge/edge-image-builder/pkg/log"
+)
+
 type LocalRPMConfig struct {
 	// RPMPath is the path to the directory holding RPMs that will be side-loaded
 	RPMPath string
@@ -22,8 +30,111 @@ type Context struct {
 	ArtifactSources *ArtifactSources
 	// CacheDir contains all of the artifacts that are cached for the build process.
 	CacheDir string
+	// ComponentsDir stores the outputs of the Combustion components configured by previous builds.
+	// The outputs are neither stored nor reused if empty.
+	ComponentsDir string
+	// ReuseComponents enables the reuse of the stored outputs of components whose inputs are unchanged.
+	ReuseComponents bool
+	// CacheMaxSize is the maximum size (in bytes) of the artefact cache. Zero means unlimited.
+	CacheMaxSize int64
+	// Parallelism is the maximum number of Combustion components configured concurrently.
+	// Values lower than two configure the components sequentially.
+	Parallelism int
+	// Output buffers the audit output of a component configured concurrently with others.
+	// It may be nil, in which case the output is displayed immediately.
+	Output *log.Buffer
+	// StageTimeout limits the duration of each build stage, i.e. the generation of the customization
+	// components of an architecture and the build of each image. Zero means no limit.
+	StageTimeout time.Duration
+
+	// ctx is cancelled when the build is interrupted or a stage exceeds its timeout.
+	ctx context.Context
+	// recorder collects the details of the build.
+	recorder Recorder
+}
+
+// Recorder collects the details of the build, e.g. to summarise them in the build report.
+type Recorder interface {
+	AddComponent(name, arch string, duration time.Duration, scripts []string, err error)
+	AddPackage(name, version, arch, repository string)
+	AddContainerImage(name, arch, digest, archivePath string) error
+	AddHelmChart(name, repository, version string)
+	AddSideLoadedFile(name, path string) error
+	AddImage(path, imageType, arch string) error
+}
+
+// Context returns the context cancelling the operations of the build.
+// The background context is returned if none has been set.
+func (c *Context) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+
+	return c.ctx
+}
+
+// WithContext returns a shallow copy of the image context whose operations are cancelled through the given context.
+func (c *Context) WithContext(ctx context.Context) *Context {
+	imageCtx := *c
+	imageCtx.ctx = ctx
+
+	return &imageCtx
+}
+
+// Recorder returns the recorder collecting the details of the build.
+// A recorder discarding all details is returned if none has been set.
+func (c *Context) Recorder() Recorder {
+	if c.recorder == nil {
+		return nopRecorder{}
+	}
+
+	return c.recorder
+}
+
+// WithRecorder returns a shallow copy of the image context whose details are collected by the given recorder.
+func (c *Context) WithRecorder(recorder Recorder) *Context {
+	imageCtx := *c
+	imageCtx.recorder = recorder
+
+	return &imageCtx
+}
+
+// OutputImagePath returns the path of the image built for the context.
+func (c *Context) OutputImagePath() string {
+	return filepath.Join(c.ImageConfigDir, c.ImageDefinition.Image.OutputImageName)
+}
+
+// BaseImagePath returns the path of the base image customized for the context.
+func (c *Context) BaseImagePath() string {
+	return filepath.Join(c.ImageConfigDir, "base-images", c.ImageDefinition.Image.BaseImage)
+}
+
+// ForImage returns a copy of the context in which the image section of the definition is replaced by the given image.
+// The rest of the definition is shared with the original context.
+func (c *Context) ForImage(img Image) *Context {
+	definition := *c.ImageDefinition
+	definition.Image = img
+
+	imageCtx := *c
+	imageCtx.ImageDefinition = &definition
+
+	return &imageCtx
 }
 
+type nopRecorder struct{}
+
+func (nopRecorder) AddComponent(string, string, time.Duration, []string, error) {}
+
+func (nopRecorder) AddPackage(string, string, string, string) {}
+
+func (nopRecorder) AddContainerImage(string, string, string, string) error { return nil }
+
+func (nopRecorder) AddHelmChart(string, string, string) {}
+
+func (nopRecorder) AddSideLoadedFile(string, string) error { return nil }
+
+func (nopRecorder) AddImage(string, string, string) error { return nil }
+
 type ArtifactSources struct {
 	MetalLB struct {
 		Chart      string `yaml:"chart"`
@@ -45,4 +156,12 @@ type ArtifactSources struct {
 			SELinuxRepository string `yaml:"selinuxRepository"`
 		} `yaml:"rke2"`
 	} `yaml:"kubernetes"`
+	Downloads struct {
+		Retries int    `yaml:"retries"`
+		Proxy   string `yaml:"proxy"`
+		Mirrors []struct {
+			Source string `yaml:"source"`
+			Mirror string `yaml:"mirror"`
+		} `yaml:"mirrors"`
+	} `yaml:"downloads"`
 }
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -13,8 +15,11 @@ import (
 )
 
 const (
-	TypeISO = "iso"
-	TypeRAW = "raw"
+	TypeISO   = "iso"
+	TypeRAW   = "raw"
+	TypeQCOW2 = "qcow2"
+	TypeVMDK  = "vmdk"
+	TypeVHDX  = "vhdx"
 
 	ArchTypeX86 Arch = "x86_64"
 	ArchTypeARM Arch = "aarch64"
@@ -41,6 +46,26 @@ type Definition struct {
 	OperatingSystem          OperatingSystem          `yaml:"operatingSystem"`
 	EmbeddedArtifactRegistry EmbeddedArtifactRegistry `yaml:"embeddedArtifactRegistry"`
 	Kubernetes               Kubernetes               `yaml:"kubernetes"`
+	NodeOverrides            []NodeOverride           `yaml:"nodeOverrides"`
+
+	deprecations []string
+	// document is the parsed definition, used to locate its values.
+	document *Document
+}
+
+// Sources lists the files the definition was combined with, i.e. its includes and overlays.
+func (d *Definition) Sources() []string {
+	if d.document == nil {
+		return nil
+	}
+
+	return d.document.Sources
+}
+
+// Deprecations lists the fields of an older schema version used by the parsed definition
+// which have since been renamed or moved.
+func (d *Definition) Deprecations() []string {
+	return d.deprecations
 }
 
 type Arch string
@@ -62,6 +87,64 @@ type Image struct {
 	Arch            Arch   `yaml:"arch"`
 	BaseImage       string `yaml:"baseImage"`
 	OutputImageName string `yaml:"outputImageName"`
+	// Outputs replaces the fields above when multiple images are built from the same definition.
+	Outputs []ImageOutput `yaml:"outputs"`
+}
+
+// ImageOutput describes one of multiple images built from the same definition.
+type ImageOutput struct {
+	ImageType       string `yaml:"imageType"`
+	Arch            Arch   `yaml:"arch"`
+	BaseImage       string `yaml:"baseImage"`
+	OutputImageName string `yaml:"outputImageName"`
+}
+
+// Targets lists every image to be built, each describing a single output.
+func (i Image) Targets() []Image {
+	if len(i.Outputs) == 0 {
+		return []Image{i}
+	}
+
+	targets := make([]Image, 0, len(i.Outputs))
+	for _, o := range i.Outputs {
+		targets = append(targets, Image{
+			ImageType:       o.ImageType,
+			Arch:            o.Arch,
+			BaseImage:       o.BaseImage,
+			OutputImageName: o.OutputImageName,
+		})
+	}
+
+	return targets
+}
+
+// Architectures lists the distinct architectures of the images to be built
+// in the order in which they are first listed.
+func (i Image) Architectures() []Arch {
+	var archs []Arch
+	for _, target := range i.Targets() {
+		if !slices.Contains(archs, target.Arch) {
+			archs = append(archs, target.Arch)
+		}
+	}
+
+	return archs
+}
+
+// IsDiskImage indicates whether the image is built by customizing a RAW base image.
+// Apart from RAW images themselves, this includes the virtual disk formats the RAW image is converted to.
+func (i Image) IsDiskImage() bool {
+	switch i.ImageType {
+	case TypeRAW, TypeQCOW2, TypeVMDK, TypeVHDX:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasTarget indicates whether any of the images to be built matches the given condition.
+func (i Image) HasTarget(matches func(target Image) bool) bool {
+	return slices.ContainsFunc(i.Targets(), matches)
 }
 
 type OperatingSystem struct {
@@ -120,13 +203,15 @@ func (d DiskSize) ToMB() int64 {
 
 type RawConfiguration struct {
 	DiskSize DiskSize `yaml:"diskSize"`
+	// Compress enables the compression of converted disk images. Only supported for qcow2 and vmdk images.
+	Compress bool `yaml:"compress"`
 }
 
 type Packages struct {
 	NoGPGCheck      bool      `yaml:"noGPGCheck"`
 	PKGList         []string  `yaml:"packageList"`
 	AdditionalRepos []AddRepo `yaml:"additionalRepos"`
-	RegCode         string    `yaml:"sccRegistrationCode"`
+	RegCode         string    `yaml:"registrationCode"`
 }
 
 type AddRepo struct {
@@ -180,8 +265,17 @@ type EmbeddedArtifactRegistry struct {
 	ContainerImages []ContainerImage `yaml:"images"`
 }
 
+// ContainerImage is listed by its name only, e.g. "hello-world:latest".
 type ContainerImage struct {
-	Name string `yaml:"name"`
+	Name string
+}
+
+func (c *ContainerImage) UnmarshalYAML(value *yaml.Node) error {
+	return value.Decode(&c.Name)
+}
+
+func (c ContainerImage) MarshalYAML() (any, error) {
+	return c.Name, nil
 }
 
 type Kubernetes struct {
@@ -237,22 +331,202 @@ type HelmAuthentication struct {
 	Password string `yaml:"password"`
 }
 
+// NodeOverride customizes the node identified by its hostname or the MAC address of one of its interfaces
+// in addition to the configuration applied to all nodes. Files and scripts specific to the node are provided
+// in a directory named after the identifier under the 'node-overrides' configuration directory.
+type NodeOverride struct {
+	Hostname   string                `yaml:"hostname"`
+	MACAddress string                `yaml:"macAddress"`
+	Users      []OperatingSystemUser `yaml:"users"`
+	Systemd    Systemd               `yaml:"systemd"`
+}
+
+// ID returns the hostname or MAC address identifying the node.
+func (n NodeOverride) ID() string {
+	if n.Hostname != "" {
+		return n.Hostname
+	}
+
+	return n.MACAddress
+}
+
+// SensitiveValues lists the values of all fields holding secrets, which must never be logged.
+func (d *Definition) SensitiveValues() []string {
+	values := []string{
+		d.OperatingSystem.Packages.RegCode,
+		d.OperatingSystem.Suma.ActivationKey,
+	}
+
+	for _, user := range d.OperatingSystem.Users {
+		values = append(values, user.EncryptedPassword)
+	}
+
+	for _, override := range d.NodeOverrides {
+		for _, user := range override.Users {
+			values = append(values, user.EncryptedPassword)
+		}
+	}
+
+	for _, repo := range d.Kubernetes.Helm.Repositories {
+		values = append(values, repo.Authentication.Password)
+	}
+
+	return slices.DeleteFunc(values, func(value string) bool {
+		return value == ""
+	})
+}
+
 var ErrorInvalidSchemaVersion = errors.New("invalid schema version")
 
+// ParseDefinition decodes the image definition according to its schema version and converts
+// definitions of older schema versions to the latest one.
 func ParseDefinition(data []byte) (*Definition, error) {
-	var definition Definition
+	var document yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&document); err != nil {
+		return nil, fmt.Errorf("could not parse the image definition: %w", err)
+	}
 
-	decoder := yaml.NewDecoder(bytes.NewReader(data))
-	decoder.KnownFields(true)
+	return ParseDocument(&Document{Root: document.Content[0]})
+}
+
+// ParseDocument decodes the already parsed image definition, such as one combined with its includes
+// and overlays, in the same way as ParseDefinition. The document is retained to locate its values.
+func ParseDocument(document *Document) (*Definition, error) {
+	var header struct {
+		APIVersion string `yaml:"apiVersion"`
+	}
 
-	if err := decoder.Decode(&definition); err != nil {
+	if err := document.Root.Decode(&header); err != nil {
 		return nil, fmt.Errorf("could not parse the image definition: %w", err)
 	}
+
+	model, err := DefinitionModel(header.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = decodeDefinition(document.Root, model); err != nil {
+		return nil, err
+	}
+
+	var definition *Definition
+
+	switch m := model.(type) {
+	case *definitionV11:
+		definition = m.convert()
+	case *Definition:
+		definition = m
+	}
+
+	definition.document = document
+
 	definition.Image.ImageType = strings.ToLower(definition.Image.ImageType)
+	for i := range definition.Image.Outputs {
+		definition.Image.Outputs[i].ImageType = strings.ToLower(definition.Image.Outputs[i].ImageType)
+	}
+
+	return definition, nil
+}
 
-	if !version.IsSchemaVersionSupported(definition.APIVersion) {
+// DefinitionModel returns an empty value of the type definitions of the given schema version are decoded into.
+func DefinitionModel(apiVersion string) (any, error) {
+	if !version.IsSchemaVersionSupported(apiVersion) {
 		return nil, ErrorInvalidSchemaVersion
 	}
 
-	return &definition, nil
+	switch apiVersion {
+	case version.SchemaVersion10, version.SchemaVersion11:
+		return &definitionV11{}, nil
+	default:
+		return &Definition{}, nil
+	}
+}
+
+// decodeDefinition decodes the node directly rather than its encoded form, so that the reported lines
+// refer to the files the values were read from. As nodes cannot be decoded strictly, unknown fields
+// are looked up separately and reported along with any invalid values.
+func decodeDefinition(node *yaml.Node, definition any) error {
+	unknown := unknownFields(node, reflect.TypeOf(definition))
+
+	err := node.Decode(definition)
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		unknown = append(unknown, typeErr.Errors...)
+	} else if err != nil {
+		return fmt.Errorf("could not parse the image definition: %w", err)
+	}
+
+	if len(unknown) != 0 {
+		return fmt.Errorf("could not parse the image definition: %w", &yaml.TypeError{Errors: unknown})
+	}
+
+	return nil
+}
+
+var unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+
+// unknownFields lists the mapping keys of the node which do not match a field of the given type.
+func unknownFields(node *yaml.Node, t reflect.Type) []string {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	// Types decoding themselves accept whichever fields they choose
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	var unknown []string
+
+	switch {
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := structFields(t)
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+
+			fieldType, ok := fields[key.Value]
+			if !ok {
+				unknown = append(unknown, fmt.Sprintf("line %d: field %s not found in type %s", key.Line, key.Value, t))
+				continue
+			}
+
+			unknown = append(unknown, unknownFields(node.Content[i+1], fieldType)...)
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for _, item := range node.Content {
+			unknown = append(unknown, unknownFields(item, t.Elem())...)
+		}
+	}
+
+	return unknown
+}
+
+// structFields maps the keys of the struct fields to their types the same way the YAML decoder does.
+func structFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = strings.ToLower(field.Name)
+		}
+
+		fields[name] = field.Type
+	}
+
+	return fields
 }
@@ -2,6 +2,7 @@ package image
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -255,6 +256,98 @@ apiVersion: 10.0
 	require.ErrorIs(t, err, ErrorInvalidSchemaVersion)
 }
 
+func TestParseDefinition_SchemaVersion12(t *testing.T) {
+	config := `
+apiVersion: 1.2
+image:
+  imageType: RAW
+operatingSystem:
+  packages:
+    registrationCode: INTERNAL-USE-ONLY-foo-bar
+embeddedArtifactRegistry:
+  images:
+    - hello-world:latest
+    - nginx:1.27
+`
+
+	definition, err := ParseDefinition([]byte(config))
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.2", definition.APIVersion)
+	assert.Equal(t, "raw", definition.Image.ImageType)
+	assert.Equal(t, "INTERNAL-USE-ONLY-foo-bar", definition.OperatingSystem.Packages.RegCode)
+	assert.Equal(t, []ContainerImage{{Name: "hello-world:latest"}, {Name: "nginx:1.27"}}, definition.EmbeddedArtifactRegistry.ContainerImages)
+	assert.Empty(t, definition.Deprecations())
+}
+
+func TestParseDefinition_NodeOverrides(t *testing.T) {
+	config := `
+apiVersion: 1.2
+nodeOverrides:
+  - hostname: node1.suse.com
+    systemd:
+      enable:
+        - iscsid
+    users:
+      - username: storage
+        encryptedPassword: $6$storage
+  - macAddress: 34:8A:B1:4B:16:E1
+    systemd:
+      disable:
+        - cups
+`
+
+	definition, err := ParseDefinition([]byte(config))
+	require.NoError(t, err)
+
+	require.Len(t, definition.NodeOverrides, 2)
+
+	node1 := definition.NodeOverrides[0]
+	assert.Equal(t, "node1.suse.com", node1.ID())
+	assert.Equal(t, []string{"iscsid"}, node1.Systemd.Enable)
+	assert.Equal(t, []OperatingSystemUser{{Username: "storage", EncryptedPassword: "$6$storage"}}, node1.Users)
+
+	node2 := definition.NodeOverrides[1]
+	assert.Equal(t, "34:8A:B1:4B:16:E1", node2.ID())
+	assert.Equal(t, []string{"cups"}, node2.Systemd.Disable)
+
+	// Node overrides are not available in older schema versions
+	_, err = ParseDefinition([]byte(strings.Replace(config, "1.2", "1.1", 1)))
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "field nodeOverrides not found")
+}
+
+func TestParseDefinition_SchemaVersion12RenamedFields(t *testing.T) {
+	config := `
+apiVersion: 1.2
+operatingSystem:
+  packages:
+    sccRegistrationCode: INTERNAL-USE-ONLY-foo-bar
+`
+
+	_, err := ParseDefinition([]byte(config))
+
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "line 5: field sccRegistrationCode not found in type image.Packages")
+}
+
+func TestParseDefinition_Deprecations(t *testing.T) {
+	definition, err := ParseDefinition([]byte("apiVersion: 1.1\n"))
+	require.NoError(t, err)
+	assert.Empty(t, definition.Deprecations())
+
+	configData, err := os.ReadFile("./testdata/full-valid-example.yaml")
+	require.NoError(t, err)
+
+	definition, err = ParseDefinition(configData)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"The 'operatingSystem.packages.sccRegistrationCode' field is renamed to 'operatingSystem.packages.registrationCode' in schema version 1.2.",
+		"The 'embeddedArtifactRegistry.images' entries are plain image names instead of objects with a 'name' field in schema version 1.2.",
+	}, definition.Deprecations())
+}
+
 func TestArch_Short(t *testing.T) {
 	assert.Equal(t, "amd64", ArchTypeX86.Short())
 	assert.Equal(t, "arm64", ArchTypeARM.Short())
@@ -264,6 +357,38 @@ func TestArch_Short(t *testing.T) {
 	})
 }
 
+func TestImage_IsDiskImage(t *testing.T) {
+	assert.False(t, Image{ImageType: TypeISO}.IsDiskImage())
+	assert.True(t, Image{ImageType: TypeRAW}.IsDiskImage())
+	assert.True(t, Image{ImageType: TypeQCOW2}.IsDiskImage())
+	assert.True(t, Image{ImageType: TypeVMDK}.IsDiskImage())
+	assert.True(t, Image{ImageType: TypeVHDX}.IsDiskImage())
+}
+
+func TestImage_Targets(t *testing.T) {
+	single := Image{ImageType: TypeISO, Arch: ArchTypeX86, BaseImage: "base.iso", OutputImageName: "eib.iso"}
+	assert.Equal(t, []Image{single}, single.Targets())
+
+	multiple := Image{
+		Outputs: []ImageOutput{
+			{ImageType: TypeISO, Arch: ArchTypeX86, BaseImage: "base.iso", OutputImageName: "eib.iso"},
+			{ImageType: TypeQCOW2, Arch: ArchTypeARM, BaseImage: "base.raw", OutputImageName: "eib.qcow2"},
+			{ImageType: TypeRAW, Arch: ArchTypeX86, BaseImage: "base.raw", OutputImageName: "eib.raw"},
+		},
+	}
+	assert.Equal(t, []Image{
+		{ImageType: TypeISO, Arch: ArchTypeX86, BaseImage: "base.iso", OutputImageName: "eib.iso"},
+		{ImageType: TypeQCOW2, Arch: ArchTypeARM, BaseImage: "base.raw", OutputImageName: "eib.qcow2"},
+		{ImageType: TypeRAW, Arch: ArchTypeX86, BaseImage: "base.raw", OutputImageName: "eib.raw"},
+	}, multiple.Targets())
+	assert.Equal(t, []Arch{ArchTypeX86, ArchTypeARM}, multiple.Architectures())
+
+	assert.True(t, multiple.HasTarget(Image.IsDiskImage))
+	assert.True(t, multiple.HasTarget(func(target Image) bool { return target.ImageType == TypeISO }))
+	assert.False(t, multiple.HasTarget(func(target Image) bool { return target.ImageType == TypeVMDK }))
+	assert.False(t, single.HasTarget(Image.IsDiskImage))
+}
+
 func TestDiskSize_ToMB(t *testing.T) {
 	assert.EqualValues(t, 50, DiskSize("50M").ToMB())
 	assert.EqualValues(t, 4096, DiskSize("4G").ToMB())
@@ -273,3 +398,30 @@ func TestDiskSize_ToMB(t *testing.T) {
 		DiskSize("10K").ToMB()
 	})
 }
+
+func TestDefinition_SensitiveValues(t *testing.T) {
+	definition := Definition{
+		OperatingSystem: OperatingSystem{
+			Users: []OperatingSystemUser{
+				{Username: "alice", EncryptedPassword: "$6$alice"},
+				{Username: "bob", SSHKeys: []string{"ssh-rsa key"}},
+			},
+			Packages: Packages{RegCode: "scc-code"},
+			Suma:     Suma{Host: "suma.example.com", ActivationKey: "activation-key"},
+		},
+		Kubernetes: Kubernetes{
+			Helm: Helm{
+				Repositories: []HelmRepository{
+					{Name: "public", URL: "https://charts.example.com"},
+					{Name: "private", URL: "oci://registry.example.com", Authentication: HelmAuthentication{Username: "admin", Password: "helm-password"}},
+				},
+			},
+		},
+		NodeOverrides: []NodeOverride{
+			{Hostname: "node1.suse.com", Users: []OperatingSystemUser{{Username: "carol", EncryptedPassword: "$6$carol"}}},
+		},
+	}
+
+	assert.Equal(t, []string{"scc-code", "activation-key", "$6$alice", "$6$carol", "helm-password"}, definition.SensitiveValues())
+	assert.Empty(t, (&Definition{}).SensitiveValues())
+}
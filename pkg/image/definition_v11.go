@@ -0,0 +1,97 @@
+package image
+
+import (
+	"fmt"
+
+	"github.com/suse-edge/edge-image-builder/pkg/version"
+)
+
+// definitionV11 is the image definition as of schema versions 1.0 and 1.1.
+// Only the types which changed in later schema versions are redefined.
+type definitionV11 struct {
+	APIVersion               string                      `yaml:"apiVersion"`
+	Image                    Image                       `yaml:"image"`
+	OperatingSystem          operatingSystemV11          `yaml:"operatingSystem"`
+	EmbeddedArtifactRegistry embeddedArtifactRegistryV11 `yaml:"embeddedArtifactRegistry"`
+	Kubernetes               Kubernetes                  `yaml:"kubernetes"`
+}
+
+type operatingSystemV11 struct {
+	KernelArgs       []string               `yaml:"kernelArgs"`
+	Groups           []OperatingSystemGroup `yaml:"groups"`
+	Users            []OperatingSystemUser  `yaml:"users"`
+	Systemd          Systemd                `yaml:"systemd"`
+	Suma             Suma                   `yaml:"suma"`
+	Packages         packagesV11            `yaml:"packages"`
+	IsoConfiguration IsoConfiguration       `yaml:"isoConfiguration"`
+	RawConfiguration RawConfiguration       `yaml:"rawConfiguration"`
+	Time             Time                   `yaml:"time"`
+	Proxy            Proxy                  `yaml:"proxy"`
+	Keymap           string                 `yaml:"keymap"`
+	EnableFips       bool                   `yaml:"enableFIPS"`
+}
+
+// packagesV11 holds the registration code in the 'sccRegistrationCode' field, which is named 'registrationCode' as of 1.2.
+type packagesV11 struct {
+	NoGPGCheck      bool      `yaml:"noGPGCheck"`
+	PKGList         []string  `yaml:"packageList"`
+	AdditionalRepos []AddRepo `yaml:"additionalRepos"`
+	RegCode         string    `yaml:"sccRegistrationCode"`
+}
+
+// embeddedArtifactRegistryV11 lists container images as objects, which are plain names as of 1.2.
+type embeddedArtifactRegistryV11 struct {
+	ContainerImages []containerImageV11 `yaml:"images"`
+}
+
+type containerImageV11 struct {
+	Name string `yaml:"name"`
+}
+
+func (d *definitionV11) convert() *Definition {
+	os := d.OperatingSystem
+
+	definition := &Definition{
+		APIVersion: d.APIVersion,
+		Image:      d.Image,
+		OperatingSystem: OperatingSystem{
+			KernelArgs: os.KernelArgs,
+			Groups:     os.Groups,
+			Users:      os.Users,
+			Systemd:    os.Systemd,
+			Suma:       os.Suma,
+			Packages: Packages{
+				NoGPGCheck:      os.Packages.NoGPGCheck,
+				PKGList:         os.Packages.PKGList,
+				AdditionalRepos: os.Packages.AdditionalRepos,
+				RegCode:         os.Packages.RegCode,
+			},
+			IsoConfiguration: os.IsoConfiguration,
+			RawConfiguration: os.RawConfiguration,
+			Time:             os.Time,
+			Proxy:            os.Proxy,
+			Keymap:           os.Keymap,
+			EnableFips:       os.EnableFips,
+		},
+		Kubernetes: d.Kubernetes,
+	}
+
+	for _, img := range d.EmbeddedArtifactRegistry.ContainerImages {
+		definition.EmbeddedArtifactRegistry.ContainerImages = append(definition.EmbeddedArtifactRegistry.ContainerImages,
+			ContainerImage{Name: img.Name})
+	}
+
+	if os.Packages.RegCode != "" {
+		definition.deprecations = append(definition.deprecations, fmt.Sprintf(
+			"The 'operatingSystem.packages.sccRegistrationCode' field is renamed to "+
+				"'operatingSystem.packages.registrationCode' in schema version %s.", version.SchemaVersion12))
+	}
+
+	if len(d.EmbeddedArtifactRegistry.ContainerImages) != 0 {
+		definition.deprecations = append(definition.deprecations, fmt.Sprintf(
+			"The 'embeddedArtifactRegistry.images' entries are plain image names instead of objects with a 'name' field "+
+				"in schema version %s.", version.SchemaVersion12))
+	}
+
+	return definition
+}
@@ -0,0 +1,302 @@
+package overlay
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// IncludeKey lists the files a definition or overlay is based on.
+	IncludeKey = "include"
+
+	// ReplaceTag marks overlay values which replace the corresponding base value instead of being merged into it.
+	ReplaceTag = "!replace"
+)
+
+// mergeKeys identifies the entries of lists of objects. Overlay entries are merged into the base entry
+// with the same key, while lists without a merge key are appended to.
+var mergeKeys = map[string]string{
+	"image.outputs":                            "outputImageName",
+	"operatingSystem.groups":                   "name",
+	"operatingSystem.users":                    "username",
+	"operatingSystem.packages.additionalRepos": "url",
+	"embeddedArtifactRegistry.images":          "name",
+	"kubernetes.nodes":                         "hostname",
+	"kubernetes.helm.charts":                   "name",
+	"kubernetes.helm.repositories":             "name",
+}
+
+// Resolve combines the image definition with the files it includes and the given overlays, which are applied in order.
+// Includes are resolved relative to the including file, overlays relative to the directory of the definition.
+// The nodes of the combined definition retain their positions and are attributed to the file they were read from.
+func Resolve(definitionPath string, data []byte, overlayPaths []string) (*image.Document, error) {
+	l := &loader{files: map[*yaml.Node]string{}}
+
+	definition, err := l.parse(definitionPath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, overlayPath := range overlayPaths {
+		if !filepath.IsAbs(overlayPath) {
+			overlayPath = filepath.Join(filepath.Dir(definitionPath), overlayPath)
+		}
+
+		overlay, err := l.load(overlayPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading overlay: %w", err)
+		}
+
+		definition = merge(definition, overlay, "")
+	}
+
+	stripTags(definition)
+
+	return &image.Document{Root: definition, Files: l.files, Sources: l.sources}, nil
+}
+
+type loader struct {
+	// loading holds the chain of files currently being loaded in order to detect include cycles.
+	loading []string
+	// files maps the loaded nodes to the file they were read from.
+	files map[*yaml.Node]string
+	// sources lists the included and overlay files in the order they were loaded.
+	sources []string
+}
+
+func (l *loader) load(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading '%s': %w", path, err)
+	}
+
+	if !slices.Contains(l.sources, path) {
+		l.sources = append(l.sources, path)
+	}
+
+	return l.parse(path, data)
+}
+
+// parse decodes the file and merges its contents into the files it includes.
+func (l *loader) parse(path string, data []byte) (*yaml.Node, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolving path '%s': %w", path, err)
+	}
+
+	if slices.Contains(l.loading, absPath) {
+		return nil, fmt.Errorf("include cycle detected: %s -> %s", strings.Join(l.loading, " -> "), absPath)
+	}
+
+	l.loading = append(l.loading, absPath)
+	defer func() {
+		l.loading = l.loading[:len(l.loading)-1]
+	}()
+
+	var document yaml.Node
+	if err = yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("parsing '%s': %w", path, err)
+	}
+
+	// Empty files contribute nothing
+	if len(document.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("parsing '%s': the document must be a mapping", path)
+	}
+
+	l.record(root, path)
+
+	includes, err := removeIncludes(root)
+	if err != nil {
+		return nil, fmt.Errorf("parsing '%s': %w", path, err)
+	}
+
+	if len(includes) == 0 {
+		return root, nil
+	}
+
+	var combined *yaml.Node
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+
+		included, err := l.load(include)
+		if err != nil {
+			return nil, err
+		}
+
+		if combined == nil {
+			combined = included
+		} else {
+			combined = merge(combined, included, "")
+		}
+	}
+
+	return merge(combined, root, ""), nil
+}
+
+// record attributes the node and all of its descendants to the file.
+func (l *loader) record(node *yaml.Node, path string) {
+	l.files[node] = path
+
+	for _, child := range node.Content {
+		l.record(child, path)
+	}
+}
+
+// removeIncludes removes the include key from the mapping and returns the listed files.
+func removeIncludes(root *yaml.Node) ([]string, error) {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != IncludeKey {
+			continue
+		}
+
+		value := root.Content[i+1]
+		root.Content = slices.Delete(root.Content, i, i+2)
+
+		var includes []string
+
+		switch value.Kind {
+		case yaml.ScalarNode:
+			includes = append(includes, value.Value)
+		case yaml.SequenceNode:
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					return nil, errors.New("the 'include' list may only contain file paths")
+				}
+				includes = append(includes, item.Value)
+			}
+		default:
+			return nil, errors.New("the 'include' field must be a file path or a list of file paths")
+		}
+
+		return includes, nil
+	}
+
+	return nil, nil
+}
+
+// merge applies the overlay node onto the base node located at the given path and returns the result:
+//   - Mappings are merged key by key
+//   - Lists of objects with a merge key have overlay entries merged into the base entries with the same key,
+//     other overlay entries are appended
+//   - Lists of values are appended to, skipping values already present
+//   - Any other value, as well as values tagged with !replace, replace the base value
+func merge(base, overlay *yaml.Node, path string) *yaml.Node {
+	if overlay.Tag == ReplaceTag {
+		return overlay
+	}
+
+	switch {
+	case base.Kind == yaml.MappingNode && overlay.Kind == yaml.MappingNode:
+		mergeMappings(base, overlay, path)
+		return base
+	case base.Kind == yaml.SequenceNode && overlay.Kind == yaml.SequenceNode:
+		mergeSequences(base, overlay, path)
+		return base
+	default:
+		return overlay
+	}
+}
+
+func mergeMappings(base, overlay *yaml.Node, path string) {
+	for i := 0; i+1 < len(overlay.Content); i += 2 {
+		key, value := overlay.Content[i], overlay.Content[i+1]
+
+		if index := mappingIndex(base, key.Value); index >= 0 {
+			base.Content[index+1] = merge(base.Content[index+1], value, joinPath(path, key.Value))
+			continue
+		}
+
+		base.Content = append(base.Content, key, value)
+	}
+}
+
+func mergeSequences(base, overlay *yaml.Node, path string) {
+	mergeKey := mergeKeys[path]
+
+	for _, item := range overlay.Content {
+		switch {
+		case item.Kind == yaml.MappingNode && mergeKey != "":
+			if index := entryIndex(base, mergeKey, mappingValue(item, mergeKey)); index >= 0 {
+				base.Content[index] = merge(base.Content[index], item, path)
+				continue
+			}
+		case item.Kind == yaml.ScalarNode:
+			if slices.ContainsFunc(base.Content, func(n *yaml.Node) bool {
+				return n.Kind == yaml.ScalarNode && n.Value == item.Value
+			}) {
+				continue
+			}
+		}
+
+		base.Content = append(base.Content, item)
+	}
+}
+
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if index := mappingIndex(mapping, key); index >= 0 {
+		return mapping.Content[index+1]
+	}
+
+	return nil
+}
+
+func entryIndex(sequence *yaml.Node, key string, value *yaml.Node) int {
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return -1
+	}
+
+	for i, entry := range sequence.Content {
+		if entry.Kind != yaml.MappingNode {
+			continue
+		}
+
+		if v := mappingValue(entry, key); v != nil && v.Kind == yaml.ScalarNode && v.Value == value.Value {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// stripTags removes the merge tags, which are not understood by the definition parser.
+func stripTags(node *yaml.Node) {
+	if node.Tag == ReplaceTag {
+		node.Tag = ""
+	}
+
+	for _, child := range node.Content {
+		stripTags(child)
+	}
+}
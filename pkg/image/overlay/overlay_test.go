@@ -0,0 +1,281 @@
+package overlay
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+)
+
+const baseDefinition = `apiVersion: 1.1
+image:
+  imageType: iso
+  arch: x86_64
+  baseImage: base.iso
+  outputImageName: eib-image.iso
+operatingSystem:
+  kernelArgs:
+    - quiet
+  users:
+    - username: root
+      encryptedPassword: $6$root
+  packages:
+    packageList:
+      - vim
+kubernetes:
+  version: v1.30.3+rke2r1
+  network:
+    apiVIP: 192.168.122.100
+  nodes:
+    - hostname: node1.suse.com
+      type: server
+    - hostname: node2.suse.com
+      type: server
+`
+
+func writeFile(t *testing.T, dir, name, contents string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+
+	return path
+}
+
+func resolve(t *testing.T, definitionPath string, overlays ...string) *image.Definition {
+	data, err := os.ReadFile(definitionPath)
+	require.NoError(t, err)
+
+	resolved, err := Resolve(definitionPath, data, overlays)
+	require.NoError(t, err)
+
+	definition, err := image.ParseDocument(resolved)
+	require.NoError(t, err)
+
+	return definition
+}
+
+func TestResolve_NoOverlays(t *testing.T) {
+	definitionPath := writeFile(t, t.TempDir(), "definition.yaml", baseDefinition)
+
+	resolved, err := Resolve(definitionPath, []byte(baseDefinition), nil)
+	require.NoError(t, err)
+
+	definition, err := image.ParseDocument(resolved)
+	require.NoError(t, err)
+
+	expected, err := image.ParseDefinition([]byte(baseDefinition))
+	require.NoError(t, err)
+
+	assert.Equal(t, expected.Image, definition.Image)
+	assert.Equal(t, expected.OperatingSystem, definition.OperatingSystem)
+	assert.Equal(t, expected.Kubernetes, definition.Kubernetes)
+	assert.Equal(t, image.Position{File: definitionPath, Line: 23, Column: 17}, definition.Locate("kubernetes.nodes[1].hostname"))
+	assert.Empty(t, definition.Sources())
+}
+
+func TestResolve_Overlays(t *testing.T) {
+	dir := t.TempDir()
+
+	definitionPath := writeFile(t, dir, "definition.yaml", baseDefinition)
+	writeFile(t, dir, "sites/site-a.yaml", `
+image:
+  outputImageName: site-a.iso
+operatingSystem:
+  kernelArgs:
+    - quiet
+    - console=ttyS0
+  users:
+    - username: root
+      sshKeys:
+        - ssh-ed25519 key
+    - username: operator
+  packages:
+    packageList:
+      - htop
+kubernetes:
+  network:
+    apiVIP: 10.0.0.100
+  nodes:
+    - hostname: node2.suse.com
+      type: agent
+    - hostname: node3.suse.com
+      type: agent
+`)
+	writeFile(t, dir, "sites/small.yaml", `
+kubernetes:
+  nodes: !replace
+    - hostname: single.suse.com
+      type: server
+`)
+
+	definition := resolve(t, definitionPath, "sites/site-a.yaml")
+
+	assert.Equal(t, "site-a.iso", definition.Image.OutputImageName)
+	assert.Equal(t, "base.iso", definition.Image.BaseImage)
+	assert.Equal(t, []string{"quiet", "console=ttyS0"}, definition.OperatingSystem.KernelArgs)
+	assert.Equal(t, []image.OperatingSystemUser{
+		{Username: "root", EncryptedPassword: "$6$root", SSHKeys: []string{"ssh-ed25519 key"}},
+		{Username: "operator"},
+	}, definition.OperatingSystem.Users)
+	assert.Equal(t, []string{"vim", "htop"}, definition.OperatingSystem.Packages.PKGList)
+	assert.Equal(t, "10.0.0.100", definition.Kubernetes.Network.APIVIP)
+	assert.Equal(t, "v1.30.3+rke2r1", definition.Kubernetes.Version)
+	assert.Equal(t, []image.Node{
+		{Hostname: "node1.suse.com", Type: "server"},
+		{Hostname: "node2.suse.com", Type: "agent"},
+		{Hostname: "node3.suse.com", Type: "agent"},
+	}, definition.Kubernetes.Nodes)
+
+	// Overlays are applied in order
+	definition = resolve(t, definitionPath, "sites/site-a.yaml", "sites/small.yaml")
+
+	assert.Equal(t, "site-a.iso", definition.Image.OutputImageName)
+	assert.Equal(t, []image.Node{{Hostname: "single.suse.com", Type: "server"}}, definition.Kubernetes.Nodes)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "sites/site-a.yaml"),
+		filepath.Join(dir, "sites/small.yaml"),
+	}, definition.Sources())
+}
+
+func TestResolve_Includes(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, dir, "common/os.yaml", `
+operatingSystem:
+  kernelArgs:
+    - quiet
+  time:
+    timezone: Europe/London
+`)
+	writeFile(t, dir, "common/base.yaml", `
+include: os.yaml
+apiVersion: 1.1
+image:
+  imageType: raw
+  arch: x86_64
+  baseImage: base.raw
+  outputImageName: eib-image.raw
+`)
+	definitionPath := writeFile(t, dir, "definition.yaml", `
+include:
+  - common/base.yaml
+operatingSystem:
+  time:
+    timezone: Europe/Berlin
+`)
+
+	definition := resolve(t, definitionPath)
+
+	assert.Equal(t, "1.1", definition.APIVersion)
+	assert.Equal(t, "eib-image.raw", definition.Image.OutputImageName)
+	assert.Equal(t, []string{"quiet"}, definition.OperatingSystem.KernelArgs)
+	assert.Equal(t, "Europe/Berlin", definition.OperatingSystem.Time.Timezone)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "common/base.yaml"),
+		filepath.Join(dir, "common/os.yaml"),
+	}, definition.Sources())
+}
+
+func TestResolve_Positions(t *testing.T) {
+	dir := t.TempDir()
+
+	definitionPath := writeFile(t, dir, "definition.yaml", baseDefinition)
+	overlayPath := writeFile(t, dir, "sites/site-a.yaml", `
+image:
+  outputImageName: site-a.iso
+operatingSystem:
+  users:
+    - username: root
+      sshKeys:
+        - ssh-ed25519 key
+    - username: operator
+`)
+
+	definition := resolve(t, definitionPath, "sites/site-a.yaml")
+
+	tests := map[string]struct {
+		path     string
+		expected image.Position
+	}{
+		"value of the definition": {
+			path:     "kubernetes.nodes[0].hostname",
+			expected: image.Position{File: definitionPath, Line: 21, Column: 17},
+		},
+		"value replaced by the overlay": {
+			path:     "image.outputImageName",
+			expected: image.Position{File: overlayPath, Line: 3, Column: 20},
+		},
+		"entry merged with the overlay": {
+			path:     "operatingSystem.users[0].encryptedPassword",
+			expected: image.Position{File: definitionPath, Line: 12, Column: 26},
+		},
+		"entry added by the overlay": {
+			path:     "operatingSystem.users[1]",
+			expected: image.Position{File: overlayPath, Line: 9, Column: 7},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, definition.Locate(test.path))
+		})
+	}
+}
+
+func TestResolve_Errors(t *testing.T) {
+	tests := map[string]struct {
+		files       map[string]string
+		overlays    []string
+		expectedErr string
+	}{
+		"missing overlay": {
+			overlays:    []string{"missing.yaml"},
+			expectedErr: "loading overlay: reading",
+		},
+		"missing include": {
+			files:       map[string]string{"definition.yaml": "include: missing.yaml\n"},
+			expectedErr: "missing.yaml",
+		},
+		"include cycle": {
+			files: map[string]string{
+				"definition.yaml": "include: a.yaml\n",
+				"a.yaml":          "include: b.yaml\n",
+				"b.yaml":          "include: a.yaml\n",
+			},
+			expectedErr: "include cycle detected",
+		},
+		"invalid include": {
+			files:       map[string]string{"definition.yaml": "include:\n  file: a.yaml\n"},
+			expectedErr: "the 'include' field must be a file path or a list of file paths",
+		},
+		"invalid overlay": {
+			files:       map[string]string{"overlay.yaml": "- a\n- b\n"},
+			overlays:    []string{"overlay.yaml"},
+			expectedErr: "the document must be a mapping",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			files := map[string]string{"definition.yaml": baseDefinition}
+			for file, contents := range test.files {
+				files[file] = contents
+			}
+
+			for file, contents := range files {
+				writeFile(t, dir, file, contents)
+			}
+
+			definitionPath := filepath.Join(dir, "definition.yaml")
+
+			_, err := Resolve(definitionPath, []byte(files["definition.yaml"]), test.overlays)
+			require.Error(t, err)
+			assert.ErrorContains(t, err, test.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package image
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is the parsed image definition. Definitions combined with their includes and overlays
+// consist of nodes read from several files, each of which retains its position within its own file.
+type Document struct {
+	// Root is the mapping node holding the definition.
+	Root *yaml.Node
+	// Files maps the nodes to the path of the file they were read from.
+	Files map[*yaml.Node]string
+	// Sources lists the paths of the files the definition was combined with, i.e. its includes and overlays.
+	Sources []string
+}
+
+// Position locates a value in the image definition file. The zero value denotes an unknown position.
+type Position struct {
+	// File is the path of the file the value was read from, if known.
+	File   string
+	Line   int
+	Column int
+}
+
+func (p Position) IsKnown() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if p.File == "" {
+		return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+	}
+
+	return fmt.Sprintf("%s, line %d, column %d", p.File, p.Line, p.Column)
+}
+
+// Locate returns the position of the value at the given path of the parsed definition,
+// e.g. 'operatingSystem.users[1].username'. If the value is not present in the file, such as
+// a missing required field, the position of its closest present parent is returned instead.
+// Values merged from includes and overlays are located in the file they were read from.
+func (d *Definition) Locate(path string) Position {
+	if d.document == nil || d.document.Root == nil {
+		return Position{}
+	}
+
+	node := d.document.Root
+	position := d.document.position(node)
+
+	for _, segment := range splitPath(path) {
+		if node = childNode(node, segment); node == nil {
+			break
+		}
+
+		position = d.document.position(node)
+	}
+
+	return position
+}
+
+func (d *Document) position(node *yaml.Node) Position {
+	return Position{File: d.Files[node], Line: node.Line, Column: node.Column}
+}
+
+// splitPath separates the path into mapping keys and list indices, e.g. 'users[1].name' into 'users', '[1]', 'name'.
+func splitPath(path string) []string {
+	var segments []string
+
+	for _, key := range strings.Split(path, ".") {
+		name, indices, _ := strings.Cut(key, "[")
+		if name != "" {
+			segments = append(segments, name)
+		}
+
+		if indices != "" {
+			for _, index := range strings.Split(strings.TrimSuffix(indices, "]"), "][") {
+				segments = append(segments, "["+index+"]")
+			}
+		}
+	}
+
+	return segments
+}
+
+func childNode(node *yaml.Node, segment string) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if index, ok := strings.CutPrefix(segment, "["); ok {
+		i, err := strconv.Atoi(strings.TrimSuffix(index, "]"))
+		if err != nil || node.Kind != yaml.SequenceNode || i < 0 || i >= len(node.Content) {
+			return nil
+		}
+
+		return node.Content[i]
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == segment {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
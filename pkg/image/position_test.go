@@ -0,0 +1,82 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefinition_Locate(t *testing.T) {
+	config := `apiVersion: 1.2
+image:
+  imageType: iso
+operatingSystem:
+  users:
+    - username: alice
+    - username: bob
+      sshKeys:
+        - ssh-ed25519 key
+  kernelArgs:
+    - quiet
+`
+
+	definition, err := ParseDefinition([]byte(config))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		path     string
+		expected Position
+	}{
+		"root": {
+			path:     "",
+			expected: Position{Line: 1, Column: 1},
+		},
+		"mapping value": {
+			path:     "image.imageType",
+			expected: Position{Line: 3, Column: 14},
+		},
+		"list entry": {
+			path:     "operatingSystem.users[1]",
+			expected: Position{Line: 7, Column: 7},
+		},
+		"nested list entry": {
+			path:     "operatingSystem.users[1].sshKeys[0]",
+			expected: Position{Line: 9, Column: 11},
+		},
+		"scalar list entry": {
+			path:     "operatingSystem.kernelArgs[0]",
+			expected: Position{Line: 11, Column: 7},
+		},
+		"missing field": {
+			path:     "operatingSystem.users[0].encryptedPassword",
+			expected: Position{Line: 6, Column: 7},
+		},
+		"index out of range": {
+			path:     "operatingSystem.users[5].username",
+			expected: Position{Line: 6, Column: 5},
+		},
+		"missing section": {
+			path:     "kubernetes.nodes[0]",
+			expected: Position{Line: 1, Column: 1},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.expected, definition.Locate(test.path))
+		})
+	}
+}
+
+func TestDefinition_LocateUnparsed(t *testing.T) {
+	definition := Definition{}
+
+	position := definition.Locate("image.imageType")
+
+	assert.False(t, position.IsKnown())
+}
+
+func TestPosition_String(t *testing.T) {
+	assert.Equal(t, "line 7, column 7", Position{Line: 7, Column: 7}.String())
+}
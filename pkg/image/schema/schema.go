@@ -0,0 +1,181 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/image/overlay"
+)
+
+const (
+	draft = "https://json-schema.org/draft/2020-12/schema"
+
+	typeObject  = "object"
+	typeArray   = "array"
+	typeString  = "string"
+	typeInteger = "integer"
+	typeBoolean = "boolean"
+)
+
+// Schema is the subset of JSON Schema used to describe the image definition.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+
+	// values lists the values matched by the pattern, used to describe it in validation messages.
+	values []string
+}
+
+// typeSchemas describes the types which are not decoded field by field.
+var typeSchemas = map[reflect.Type]func() *Schema{
+	reflect.TypeOf(image.Arch("")): func() *Schema {
+		return &Schema{Type: typeString, Enum: []any{string(image.ArchTypeX86), string(image.ArchTypeARM)}}
+	},
+	reflect.TypeOf(image.ContainerImage{}): func() *Schema {
+		return &Schema{Type: typeString}
+	},
+}
+
+// fieldEnums lists the allowed values of plain string fields, identified by their path in the definition.
+var fieldEnums = map[string][]string{
+	"kubernetes.nodes.type": {image.KubernetesNodeTypeServer, image.KubernetesNodeTypeAgent},
+}
+
+// caseInsensitiveFields lists the allowed values of plain string fields which are accepted in any case,
+// such as the image types which are lowercased when the definition is parsed.
+var caseInsensitiveFields = map[string][]string{
+	"image.imageType":         imageTypes,
+	"image.outputs.imageType": imageTypes,
+}
+
+var imageTypes = []string{image.TypeISO, image.TypeRAW, image.TypeQCOW2, image.TypeVMDK, image.TypeVHDX}
+
+// caseInsensitivePattern matches any of the values in any case. Inline flags such as '(?i)' are not
+// supported by the regular expressions of JSON Schema, letters are matched by character classes instead.
+func caseInsensitivePattern(values []string) string {
+	alternatives := make([]string, 0, len(values))
+
+	for _, value := range values {
+		var b strings.Builder
+		for _, r := range value {
+			if unicode.IsLetter(r) {
+				fmt.Fprintf(&b, "[%c%c]", unicode.ToLower(r), unicode.ToUpper(r))
+			} else {
+				b.WriteString(regexp.QuoteMeta(string(r)))
+			}
+		}
+
+		alternatives = append(alternatives, b.String())
+	}
+
+	return "^(" + strings.Join(alternatives, "|") + ")$"
+}
+
+// Generate describes the image definition of the given schema version.
+func Generate(apiVersion string) (*Schema, error) {
+	model, err := image.DefinitionModel(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	s := generate(reflect.TypeOf(model).Elem(), "")
+	s.Schema = draft
+	s.Title = fmt.Sprintf("Edge Image Builder image definition (schema version %s)", apiVersion)
+
+	// The version is commonly written as a number, e.g. 'apiVersion: 1.1'
+	s.Properties["apiVersion"] = &Schema{Enum: []any{apiVersion, json.Number(apiVersion)}}
+
+	s.Properties[overlay.IncludeKey] = &Schema{
+		AnyOf: []*Schema{
+			{Type: typeString},
+			{Type: typeArray, Items: &Schema{Type: typeString}},
+		},
+	}
+
+	return s, nil
+}
+
+// Export encodes the schema of the image definition of the given schema version as JSON.
+func Export(apiVersion string) ([]byte, error) {
+	s, err := Generate(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encoding schema: %w", err)
+	}
+
+	return append(data, '\n'), nil
+}
+
+func generate(t reflect.Type, path string) *Schema {
+	if typeSchema, ok := typeSchemas[t]; ok {
+		return typeSchema()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		closed := false
+		s := &Schema{
+			Type:                 typeObject,
+			Properties:           map[string]*Schema{},
+			AdditionalProperties: &closed,
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if name == "" || name == "-" {
+				continue
+			}
+
+			s.Properties[name] = generate(field.Type, joinPath(path, name))
+		}
+
+		return s
+	case reflect.Slice:
+		return &Schema{Type: typeArray, Items: generate(t.Elem(), path)}
+	case reflect.Bool:
+		return &Schema{Type: typeBoolean}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: typeInteger}
+	default:
+		s := &Schema{Type: typeString}
+		if values, ok := caseInsensitiveFields[path]; ok {
+			s.Pattern = caseInsensitivePattern(values)
+			s.values = values
+		}
+
+		for _, value := range fieldEnums[path] {
+			s.Enum = append(s.Enum, value)
+		}
+
+		return s
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+)
+
+func TestGenerate(t *testing.T) {
+	s, err := Generate("1.2")
+	require.NoError(t, err)
+
+	assert.Equal(t, draft, s.Schema)
+	assert.Equal(t, typeObject, s.Type)
+	require.NotNil(t, s.AdditionalProperties)
+	assert.False(t, *s.AdditionalProperties)
+	assert.Equal(t, []any{"1.2", json.Number("1.2")}, s.Properties["apiVersion"].Enum)
+	assert.Contains(t, s.Properties, "include")
+
+	img := s.Properties["image"]
+	assert.Equal(t, []any{"x86_64", "aarch64"}, img.Properties["arch"].Enum)
+	assert.Empty(t, img.Properties["imageType"].Enum)
+	assert.Equal(t, "^([iI][sS][oO]|[rR][aA][wW]|[qQ][cC][oO][wW]2|[vV][mM][dD][kK]|[vV][hH][dD][xX])$",
+		img.Properties["imageType"].Pattern)
+	assert.Equal(t, img.Properties["imageType"].Pattern, img.Properties["outputs"].Items.Properties["imageType"].Pattern)
+
+	users := s.Properties["operatingSystem"].Properties["users"]
+	assert.Equal(t, typeArray, users.Type)
+	assert.Equal(t, typeInteger, users.Items.Properties["uid"].Type)
+	assert.Equal(t, typeBoolean, users.Items.Properties["createHomeDir"].Type)
+
+	packages := s.Properties["operatingSystem"].Properties["packages"]
+	assert.Contains(t, packages.Properties, "registrationCode")
+	assert.NotContains(t, packages.Properties, "sccRegistrationCode")
+
+	assert.Equal(t, &Schema{Type: typeString}, s.Properties["embeddedArtifactRegistry"].Properties["images"].Items)
+
+	nodes := s.Properties["kubernetes"].Properties["nodes"]
+	assert.Equal(t, []any{image.KubernetesNodeTypeServer, image.KubernetesNodeTypeAgent}, nodes.Items.Properties["type"].Enum)
+}
+
+func TestGenerate_PreviousVersion(t *testing.T) {
+	s, err := Generate("1.1")
+	require.NoError(t, err)
+
+	packages := s.Properties["operatingSystem"].Properties["packages"]
+	assert.Contains(t, packages.Properties, "sccRegistrationCode")
+	assert.NotContains(t, packages.Properties, "registrationCode")
+
+	images := s.Properties["embeddedArtifactRegistry"].Properties["images"]
+	assert.Equal(t, typeObject, images.Items.Type)
+	assert.Contains(t, images.Items.Properties, "name")
+}
+
+func TestGenerate_UnsupportedVersion(t *testing.T) {
+	_, err := Generate("0.9")
+	require.ErrorIs(t, err, image.ErrorInvalidSchemaVersion)
+}
+
+func TestExport(t *testing.T) {
+	data, err := Export("1.1")
+	require.NoError(t, err)
+
+	var exported map[string]any
+	require.NoError(t, json.Unmarshal(data, &exported))
+
+	assert.Equal(t, draft, exported["$schema"])
+	assert.Equal(t, "Edge Image Builder image definition (schema version 1.1)", exported["title"])
+	assert.Equal(t, []any{"1.1", 1.1}, exported["properties"].(map[string]any)["apiVersion"].(map[string]any)["enum"])
+}
@@ -0,0 +1,193 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/version"
+	"gopkg.in/yaml.v3"
+)
+
+// Violation describes a value of the image definition which does not conform to the schema.
+type Violation struct {
+	// Path locates the value within the definition, e.g. 'operatingSystem.users[1].uid'.
+	Path string
+	// File is the path of the file the value was read from, if known.
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (v Violation) String() string {
+	if v.File == "" {
+		return fmt.Sprintf("%s (line %d, column %d): %s", v.Path, v.Line, v.Column, v.Message)
+	}
+
+	return fmt.Sprintf("%s (%s, line %d, column %d): %s", v.Path, v.File, v.Line, v.Column, v.Message)
+}
+
+// Validate checks the image definition against the schema of its schema version.
+// Definitions of unsupported schema versions are rejected when they are parsed and not checked here.
+func Validate(document *image.Document) ([]Violation, error) {
+	var header struct {
+		APIVersion string `yaml:"apiVersion"`
+	}
+
+	if err := document.Root.Decode(&header); err != nil {
+		return nil, fmt.Errorf("could not parse the image definition: %w", err)
+	}
+
+	if !version.IsSchemaVersionSupported(header.APIVersion) {
+		return nil, nil
+	}
+
+	s, err := Generate(header.APIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{files: document.Files}
+	s.validate(document.Root, "", v)
+
+	return v.violations, nil
+}
+
+// validator collects the violations of the validated definition.
+type validator struct {
+	files      map[*yaml.Node]string
+	violations []Violation
+}
+
+func (v *validator) report(node *yaml.Node, path, message string) {
+	v.violations = append(v.violations, Violation{
+		Path:    path,
+		File:    v.files[node],
+		Line:    node.Line,
+		Column:  node.Column,
+		Message: message,
+	})
+}
+
+func (s *Schema) validate(node *yaml.Node, path string, v *validator) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) != 0 {
+			s.validate(node.Content[0], path, v)
+		}
+		return
+	case yaml.AliasNode:
+		s.validate(node.Alias, path, v)
+		return
+	}
+
+	// Empty values are equivalent to omitting the field
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return
+	}
+
+	if len(s.AnyOf) != 0 {
+		for _, alternative := range s.AnyOf {
+			alternativeValidator := &validator{files: v.files}
+			if alternative.validate(node, path, alternativeValidator); len(alternativeValidator.violations) == 0 {
+				return
+			}
+		}
+
+		v.report(node, path, "must be "+describeAlternatives(s.AnyOf))
+		return
+	}
+
+	switch s.Type {
+	case typeObject:
+		if node.Kind != yaml.MappingNode {
+			v.report(node, path, "must be a mapping")
+			return
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			keyPath := joinPath(path, key.Value)
+
+			property, ok := s.Properties[key.Value]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					v.report(key, keyPath, "unknown field")
+				}
+				continue
+			}
+
+			property.validate(value, keyPath, v)
+		}
+	case typeArray:
+		if node.Kind != yaml.SequenceNode {
+			v.report(node, path, "must be a list")
+			return
+		}
+
+		for i, item := range node.Content {
+			s.Items.validate(item, path+"["+strconv.Itoa(i)+"]", v)
+		}
+	case typeString:
+		if node.Kind != yaml.ScalarNode {
+			v.report(node, path, "must be a string")
+			return
+		}
+	case typeInteger:
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!int" {
+			v.report(node, path, "must be an integer")
+			return
+		}
+	case typeBoolean:
+		if node.Kind != yaml.ScalarNode || node.ShortTag() != "!!bool" {
+			v.report(node, path, "must be true or false")
+			return
+		}
+	}
+
+	if len(s.Enum) != 0 && !slices.ContainsFunc(s.Enum, func(value any) bool {
+		return node.Kind == yaml.ScalarNode && fmt.Sprint(value) == node.Value
+	}) {
+		v.report(node, path, "must be one of: "+strings.Join(enumValues(s.Enum), ", "))
+	}
+
+	if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(node.Value) {
+		if len(s.values) != 0 {
+			v.report(node, path, "must be one of: "+strings.Join(s.values, ", "))
+		} else {
+			v.report(node, path, "must match the pattern "+s.Pattern)
+		}
+	}
+}
+
+// enumValues lists the distinct allowed values, ignoring the different types of the same value.
+func enumValues(enum []any) []string {
+	var values []string
+	for _, value := range enum {
+		if v := fmt.Sprint(value); !slices.Contains(values, v) {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+func describeAlternatives(alternatives []*Schema) string {
+	descriptions := make([]string, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		switch alternative.Type {
+		case typeArray:
+			descriptions = append(descriptions, "a list")
+		case typeObject:
+			descriptions = append(descriptions, "a mapping")
+		default:
+			descriptions = append(descriptions, "a "+alternative.Type)
+		}
+	}
+
+	return strings.Join(descriptions, " or ")
+}
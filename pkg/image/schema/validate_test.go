@@ -0,0 +1,125 @@
+package schema
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"gopkg.in/yaml.v3"
+)
+
+func parseDocument(t *testing.T, data []byte) *image.Document {
+	var document yaml.Node
+	require.NoError(t, yaml.Unmarshal(data, &document))
+
+	return &image.Document{Root: document.Content[0]}
+}
+
+func TestValidate(t *testing.T) {
+	tests := map[string]struct {
+		definition         string
+		expectedViolations []Violation
+	}{
+		"valid": {
+			definition: `apiVersion: 1.2
+include: base.yaml
+image:
+  imageType: RAW
+  arch: x86_64
+  outputs:
+    - imageType: Qcow2
+operatingSystem:
+  users:
+    - username: alice
+      uid: 2000
+      createHomeDir: true
+  time:
+embeddedArtifactRegistry:
+  images:
+    - hello-world:latest
+`,
+		},
+		"unsupported version": {
+			definition: "apiVersion: 0.9\nunknown: field\n",
+		},
+		"invalid values": {
+			definition: `apiVersion: 1.2
+image:
+  imageType: tar
+  arch: x86
+  outputs: iso
+operatingSystem:
+  users:
+    - username: alice
+    - username: bob
+      uid: two
+      createHomeDir: yes
+  kernelArgs: quiet
+  packages:
+    sccRegistrationCode: abc
+kubernetes:
+  nodes:
+    - hostname: node1
+      type: controller
+`,
+			expectedViolations: []Violation{
+				{Path: "image.imageType", Line: 3, Column: 14, Message: "must be one of: iso, raw, qcow2, vmdk, vhdx"},
+				{Path: "image.arch", Line: 4, Column: 9, Message: "must be one of: x86_64, aarch64"},
+				{Path: "image.outputs", Line: 5, Column: 12, Message: "must be a list"},
+				{Path: "operatingSystem.users[1].uid", Line: 10, Column: 12, Message: "must be an integer"},
+				{Path: "operatingSystem.users[1].createHomeDir", Line: 11, Column: 22, Message: "must be true or false"},
+				{Path: "operatingSystem.kernelArgs", Line: 12, Column: 15, Message: "must be a list"},
+				{Path: "operatingSystem.packages.sccRegistrationCode", Line: 14, Column: 5, Message: "unknown field"},
+				{Path: "kubernetes.nodes[0].type", Line: 18, Column: 13, Message: "must be one of: server, agent"},
+			},
+		},
+		"invalid include": {
+			definition: "apiVersion: 1.1\ninclude:\n  file: base.yaml\n",
+			expectedViolations: []Violation{
+				{Path: "include", Line: 3, Column: 3, Message: "must be a string or a list"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			violations, err := Validate(parseDocument(t, []byte(test.definition)))
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedViolations, violations)
+		})
+	}
+}
+
+func TestValidate_FullExample(t *testing.T) {
+	data, err := os.ReadFile("../testdata/full-valid-example.yaml")
+	require.NoError(t, err)
+
+	violations, err := Validate(parseDocument(t, data))
+	require.NoError(t, err)
+
+	assert.Empty(t, violations)
+}
+
+func TestValidate_Files(t *testing.T) {
+	document := parseDocument(t, []byte("apiVersion: 1.2\noperatingSystem:\n  users:\n    - username: alice\n      uid: alice\n"))
+
+	uid := document.Root.Content[3].Content[1].Content[0].Content[3]
+	document.Files = map[*yaml.Node]string{uid: "sites/site-a.yaml"}
+
+	violations, err := Validate(document)
+	require.NoError(t, err)
+
+	expected := Violation{Path: "operatingSystem.users[0].uid", File: "sites/site-a.yaml", Line: 5, Column: 12, Message: "must be an integer"}
+	assert.Equal(t, []Violation{expected}, violations)
+	assert.Equal(t, "operatingSystem.users[0].uid (sites/site-a.yaml, line 5, column 12): must be an integer", expected.String())
+}
+
+func TestValidate_InvalidDefinition(t *testing.T) {
+	_, err := Validate(parseDocument(t, []byte("- apiVersion: 1.2")))
+
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "could not parse the image definition")
+}
@@ -0,0 +1,158 @@
+package image
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/suse-edge/edge-image-builder/pkg/version"
+	"gopkg.in/yaml.v3"
+)
+
+// migration rewrites a definition of the given schema version to the next one.
+type migration struct {
+	from    string
+	to      string
+	migrate func(root *yaml.Node) []string
+}
+
+var migrations = []migration{
+	{
+		from: version.SchemaVersion10,
+		to:   version.SchemaVersion11,
+		// 1.1 only introduced new fields
+		migrate: func(*yaml.Node) []string { return nil },
+	},
+	{
+		from:    version.SchemaVersion11,
+		to:      version.SchemaVersion12,
+		migrate: migrateV11ToV12,
+	},
+}
+
+// UpgradeDefinition rewrites the image definition to the latest schema version and lists the changes made.
+// Comments and the order of the fields are retained. Definitions already at the latest version are returned unmodified.
+func UpgradeDefinition(data []byte) ([]byte, []string, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, nil, fmt.Errorf("could not parse the image definition: %w", err)
+	}
+
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, errors.New("could not parse the image definition: the document must be a mapping")
+	}
+
+	root := document.Content[0]
+
+	apiVersion := lookupNode(root, "apiVersion")
+	if apiVersion == nil || !version.IsSchemaVersionSupported(apiVersion.Value) {
+		return nil, nil, ErrorInvalidSchemaVersion
+	}
+
+	if apiVersion.Value == version.LatestSchemaVersion {
+		return data, nil, nil
+	}
+
+	var changes []string
+
+	start := slices.IndexFunc(migrations, func(m migration) bool {
+		return m.from == apiVersion.Value
+	})
+
+	for _, m := range migrations[start:] {
+		changes = append(changes, m.migrate(root)...)
+	}
+
+	changes = append(changes, fmt.Sprintf("Updated 'apiVersion' from %s to %s", apiVersion.Value, version.LatestSchemaVersion))
+	apiVersion.Value = version.LatestSchemaVersion
+
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&document); err != nil {
+		return nil, nil, fmt.Errorf("encoding upgraded definition: %w", err)
+	}
+
+	return buf.Bytes(), changes, nil
+}
+
+func migrateV11ToV12(root *yaml.Node) []string {
+	var changes []string
+
+	if packages := lookupNode(root, "operatingSystem", "packages"); packages != nil {
+		for i := 0; i+1 < len(packages.Content); i += 2 {
+			if key := packages.Content[i]; key.Value == "sccRegistrationCode" {
+				key.Value = "registrationCode"
+				changes = append(changes, "Renamed 'operatingSystem.packages.sccRegistrationCode' to 'operatingSystem.packages.registrationCode'")
+			}
+		}
+	}
+
+	if images := lookupNode(root, "embeddedArtifactRegistry", "images"); images != nil && images.Kind == yaml.SequenceNode {
+		converted := false
+
+		for i, item := range images.Content {
+			if item.Kind != yaml.MappingNode {
+				continue
+			}
+
+			name := lookupNode(item, "name")
+			if name == nil {
+				continue
+			}
+
+			// Keep any comments attached to the entry
+			name.HeadComment = joinComments(item.HeadComment, name.HeadComment)
+			name.LineComment = joinComments(item.LineComment, name.LineComment)
+			name.FootComment = joinComments(item.FootComment, name.FootComment)
+
+			images.Content[i] = name
+			converted = true
+		}
+
+		if converted {
+			changes = append(changes, "Converted the 'embeddedArtifactRegistry.images' entries to plain image names")
+		}
+	}
+
+	return changes
+}
+
+// lookupNode returns the value at the given path of mapping keys, or nil if it is not present.
+func lookupNode(node *yaml.Node, path ...string) *yaml.Node {
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		var value *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				value = node.Content[i+1]
+				break
+			}
+		}
+
+		if value == nil {
+			return nil
+		}
+
+		node = value
+	}
+
+	return node
+}
+
+func joinComments(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	default:
+		return a + "\n" + b
+	}
+}
@@ -0,0 +1,109 @@
+package image
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpgradeDefinition(t *testing.T) {
+	definition := `# Edge site definition
+apiVersion: 1.0
+image:
+  imageType: iso
+  arch: x86_64
+  baseImage: base.iso
+  outputImageName: eib-image.iso
+operatingSystem:
+  packages:
+    packageList:
+      - vim
+    # Provided by the customer
+    sccRegistrationCode: INTERNAL-USE-ONLY-foo-bar
+embeddedArtifactRegistry:
+  images:
+    # Used by the smoke tests
+    - name: hello-world:latest
+    - name: nginx:1.27 # pinned
+`
+
+	expected := `# Edge site definition
+apiVersion: 1.2
+image:
+  imageType: iso
+  arch: x86_64
+  baseImage: base.iso
+  outputImageName: eib-image.iso
+operatingSystem:
+  packages:
+    packageList:
+      - vim
+    # Provided by the customer
+    registrationCode: INTERNAL-USE-ONLY-foo-bar
+embeddedArtifactRegistry:
+  images:
+    # Used by the smoke tests
+    - hello-world:latest
+    - nginx:1.27 # pinned
+`
+
+	upgraded, changes, err := UpgradeDefinition([]byte(definition))
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, string(upgraded))
+	assert.Equal(t, []string{
+		"Renamed 'operatingSystem.packages.sccRegistrationCode' to 'operatingSystem.packages.registrationCode'",
+		"Converted the 'embeddedArtifactRegistry.images' entries to plain image names",
+		"Updated 'apiVersion' from 1.0 to 1.2",
+	}, changes)
+
+	parsed, err := ParseDefinition(upgraded)
+	require.NoError(t, err)
+
+	assert.Equal(t, "INTERNAL-USE-ONLY-foo-bar", parsed.OperatingSystem.Packages.RegCode)
+	assert.Equal(t, []ContainerImage{{Name: "hello-world:latest"}, {Name: "nginx:1.27"}}, parsed.EmbeddedArtifactRegistry.ContainerImages)
+	assert.Empty(t, parsed.Deprecations())
+}
+
+func TestUpgradeDefinition_LatestVersion(t *testing.T) {
+	definition := "apiVersion: 1.2\nimage:\n    imageType: iso\n"
+
+	upgraded, changes, err := UpgradeDefinition([]byte(definition))
+	require.NoError(t, err)
+
+	assert.Equal(t, definition, string(upgraded))
+	assert.Empty(t, changes)
+}
+
+func TestUpgradeDefinition_Errors(t *testing.T) {
+	tests := map[string]struct {
+		definition  string
+		expectedErr string
+	}{
+		"invalid YAML": {
+			definition:  "apiVersion: [1.1",
+			expectedErr: "could not parse the image definition",
+		},
+		"not a mapping": {
+			definition:  "- apiVersion: 1.1\n",
+			expectedErr: "the document must be a mapping",
+		},
+		"missing version": {
+			definition:  "image:\n  imageType: iso\n",
+			expectedErr: ErrorInvalidSchemaVersion.Error(),
+		},
+		"unsupported version": {
+			definition:  "apiVersion: 0.9\n",
+			expectedErr: ErrorInvalidSchemaVersion.Error(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := UpgradeDefinition([]byte(test.definition))
+			require.Error(t, err)
+			assert.ErrorContains(t, err, test.expectedErr)
+		})
+	}
+}
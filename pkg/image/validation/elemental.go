@@ -99,6 +99,7 @@ func validateElementalConfiguration(ctx *image.Context) []FailedValidation {
 			failures = append(failures, FailedValidation{
 				UserMessage: fmt.Sprintf("Operating system package registration code field must be defined when using Elemental "+
 					"or the %s RPMs must be manually side-loaded", combustion.ElementalPackages),
+				Path: "operatingSystem.packages",
 			})
 		}
 	} else if len(foundPackages) != len(combustion.ElementalPackages) {
@@ -17,57 +17,102 @@ const (
 func validateImage(ctx *image.Context) []FailedValidation {
 	def := ctx.ImageDefinition
 
-	validImageTypes := []string{image.TypeISO, image.TypeRAW}
+	if len(def.Image.Outputs) == 0 {
+		return validateTarget(ctx, def.Image, "in the 'image' section", "image")
+	}
+
+	var failures []FailedValidation
+
+	if def.Image.ImageType != "" || def.Image.Arch != "" || def.Image.BaseImage != "" || def.Image.OutputImageName != "" {
+		failures = append(failures, FailedValidation{
+			UserMessage: "The 'imageType', 'arch', 'baseImage' and 'outputImageName' fields cannot be used " +
+				"in the 'image' section when 'outputs' are specified.",
+			Path: "image",
+		})
+	}
+
+	var outputNames []string
+	for i, target := range def.Image.Targets() {
+		failures = append(failures, validateTarget(ctx, target, fmt.Sprintf("for output #%d", i+1), fmt.Sprintf("image.outputs[%d]", i))...)
+
+		if target.OutputImageName != "" {
+			outputNames = append(outputNames, target.OutputImageName)
+		}
+	}
+
+	if duplicates := findDuplicates(outputNames); len(duplicates) > 0 {
+		msg := fmt.Sprintf("The 'outputs' field contains duplicate output image names: %s", strings.Join(duplicates, ", "))
+		failures = append(failures, FailedValidation{
+			UserMessage: msg,
+			Path:        "image.outputs",
+		})
+	}
+
+	return failures
+}
+
+// validateTarget checks a single image to be built, described by the given location in messages
+// and found at the given path of the definition.
+func validateTarget(ctx *image.Context, target image.Image, location, path string) []FailedValidation {
+	validImageTypes := []string{image.TypeISO, image.TypeRAW, image.TypeQCOW2, image.TypeVMDK, image.TypeVHDX}
 	validArchTypes := []string{string(image.ArchTypeARM), string(image.ArchTypeX86)}
 
 	var failures []FailedValidation
 
-	if def.Image.ImageType == "" {
+	if target.ImageType == "" {
 		failures = append(failures, FailedValidation{
-			UserMessage: "The 'imageType' field is required in the 'image' section.",
+			UserMessage: fmt.Sprintf("The 'imageType' field is required %s.", location),
+			Path:        path,
 		})
-	} else if !slices.Contains(validImageTypes, def.Image.ImageType) {
+	} else if !slices.Contains(validImageTypes, target.ImageType) {
 		msg := fmt.Sprintf("The 'imageType' field must be one of: %s", strings.Join(validImageTypes, ", "))
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        path + ".imageType",
 		})
 	}
 
-	if def.Image.Arch == "" {
+	if target.Arch == "" {
 		failures = append(failures, FailedValidation{
-			UserMessage: "The 'arch' field is required in the 'image' section.",
+			UserMessage: fmt.Sprintf("The 'arch' field is required %s.", location),
+			Path:        path,
 		})
-	} else if !slices.Contains(validArchTypes, string(def.Image.Arch)) {
+	} else if !slices.Contains(validArchTypes, string(target.Arch)) {
 		msg := fmt.Sprintf("The 'arch' field must be one of: %s", strings.Join(validArchTypes, ", "))
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        path + ".arch",
 		})
 	}
 
-	if def.Image.OutputImageName == "" {
+	if target.OutputImageName == "" {
 		failures = append(failures, FailedValidation{
-			UserMessage: "The 'outputImageName' field is required in the 'image' section.",
+			UserMessage: fmt.Sprintf("The 'outputImageName' field is required %s.", location),
+			Path:        path,
 		})
 	}
 
-	if def.Image.BaseImage == "" {
+	if target.BaseImage == "" {
 		failures = append(failures, FailedValidation{
-			UserMessage: "The 'baseImage' field is required in the 'image' section.",
+			UserMessage: fmt.Sprintf("The 'baseImage' field is required %s.", location),
+			Path:        path,
 		})
 	} else {
-		baseImageFilename := filepath.Join(ctx.ImageConfigDir, "base-images", def.Image.BaseImage)
+		baseImageFilename := filepath.Join(ctx.ImageConfigDir, "base-images", target.BaseImage)
 		_, err := os.Stat(baseImageFilename)
 		if err != nil {
 			if os.IsNotExist(err) {
-				msg := fmt.Sprintf("The specified base image '%s' cannot be found.", def.Image.BaseImage)
+				msg := fmt.Sprintf("The specified base image '%s' cannot be found.", target.BaseImage)
 				failures = append(failures, FailedValidation{
 					UserMessage: msg,
+					Path:        path + ".baseImage",
 				})
 			} else {
-				msg := fmt.Sprintf("The specified base image '%s' cannot be read. See the logs for more information.", def.Image.BaseImage)
+				msg := fmt.Sprintf("The specified base image '%s' cannot be read. See the logs for more information.", target.BaseImage)
 				failures = append(failures, FailedValidation{
 					UserMessage: msg,
 					Error:       err,
+					Path:        path + ".baseImage",
 				})
 			}
 		}
@@ -60,7 +60,7 @@ func TestValidateImage(t *testing.T) {
 				},
 			},
 			ExpectedFailedMessages: []string{
-				"The 'imageType' field must be one of: iso, raw",
+				"The 'imageType' field must be one of: iso, raw, qcow2, vmdk, vhdx",
 				"The 'arch' field must be one of: aarch64, x86_64",
 			},
 		},
@@ -77,6 +77,52 @@ func TestValidateImage(t *testing.T) {
 				"The specified base image 'not-there' cannot be found.",
 			},
 		},
+		`valid outputs`: {
+			ImageDefinition: image.Definition{
+				Image: image.Image{
+					Outputs: []image.ImageOutput{
+						{
+							ImageType:       image.TypeISO,
+							Arch:            image.ArchTypeX86,
+							BaseImage:       "base-image.iso",
+							OutputImageName: "eib-created.iso",
+						},
+						{
+							ImageType:       image.TypeQCOW2,
+							Arch:            image.ArchTypeARM,
+							BaseImage:       "base-image.iso",
+							OutputImageName: "eib-created.qcow2",
+						},
+					},
+				},
+			},
+		},
+		`invalid outputs`: {
+			ImageDefinition: image.Definition{
+				Image: image.Image{
+					ImageType: image.TypeISO,
+					Outputs: []image.ImageOutput{
+						{
+							ImageType:       image.TypeISO,
+							Arch:            image.ArchTypeX86,
+							BaseImage:       "base-image.iso",
+							OutputImageName: "eib-created.iso",
+						},
+						{
+							Arch:            image.ArchTypeARM,
+							BaseImage:       "not-there",
+							OutputImageName: "eib-created.iso",
+						},
+					},
+				},
+			},
+			ExpectedFailedMessages: []string{
+				"The 'imageType', 'arch', 'baseImage' and 'outputImageName' fields cannot be used in the 'image' section when 'outputs' are specified.",
+				"The 'imageType' field is required for output #2.",
+				"The specified base image 'not-there' cannot be found.",
+				"The 'outputs' field contains duplicate output image names: eib-created.iso",
+			},
+		},
 	}
 
 	for name, test := range tests {
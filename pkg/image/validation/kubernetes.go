@@ -11,6 +11,7 @@ import (
 
 	"github.com/suse-edge/edge-image-builder/pkg/combustion"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/kubernetes"
 )
 
 const (
@@ -33,6 +34,9 @@ func validateKubernetes(ctx *image.Context) []FailedValidation {
 	}
 
 	failures = append(failures, validateNodes(&def.Kubernetes)...)
+	failures = append(failures, validateIngress(&def.Kubernetes)...)
+	failures = append(failures, validateArchitectureSupport(def)...)
+	failures = append(failures, validateKubernetesConfig(ctx)...)
 	failures = append(failures, validateManifestURLs(&def.Kubernetes)...)
 	failures = append(failures, validateHelm(&def.Kubernetes, combustion.HelmValuesPath(ctx), combustion.HelmCertsPath(ctx))...)
 
@@ -55,6 +59,7 @@ func validateNodes(k8s *image.Kubernetes) []FailedValidation {
 	if k8s.Network.APIVIP == "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: "The 'apiVIP' field is required in the 'network' section when defining entries under 'nodes'.",
+			Path:        "kubernetes.network",
 		})
 	}
 
@@ -62,10 +67,13 @@ func validateNodes(k8s *image.Kubernetes) []FailedValidation {
 	var nodeNames []string
 	var initialisers []*image.Node
 
-	for _, node := range k8s.Nodes {
+	for i, node := range k8s.Nodes {
+		path := fmt.Sprintf("kubernetes.nodes[%d]", i)
+
 		if node.Hostname == "" {
 			failures = append(failures, FailedValidation{
 				UserMessage: "The 'hostname' field is required for entries in the 'nodes' section.",
+				Path:        path,
 			})
 		}
 
@@ -74,6 +82,7 @@ func validateNodes(k8s *image.Kubernetes) []FailedValidation {
 			msg := fmt.Sprintf("The 'type' field for entries in the 'nodes' section must be one of: %s", options)
 			failures = append(failures, FailedValidation{
 				UserMessage: msg,
+				Path:        path + ".type",
 			})
 		}
 
@@ -85,6 +94,7 @@ func validateNodes(k8s *image.Kubernetes) []FailedValidation {
 				msg := fmt.Sprintf("The node labeled with 'initialiser' must be of type '%s'.", image.KubernetesNodeTypeServer)
 				failures = append(failures, FailedValidation{
 					UserMessage: msg,
+					Path:        path + ".type",
 				})
 			}
 		}
@@ -98,6 +108,7 @@ func validateNodes(k8s *image.Kubernetes) []FailedValidation {
 		msg := fmt.Sprintf("The 'nodes' section contains duplicate entries: %s", duplicateValues)
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        "kubernetes.nodes",
 		})
 	}
 
@@ -105,18 +116,70 @@ func validateNodes(k8s *image.Kubernetes) []FailedValidation {
 		msg := fmt.Sprintf("There must be at least one node of type '%s' defined.", image.KubernetesNodeTypeServer)
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        "kubernetes.nodes",
 		})
 	}
 
 	if len(initialisers) > 1 {
 		failures = append(failures, FailedValidation{
 			UserMessage: "Only one node may be specified as the cluster initializer.",
+			Path:        "kubernetes.nodes",
+		})
+	}
+
+	if kubernetes.ServersCount(k8s.Nodes) == 2 {
+		failures = append(failures, FailedValidation{
+			UserMessage: "Kubernetes clusters consisting of two server nodes cannot form a highly available architecture.",
+			Path:        "kubernetes.nodes",
+			Severity:    SeverityWarning,
 		})
 	}
 
 	return failures
 }
 
+// validateIngress warns about k3s clusters whose virtual IP address takes over the address
+// the bundled Traefik ingress controller would otherwise be exposed on.
+func validateIngress(k8s *image.Kubernetes) []FailedValidation {
+	if !strings.Contains(k8s.Version, image.KubernetesDistroK3S) {
+		return nil
+	}
+
+	var failures []FailedValidation
+
+	if len(k8s.Nodes) >= 2 {
+		failures = append(failures, FailedValidation{
+			UserMessage: "An external IP address for the Ingress Controller (Traefik) must be manually configured in multi-node clusters.",
+			Path:        "kubernetes.nodes",
+			Severity:    SeverityWarning,
+		})
+	} else if k8s.Network.APIVIP != "" {
+		failures = append(failures, FailedValidation{
+			UserMessage: "A Virtual IP address for the k3s cluster has been provided. " +
+				"An external IP address for the Ingress Controller (Traefik) must be manually configured.",
+			Path:     "kubernetes.network.apiVIP",
+			Severity: SeverityWarning,
+		})
+	}
+
+	return failures
+}
+
+func validateArchitectureSupport(def *image.Definition) []FailedValidation {
+	if !strings.Contains(def.Kubernetes.Version, image.KubernetesDistroRKE2) ||
+		!slices.Contains(def.Image.Architectures(), image.ArchTypeARM) {
+		return nil
+	}
+
+	return []FailedValidation{
+		{
+			UserMessage: "RKE2 support for aarch64 platforms is limited and experimental.",
+			Path:        "kubernetes.version",
+			Severity:    SeverityWarning,
+		},
+	}
+}
+
 func validateManifestURLs(k8s *image.Kubernetes) []FailedValidation {
 	var failures []FailedValidation
 
@@ -125,10 +188,13 @@ func validateManifestURLs(k8s *image.Kubernetes) []FailedValidation {
 	}
 
 	seenManifests := make(map[string]bool)
-	for _, manifest := range k8s.Manifests.URLs {
+	for i, manifest := range k8s.Manifests.URLs {
+		path := fmt.Sprintf("kubernetes.manifests.urls[%d]", i)
+
 		if !strings.HasPrefix(manifest, "http") {
 			failures = append(failures, FailedValidation{
 				UserMessage: "Entries in 'urls' must begin with either 'http://' or 'https://'.",
+				Path:        path,
 			})
 		}
 
@@ -136,6 +202,7 @@ func validateManifestURLs(k8s *image.Kubernetes) []FailedValidation {
 			msg := fmt.Sprintf("The 'urls' field contains duplicate entries: %s", manifest)
 			failures = append(failures, FailedValidation{
 				UserMessage: msg,
+				Path:        path,
 			})
 		}
 
@@ -155,6 +222,7 @@ func validateHelm(k8s *image.Kubernetes, valuesDir, certsDir string) []FailedVal
 	if len(k8s.Helm.Repositories) == 0 {
 		failures = append(failures, FailedValidation{
 			UserMessage: "Helm charts defined with no Helm repositories defined.",
+			Path:        "kubernetes.helm",
 		})
 
 		return failures
@@ -168,61 +236,69 @@ func validateHelm(k8s *image.Kubernetes, valuesDir, certsDir string) []FailedVal
 	if failure := validateHelmChartDuplicates(k8s.Helm.Charts); failure != "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: failure,
+			Path:        "kubernetes.helm.charts",
 		})
 	}
 
 	seenHelmRepos := make(map[string]bool)
 	for i := range k8s.Helm.Charts {
-		failures = append(failures, validateChart(&k8s.Helm.Charts[i], helmRepositoryNames, valuesDir)...)
+		path := fmt.Sprintf("kubernetes.helm.charts[%d]", i)
+		failures = append(failures, validateChart(&k8s.Helm.Charts[i], path, helmRepositoryNames, valuesDir)...)
 
 		seenHelmRepos[k8s.Helm.Charts[i].RepositoryName] = true
 	}
 
-	for _, repo := range k8s.Helm.Repositories {
+	for i, repo := range k8s.Helm.Repositories {
 		r := repo
-		failures = append(failures, validateRepo(&r, seenHelmRepos, certsDir)...)
+		path := fmt.Sprintf("kubernetes.helm.repositories[%d]", i)
+		failures = append(failures, validateRepo(&r, path, seenHelmRepos, certsDir)...)
 	}
 
 	return failures
 }
 
-func validateChart(chart *image.HelmChart, repositoryNames []string, valuesDir string) []FailedValidation {
+func validateChart(chart *image.HelmChart, path string, repositoryNames []string, valuesDir string) []FailedValidation {
 	var failures []FailedValidation
 
 	if chart.Name == "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: "Helm chart 'name' field must be defined.",
+			Path:        path + ".name",
 		})
 	}
 
 	if chart.RepositoryName == "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm chart 'repositoryName' field for %q must be defined.", chart.Name),
+			Path:        path + ".repositoryName",
 		})
 	} else if !slices.Contains(repositoryNames, chart.RepositoryName) {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm chart 'repositoryName' %q for Helm chart %q does not match the name of any defined repository.", chart.RepositoryName, chart.Name),
+			Path:        path + ".repositoryName",
 		})
 	}
 
 	if chart.Version == "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm chart 'version' field for %q field must be defined.", chart.Name),
+			Path:        path + ".version",
 		})
 	}
 
 	if chart.CreateNamespace && chart.TargetNamespace == "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm chart 'createNamespace' field for %q cannot be true without 'targetNamespace' being defined.", chart.Name),
+			Path:        path + ".createNamespace",
 		})
 	}
 
-	failures = append(failures, validateHelmChartValues(chart.Name, chart.ValuesFile, valuesDir)...)
+	failures = append(failures, validateHelmChartValues(chart.Name, chart.ValuesFile, path+".valuesFile", valuesDir)...)
 
 	return failures
 }
 
-func validateRepo(repo *image.HelmRepository, seenHelmRepos map[string]bool, certsDir string) []FailedValidation {
+func validateRepo(repo *image.HelmRepository, path string, seenHelmRepos map[string]bool, certsDir string) []FailedValidation {
 	var failures []FailedValidation
 
 	parsedURL, err := url.Parse(repo.URL)
@@ -230,119 +306,133 @@ func validateRepo(repo *image.HelmRepository, seenHelmRepos map[string]bool, cer
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository URL '%s' could not be parsed.", repo.URL),
 			Error:       err,
+			Path:        path + ".url",
 		})
 
 		return failures
 	}
 
-	failures = append(failures, validateHelmRepoName(repo, seenHelmRepos)...)
-	failures = append(failures, validateHelmRepoURL(parsedURL, repo)...)
-	failures = append(failures, validateHelmRepoAuth(repo)...)
-	failures = append(failures, validateHelmRepoArgs(parsedURL, repo)...)
-	failures = append(failures, validateHelmRepoCert(repo.Name, repo.CAFile, certsDir)...)
+	failures = append(failures, validateHelmRepoName(repo, path, seenHelmRepos)...)
+	failures = append(failures, validateHelmRepoURL(parsedURL, repo, path)...)
+	failures = append(failures, validateHelmRepoAuth(repo, path)...)
+	failures = append(failures, validateHelmRepoArgs(parsedURL, repo, path)...)
+	failures = append(failures, validateHelmRepoCert(repo.Name, repo.CAFile, path+".caFile", certsDir)...)
 
 	return failures
 }
 
-func validateHelmRepoName(repo *image.HelmRepository, seenHelmRepos map[string]bool) []FailedValidation {
+func validateHelmRepoName(repo *image.HelmRepository, path string, seenHelmRepos map[string]bool) []FailedValidation {
 	var failures []FailedValidation
 
 	if repo.Name == "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: "Helm repository 'name' field must be defined.",
+			Path:        path + ".name",
 		})
 	} else if !seenHelmRepos[repo.Name] {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'name' field for %q must match the 'repositoryName' field in at least one defined Helm chart.", repo.Name),
+			Path:        path + ".name",
 		})
 	}
 
 	return failures
 }
 
-func validateHelmRepoURL(parsedURL *url.URL, repo *image.HelmRepository) []FailedValidation {
+func validateHelmRepoURL(parsedURL *url.URL, repo *image.HelmRepository, path string) []FailedValidation {
 	var failures []FailedValidation
 
 	if repo.URL == "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'url' field for %q must be defined.", repo.Name),
+			Path:        path + ".url",
 		})
 	} else if parsedURL.Scheme != httpScheme && parsedURL.Scheme != httpsScheme && parsedURL.Scheme != ociScheme {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'url' field for %q must begin with either 'oci://', 'http://', or 'https://'.", repo.Name),
+			Path:        path + ".url",
 		})
 	}
 
 	return failures
 }
 
-func validateHelmRepoAuth(repo *image.HelmRepository) []FailedValidation {
+func validateHelmRepoAuth(repo *image.HelmRepository, path string) []FailedValidation {
 	var failures []FailedValidation
 
 	if repo.Authentication.Username != "" && repo.Authentication.Password == "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'password' field not defined for %q.", repo.Name),
+			Path:        path + ".authentication.password",
 		})
 	}
 
 	if repo.Authentication.Username == "" && repo.Authentication.Password != "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'username' field not defined for %q.", repo.Name),
+			Path:        path + ".authentication.username",
 		})
 	}
 
 	return failures
 }
 
-func validateHelmRepoArgs(parsedURL *url.URL, repo *image.HelmRepository) []FailedValidation {
+func validateHelmRepoArgs(parsedURL *url.URL, repo *image.HelmRepository, path string) []FailedValidation {
 	var failures []FailedValidation
 
 	if repo.SkipTLSVerify && repo.PlainHTTP {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'plainHTTP' and 'skipTLSVerify' fields for %q cannot both be true.", repo.Name),
+			Path:        path + ".skipTLSVerify",
 		})
 	}
 
 	if parsedURL.Scheme == httpScheme && !repo.PlainHTTP {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'url' field for %q contains 'http://' but 'plainHTTP' field is false.", repo.Name),
+			Path:        path + ".url",
 		})
 	}
 
 	if parsedURL.Scheme == httpsScheme && repo.PlainHTTP {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'url' field for %q contains 'https://' but 'plainHTTP' field is true.", repo.Name),
+			Path:        path + ".plainHTTP",
 		})
 	}
 
 	if parsedURL.Scheme == httpScheme && repo.SkipTLSVerify {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'url' field for %q contains 'http://' but 'skipTLSVerify' field is true.", repo.Name),
+			Path:        path + ".skipTLSVerify",
 		})
 	}
 
 	if repo.SkipTLSVerify && repo.CAFile != "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'caFile' field for %q cannot be defined while 'skipTLSVerify' is true.", repo.Name),
+			Path:        path + ".caFile",
 		})
 	}
 
 	if repo.PlainHTTP && repo.CAFile != "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'caFile' field for %q cannot be defined while 'plainHTTP' is true.", repo.Name),
+			Path:        path + ".caFile",
 		})
 	}
 
 	if parsedURL.Scheme == httpScheme && repo.CAFile != "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm repository 'url' field for %q contains 'http://' but 'caFile' field is defined.", repo.Name),
+			Path:        path + ".caFile",
 		})
 	}
 
 	return failures
 }
 
-func validateHelmRepoCert(repoName, certFile, certsDir string) []FailedValidation {
+func validateHelmRepoCert(repoName, certFile, path, certsDir string) []FailedValidation {
 	if certFile == "" {
 		return nil
 	}
@@ -354,6 +444,7 @@ func validateHelmRepoCert(repoName, certFile, certsDir string) []FailedValidatio
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm chart 'caFile' field for %q must be the name of a valid cert file/bundle with one of the following extensions: %s",
 				repoName, strings.Join(validExtensions, ", ")),
+			Path: path,
 		})
 		return failures
 	}
@@ -364,11 +455,13 @@ func validateHelmRepoCert(repoName, certFile, certsDir string) []FailedValidatio
 		if errors.Is(err, os.ErrNotExist) {
 			failures = append(failures, FailedValidation{
 				UserMessage: fmt.Sprintf("Helm repo cert file/bundle '%s' could not be found at '%s'.", certFile, certFilePath),
+				Path:        path,
 			})
 		} else {
 			failures = append(failures, FailedValidation{
 				UserMessage: fmt.Sprintf("Helm repo cert file/bundle '%s' could not be read", certFile),
 				Error:       err,
+				Path:        path,
 			})
 		}
 	}
@@ -376,7 +469,7 @@ func validateHelmRepoCert(repoName, certFile, certsDir string) []FailedValidatio
 	return failures
 }
 
-func validateHelmChartValues(chartName, valuesFile, valuesDir string) []FailedValidation {
+func validateHelmChartValues(chartName, valuesFile, path, valuesDir string) []FailedValidation {
 	if valuesFile == "" {
 		return nil
 	}
@@ -386,6 +479,7 @@ func validateHelmChartValues(chartName, valuesFile, valuesDir string) []FailedVa
 	if filepath.Ext(valuesFile) != ".yaml" && filepath.Ext(valuesFile) != ".yml" {
 		failures = append(failures, FailedValidation{
 			UserMessage: fmt.Sprintf("Helm chart 'valuesFile' field for %q must be the name of a valid yaml file ending in '.yaml' or '.yml'.", chartName),
+			Path:        path,
 		})
 		return failures
 	}
@@ -396,11 +490,13 @@ func validateHelmChartValues(chartName, valuesFile, valuesDir string) []FailedVa
 		if errors.Is(err, os.ErrNotExist) {
 			failures = append(failures, FailedValidation{
 				UserMessage: fmt.Sprintf("Helm chart values file '%s' could not be found at '%s'.", valuesFile, valuesFilePath),
+				Path:        path,
 			})
 		} else {
 			failures = append(failures, FailedValidation{
 				UserMessage: fmt.Sprintf("Helm chart values file '%s' could not be read.", valuesFile),
 				Error:       err,
+				Path:        path,
 			})
 		}
 	}
@@ -442,11 +538,13 @@ func validateAdditionalArtifacts(ctx *image.Context) []FailedValidation {
 	if len(ctx.ImageDefinition.Kubernetes.Helm.Charts) != 0 {
 		failures = append(failures, FailedValidation{
 			UserMessage: "Kubernetes version must be defined when Helm charts are specified",
+			Path:        "kubernetes.helm.charts",
 		})
 	}
 	if len(ctx.ImageDefinition.Kubernetes.Manifests.URLs) != 0 {
 		failures = append(failures, FailedValidation{
 			UserMessage: "Kubernetes version must be defined when manifest URLs are specified",
+			Path:        "kubernetes.manifests.urls",
 		})
 	}
 
@@ -0,0 +1,547 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/combustion"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/kubernetes"
+	"gopkg.in/yaml.v3"
+)
+
+type configValueType int
+
+const (
+	configString configValueType = iota
+	configBool
+	configInt
+	// configList values are either a list of strings or a single comma-separated string.
+	configList
+)
+
+type configOption struct {
+	valueType configValueType
+	// serverOnly options are not accepted in the agent configuration.
+	serverOnly bool
+}
+
+// commonConfigOptions lists the configuration file options supported by both RKE2 and k3s.
+var commonConfigOptions = map[string]configOption{
+	"token":                             {valueType: configString},
+	"token-file":                        {valueType: configString},
+	"server":                            {valueType: configString},
+	"data-dir":                          {valueType: configString},
+	"debug":                             {valueType: configBool},
+	"selinux":                           {valueType: configBool},
+	"node-name":                         {valueType: configString},
+	"with-node-id":                      {valueType: configBool},
+	"node-label":                        {valueType: configList},
+	"node-taint":                        {valueType: configList},
+	"node-ip":                           {valueType: configList},
+	"node-external-ip":                  {valueType: configList},
+	"image-credential-provider-bin-dir": {valueType: configString},
+	"image-credential-provider-config":  {valueType: configString},
+	"container-runtime-endpoint":        {valueType: configString},
+	"snapshotter":                       {valueType: configString},
+	"private-registry":                  {valueType: configString},
+	"pause-image":                       {valueType: configString},
+	"resolv-conf":                       {valueType: configString},
+	"kubelet-arg":                       {valueType: configList},
+	"kube-proxy-arg":                    {valueType: configList},
+	"protect-kernel-defaults":           {valueType: configBool},
+	"lb-server-port":                    {valueType: configInt},
+	"log":                               {valueType: configString},
+	"alsologtostderr":                   {valueType: configBool},
+	"v":                                 {valueType: configInt},
+	"agent-token":                       {valueType: configString, serverOnly: true},
+	"agent-token-file":                  {valueType: configString, serverOnly: true},
+	"advertise-address":                 {valueType: configString, serverOnly: true},
+	"bind-address":                      {valueType: configString, serverOnly: true},
+	"tls-san":                           {valueType: configList, serverOnly: true},
+	"tls-san-security":                  {valueType: configBool, serverOnly: true},
+	"cluster-cidr":                      {valueType: configList, serverOnly: true},
+	"service-cidr":                      {valueType: configList, serverOnly: true},
+	"service-node-port-range":           {valueType: configString, serverOnly: true},
+	"cluster-dns":                       {valueType: configList, serverOnly: true},
+	"cluster-domain":                    {valueType: configString, serverOnly: true},
+	"egress-selector-mode":              {valueType: configString, serverOnly: true},
+	"write-kubeconfig":                  {valueType: configString, serverOnly: true},
+	"write-kubeconfig-mode":             {valueType: configString, serverOnly: true},
+	"disable":                           {valueType: configList, serverOnly: true},
+	"disable-scheduler":                 {valueType: configBool, serverOnly: true},
+	"disable-cloud-controller":          {valueType: configBool, serverOnly: true},
+	"disable-kube-proxy":                {valueType: configBool, serverOnly: true},
+	"disable-apiserver":                 {valueType: configBool, serverOnly: true},
+	"disable-controller-manager":        {valueType: configBool, serverOnly: true},
+	"disable-etcd":                      {valueType: configBool, serverOnly: true},
+	"kube-apiserver-arg":                {valueType: configList, serverOnly: true},
+	"kube-scheduler-arg":                {valueType: configList, serverOnly: true},
+	"kube-controller-manager-arg":       {valueType: configList, serverOnly: true},
+	"kube-cloud-controller-manager-arg": {valueType: configList, serverOnly: true},
+	"etcd-arg":                          {valueType: configList, serverOnly: true},
+	"etcd-expose-metrics":               {valueType: configBool, serverOnly: true},
+	"etcd-disable-snapshots":            {valueType: configBool, serverOnly: true},
+	"etcd-snapshot-name":                {valueType: configString, serverOnly: true},
+	"etcd-snapshot-schedule-cron":       {valueType: configString, serverOnly: true},
+	"etcd-snapshot-retention":           {valueType: configInt, serverOnly: true},
+	"etcd-snapshot-dir":                 {valueType: configString, serverOnly: true},
+	"etcd-snapshot-compress":            {valueType: configBool, serverOnly: true},
+	"etcd-s3":                           {valueType: configBool, serverOnly: true},
+	"etcd-s3-endpoint":                  {valueType: configString, serverOnly: true},
+	"etcd-s3-endpoint-ca":               {valueType: configString, serverOnly: true},
+	"etcd-s3-skip-ssl-verify":           {valueType: configBool, serverOnly: true},
+	"etcd-s3-access-key":                {valueType: configString, serverOnly: true},
+	"etcd-s3-secret-key":                {valueType: configString, serverOnly: true},
+	"etcd-s3-bucket":                    {valueType: configString, serverOnly: true},
+	"etcd-s3-region":                    {valueType: configString, serverOnly: true},
+	"etcd-s3-folder":                    {valueType: configString, serverOnly: true},
+	"etcd-s3-insecure":                  {valueType: configBool, serverOnly: true},
+	"etcd-s3-timeout":                   {valueType: configString, serverOnly: true},
+	"cluster-reset":                     {valueType: configBool, serverOnly: true},
+	"cluster-reset-restore-path":        {valueType: configString, serverOnly: true},
+	"secrets-encryption":                {valueType: configBool, serverOnly: true},
+	"embedded-registry":                 {valueType: configBool, serverOnly: true},
+	"supervisor-metrics":                {valueType: configBool, serverOnly: true},
+	"system-default-registry":           {valueType: configString, serverOnly: true},
+}
+
+// rke2ConfigOptions lists the configuration file options specific to RKE2.
+var rke2ConfigOptions = map[string]configOption{
+	"cni":                                {valueType: configList},
+	"kubelet-path":                       {valueType: configString},
+	"cloud-provider-name":                {valueType: configString},
+	"cloud-provider-config":              {valueType: configString},
+	"profile":                            {valueType: configString},
+	"audit-policy-file":                  {valueType: configString, serverOnly: true},
+	"pod-security-admission-config-file": {valueType: configString, serverOnly: true},
+	"enable-servicelb":                   {valueType: configBool, serverOnly: true},
+	"ingress-controller":                 {valueType: configList, serverOnly: true},
+	"control-plane-resource-requests":    {valueType: configString, serverOnly: true},
+	"control-plane-resource-limits":      {valueType: configString, serverOnly: true},
+	"kube-apiserver-image":               {valueType: configString, serverOnly: true},
+	"kube-controller-manager-image":      {valueType: configString, serverOnly: true},
+	"kube-scheduler-image":               {valueType: configString, serverOnly: true},
+	"etcd-image":                         {valueType: configString, serverOnly: true},
+}
+
+// k3sConfigOptions lists the configuration file options specific to k3s.
+var k3sConfigOptions = map[string]configOption{
+	"flannel-iface":              {valueType: configString},
+	"flannel-conf":               {valueType: configString},
+	"flannel-cni-conf":           {valueType: configString},
+	"docker":                     {valueType: configBool},
+	"prefer-bundled-bin":         {valueType: configBool},
+	"rootless":                   {valueType: configBool},
+	"https-listen-port":          {valueType: configInt, serverOnly: true},
+	"advertise-port":             {valueType: configInt, serverOnly: true},
+	"cluster-init":               {valueType: configBool, serverOnly: true},
+	"flannel-backend":            {valueType: configString, serverOnly: true},
+	"flannel-ipv6-masq":          {valueType: configBool, serverOnly: true},
+	"flannel-external-ip":        {valueType: configBool, serverOnly: true},
+	"disable-network-policy":     {valueType: configBool, serverOnly: true},
+	"disable-helm-controller":    {valueType: configBool, serverOnly: true},
+	"default-local-storage-path": {valueType: configString, serverOnly: true},
+	"write-kubeconfig-group":     {valueType: configString, serverOnly: true},
+	"servicelb-namespace":        {valueType: configString, serverOnly: true},
+}
+
+// disableableComponents lists the packaged components which may be turned off through the 'disable' option.
+var disableableComponents = map[string][]string{
+	image.KubernetesDistroRKE2: {
+		"rke2-coredns", "rke2-ingress-nginx", "rke2-traefik", "rke2-metrics-server", "rke2-runtimeclasses",
+		"rke2-snapshot-controller", "rke2-snapshot-controller-crd", "rke2-snapshot-validation-webhook",
+		"rke2-canal", "rke2-calico", "rke2-calico-crd", "rke2-cilium", "rke2-multus",
+	},
+	image.KubernetesDistroK3S: {
+		"coredns", "servicelb", "traefik", "local-storage", "metrics-server", "runtimes",
+	},
+}
+
+// multusCNI may be combined with any of the CNIs supported by the build.
+const multusCNI = "multus"
+
+// kubernetesConfig is a parsed server or agent configuration file.
+type kubernetesConfig struct {
+	// path of the file and its name, which is used in messages.
+	path string
+	file string
+	// keys and values hold the top level entries of the file in the order they are defined.
+	keys   []*yaml.Node
+	values []*yaml.Node
+}
+
+func (c *kubernetesConfig) lookup(key string) (*yaml.Node, *yaml.Node) {
+	for i, k := range c.keys {
+		if k.Value == key {
+			return k, c.values[i]
+		}
+	}
+
+	return nil, nil
+}
+
+// position returns the position of the node within the configuration file.
+func (c *kubernetesConfig) position(node *yaml.Node) image.Position {
+	return image.Position{
+		File:   c.path,
+		Line:   node.Line,
+		Column: node.Column,
+	}
+}
+
+func validateKubernetesConfig(ctx *image.Context) []FailedValidation {
+	k8s := &ctx.ImageDefinition.Kubernetes
+
+	var distro string
+	switch {
+	case strings.Contains(k8s.Version, image.KubernetesDistroRKE2):
+		distro = image.KubernetesDistroRKE2
+	case strings.Contains(k8s.Version, image.KubernetesDistroK3S):
+		distro = image.KubernetesDistroK3S
+	default:
+		return nil
+	}
+
+	var failures []FailedValidation
+
+	server, serverFailures := loadKubernetesConfig(combustion.KubernetesConfigPath(ctx))
+	failures = append(failures, serverFailures...)
+
+	agent, agentFailures := loadKubernetesConfig(combustion.KubernetesAgentConfigPath(ctx))
+	failures = append(failures, agentFailures...)
+
+	if server != nil {
+		failures = append(failures, validateConfigOptions(server, distro, false)...)
+		failures = append(failures, validateDisabledComponents(server, distro)...)
+
+		if distro == image.KubernetesDistroRKE2 {
+			failures = append(failures, validateConfigCNI(server)...)
+		}
+	}
+
+	if agent != nil {
+		failures = append(failures, validateConfigOptions(agent, distro, true)...)
+
+		if distro == image.KubernetesDistroRKE2 {
+			failures = append(failures, validateConfigCNI(agent)...)
+		}
+
+		if len(k8s.Nodes) >= 2 {
+			failures = append(failures, validateConfigSELinux(server, agent)...)
+		}
+	}
+
+	return failures
+}
+
+// loadKubernetesConfig parses the configuration file at the given path.
+// Missing files are valid and result in a nil configuration.
+func loadKubernetesConfig(path string) (*kubernetesConfig, []FailedValidation) {
+	file := filepath.Base(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, []FailedValidation{
+			{
+				UserMessage: fmt.Sprintf("Kubernetes config file '%s' could not be read.", file),
+				Error:       err,
+			},
+		}
+	}
+
+	var document yaml.Node
+	if err = yaml.Unmarshal(data, &document); err != nil {
+		return nil, []FailedValidation{
+			{
+				UserMessage: fmt.Sprintf("Kubernetes config file '%s' could not be parsed.", file),
+				Error:       err,
+			},
+		}
+	}
+
+	config := &kubernetesConfig{path: path, file: file}
+
+	// Empty files are equivalent to not providing a configuration
+	if len(document.Content) == 0 {
+		return config, nil
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, []FailedValidation{
+			{
+				UserMessage: fmt.Sprintf("Kubernetes config file '%s' must contain a mapping of configuration options.", file),
+			},
+		}
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		config.keys = append(config.keys, root.Content[i])
+		config.values = append(config.values, root.Content[i+1])
+	}
+
+	return config, nil
+}
+
+func validateConfigOptions(config *kubernetesConfig, distro string, agent bool) []FailedValidation {
+	var failures []FailedValidation
+
+	distroOptions := rke2ConfigOptions
+	if distro == image.KubernetesDistroK3S {
+		distroOptions = k3sConfigOptions
+	}
+
+	for i, key := range config.keys {
+		option, ok := commonConfigOptions[key.Value]
+		if !ok {
+			option, ok = distroOptions[key.Value]
+		}
+
+		if !ok {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("The '%s' option in '%s' is not a known %s configuration option and may be ignored or rejected.",
+					key.Value, config.file, distro),
+				Severity: SeverityWarning,
+				Position: config.position(key),
+			})
+			continue
+		}
+
+		if agent && option.serverOnly {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("The '%s' option in '%s' is only supported in the server configuration.",
+					key.Value, config.file),
+				Position: config.position(key),
+			})
+			continue
+		}
+
+		value := config.values[i]
+		if description := checkConfigValueType(value, option.valueType); description != "" {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("The '%s' option in '%s' must be %s.", key.Value, config.file, description),
+				Position:    config.position(value),
+			})
+		}
+	}
+
+	return failures
+}
+
+// checkConfigValueType returns a description of the expected value if the node does not match the value type.
+func checkConfigValueType(value *yaml.Node, valueType configValueType) string {
+	if value.Kind == yaml.AliasNode {
+		value = value.Alias
+	}
+
+	// Empty values are equivalent to omitting the option
+	if value.Kind == yaml.ScalarNode && value.ShortTag() == "!!null" {
+		return ""
+	}
+
+	switch valueType {
+	case configBool:
+		if value.Kind != yaml.ScalarNode || value.ShortTag() != "!!bool" {
+			return "true or false"
+		}
+	case configInt:
+		if value.Kind != yaml.ScalarNode || value.ShortTag() != "!!int" {
+			return "an integer"
+		}
+	case configList:
+		if value.Kind == yaml.ScalarNode {
+			return ""
+		}
+
+		if value.Kind != yaml.SequenceNode || slices.ContainsFunc(value.Content, func(item *yaml.Node) bool {
+			return item.Kind != yaml.ScalarNode
+		}) {
+			return "a string or a list of strings"
+		}
+	default:
+		if value.Kind != yaml.ScalarNode {
+			return "a string"
+		}
+	}
+
+	return ""
+}
+
+// configListValues returns the entries of a list option, splitting comma-separated strings.
+// Values of the wrong type, which are reported separately, result in no entries.
+func configListValues(value *yaml.Node) []string {
+	if checkConfigValueType(value, configList) != "" {
+		return nil
+	}
+
+	var values []string
+
+	switch value.Kind {
+	case yaml.ScalarNode:
+		if value.ShortTag() == "!!null" {
+			return nil
+		}
+
+		for _, v := range strings.Split(value.Value, ",") {
+			values = append(values, strings.TrimSpace(v))
+		}
+	case yaml.SequenceNode:
+		for _, item := range value.Content {
+			values = append(values, item.Value)
+		}
+	}
+
+	return values
+}
+
+func validateConfigCNI(config *kubernetesConfig) []FailedValidation {
+	key, value := config.lookup("cni")
+	if key == nil {
+		return nil
+	}
+
+	cnis := configListValues(value)
+	if len(cnis) == 0 {
+		return nil
+	}
+
+	var failures []FailedValidation
+
+	// Only the CNIs the Kubernetes artefacts are provided for can be built
+	validCNIs := append(kubernetes.SupportedCNIs(), multusCNI)
+
+	for _, cni := range cnis {
+		if !slices.Contains(validCNIs, cni) {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("The 'cni' option in '%s' contains an unsupported CNI '%s', valid values are: %s",
+					config.file, cni, strings.Join(validCNIs, ", ")),
+				Position: config.position(value),
+			})
+		}
+	}
+
+	if _, _, err := kubernetes.ConfiguredCNI(map[string]any{"cni": cnis}); err != nil {
+		failures = append(failures, FailedValidation{
+			UserMessage: fmt.Sprintf("The 'cni' option in '%s' is invalid: %s.", config.file, err),
+			Position:    config.position(value),
+		})
+	}
+
+	return failures
+}
+
+// validateDisabledComponents checks the 'disable' option for unknown or repeated components
+// as well as components which are required by other options in the configuration.
+func validateDisabledComponents(config *kubernetesConfig, distro string) []FailedValidation {
+	key, value := config.lookup("disable")
+	if key == nil {
+		return nil
+	}
+
+	var failures []FailedValidation
+
+	disabled := configListValues(value)
+	for _, duplicate := range findDuplicates(disabled) {
+		failures = append(failures, FailedValidation{
+			UserMessage: fmt.Sprintf("The 'disable' option in '%s' contains duplicate entries: %s", config.file, duplicate),
+			Severity:    SeverityWarning,
+			Position:    config.position(value),
+		})
+	}
+
+	components := slices.Clone(disabled)
+	slices.Sort(components)
+
+	for _, component := range slices.Compact(components) {
+		if !slices.Contains(disableableComponents[distro], component) {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("The 'disable' option in '%s' contains an unknown component '%s'.", config.file, component),
+				Severity:    SeverityWarning,
+				Position:    config.position(value),
+			})
+		}
+	}
+
+	if distro != image.KubernetesDistroRKE2 {
+		return failures
+	}
+
+	// Charts of the selected CNI and ingress controller are required by the cluster
+	var required []string
+
+	if _, cniValue := config.lookup("cni"); cniValue != nil {
+		for _, cni := range configListValues(cniValue) {
+			required = append(required, "rke2-"+cni)
+		}
+	} else {
+		required = append(required, "rke2-"+image.CNITypeCilium)
+	}
+
+	if _, ingressValue := config.lookup("ingress-controller"); ingressValue != nil {
+		for _, ingress := range configListValues(ingressValue) {
+			required = append(required, "rke2-"+ingress)
+		}
+	}
+
+	for _, component := range required {
+		// Not every CNI is deployed through a chart, e.g. 'none'
+		if slices.Contains(disableableComponents[distro], component) && slices.Contains(disabled, component) {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("The 'disable' option in '%s' conflicts with the cluster configuration: "+
+					"'%s' is required by the selected CNI or ingress controller.", config.file, component),
+				Position: config.position(value),
+			})
+		}
+	}
+
+	return failures
+}
+
+// validateConfigSELinux checks that the agents use the same SELinux setting as the servers.
+// The server setting, which defaults to disabled, is enforced on the agents in multi node clusters.
+func validateConfigSELinux(server, agent *kubernetesConfig) []FailedValidation {
+	agentSELinux, node, ok := configBoolValue(agent, "selinux")
+	if !ok {
+		return nil
+	}
+
+	serverSELinux, _, _ := configBoolValue(server, "selinux")
+	if agentSELinux == serverSELinux {
+		return nil
+	}
+
+	return []FailedValidation{
+		{
+			UserMessage: fmt.Sprintf("The 'selinux' option in '%s' must match the server configuration, "+
+				"which is set to '%t'.", agent.file, serverSELinux),
+			Position: agent.position(node),
+		},
+	}
+}
+
+// configBoolValue returns the value of a boolean option along with its node, if it is set to a valid value.
+func configBoolValue(config *kubernetesConfig, key string) (value bool, node *yaml.Node, ok bool) {
+	if config == nil {
+		return false, nil, false
+	}
+
+	k, v := config.lookup(key)
+	if k == nil || v.Kind != yaml.ScalarNode || v.ShortTag() != "!!bool" {
+		return false, nil, false
+	}
+
+	if err := v.Decode(&value); err != nil {
+		return false, nil, false
+	}
+
+	return value, v, true
+}
@@ -0,0 +1,231 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+)
+
+func TestValidateKubernetesConfig(t *testing.T) {
+	multiNode := []image.Node{
+		{Hostname: "server", Type: image.KubernetesNodeTypeServer},
+		{Hostname: "agent", Type: image.KubernetesNodeTypeAgent},
+	}
+
+	tests := map[string]struct {
+		Version          string
+		Nodes            []image.Node
+		ServerConfig     string
+		AgentConfig      string
+		ExpectedErrors   []string
+		ExpectedWarnings []string
+	}{
+		`no config files`: {
+			Version: "v1.30.3+rke2r1",
+		},
+		`empty config files`: {
+			Version: "v1.30.3+rke2r1",
+			Nodes:   multiNode,
+		},
+		`valid rke2`: {
+			Version: "v1.30.3+rke2r1",
+			Nodes:   multiNode,
+			ServerConfig: `cni:
+  - multus
+  - calico
+selinux: true
+tls-san: host.com, 192.168.122.100
+disable:
+  - rke2-ingress-nginx
+etcd-snapshot-retention: 5
+`,
+			AgentConfig: `selinux: true
+node-label:
+  - role=worker
+`,
+		},
+		`valid k3s`: {
+			Version: "v1.30.3+k3s1",
+			ServerConfig: `disable: traefik,servicelb
+flannel-backend: wireguard-native
+cluster-init: true
+`,
+		},
+		`unknown options`: {
+			Version: "v1.30.3+k3s1",
+			ServerConfig: `cni: calico
+made-up: true
+`,
+			ExpectedWarnings: []string{
+				"server.yaml:1:1: The 'cni' option in 'server.yaml' is not a known k3s configuration option and may be ignored or rejected.",
+				"server.yaml:2:1: The 'made-up' option in 'server.yaml' is not a known k3s configuration option and may be ignored or rejected.",
+			},
+		},
+		`wrong types`: {
+			Version: "v1.30.3+rke2r1",
+			ServerConfig: `selinux: "yes"
+tls-san:
+  first: host.com
+etcd-snapshot-retention: many
+node-name: [a, b]
+`,
+			ExpectedErrors: []string{
+				"server.yaml:1:10: The 'selinux' option in 'server.yaml' must be true or false.",
+				"server.yaml:3:3: The 'tls-san' option in 'server.yaml' must be a string or a list of strings.",
+				"server.yaml:4:26: The 'etcd-snapshot-retention' option in 'server.yaml' must be an integer.",
+				"server.yaml:5:12: The 'node-name' option in 'server.yaml' must be a string.",
+			},
+		},
+		`server options in agent config`: {
+			Version:     "v1.30.3+rke2r1",
+			Nodes:       multiNode,
+			AgentConfig: "tls-san: host.com\n",
+			ExpectedErrors: []string{
+				"agent.yaml:1:1: The 'tls-san' option in 'agent.yaml' is only supported in the server configuration.",
+			},
+		},
+		`invalid cni combinations`: {
+			Version:      "v1.30.3+rke2r1",
+			Nodes:        multiNode,
+			ServerConfig: "cni: calico, multus\n",
+			AgentConfig:  "cni: [multus]\n",
+			ExpectedErrors: []string{
+				"server.yaml:1:6: The 'cni' option in 'server.yaml' is invalid: multiple cni values are only allowed if multus is the first one.",
+				"agent.yaml:1:6: The 'cni' option in 'agent.yaml' is invalid: multus must be used alongside another primary cni selection.",
+			},
+		},
+		`unsupported cni`: {
+			Version:      "v1.30.3+rke2r1",
+			Nodes:        multiNode,
+			ServerConfig: "cni: weave\n",
+			AgentConfig:  "cni: multus, flannel\n",
+			ExpectedErrors: []string{
+				"server.yaml:1:6: The 'cni' option in 'server.yaml' contains an unsupported CNI 'weave', valid values are: none, canal, calico, cilium, multus",
+				"agent.yaml:1:6: The 'cni' option in 'agent.yaml' contains an unsupported CNI 'flannel', valid values are: none, canal, calico, cilium, multus",
+			},
+		},
+		`conflicting disable entries`: {
+			Version: "v1.30.3+rke2r1",
+			ServerConfig: `cni: multus,canal
+ingress-controller: traefik
+disable: [rke2-canal, rke2-multus, rke2-traefik, rke2-coredns, rke2-coredns, rke2-unknown]
+`,
+			ExpectedErrors: []string{
+				"server.yaml:3:10: The 'disable' option in 'server.yaml' conflicts with the cluster configuration: 'rke2-multus' is required by the selected CNI or ingress controller.",
+				"server.yaml:3:10: The 'disable' option in 'server.yaml' conflicts with the cluster configuration: 'rke2-canal' is required by the selected CNI or ingress controller.",
+				"server.yaml:3:10: The 'disable' option in 'server.yaml' conflicts with the cluster configuration: 'rke2-traefik' is required by the selected CNI or ingress controller.",
+			},
+			ExpectedWarnings: []string{
+				"server.yaml:3:10: The 'disable' option in 'server.yaml' contains duplicate entries: rke2-coredns",
+				"server.yaml:3:10: The 'disable' option in 'server.yaml' contains an unknown component 'rke2-unknown'.",
+			},
+		},
+		`disable entries of cnis without charts`: {
+			Version:      "v1.30.3+rke2r1",
+			ServerConfig: "cni: none\ndisable: [rke2-none, rke2-flannel]\n",
+			ExpectedWarnings: []string{
+				"server.yaml:2:10: The 'disable' option in 'server.yaml' contains an unknown component 'rke2-flannel'.",
+				"server.yaml:2:10: The 'disable' option in 'server.yaml' contains an unknown component 'rke2-none'.",
+			},
+		},
+		`disabled default cni`: {
+			Version:      "v1.30.3+rke2r1",
+			ServerConfig: "disable: rke2-cilium\n",
+			ExpectedErrors: []string{
+				"server.yaml:1:10: The 'disable' option in 'server.yaml' conflicts with the cluster configuration: 'rke2-cilium' is required by the selected CNI or ingress controller.",
+			},
+		},
+		`selinux mismatch`: {
+			Version:      "v1.30.3+k3s1",
+			Nodes:        multiNode,
+			ServerConfig: "selinux: true\n",
+			AgentConfig:  "debug: true\nselinux: false\n",
+			ExpectedErrors: []string{
+				"agent.yaml:2:10: The 'selinux' option in 'agent.yaml' must match the server configuration, which is set to 'true'.",
+			},
+		},
+		`selinux only enabled on agents`: {
+			Version:     "v1.30.3+k3s1",
+			Nodes:       multiNode,
+			AgentConfig: "selinux: true\n",
+			ExpectedErrors: []string{
+				"agent.yaml:1:10: The 'selinux' option in 'agent.yaml' must match the server configuration, which is set to 'false'.",
+			},
+		},
+		`agent config in single node cluster`: {
+			Version:      "v1.30.3+k3s1",
+			ServerConfig: "selinux: true\n",
+			AgentConfig:  "selinux: false\n",
+		},
+		`invalid yaml`: {
+			Version:      "v1.30.3+k3s1",
+			ServerConfig: "selinux: [true\n",
+			ExpectedErrors: []string{
+				"Kubernetes config file 'server.yaml' could not be parsed.",
+			},
+		},
+		`not a mapping`: {
+			Version:      "v1.30.3+k3s1",
+			ServerConfig: "- selinux\n",
+			ExpectedErrors: []string{
+				"Kubernetes config file 'server.yaml' must contain a mapping of configuration options.",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			configDir := t.TempDir()
+
+			k8sConfigDir := filepath.Join(configDir, "kubernetes", "config")
+			require.NoError(t, os.MkdirAll(k8sConfigDir, os.ModePerm))
+
+			if test.ServerConfig != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(k8sConfigDir, "server.yaml"), []byte(test.ServerConfig), 0o600))
+			}
+
+			if test.AgentConfig != "" {
+				require.NoError(t, os.WriteFile(filepath.Join(k8sConfigDir, "agent.yaml"), []byte(test.AgentConfig), 0o600))
+			}
+
+			ctx := &image.Context{
+				ImageConfigDir: configDir,
+				ImageDefinition: &image.Definition{
+					Kubernetes: image.Kubernetes{
+						Version: test.Version,
+						Nodes:   test.Nodes,
+					},
+				},
+			}
+
+			failures := validateKubernetesConfig(ctx)
+
+			var errorMessages, warningMessages []string
+			for _, failure := range failures {
+				message := failure.UserMessage
+
+				// Prefix the messages locating a value with the position relative to the config directory
+				if failure.Position.IsKnown() {
+					file, err := filepath.Rel(k8sConfigDir, failure.Position.File)
+					require.NoError(t, err)
+
+					message = fmt.Sprintf("%s:%d:%d: %s", file, failure.Position.Line, failure.Position.Column, message)
+				}
+
+				if failure.Severity == SeverityWarning {
+					warningMessages = append(warningMessages, message)
+				} else {
+					errorMessages = append(errorMessages, message)
+				}
+			}
+
+			assert.ElementsMatch(t, test.ExpectedErrors, errorMessages)
+			assert.ElementsMatch(t, test.ExpectedWarnings, warningMessages)
+		})
+	}
+}
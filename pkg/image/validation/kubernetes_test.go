@@ -41,7 +41,7 @@ func TestValidateKubernetes(t *testing.T) {
 		},
 		`all valid`: {
 			K8s: image.Kubernetes{
-				Version: "v1.30.3+k3s1",
+				Version: "v1.30.3+rke2r1",
 				Network: validNetwork,
 				Nodes: []image.Node{
 					{
@@ -218,6 +218,7 @@ func TestValidateNodes(t *testing.T) {
 			},
 			ExpectedFailedMessages: []string{
 				"The 'hostname' field is required for entries in the 'nodes' section.",
+				"Kubernetes clusters consisting of two server nodes cannot form a highly available architecture.",
 			},
 		},
 		`missing type`: {
@@ -337,6 +338,7 @@ func TestValidateNodes(t *testing.T) {
 			},
 			ExpectedFailedMessages: []string{
 				"Only one node may be specified as the cluster initializer.",
+				"Kubernetes clusters consisting of two server nodes cannot form a highly available architecture.",
 			},
 		},
 	}
@@ -360,6 +362,89 @@ func TestValidateNodes(t *testing.T) {
 	}
 }
 
+func TestValidateIngress(t *testing.T) {
+	tests := map[string]struct {
+		K8s                    image.Kubernetes
+		ExpectedFailedMessages []string
+	}{
+		`rke2`: {
+			K8s: image.Kubernetes{
+				Version: "v1.30.3+rke2r1",
+				Network: validNetwork,
+				Nodes: []image.Node{
+					{Hostname: "server", Type: image.KubernetesNodeTypeServer},
+					{Hostname: "agent", Type: image.KubernetesNodeTypeAgent},
+				},
+			},
+		},
+		`k3s single node`: {
+			K8s: image.Kubernetes{
+				Version: "v1.30.3+k3s1",
+			},
+		},
+		`k3s single node with virtual IP`: {
+			K8s: image.Kubernetes{
+				Version: "v1.30.3+k3s1",
+				Network: validNetwork,
+			},
+			ExpectedFailedMessages: []string{
+				"A Virtual IP address for the k3s cluster has been provided. " +
+					"An external IP address for the Ingress Controller (Traefik) must be manually configured.",
+			},
+		},
+		`k3s multi node`: {
+			K8s: image.Kubernetes{
+				Version: "v1.30.3+k3s1",
+				Network: validNetwork,
+				Nodes: []image.Node{
+					{Hostname: "server", Type: image.KubernetesNodeTypeServer},
+					{Hostname: "agent", Type: image.KubernetesNodeTypeAgent},
+				},
+			},
+			ExpectedFailedMessages: []string{
+				"An external IP address for the Ingress Controller (Traefik) must be manually configured in multi-node clusters.",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			k := test.K8s
+			failures := validateIngress(&k)
+			assert.Len(t, failures, len(test.ExpectedFailedMessages))
+
+			for i, expectedMessage := range test.ExpectedFailedMessages {
+				assert.Equal(t, expectedMessage, failures[i].UserMessage)
+				assert.Equal(t, SeverityWarning, failures[i].Severity)
+			}
+		})
+	}
+}
+
+func TestValidateArchitectureSupport(t *testing.T) {
+	rke2ARM := image.Definition{
+		Image:      image.Image{Arch: image.ArchTypeARM},
+		Kubernetes: image.Kubernetes{Version: "v1.30.3+rke2r1"},
+	}
+
+	failures := validateArchitectureSupport(&rke2ARM)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "RKE2 support for aarch64 platforms is limited and experimental.", failures[0].UserMessage)
+	assert.Equal(t, SeverityWarning, failures[0].Severity)
+
+	k3sARM := image.Definition{
+		Image:      image.Image{Arch: image.ArchTypeARM},
+		Kubernetes: image.Kubernetes{Version: "v1.30.3+k3s1"},
+	}
+	assert.Empty(t, validateArchitectureSupport(&k3sARM))
+
+	rke2X86 := image.Definition{
+		Image:      image.Image{Arch: image.ArchTypeX86},
+		Kubernetes: image.Kubernetes{Version: "v1.30.3+rke2r1"},
+	}
+	assert.Empty(t, validateArchitectureSupport(&rke2X86))
+}
+
 func TestValidateManifestURLs(t *testing.T) {
 	tests := map[string]struct {
 		K8s                    image.Kubernetes
@@ -436,6 +521,7 @@ func TestValidateHelmCharts(t *testing.T) {
 	tests := map[string]struct {
 		K8s                    image.Kubernetes
 		ExpectedFailedMessages []string
+		ExpectedPaths          []string
 	}{
 		`valid`: {
 			K8s: image.Kubernetes{
@@ -478,6 +564,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm charts defined with no Helm repositories defined.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm",
+			},
 		},
 		`helm chart no name`: {
 			K8s: image.Kubernetes{
@@ -500,6 +589,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm chart 'name' field must be defined.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.charts[0].name",
+			},
 		},
 		`helm chart undefined repository name`: {
 			K8s: image.Kubernetes{
@@ -527,6 +619,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm chart 'repositoryName' field for \"metallb\" must be defined.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.charts[1].repositoryName",
+			},
 		},
 		`helm chart no matching repository name`: {
 			K8s: image.Kubernetes{
@@ -554,6 +649,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm chart 'repositoryName' \"this-is-not-suse-edge\" for Helm chart \"metallb\" does not match the name of any defined repository.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.charts[1].repositoryName",
+			},
 		},
 		`helm chart no version`: {
 			K8s: image.Kubernetes{
@@ -576,6 +674,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm chart 'version' field for \"apache\" field must be defined.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.charts[0].version",
+			},
 		},
 		`helm chart create namespace no target`: {
 			K8s: image.Kubernetes{
@@ -599,6 +700,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm chart 'createNamespace' field for \"apache\" cannot be true without 'targetNamespace' being defined.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.charts[0].createNamespace",
+			},
 		},
 		`helm chart duplicate name`: {
 			K8s: image.Kubernetes{
@@ -626,6 +730,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"The 'helmCharts' field contains duplicate entries: apache",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.charts",
+			},
 		},
 		`helm chart invalid values file`: {
 			K8s: image.Kubernetes{
@@ -649,6 +756,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm chart 'valuesFile' field for \"apache\" must be the name of a valid yaml file ending in '.yaml' or '.yml'.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.charts[0].valuesFile",
+			},
 		},
 		`helm chart nonexistent values file`: {
 			K8s: image.Kubernetes{
@@ -672,6 +782,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm chart values file 'nonexistent.yaml' could not be found at 'kubernetes/helm/values/nonexistent.yaml'.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.charts[0].valuesFile",
+			},
 		},
 		`helm repository no name`: {
 			K8s: image.Kubernetes{
@@ -698,6 +811,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm repository 'name' field must be defined.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].name",
+			},
 		},
 		`helm repository no url`: {
 			K8s: image.Kubernetes{
@@ -720,6 +836,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm repository 'url' field for \"apache-repo\" must be defined.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].url",
+			},
 		},
 		`helm repository invalid url`: {
 			K8s: image.Kubernetes{
@@ -742,6 +861,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm repository 'url' field for \"apache-repo\" must begin with either 'oci://', 'http://', or 'https://'.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].url",
+			},
 		},
 		`helm repository username no password`: {
 			K8s: image.Kubernetes{
@@ -768,6 +890,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm repository 'password' field not defined for \"apache-repo\".",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].authentication.password",
+			},
 		},
 		`helm repository password no username`: {
 			K8s: image.Kubernetes{
@@ -794,6 +919,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm repository 'username' field not defined for \"apache-repo\".",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].authentication.username",
+			},
 		},
 		`helm repository both skipTLSVerify and plainHTTP true`: {
 			K8s: image.Kubernetes{
@@ -818,6 +946,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm repository 'plainHTTP' and 'skipTLSVerify' fields for \"apache-repo\" cannot both be true.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].skipTLSVerify",
+			},
 		},
 		`helm repository skipTLSVerify true for http`: {
 			K8s: image.Kubernetes{
@@ -842,6 +973,10 @@ func TestValidateHelmCharts(t *testing.T) {
 				"Helm repository 'url' field for \"suse-edge\" contains 'http://' but 'plainHTTP' field is false.",
 				"Helm repository 'url' field for \"suse-edge\" contains 'http://' but 'skipTLSVerify' field is true.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].url",
+				"kubernetes.helm.repositories[0].skipTLSVerify",
+			},
 		},
 		`helm repository plainHTTP false for http`: {
 			K8s: image.Kubernetes{
@@ -865,6 +1000,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm repository 'url' field for \"suse-edge\" contains 'http://' but 'plainHTTP' field is false.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].url",
+			},
 		},
 		`helm repository plainHTTP true for https`: {
 			K8s: image.Kubernetes{
@@ -888,6 +1026,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm repository 'url' field for \"suse-edge\" contains 'https://' but 'plainHTTP' field is true.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].plainHTTP",
+			},
 		},
 		`helm repository plainHTTP and ca file`: {
 			K8s: image.Kubernetes{
@@ -914,6 +1055,11 @@ func TestValidateHelmCharts(t *testing.T) {
 				"Helm repository 'url' field for \"suse-edge\" contains 'http://' but 'caFile' field is defined.",
 				"Helm repo cert file/bundle 'suse-edge.crt' could not be found at 'kubernetes/helm/certs/suse-edge.crt'.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].caFile",
+				"kubernetes.helm.repositories[0].caFile",
+				"kubernetes.helm.repositories[0].caFile",
+			},
 		},
 		`helm repository skipTLSVerify and ca file`: {
 			K8s: image.Kubernetes{
@@ -939,6 +1085,10 @@ func TestValidateHelmCharts(t *testing.T) {
 				"Helm repository 'caFile' field for \"suse-edge\" cannot be defined while 'skipTLSVerify' is true.",
 				"Helm repo cert file/bundle 'suse-edge.crt' could not be found at 'kubernetes/helm/certs/suse-edge.crt'.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].caFile",
+				"kubernetes.helm.repositories[0].caFile",
+			},
 		},
 		`helm repo nonexistent cert file`: {
 			K8s: image.Kubernetes{
@@ -962,6 +1112,9 @@ func TestValidateHelmCharts(t *testing.T) {
 			ExpectedFailedMessages: []string{
 				"Helm repo cert file/bundle 'nonexistent-apache.crt' could not be found at 'kubernetes/helm/certs/nonexistent-apache.crt'.",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].caFile",
+			},
 		},
 		`helm repo invalid cert file`: {
 			K8s: image.Kubernetes{
@@ -986,6 +1139,9 @@ func TestValidateHelmCharts(t *testing.T) {
 				"Helm chart 'caFile' field for \"apache-repo\" must be the name of a valid cert file/bundle with one of the " +
 					"following extensions: .pem, .crt, .cer",
 			},
+			ExpectedPaths: []string{
+				"kubernetes.helm.repositories[0].caFile",
+			},
 		},
 	}
 
@@ -995,14 +1151,17 @@ func TestValidateHelmCharts(t *testing.T) {
 			failures := validateHelm(&k, "kubernetes/helm/values", "kubernetes/helm/certs")
 			assert.Len(t, failures, len(test.ExpectedFailedMessages))
 
-			var foundMessages []string
+			var foundMessages, foundPaths []string
 			for _, foundValidation := range failures {
 				foundMessages = append(foundMessages, foundValidation.UserMessage)
+				foundPaths = append(foundPaths, foundValidation.Path)
 			}
 
 			for _, expectedMessage := range test.ExpectedFailedMessages {
 				assert.Contains(t, foundMessages, expectedMessage)
 			}
+
+			assert.ElementsMatch(t, test.ExpectedPaths, foundPaths)
 		})
 	}
 }
@@ -0,0 +1,331 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/combustion"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	networkComponent = "Network"
+	ethernetType     = "ethernet"
+)
+
+// hostNetworkConfig is the subset of the nmstate desired state checked during validation.
+type hostNetworkConfig struct {
+	Interfaces []networkInterface `yaml:"interfaces"`
+	Routes     struct {
+		Config []networkRoute `yaml:"config"`
+	} `yaml:"routes"`
+	DNSResolver struct {
+		Config struct {
+			Server []string `yaml:"server"`
+		} `yaml:"config"`
+	} `yaml:"dns-resolver"`
+}
+
+type networkInterface struct {
+	Name       string          `yaml:"name"`
+	Type       string          `yaml:"type"`
+	MACAddress string          `yaml:"mac-address"`
+	IPv4       networkIPConfig `yaml:"ipv4"`
+	IPv6       networkIPConfig `yaml:"ipv6"`
+}
+
+type networkIPConfig struct {
+	Address []networkAddress `yaml:"address"`
+}
+
+type networkAddress struct {
+	IP           string `yaml:"ip"`
+	PrefixLength int    `yaml:"prefix-length"`
+}
+
+type networkRoute struct {
+	Destination    string `yaml:"destination"`
+	NextHopAddress string `yaml:"next-hop-address"`
+}
+
+// hostNetwork is the parsed network configuration of a single host, named after its file.
+type hostNetwork struct {
+	file     string
+	hostname string
+	config   hostNetworkConfig
+}
+
+func validateNetwork(ctx *image.Context) []FailedValidation {
+	networkDir := combustion.NetworkConfigPath(ctx)
+
+	entries, err := os.ReadDir(networkDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return []FailedValidation{
+			{
+				UserMessage: "Network config directory could not be read.",
+				Error:       err,
+			},
+		}
+	}
+
+	if len(entries) == 0 {
+		return []FailedValidation{
+			{
+				UserMessage: "Network config directory must not be empty if present.",
+			},
+		}
+	}
+
+	// Custom scripts configure the network on their own, the host files are not used
+	if _, err = os.Stat(combustion.NetworkCustomScriptPath(ctx)); err == nil {
+		return nil
+	}
+
+	var failures []FailedValidation
+	var hosts []hostNetwork
+
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		host := hostNetwork{
+			file:     entry.Name(),
+			hostname: strings.TrimSuffix(entry.Name(), ext),
+		}
+
+		data, err := os.ReadFile(filepath.Join(networkDir, entry.Name()))
+		if err != nil {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Network config file '%s' could not be read.", host.file),
+				Error:       err,
+			})
+			continue
+		}
+
+		if err = yaml.Unmarshal(data, &host.config); err != nil {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Network config file '%s' could not be parsed.", host.file),
+				Error:       err,
+			})
+			continue
+		}
+
+		failures = append(failures, validateHostNetwork(&host)...)
+		hosts = append(hosts, host)
+	}
+
+	failures = append(failures, validateNetworkDuplicates(hosts)...)
+	failures = append(failures, validateNetworkNodes(&ctx.ImageDefinition.Kubernetes, hosts)...)
+
+	return failures
+}
+
+func validateHostNetwork(host *hostNetwork) []FailedValidation {
+	var failures []FailedValidation
+
+	var names, macs []string
+
+	for i, iface := range host.config.Interfaces {
+		// Unnamed interfaces are identified by their position in the list
+		label := iface.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Network config file '%s' must define a 'name' for interface %s.", host.file, label),
+			})
+		} else {
+			names = append(names, iface.Name)
+		}
+
+		if iface.MACAddress != "" {
+			if _, err := net.ParseMAC(iface.MACAddress); err != nil {
+				failures = append(failures, FailedValidation{
+					UserMessage: fmt.Sprintf("Network config file '%s' contains an invalid MAC address '%s' for interface '%s'.",
+						host.file, iface.MACAddress, label),
+				})
+			} else if iface.Type == ethernetType {
+				macs = append(macs, strings.ToLower(iface.MACAddress))
+			}
+		}
+
+		failures = append(failures, validateInterfaceAddresses(host.file, label, iface.IPv4.Address, false)...)
+		failures = append(failures, validateInterfaceAddresses(host.file, label, iface.IPv6.Address, true)...)
+	}
+
+	if duplicates := findDuplicates(names); len(duplicates) > 0 {
+		failures = append(failures, FailedValidation{
+			UserMessage: fmt.Sprintf("Network config file '%s' contains duplicate interface names: %s",
+				host.file, strings.Join(duplicates, ", ")),
+		})
+	}
+
+	if duplicates := findDuplicates(macs); len(duplicates) > 0 {
+		failures = append(failures, FailedValidation{
+			UserMessage: fmt.Sprintf("Network config file '%s' contains ethernet interfaces with duplicate MAC addresses: %s",
+				host.file, strings.Join(duplicates, ", ")),
+		})
+	}
+
+	for _, route := range host.config.Routes.Config {
+		if _, _, err := net.ParseCIDR(route.Destination); route.Destination != "" && err != nil {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Network config file '%s' contains an invalid route destination '%s', a CIDR is expected.",
+					host.file, route.Destination),
+			})
+		}
+
+		if route.NextHopAddress != "" && net.ParseIP(route.NextHopAddress) == nil {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Network config file '%s' contains an invalid route next hop address '%s'.",
+					host.file, route.NextHopAddress),
+			})
+		}
+	}
+
+	for _, server := range host.config.DNSResolver.Config.Server {
+		if net.ParseIP(server) == nil {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Network config file '%s' contains an invalid DNS server address '%s'.", host.file, server),
+			})
+		}
+	}
+
+	return failures
+}
+
+func validateInterfaceAddresses(file, iface string, addresses []networkAddress, ipv6 bool) []FailedValidation {
+	var failures []FailedValidation
+
+	family, maxPrefixLength := "IPv4", 32
+	if ipv6 {
+		family, maxPrefixLength = "IPv6", 128
+	}
+
+	for _, address := range addresses {
+		ip := net.ParseIP(address.IP)
+		if ip == nil || (ip.To4() == nil) != ipv6 {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Network config file '%s' contains an invalid %s address '%s' for interface '%s'.",
+					file, family, address.IP, iface),
+			})
+			continue
+		}
+
+		if address.PrefixLength < 0 || address.PrefixLength > maxPrefixLength {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Network config file '%s' contains an invalid prefix length %d for address '%s' of interface '%s', "+
+					"it must be between 0 and %d.", file, address.PrefixLength, address.IP, iface, maxPrefixLength),
+			})
+		}
+	}
+
+	return failures
+}
+
+// validateNetworkDuplicates checks that MAC and IP addresses are not shared between hosts.
+// The MAC addresses identify the host configuration to apply when the image boots.
+func validateNetworkDuplicates(hosts []hostNetwork) []FailedValidation {
+	var failures []FailedValidation
+
+	macHosts := map[string][]string{}
+	ipHosts := map[string][]string{}
+
+	for _, host := range hosts {
+		var macs, ips []string
+
+		for _, iface := range host.config.Interfaces {
+			if mac, err := net.ParseMAC(iface.MACAddress); err == nil {
+				macs = append(macs, mac.String())
+			}
+
+			for _, address := range slices.Concat(iface.IPv4.Address, iface.IPv6.Address) {
+				if ip := net.ParseIP(address.IP); ip != nil {
+					ips = append(ips, ip.String())
+				}
+			}
+		}
+
+		slices.Sort(macs)
+		for _, mac := range slices.Compact(macs) {
+			macHosts[mac] = append(macHosts[mac], host.file)
+		}
+
+		for _, duplicate := range findDuplicates(ips) {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Network config file '%s' assigns the IP address '%s' multiple times.", host.file, duplicate),
+			})
+		}
+
+		slices.Sort(ips)
+		for _, ip := range slices.Compact(ips) {
+			ipHosts[ip] = append(ipHosts[ip], host.file)
+		}
+	}
+
+	for _, mac := range sortedKeys(macHosts) {
+		if files := macHosts[mac]; len(files) > 1 {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("The MAC address '%s' is used in multiple network config files: %s", mac, strings.Join(files, ", ")),
+			})
+		}
+	}
+
+	for _, ip := range sortedKeys(ipHosts) {
+		if files := ipHosts[ip]; len(files) > 1 {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("The IP address '%s' is assigned in multiple network config files: %s", ip, strings.Join(files, ", ")),
+			})
+		}
+	}
+
+	return failures
+}
+
+// validateNetworkNodes checks that each Kubernetes node has a network configuration,
+// since the node hostnames are set from the matching network config file names.
+func validateNetworkNodes(k8s *image.Kubernetes, hosts []hostNetwork) []FailedValidation {
+	if len(k8s.Nodes) < 2 {
+		return nil
+	}
+
+	var failures []FailedValidation
+
+	for i, node := range k8s.Nodes {
+		if node.Hostname == "" {
+			continue
+		}
+
+		if !slices.ContainsFunc(hosts, func(host hostNetwork) bool { return host.hostname == node.Hostname }) {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Kubernetes node '%s' does not have a matching network config file, "+
+					"expected '%s.yaml' in the network directory.", node.Hostname, node.Hostname),
+				Path: fmt.Sprintf("kubernetes.nodes[%d].hostname", i),
+			})
+		}
+	}
+
+	return failures
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
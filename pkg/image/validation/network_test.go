@@ -0,0 +1,215 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+)
+
+const node1Network = `interfaces:
+- name: eth0
+  type: ethernet
+  state: up
+  mac-address: 34:8A:B1:4B:16:E1
+  ipv4:
+    address:
+    - ip: 192.168.122.50
+      prefix-length: 24
+    dhcp: false
+    enabled: true
+  ipv6:
+    address:
+    - ip: fd00::50
+      prefix-length: 64
+    enabled: true
+routes:
+  config:
+  - destination: 0.0.0.0/0
+    next-hop-address: 192.168.122.1
+    next-hop-interface: eth0
+dns-resolver:
+  config:
+    server:
+    - 192.168.122.1
+`
+
+const node2Network = `interfaces:
+- name: eth0
+  type: ethernet
+  mac-address: 34:8A:B1:4B:16:E2
+  ipv4:
+    address:
+    - ip: 192.168.122.51
+      prefix-length: 24
+`
+
+func TestValidateNetwork(t *testing.T) {
+	multiNode := []image.Node{
+		{Hostname: "node1", Type: image.KubernetesNodeTypeServer},
+		{Hostname: "node2", Type: image.KubernetesNodeTypeAgent},
+	}
+
+	tests := map[string]struct {
+		Files                  map[string]string
+		Nodes                  []image.Node
+		ExpectedFailedMessages []string
+		ExpectedPaths          []string
+	}{
+		`not configured`: {},
+		`valid`: {
+			Files: map[string]string{
+				"node1.yaml": node1Network,
+				"node2.yml":  node2Network,
+			},
+			Nodes: multiNode,
+		},
+		`empty directory`: {
+			Files: map[string]string{},
+			ExpectedFailedMessages: []string{
+				"Network config directory must not be empty if present.",
+			},
+		},
+		`custom script`: {
+			Files: map[string]string{
+				"configure-network.sh": "#!/bin/bash",
+				"node1.yaml":           "interfaces: [",
+			},
+			Nodes: multiNode,
+		},
+		`unparsable file`: {
+			Files: map[string]string{
+				"node1.yaml": "interfaces: [",
+			},
+			ExpectedFailedMessages: []string{
+				"Network config file 'node1.yaml' could not be parsed.",
+			},
+		},
+		`invalid host config`: {
+			Files: map[string]string{
+				"node1.yaml": `interfaces:
+- name: eth0
+  type: ethernet
+  mac-address: 34:8A:B1:4B:16:E1
+  ipv4:
+    address:
+    - ip: 192.168.122.300
+      prefix-length: 24
+    - ip: fd00::50
+      prefix-length: 24
+- name: eth0
+  type: ethernet
+  mac-address: 34:8a:b1:4b:16:e1
+  ipv4:
+    address:
+    - ip: 192.168.122.50
+      prefix-length: 33
+- type: ethernet
+  mac-address: not-a-mac
+  ipv6:
+    address:
+    - ip: 192.168.122.60
+      prefix-length: 64
+routes:
+  config:
+  - destination: 0.0.0.0
+    next-hop-address: gateway
+dns-resolver:
+  config:
+    server:
+    - dns.suse.com
+`,
+			},
+			ExpectedFailedMessages: []string{
+				"Network config file 'node1.yaml' contains an invalid IPv4 address '192.168.122.300' for interface 'eth0'.",
+				"Network config file 'node1.yaml' contains an invalid IPv4 address 'fd00::50' for interface 'eth0'.",
+				"Network config file 'node1.yaml' contains an invalid prefix length 33 for address '192.168.122.50' of interface 'eth0', it must be between 0 and 32.",
+				"Network config file 'node1.yaml' must define a 'name' for interface #3.",
+				"Network config file 'node1.yaml' contains an invalid MAC address 'not-a-mac' for interface '#3'.",
+				"Network config file 'node1.yaml' contains an invalid IPv6 address '192.168.122.60' for interface '#3'.",
+				"Network config file 'node1.yaml' contains duplicate interface names: eth0",
+				"Network config file 'node1.yaml' contains ethernet interfaces with duplicate MAC addresses: 34:8a:b1:4b:16:e1",
+				"Network config file 'node1.yaml' contains an invalid route destination '0.0.0.0', a CIDR is expected.",
+				"Network config file 'node1.yaml' contains an invalid route next hop address 'gateway'.",
+				"Network config file 'node1.yaml' contains an invalid DNS server address 'dns.suse.com'.",
+			},
+		},
+		`duplicates across hosts`: {
+			Files: map[string]string{
+				"node1.yaml": node1Network,
+				"node2.yaml": `interfaces:
+- name: eth0
+  type: ethernet
+  mac-address: 34:8a:b1:4b:16:e1
+  ipv4:
+    address:
+    - ip: 192.168.122.50
+      prefix-length: 24
+    - ip: 192.168.122.51
+      prefix-length: 24
+- name: eth1
+  type: ethernet
+  ipv4:
+    address:
+    - ip: 192.168.122.51
+      prefix-length: 24
+`,
+			},
+			ExpectedFailedMessages: []string{
+				"Network config file 'node2.yaml' assigns the IP address '192.168.122.51' multiple times.",
+				"The MAC address '34:8a:b1:4b:16:e1' is used in multiple network config files: node1.yaml, node2.yaml",
+				"The IP address '192.168.122.50' is assigned in multiple network config files: node1.yaml, node2.yaml",
+			},
+		},
+		`missing node config`: {
+			Files: map[string]string{
+				"node1.yaml": node1Network,
+			},
+			Nodes: multiNode,
+			ExpectedFailedMessages: []string{
+				"Kubernetes node 'node2' does not have a matching network config file, expected 'node2.yaml' in the network directory.",
+			},
+			ExpectedPaths: []string{"kubernetes.nodes[1].hostname"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			configDir := t.TempDir()
+
+			if test.Files != nil {
+				networkDir := filepath.Join(configDir, "network")
+				require.NoError(t, os.Mkdir(networkDir, os.ModePerm))
+
+				for file, contents := range test.Files {
+					require.NoError(t, os.WriteFile(filepath.Join(networkDir, file), []byte(contents), 0o600))
+				}
+			}
+
+			ctx := &image.Context{
+				ImageConfigDir: configDir,
+				ImageDefinition: &image.Definition{
+					Kubernetes: image.Kubernetes{
+						Nodes: test.Nodes,
+					},
+				},
+			}
+
+			failures := validateNetwork(ctx)
+
+			var foundMessages, foundPaths []string
+			for _, failure := range failures {
+				foundMessages = append(foundMessages, failure.UserMessage)
+				if failure.Path != "" {
+					foundPaths = append(foundPaths, failure.Path)
+				}
+			}
+
+			assert.ElementsMatch(t, test.ExpectedFailedMessages, foundMessages)
+			assert.ElementsMatch(t, test.ExpectedPaths, foundPaths)
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/combustion"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+)
+
+const (
+	nodeOverridesComponent = "Node Overrides"
+)
+
+var (
+	hostnameRegex   = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?$`)
+	macAddressRegex = regexp.MustCompile(`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`)
+)
+
+func validateNodeOverrides(ctx *image.Context) []FailedValidation {
+	def := ctx.ImageDefinition
+
+	var failures []FailedValidation
+
+	seenIDs := make(map[string]bool)
+	for i, override := range def.NodeOverrides {
+		path := fmt.Sprintf("nodeOverrides[%d]", i)
+
+		failures = append(failures, validateNodeOverrideID(override, path)...)
+
+		id := strings.ToLower(override.ID())
+		if id != "" && seenIDs[id] {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Duplicate node override found: %s", override.ID()),
+				Path:        path,
+			})
+		}
+		seenIDs[id] = true
+
+		failures = append(failures, validateUserList(override.Users, path+".users")...)
+		failures = append(failures, validateSystemdUnits(override.Systemd, path+".systemd")...)
+
+		for j, user := range override.Users {
+			if slices.ContainsFunc(def.OperatingSystem.Users, func(u image.OperatingSystemUser) bool {
+				return u.Username == user.Username
+			}) {
+				failures = append(failures, FailedValidation{
+					UserMessage: fmt.Sprintf("User '%s' is already configured for all nodes under 'operatingSystem.users'.", user.Username),
+					Path:        fmt.Sprintf("%s.users[%d].username", path, j),
+				})
+			}
+		}
+
+		if override.ID() != "" {
+			failures = append(failures, validateNodeOverrideDir(ctx, override, path)...)
+		}
+	}
+
+	failures = append(failures, validateNodeOverridesDir(ctx)...)
+
+	return failures
+}
+
+func validateNodeOverrideID(override image.NodeOverride, path string) []FailedValidation {
+	var failures []FailedValidation
+
+	switch {
+	case override.Hostname == "" && override.MACAddress == "":
+		failures = append(failures, FailedValidation{
+			UserMessage: "Either the 'hostname' or the 'macAddress' field is required for all entries under 'nodeOverrides'.",
+			Path:        path,
+		})
+	case override.Hostname != "" && override.MACAddress != "":
+		failures = append(failures, FailedValidation{
+			UserMessage: "Only one of the 'hostname' and 'macAddress' fields may be specified for an entry under 'nodeOverrides'.",
+			Path:        path,
+		})
+	case override.Hostname != "" && !hostnameRegex.MatchString(override.Hostname):
+		failures = append(failures, FailedValidation{
+			UserMessage: fmt.Sprintf("Invalid hostname '%s', only letters, digits, hyphens and dots are allowed.", override.Hostname),
+			Path:        path + ".hostname",
+		})
+	case override.MACAddress != "" && !macAddressRegex.MatchString(override.MACAddress):
+		failures = append(failures, FailedValidation{
+			UserMessage: fmt.Sprintf("Invalid MAC address '%s', expected the format 'xx:xx:xx:xx:xx:xx'.", override.MACAddress),
+			Path:        path + ".macAddress",
+		})
+	}
+
+	return failures
+}
+
+// validateNodeOverrideDir checks the files provided for a single node in the image configuration directory.
+func validateNodeOverrideDir(ctx *image.Context, override image.NodeOverride, path string) []FailedValidation {
+	var failures []FailedValidation
+
+	nodeDir := combustion.NodeOverridePath(ctx, override)
+	entries, err := os.ReadDir(nodeDir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return []FailedValidation{
+			{
+				UserMessage: fmt.Sprintf("Node override directory for '%s' could not be read.", override.ID()),
+				Error:       err,
+				Path:        path,
+			},
+		}
+	}
+
+	allowed := []string{combustion.NodeOSFilesPath(ctx, override), combustion.NodeScriptsPath(ctx, override)}
+	for _, entry := range entries {
+		entryPath := filepath.Join(nodeDir, entry.Name())
+		if !entry.IsDir() || !slices.Contains(allowed, entryPath) {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Unexpected entry '%s' in the node override directory for '%s', "+
+					"only 'os-files' and 'scripts' directories are supported.", entry.Name(), override.ID()),
+				Path: path,
+			})
+			continue
+		}
+
+		dirEntries, readErr := os.ReadDir(entryPath)
+		if readErr != nil {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Directory '%s' could not be read.", entryPath),
+				Error:       readErr,
+				Path:        path,
+			})
+			continue
+		}
+
+		if len(dirEntries) == 0 {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Directory '%s' should not be present if it is empty.", entryPath),
+				Path:        path,
+			})
+		}
+	}
+
+	failures = append(failures, validateNodeScriptNames(ctx, override, path)...)
+
+	return failures
+}
+
+// validateNodeScriptNames ensures node scripts can be dispatched under their names
+// without replacing the custom scripts which run on all nodes.
+func validateNodeScriptNames(ctx *image.Context, override image.NodeOverride, path string) []FailedValidation {
+	var failures []FailedValidation
+
+	nodeScripts, err := os.ReadDir(combustion.NodeScriptsPath(ctx, override))
+	if err != nil {
+		return nil
+	}
+
+	sharedScripts, err := os.ReadDir(combustion.CustomScriptsPath(ctx))
+	if err != nil {
+		return nil
+	}
+
+	for _, script := range nodeScripts {
+		if slices.ContainsFunc(sharedScripts, func(shared os.DirEntry) bool { return shared.Name() == script.Name() }) {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Script '%s' for node '%s' has the same name as a custom script.", script.Name(), override.ID()),
+				Path:        path,
+			})
+		}
+	}
+
+	return failures
+}
+
+// validateNodeOverridesDir ensures every node directory in the image configuration belongs to a node override.
+func validateNodeOverridesDir(ctx *image.Context) []FailedValidation {
+	var failures []FailedValidation
+
+	entries, err := os.ReadDir(combustion.NodeOverridesPath(ctx))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return []FailedValidation{
+			{
+				UserMessage: "Node overrides directory could not be read.",
+				Error:       err,
+			},
+		}
+	}
+
+	for _, entry := range entries {
+		if !slices.ContainsFunc(ctx.ImageDefinition.NodeOverrides, func(override image.NodeOverride) bool {
+			return override.ID() == entry.Name()
+		}) {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Node overrides directory contains '%s', which does not match "+
+					"the hostname or MAC address of any entry under 'nodeOverrides'.", entry.Name()),
+			})
+		}
+	}
+
+	return failures
+}
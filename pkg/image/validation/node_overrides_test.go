@@ -0,0 +1,168 @@
+package validation
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+)
+
+func TestValidateNodeOverrides(t *testing.T) {
+	validUser := image.OperatingSystemUser{
+		Username:          "alpha",
+		EncryptedPassword: "alpha123",
+	}
+
+	tests := map[string]struct {
+		Definition             image.Definition
+		ExpectedFailedMessages []string
+	}{
+		`no overrides`: {
+			Definition: image.Definition{},
+		},
+		`all valid`: {
+			Definition: image.Definition{
+				NodeOverrides: []image.NodeOverride{
+					{
+						Hostname: "node1.example.com",
+						Users:    []image.OperatingSystemUser{validUser},
+					},
+					{
+						MACAddress: "34:8A:B1:4B:16:E2",
+						Systemd:    image.Systemd{Enable: []string{"sshd"}},
+					},
+				},
+			},
+		},
+		`missing identifier`: {
+			Definition: image.Definition{
+				NodeOverrides: []image.NodeOverride{{}},
+			},
+			ExpectedFailedMessages: []string{
+				"Either the 'hostname' or the 'macAddress' field is required for all entries under 'nodeOverrides'.",
+			},
+		},
+		`both identifiers`: {
+			Definition: image.Definition{
+				NodeOverrides: []image.NodeOverride{
+					{Hostname: "node1", MACAddress: "34:8a:b1:4b:16:e2"},
+				},
+			},
+			ExpectedFailedMessages: []string{
+				"Only one of the 'hostname' and 'macAddress' fields may be specified for an entry under 'nodeOverrides'.",
+			},
+		},
+		`invalid identifiers`: {
+			Definition: image.Definition{
+				NodeOverrides: []image.NodeOverride{
+					{Hostname: "node'1"},
+					{MACAddress: "34-8a-b1-4b-16-e2"},
+				},
+			},
+			ExpectedFailedMessages: []string{
+				"Invalid hostname 'node'1', only letters, digits, hyphens and dots are allowed.",
+				"Invalid MAC address '34-8a-b1-4b-16-e2', expected the format 'xx:xx:xx:xx:xx:xx'.",
+			},
+		},
+		`duplicate overrides`: {
+			Definition: image.Definition{
+				NodeOverrides: []image.NodeOverride{
+					{MACAddress: "34:8a:b1:4b:16:e2"},
+					{MACAddress: "34:8A:B1:4B:16:E2"},
+				},
+			},
+			ExpectedFailedMessages: []string{
+				"Duplicate node override found: 34:8A:B1:4B:16:E2",
+			},
+		},
+		`invalid users and systemd`: {
+			Definition: image.Definition{
+				OperatingSystem: image.OperatingSystem{
+					Users: []image.OperatingSystemUser{validUser},
+				},
+				NodeOverrides: []image.NodeOverride{
+					{
+						Hostname: "node1",
+						Users:    []image.OperatingSystemUser{validUser, {Username: "beta"}},
+						Systemd:  image.Systemd{Enable: []string{"sshd"}, Disable: []string{"sshd"}},
+					},
+				},
+			},
+			ExpectedFailedMessages: []string{
+				"User 'alpha' is already configured for all nodes under 'operatingSystem.users'.",
+				"User 'beta' must have either a password or at least one SSH key.",
+				"Systemd conflict found, 'sshd' is both enabled and disabled.",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := image.Context{
+				ImageConfigDir:  t.TempDir(),
+				ImageDefinition: &test.Definition,
+			}
+			failures := validateNodeOverrides(&ctx)
+			assert.Len(t, failures, len(test.ExpectedFailedMessages))
+
+			var foundMessages []string
+			for _, foundValidation := range failures {
+				foundMessages = append(foundMessages, foundValidation.UserMessage)
+			}
+
+			for _, expectedMessage := range test.ExpectedFailedMessages {
+				assert.Contains(t, foundMessages, expectedMessage)
+			}
+		})
+	}
+}
+
+func TestValidateNodeOverridesDirectory(t *testing.T) {
+	configDir := t.TempDir()
+
+	mkdir := func(path ...string) {
+		require.NoError(t, os.MkdirAll(filepath.Join(append([]string{configDir}, path...)...), os.ModePerm))
+	}
+	writeFile := func(path ...string) {
+		require.NoError(t, os.WriteFile(filepath.Join(append([]string{configDir}, path...)...), nil, 0o600))
+	}
+
+	mkdir("custom", "scripts")
+	writeFile("custom", "scripts", "foo.sh")
+
+	mkdir("node-overrides", "node1", "os-files", "etc")
+	writeFile("node-overrides", "node1", "os-files", "etc", "motd")
+	mkdir("node-overrides", "node1", "scripts")
+	writeFile("node-overrides", "node1", "scripts", "foo.sh")
+	writeFile("node-overrides", "node1", "notes.txt")
+
+	mkdir("node-overrides", "node2", "scripts")
+	mkdir("node-overrides", "node3")
+
+	ctx := image.Context{
+		ImageConfigDir: configDir,
+		ImageDefinition: &image.Definition{
+			NodeOverrides: []image.NodeOverride{
+				{Hostname: "node1"},
+				{Hostname: "node2"},
+			},
+		},
+	}
+
+	failures := validateNodeOverrides(&ctx)
+
+	var foundMessages []string
+	for _, foundValidation := range failures {
+		foundMessages = append(foundMessages, foundValidation.UserMessage)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"Unexpected entry 'notes.txt' in the node override directory for 'node1', only 'os-files' and 'scripts' directories are supported.",
+		"Script 'foo.sh' for node 'node1' has the same name as a custom script.",
+		"Directory '" + filepath.Join(configDir, "node-overrides", "node2", "scripts") + "' should not be present if it is empty.",
+		"Node overrides directory contains 'node3', which does not match the hostname or MAC address of any entry under 'nodeOverrides'.",
+	}, foundMessages)
+}
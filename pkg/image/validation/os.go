@@ -34,8 +34,9 @@ func validateKernelArgs(os *image.OperatingSystem) []FailedValidation {
 	var failures []FailedValidation
 
 	seenKeys := make(map[string]bool)
-	for _, arg := range os.KernelArgs {
+	for i, arg := range os.KernelArgs {
 		key := arg
+		path := fmt.Sprintf("operatingSystem.kernelArgs[%d]", i)
 
 		parts := strings.SplitN(arg, "=", 2)
 		if len(parts) == 2 {
@@ -44,12 +45,14 @@ func validateKernelArgs(os *image.OperatingSystem) []FailedValidation {
 			if key == "" || value == "" {
 				failures = append(failures, FailedValidation{
 					UserMessage: "Kernel arguments must be specified as 'key=value'.",
+					Path:        path,
 				})
 			}
 
 			if (key == "fips" && value == "1") && !os.EnableFips {
 				failures = append(failures, FailedValidation{
 					UserMessage: "FIPS mode has been specified via kernel arguments, please use the 'enableFIPS: true' option instead.",
+					Path:        path,
 				})
 			}
 		}
@@ -57,6 +60,7 @@ func validateKernelArgs(os *image.OperatingSystem) []FailedValidation {
 		if _, exists := seenKeys[key]; exists {
 			failures = append(failures, FailedValidation{
 				UserMessage: fmt.Sprintf("Duplicate kernel argument found: %s", key),
+				Path:        path,
 			})
 		}
 		seenKeys[key] = true
@@ -66,30 +70,37 @@ func validateKernelArgs(os *image.OperatingSystem) []FailedValidation {
 }
 
 func validateSystemd(os *image.OperatingSystem) []FailedValidation {
+	return validateSystemdUnits(os.Systemd, "operatingSystem.systemd")
+}
+
+func validateSystemdUnits(systemd image.Systemd, path string) []FailedValidation {
 	var failures []FailedValidation
 
-	if duplicates := findDuplicates(os.Systemd.Enable); len(duplicates) > 0 {
+	if duplicates := findDuplicates(systemd.Enable); len(duplicates) > 0 {
 		duplicateValues := strings.Join(duplicates, ", ")
 		msg := fmt.Sprintf("Systemd enable list contains duplicate entries: %s", duplicateValues)
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        path + ".enable",
 		})
 	}
 
-	if duplicates := findDuplicates(os.Systemd.Disable); len(duplicates) > 0 {
+	if duplicates := findDuplicates(systemd.Disable); len(duplicates) > 0 {
 		duplicateValues := strings.Join(duplicates, ", ")
 		msg := fmt.Sprintf("Systemd disable list contains duplicate entries: %s", duplicateValues)
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        path + ".disable",
 		})
 	}
 
-	for _, enableItem := range os.Systemd.Enable {
-		for _, disableItem := range os.Systemd.Disable {
+	for _, enableItem := range systemd.Enable {
+		for i, disableItem := range systemd.Disable {
 			if enableItem == disableItem {
 				msg := fmt.Sprintf("Systemd conflict found, '%s' is both enabled and disabled.", enableItem)
 				failures = append(failures, FailedValidation{
 					UserMessage: msg,
+					Path:        fmt.Sprintf("%s.disable[%d]", path, i),
 				})
 			}
 		}
@@ -104,10 +115,13 @@ func validateGroups(os *image.OperatingSystem) []FailedValidation {
 	// The script is idempotent and will not fail on creating a duplicate group,
 	// but for consistency validate that duplicates aren't in the definition.
 	seenGroupNames := make(map[string]bool)
-	for _, group := range os.Groups {
+	for i, group := range os.Groups {
+		path := fmt.Sprintf("operatingSystem.groups[%d]", i)
+
 		if group.Name == "" {
 			failures = append(failures, FailedValidation{
 				UserMessage: "The 'name' field is required for all entries under 'groups'.",
+				Path:        path,
 			})
 		}
 
@@ -115,6 +129,7 @@ func validateGroups(os *image.OperatingSystem) []FailedValidation {
 			msg := fmt.Sprintf("Duplicate group name found: %s", group.Name)
 			failures = append(failures, FailedValidation{
 				UserMessage: msg,
+				Path:        path + ".name",
 			})
 		}
 		seenGroupNames[group.Name] = true
@@ -124,13 +139,20 @@ func validateGroups(os *image.OperatingSystem) []FailedValidation {
 }
 
 func validateUsers(os *image.OperatingSystem) []FailedValidation {
+	return validateUserList(os.Users, "operatingSystem.users")
+}
+
+func validateUserList(users []image.OperatingSystemUser, usersPath string) []FailedValidation {
 	var failures []FailedValidation
 
 	seenUsernames := make(map[string]bool)
-	for _, user := range os.Users {
+	for i, user := range users {
+		path := fmt.Sprintf("%s[%d]", usersPath, i)
+
 		if user.Username == "" {
 			failures = append(failures, FailedValidation{
 				UserMessage: "The 'username' field is required for all entries under 'users'.",
+				Path:        path,
 			})
 		}
 
@@ -138,12 +160,14 @@ func validateUsers(os *image.OperatingSystem) []FailedValidation {
 			msg := fmt.Sprintf("User '%s' must have either a password or at least one SSH key.", user.Username)
 			failures = append(failures, FailedValidation{
 				UserMessage: msg,
+				Path:        path,
 			})
 		}
 
 		if !user.CreateHomeDir && len(user.SSHKeys) > 0 {
 			failures = append(failures, FailedValidation{
 				UserMessage: "The 'createHomeDir' attribute must be set to 'true' if at least one SSH key is specified.",
+				Path:        path + ".createHomeDir",
 			})
 		}
 
@@ -151,6 +175,7 @@ func validateUsers(os *image.OperatingSystem) []FailedValidation {
 			msg := fmt.Sprintf("Duplicate username found: %s", user.Username)
 			failures = append(failures, FailedValidation{
 				UserMessage: msg,
+				Path:        path + ".username",
 			})
 		}
 		seenUsernames[user.Username] = true
@@ -168,16 +193,19 @@ func validateSuma(os *image.OperatingSystem) []FailedValidation {
 	if os.Suma.Host == "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: "The 'host' field is required for the 'suma' section.",
+			Path:        "operatingSystem.suma",
 		})
 	}
 	if strings.HasPrefix(os.Suma.Host, "http") {
 		failures = append(failures, FailedValidation{
 			UserMessage: "The suma 'host' field may not contain 'http://' or 'https://'",
+			Path:        "operatingSystem.suma.host",
 		})
 	}
 	if os.Suma.ActivationKey == "" {
 		failures = append(failures, FailedValidation{
 			UserMessage: "The 'activationKey' field is required for the 'suma' section.",
+			Path:        "operatingSystem.suma",
 		})
 	}
 
@@ -187,9 +215,10 @@ func validateSuma(os *image.OperatingSystem) []FailedValidation {
 func validatePackages(os *image.OperatingSystem) []FailedValidation {
 	var failures []FailedValidation
 
-	if slices.Contains(os.Packages.PKGList, "") {
+	if i := slices.Index(os.Packages.PKGList, ""); i >= 0 {
 		failures = append(failures, FailedValidation{
 			UserMessage: "The 'packageList' field cannot contain empty values.",
+			Path:        fmt.Sprintf("operatingSystem.packages.packageList[%d]", i),
 		})
 	}
 
@@ -198,6 +227,24 @@ func validatePackages(os *image.OperatingSystem) []FailedValidation {
 		msg := fmt.Sprintf("The 'packageList' field contains duplicate packages: %s", duplicateValues)
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        "operatingSystem.packages.packageList",
+		})
+	}
+
+	if os.Packages.NoGPGCheck {
+		failures = append(failures, FailedValidation{
+			UserMessage: "Disabling GPG validation of packages is intended for development purposes only.",
+			Path:        "operatingSystem.packages.noGPGCheck",
+			Severity:    SeverityWarning,
+		})
+	}
+
+	if len(os.Packages.PKGList) > 0 && os.Packages.RegCode == "" && len(os.Packages.AdditionalRepos) == 0 {
+		failures = append(failures, FailedValidation{
+			UserMessage: "No registration code or additional repositories provided, " +
+				"package resolution may fail if the base image is SUSE Linux Micro.",
+			Path:     "operatingSystem.packages",
+			Severity: SeverityWarning,
 		})
 	}
 
@@ -206,11 +253,12 @@ func validatePackages(os *image.OperatingSystem) []FailedValidation {
 	if len(os.Packages.AdditionalRepos) > 0 {
 		var repoURLs []string
 
-		for _, repo := range os.Packages.AdditionalRepos {
+		for i, repo := range os.Packages.AdditionalRepos {
 			if repo.URL == "" {
 				msg := "The 'url' field is required for all entries under 'additionalRepos'."
 				failures = append(failures, FailedValidation{
 					UserMessage: msg,
+					Path:        fmt.Sprintf("operatingSystem.packages.additionalRepos[%d]", i),
 				})
 			}
 
@@ -222,6 +270,7 @@ func validatePackages(os *image.OperatingSystem) []FailedValidation {
 			msg := fmt.Sprintf("The 'additionalRepos' field contains duplicate repos: %s", duplicateValues)
 			failures = append(failures, FailedValidation{
 				UserMessage: msg,
+				Path:        "operatingSystem.packages.additionalRepos",
 			})
 		}
 	}
@@ -232,10 +281,15 @@ func validatePackages(os *image.OperatingSystem) []FailedValidation {
 func validateIsoConfig(def *image.Definition) []FailedValidation {
 	var failures []FailedValidation
 
-	if def.Image.ImageType != image.TypeISO && def.OperatingSystem.IsoConfiguration.InstallDevice != "" {
+	isISO := func(target image.Image) bool {
+		return target.ImageType == image.TypeISO
+	}
+
+	if def.OperatingSystem.IsoConfiguration.InstallDevice != "" && !def.Image.HasTarget(isISO) {
 		msg := fmt.Sprintf("The 'isoConfiguration/installDevice' field can only be used when 'imageType' is '%s'.", image.TypeISO)
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        "operatingSystem.isoConfiguration.installDevice",
 		})
 	}
 
@@ -245,28 +299,52 @@ func validateIsoConfig(def *image.Definition) []FailedValidation {
 func validateRawConfig(def *image.Definition) []FailedValidation {
 	var failures []FailedValidation
 
-	if def.OperatingSystem.RawConfiguration.DiskSize == "" {
-		return nil
+	rawConfig := def.OperatingSystem.RawConfiguration
+
+	isCompressible := func(target image.Image) bool {
+		return target.ImageType == image.TypeQCOW2 || target.ImageType == image.TypeVMDK
+	}
+	isISO := func(target image.Image) bool {
+		return target.ImageType == image.TypeISO
+	}
+
+	if rawConfig.Compress && !def.Image.HasTarget(isCompressible) {
+		msg := fmt.Sprintf("The 'rawConfiguration/compress' field can only be used when 'imageType' is '%s' or '%s'.",
+			image.TypeQCOW2, image.TypeVMDK)
+		failures = append(failures, FailedValidation{
+			UserMessage: msg,
+			Path:        "operatingSystem.rawConfiguration.compress",
+		})
+	}
+
+	if rawConfig.DiskSize == "" {
+		return failures
 	}
 
-	if def.Image.ImageType != image.TypeRAW {
-		msg := fmt.Sprintf("The 'rawConfiguration/diskSize' field can only be used when 'imageType' is '%s'.", image.TypeRAW)
+	if !def.Image.HasTarget(image.Image.IsDiskImage) {
+		msg := fmt.Sprintf("The 'rawConfiguration/diskSize' field can only be used when 'imageType' is one of: %s",
+			strings.Join([]string{image.TypeRAW, image.TypeQCOW2, image.TypeVMDK, image.TypeVHDX}, ", "))
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        "operatingSystem.rawConfiguration.diskSize",
 		})
 	}
 
-	if def.OperatingSystem.IsoConfiguration.InstallDevice != "" {
+	// Both sections are required when building ISO and RAW based images from the same definition
+	bothTypesBuilt := def.Image.HasTarget(isISO) && def.Image.HasTarget(image.Image.IsDiskImage)
+	if def.OperatingSystem.IsoConfiguration.InstallDevice != "" && !bothTypesBuilt {
 		msg := "You cannot simultaneously configure rawConfiguration and isoConfiguration, regardless of image type."
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        "operatingSystem.rawConfiguration",
 		})
 	}
 
-	if !def.OperatingSystem.RawConfiguration.DiskSize.IsValid() {
+	if !rawConfig.DiskSize.IsValid() {
 		msg := "The 'rawConfiguration/diskSize' field must be an integer followed by a suffix of either 'M', 'G', or 'T'."
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        "operatingSystem.rawConfiguration.diskSize",
 		})
 	}
 
@@ -284,6 +362,7 @@ func validateTimeSync(os *image.OperatingSystem) []FailedValidation {
 		msg := "If you're wanting to wait for NTP synchronization at boot, please ensure that you provide at least one NTP time source."
 		failures = append(failures, FailedValidation{
 			UserMessage: msg,
+			Path:        "operatingSystem.time.ntp",
 		})
 	}
 
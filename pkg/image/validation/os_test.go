@@ -106,6 +106,7 @@ func TestValidateOperatingSystem(t *testing.T) {
 				fmt.Sprintf("The 'isoConfiguration/installDevice' field can only be used when 'imageType' is '%s'.", image.TypeISO),
 				"The 'rawConfiguration/diskSize' field must be an integer followed by a suffix of either 'M', 'G', or 'T'.",
 				"You cannot simultaneously configure rawConfiguration and isoConfiguration, regardless of image type.",
+				"No registration code or additional repositories provided, package resolution may fail if the base image is SUSE Linux Micro.",
 			},
 		},
 	}
@@ -481,6 +482,7 @@ func TestPackages(t *testing.T) {
 		`empty package`: {
 			Packages: image.Packages{
 				PKGList: []string{"foo", "bar", ""},
+				RegCode: "regcode",
 			},
 			ExpectedFailedMessages: []string{
 				"The 'packageList' field cannot contain empty values.",
@@ -528,6 +530,24 @@ func TestPackages(t *testing.T) {
 				"The 'url' field is required for all entries under 'additionalRepos'.",
 			},
 		},
+		`disabled GPG validation`: {
+			Packages: image.Packages{
+				PKGList:    []string{"foo"},
+				RegCode:    "regcode",
+				NoGPGCheck: true,
+			},
+			ExpectedFailedMessages: []string{
+				"Disabling GPG validation of packages is intended for development purposes only.",
+			},
+		},
+		`no package sources`: {
+			Packages: image.Packages{
+				PKGList: []string{"foo"},
+			},
+			ExpectedFailedMessages: []string{
+				"No registration code or additional repositories provided, package resolution may fail if the base image is SUSE Linux Micro.",
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -685,6 +705,95 @@ func TestValidateRawConfiguration(t *testing.T) {
 				"The 'rawConfiguration/diskSize' field must be an integer followed by a suffix of either 'M', 'G', or 'T'.",
 			},
 		},
+		`iso and raw configured for multiple outputs`: {
+			Definition: image.Definition{
+				Image: image.Image{
+					Outputs: []image.ImageOutput{
+						{ImageType: image.TypeISO},
+						{ImageType: image.TypeRAW},
+					},
+				},
+				OperatingSystem: image.OperatingSystem{
+					IsoConfiguration: image.IsoConfiguration{
+						InstallDevice: "/dev/sda",
+					},
+					RawConfiguration: image.RawConfiguration{
+						DiskSize: "64G",
+					},
+				},
+			},
+		},
+		`diskSize specified for qcow2`: {
+			Definition: image.Definition{
+				Image: image.Image{
+					ImageType: image.TypeQCOW2,
+				},
+				OperatingSystem: image.OperatingSystem{
+					RawConfiguration: image.RawConfiguration{
+						DiskSize: "64G",
+						Compress: true,
+					},
+				},
+			},
+		},
+		`diskSize specified for iso`: {
+			Definition: image.Definition{
+				Image: image.Image{
+					ImageType: image.TypeISO,
+				},
+				OperatingSystem: image.OperatingSystem{
+					RawConfiguration: image.RawConfiguration{
+						DiskSize: "64G",
+					},
+				},
+			},
+			ExpectedFailedMessages: []string{
+				"The 'rawConfiguration/diskSize' field can only be used when 'imageType' is one of: raw, qcow2, vmdk, vhdx",
+			},
+		},
+		`compress specified for vmdk`: {
+			Definition: image.Definition{
+				Image: image.Image{
+					ImageType: image.TypeVMDK,
+				},
+				OperatingSystem: image.OperatingSystem{
+					RawConfiguration: image.RawConfiguration{
+						Compress: true,
+					},
+				},
+			},
+		},
+		`compress invalid for vhdx`: {
+			Definition: image.Definition{
+				Image: image.Image{
+					ImageType: image.TypeVHDX,
+				},
+				OperatingSystem: image.OperatingSystem{
+					RawConfiguration: image.RawConfiguration{
+						Compress: true,
+					},
+				},
+			},
+			ExpectedFailedMessages: []string{
+				"The 'rawConfiguration/compress' field can only be used when 'imageType' is 'qcow2' or 'vmdk'.",
+			},
+		},
+		`compress invalid for raw`: {
+			Definition: image.Definition{
+				Image: image.Image{
+					ImageType: image.TypeRAW,
+				},
+				OperatingSystem: image.OperatingSystem{
+					RawConfiguration: image.RawConfiguration{
+						DiskSize: "64G",
+						Compress: true,
+					},
+				},
+			},
+			ExpectedFailedMessages: []string{
+				"The 'rawConfiguration/compress' field can only be used when 'imageType' is 'qcow2' or 'vmdk'.",
+			},
+		},
 		`diskSize invalid as no number provided`: {
 			Definition: image.Definition{
 				Image: image.Image{
@@ -22,10 +22,13 @@ func validateContainerImages(ear *image.EmbeddedArtifactRegistry) []FailedValida
 	var failures []FailedValidation
 
 	seenContainerImages := make(map[string]bool)
-	for _, cImage := range ear.ContainerImages {
+	for i, cImage := range ear.ContainerImages {
+		path := fmt.Sprintf("embeddedArtifactRegistry.images[%d]", i)
+
 		if cImage.Name == "" {
 			failures = append(failures, FailedValidation{
 				UserMessage: "The 'name' field is required for each entry in 'images'.",
+				Path:        path,
 			})
 		}
 
@@ -33,6 +36,7 @@ func validateContainerImages(ear *image.EmbeddedArtifactRegistry) []FailedValida
 			msg := fmt.Sprintf("Duplicate image name '%s' found in the 'images' section.", cImage.Name)
 			failures = append(failures, FailedValidation{
 				UserMessage: msg,
+				Path:        path,
 			})
 		}
 		seenContainerImages[cImage.Name] = true
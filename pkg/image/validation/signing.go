@@ -0,0 +1,56 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/suse-edge/edge-image-builder/pkg/attestation"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+)
+
+const (
+	signingComponent = "Signing"
+)
+
+func validateSigning(ctx *image.Context) []FailedValidation {
+	var failures []FailedValidation
+
+	signingDir := filepath.Join(ctx.ImageConfigDir, attestation.KeyDir)
+	if _, err := os.Stat(signingDir); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		failures = append(failures, FailedValidation{
+			UserMessage: "Signing directory could not be read",
+			Error:       err,
+		})
+		return failures
+	}
+
+	keyPath := attestation.SigningKeyPath(ctx)
+	if _, err := os.Stat(keyPath); err != nil {
+		if os.IsNotExist(err) {
+			failures = append(failures, FailedValidation{
+				UserMessage: fmt.Sprintf("Signing directory must contain a '%s' file", attestation.PrivateKeyFilename),
+			})
+			return failures
+		}
+
+		failures = append(failures, FailedValidation{
+			UserMessage: "Signing key could not be read",
+			Error:       err,
+		})
+		return failures
+	}
+
+	if _, err := attestation.LoadPrivateKey(keyPath); err != nil {
+		failures = append(failures, FailedValidation{
+			UserMessage: "Signing key must be an unencrypted PEM encoded Ed25519, ECDSA or RSA private key",
+			Error:       err,
+		})
+	}
+
+	return failures
+}
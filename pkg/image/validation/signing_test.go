@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+)
+
+func TestValidateSigning(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		KeyContents            []byte
+		SkipDir                bool
+		ExpectedFailedMessages []string
+	}{
+		`no signing directory`: {
+			SkipDir: true,
+		},
+		`valid key`: {
+			KeyContents: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}),
+		},
+		`missing key`: {
+			ExpectedFailedMessages: []string{
+				"Signing directory must contain a 'signing.key' file",
+			},
+		},
+		`invalid key`: {
+			KeyContents: []byte("not a key"),
+			ExpectedFailedMessages: []string{
+				"Signing key must be an unencrypted PEM encoded Ed25519, ECDSA or RSA private key",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			configDir := t.TempDir()
+			signingDir := filepath.Join(configDir, "signing")
+
+			if !test.SkipDir {
+				require.NoError(t, os.MkdirAll(signingDir, os.ModePerm))
+			}
+			if test.KeyContents != nil {
+				require.NoError(t, os.WriteFile(filepath.Join(signingDir, "signing.key"), test.KeyContents, 0o600))
+			}
+
+			ctx := image.Context{
+				ImageConfigDir: configDir,
+			}
+			failures := validateSigning(&ctx)
+			assert.Len(t, failures, len(test.ExpectedFailedMessages))
+
+			var foundMessages []string
+			for _, foundValidation := range failures {
+				foundMessages = append(foundMessages, foundValidation.UserMessage)
+			}
+
+			for _, expectedMessage := range test.ExpectedFailedMessages {
+				assert.Contains(t, foundMessages, expectedMessage)
+			}
+		})
+	}
+}
@@ -4,9 +4,35 @@ import (
 	"github.com/suse-edge/edge-image-builder/pkg/image"
 )
 
+// Severity indicates whether a failed validation prevents the image from being built.
+type Severity int
+
+const (
+	// SeverityError marks problems which prevent the image from being built. It is the default severity.
+	SeverityError Severity = iota
+	// SeverityWarning marks advisory findings, such as configurations which are likely unintended.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
 type FailedValidation struct {
 	UserMessage string
 	Error       error
+	Severity    Severity
+	// Path locates the offending value in the image definition, e.g. 'operatingSystem.users[1]'.
+	// It is empty for failures which do not relate to a specific value.
+	Path string
+	// Position of the offending value, derived from the path unless set by the validation,
+	// e.g. for values of files other than the definition.
+	Position image.Position
 }
 
 type validateComponent func(ctx *image.Context) []FailedValidation
@@ -15,16 +41,25 @@ func ValidateDefinition(ctx *image.Context) map[string][]FailedValidation {
 	failures := map[string][]FailedValidation{}
 
 	validations := map[string]validateComponent{
-		versionComponent:   validateVersion,
-		imageComponent:     validateImage,
-		osComponent:        validateOperatingSystem,
-		registryComponent:  validateEmbeddedArtifactRegistry,
-		k8sComponent:       validateKubernetes,
-		elementalComponent: validateElemental,
+		versionComponent:       validateVersion,
+		imageComponent:         validateImage,
+		osComponent:            validateOperatingSystem,
+		registryComponent:      validateEmbeddedArtifactRegistry,
+		k8sComponent:           validateKubernetes,
+		elementalComponent:     validateElemental,
+		networkComponent:       validateNetwork,
+		signingComponent:       validateSigning,
+		nodeOverridesComponent: validateNodeOverrides,
 	}
 	for componentName, v := range validations {
 		componentFailures := v(ctx)
 
+		for i := range componentFailures {
+			if componentFailures[i].Path != "" && !componentFailures[i].Position.IsKnown() {
+				componentFailures[i].Position = ctx.ImageDefinition.Locate(componentFailures[i].Path)
+			}
+		}
+
 		if len(componentFailures) > 0 {
 			failures[componentName] = componentFailures
 		}
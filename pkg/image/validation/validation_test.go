@@ -116,3 +116,44 @@ func TestValidateDefinition(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateDefinition_Positions(t *testing.T) {
+	config := `apiVersion: 1.2
+operatingSystem:
+  users:
+    - username: alice
+      encryptedPassword: $6$alice
+    - username: alice
+      encryptedPassword: $6$bob
+  kernelArgs:
+    - foo=
+`
+
+	definition, err := image.ParseDefinition([]byte(config))
+	require.NoError(t, err)
+
+	ctx := image.Context{
+		ImageDefinition: definition,
+		ImageConfigDir:  t.TempDir(),
+	}
+
+	failures := ValidateDefinition(&ctx)
+
+	assert.Contains(t, failures[osComponent], FailedValidation{
+		UserMessage: "Duplicate username found: alice",
+		Path:        "operatingSystem.users[1].username",
+		Position:    image.Position{Line: 6, Column: 17},
+	})
+	assert.Contains(t, failures[osComponent], FailedValidation{
+		UserMessage: "Kernel arguments must be specified as 'key=value'.",
+		Path:        "operatingSystem.kernelArgs[0]",
+		Position:    image.Position{Line: 9, Column: 7},
+	})
+
+	// Missing fields are located at the closest present parent
+	assert.Contains(t, failures[imageComponent], FailedValidation{
+		UserMessage: "The 'imageType' field is required in the 'image' section.",
+		Path:        "image",
+		Position:    image.Position{Line: 1, Column: 1},
+	})
+}
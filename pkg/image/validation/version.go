@@ -24,12 +24,14 @@ func validateVersion(ctx *image.Context) []FailedValidation {
 	if definition.APIVersion == "1.0" && apiVersionsDefined {
 		failures = append(failures, FailedValidation{
 			UserMessage: "Helm chart APIVersions field is not supported in EIB version 1.0, must use EIB version 1.1",
+			Path:        "apiVersion",
 		})
 	}
 
 	if definition.APIVersion == "1.0" && definition.OperatingSystem.EnableFips {
 		failures = append(failures, FailedValidation{
 			UserMessage: "Automated FIPS configuration is not supported in EIB version 1.0, please use EIB version >= 1.1",
+			Path:        "operatingSystem.enableFIPS",
 		})
 	}
 
@@ -0,0 +1,56 @@
+package variables
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const sopsExec = "/usr/bin/sops"
+
+// SOPS decrypts files encrypted with SOPS. The keys used for decryption (e.g. age identities
+// referenced through SOPS_AGE_KEY_FILE) are looked up by SOPS itself.
+type SOPS struct{}
+
+func (SOPS) Decrypt(path, key string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := createDecryptCommand(path, key)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
+
+func createDecryptCommand(path, key string) *exec.Cmd {
+	args := []string{"--decrypt"}
+	if key != "" {
+		args = append(args, "--extract", extractPath(key))
+	}
+	args = append(args, path)
+
+	return exec.Command(sopsExec, args...)
+}
+
+// extractPath converts a dotted key (e.g. "suma.activationKey") to the tree path used by SOPS
+// (e.g. '["suma"]["activationKey"]'). Numeric segments index into lists.
+func extractPath(key string) string {
+	var b strings.Builder
+
+	for _, segment := range strings.Split(key, ".") {
+		if _, err := strconv.Atoi(segment); err == nil {
+			fmt.Fprintf(&b, "[%s]", segment)
+			continue
+		}
+
+		fmt.Fprintf(&b, "[%q]", segment)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,20 @@
+package variables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateDecryptCommand(t *testing.T) {
+	cmd := createDecryptCommand("/eib/secrets.enc.yaml", "users.0.password")
+
+	assert.Equal(t, sopsExec, cmd.Path)
+	assert.Equal(t, []string{sopsExec, "--decrypt", "--extract", `["users"][0]["password"]`, "/eib/secrets.enc.yaml"}, cmd.Args)
+}
+
+func TestCreateDecryptCommand_EntireFile(t *testing.T) {
+	cmd := createDecryptCommand("/eib/registration-code.enc", "")
+
+	assert.Equal(t, []string{sopsExec, "--decrypt", "/eib/registration-code.enc"}, cmd.Args)
+}
@@ -0,0 +1,267 @@
+package variables
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envReference  = "env"
+	fileReference = "file"
+	sopsReference = "sops"
+)
+
+var (
+	// referencePattern matches both escaped references ("$${") and references ("${NAME}", "${env:NAME}").
+	referencePattern = regexp.MustCompile(`\$\$\{|\$\{([^}]*)\}`)
+	namePattern      = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// Decrypter decrypts values from encrypted files.
+type Decrypter interface {
+	// Decrypt returns the decrypted value under the given key of the file or,
+	// if no key is specified, the entire decrypted file.
+	Decrypt(path, key string) ([]byte, error)
+}
+
+// Resolver interpolates variable and secret references in the image definition.
+//
+// The following references are supported within any value of the definition:
+//   - ${NAME} - A variable from the values file or, if not defined there, the environment
+//   - ${env:NAME} - A secret held by the environment variable
+//   - ${file:path} - A secret held by the file, relative to the image configuration directory
+//   - ${sops:path#key} - A secret under the key of the SOPS encrypted file in the image configuration directory
+//
+// A literal "${" is written as "$${".
+type Resolver struct {
+	configDir string
+	values    map[string]string
+	decrypter Decrypter
+	lookupEnv func(string) (string, bool)
+
+	secrets []string
+}
+
+func NewResolver(configDir string, values map[string]string, decrypter Decrypter) *Resolver {
+	return &Resolver{
+		configDir: configDir,
+		values:    values,
+		decrypter: decrypter,
+		lookupEnv: os.LookupEnv,
+	}
+}
+
+// LoadValues reads the variables from a YAML file mapping variable names to their values.
+func LoadValues(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading values file: %w", err)
+	}
+
+	var values map[string]string
+	if err = yaml.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("parsing values file: %w", err)
+	}
+
+	for name := range values {
+		if !namePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid variable name '%s'", name)
+		}
+	}
+
+	return values, nil
+}
+
+// Interpolate resolves all references in the values of the given image definition in place,
+// so that the interpolated values retain their positions. Mapping keys are never interpolated.
+// Returned errors never include resolved values.
+func (r *Resolver) Interpolate(document *image.Document) error {
+	return r.interpolateNode(document, document.Root)
+}
+
+// Secrets lists the values resolved from secret references.
+func (r *Resolver) Secrets() []string {
+	secrets := slices.Clone(r.secrets)
+	slices.Sort(secrets)
+
+	return slices.Compact(secrets)
+}
+
+func (r *Resolver) interpolateNode(document *image.Document, node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return r.interpolateScalar(document, node)
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			if err := r.interpolateNode(document, node.Content[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := r.interpolateNode(document, child); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		// Aliases refer to anchored nodes which are interpolated in place
+		return nil
+	}
+}
+
+func (r *Resolver) interpolateScalar(document *image.Document, node *yaml.Node) error {
+	if !strings.Contains(node.Value, "${") {
+		return nil
+	}
+
+	var resolveErr error
+
+	value := referencePattern.ReplaceAllStringFunc(node.Value, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+
+		if resolveErr != nil {
+			return match
+		}
+
+		resolved, err := r.resolve(match[2 : len(match)-1])
+		if err != nil {
+			resolveErr = fmt.Errorf("%s: %w", location(document, node), err)
+			return match
+		}
+
+		return resolved
+	})
+
+	if resolveErr != nil {
+		return resolveErr
+	}
+
+	node.Value = value
+
+	// Plain scalars have their type resolved from the interpolated value (e.g. ports and booleans)
+	if node.Style == 0 {
+		node.Tag = ""
+	}
+
+	return nil
+}
+
+// location describes the line of the node, along with its file if the definition was combined from several files.
+func location(document *image.Document, node *yaml.Node) string {
+	if file := document.Files[node]; file != "" {
+		return fmt.Sprintf("%s, line %d", file, node.Line)
+	}
+
+	return fmt.Sprintf("line %d", node.Line)
+}
+
+func (r *Resolver) resolve(reference string) (string, error) {
+	kind, target, typed := strings.Cut(reference, ":")
+	if !typed {
+		return r.variable(reference)
+	}
+
+	var value string
+	var err error
+
+	switch kind {
+	case envReference:
+		value, err = r.env(target)
+	case fileReference:
+		value, err = r.file(target)
+	case sopsReference:
+		value, err = r.sops(target)
+	default:
+		return "", fmt.Errorf("unknown reference type '%s' in '${%s}'", kind, reference)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("resolving '${%s}': %w", reference, err)
+	}
+
+	if value != "" {
+		r.secrets = append(r.secrets, value)
+	}
+
+	return value, nil
+}
+
+func (r *Resolver) variable(name string) (string, error) {
+	if !namePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid variable reference '${%s}'", name)
+	}
+
+	if value, ok := r.values[name]; ok {
+		return value, nil
+	}
+
+	if value, ok := r.lookupEnv(name); ok {
+		return value, nil
+	}
+
+	return "", fmt.Errorf("variable '%s' is not defined in the values file or the environment", name)
+}
+
+func (r *Resolver) env(name string) (string, error) {
+	if !namePattern.MatchString(name) {
+		return "", fmt.Errorf("invalid environment variable name '%s'", name)
+	}
+
+	value, ok := r.lookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable '%s' is not set", name)
+	}
+
+	return value, nil
+}
+
+func (r *Resolver) file(path string) (string, error) {
+	if path == "" {
+		return "", errors.New("file path not specified")
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.configDir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading secret file: %w", err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func (r *Resolver) sops(reference string) (string, error) {
+	path, key, _ := strings.Cut(reference, "#")
+	if path == "" {
+		return "", errors.New("encrypted file not specified")
+	}
+
+	if filepath.IsAbs(path) || !filepath.IsLocal(path) {
+		return "", fmt.Errorf("encrypted file '%s' must be located within the image configuration directory", path)
+	}
+
+	if r.decrypter == nil {
+		return "", errors.New("decryption is not available")
+	}
+
+	data, err := r.decrypter.Decrypt(filepath.Join(r.configDir, path), key)
+	if err != nil {
+		return "", fmt.Errorf("decrypting '%s': %w", path, err)
+	}
+
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
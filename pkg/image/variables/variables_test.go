@@ -0,0 +1,244 @@
+package variables
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"gopkg.in/yaml.v3"
+)
+
+type mockDecrypter struct {
+	decrypt func(path, key string) ([]byte, error)
+}
+
+func (m mockDecrypter) Decrypt(path, key string) ([]byte, error) {
+	return m.decrypt(path, key)
+}
+
+func newTestResolver(t *testing.T, values map[string]string, env map[string]string) *Resolver {
+	configDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "password"), []byte("$6$encrypted\n"), 0o600))
+
+	decrypter := mockDecrypter{
+		decrypt: func(path, key string) ([]byte, error) {
+			if path == filepath.Join(configDir, "secrets.enc.yaml") && key == "suma.activationKey" {
+				return []byte("activation-key"), nil
+			}
+			return nil, errors.New("no such key")
+		},
+	}
+
+	r := NewResolver(configDir, values, decrypter)
+	r.lookupEnv = func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	return r
+}
+
+func parseDocument(t *testing.T, definition string) *image.Document {
+	var document yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(definition), &document))
+
+	return &image.Document{Root: document.Content[0]}
+}
+
+func TestInterpolate(t *testing.T) {
+	definition := `apiVersion: "1.1"
+image:
+  imageType: iso
+  arch: ${ARCH}
+  baseImage: base-${ARCH}.iso
+  outputImageName: eib-image.iso
+operatingSystem:
+  users:
+    - username: root
+      encryptedPassword: ${file:password}
+  suma:
+    host: suma.example.com
+    activationKey: ${sops:secrets.enc.yaml#suma.activationKey}
+  packages:
+    sccRegistrationCode: ${env:SCC_CODE}
+kubernetes:
+  version: v1.30.3+rke2r1
+  network:
+    apiVIP: 192.168.122.100
+  helm:
+    repositories:
+      - name: private
+        url: https://charts.example.com
+        authentication:
+          username: ${HELM_USER}
+          password: "${env:HELM_PASSWORD}"
+  manifests:
+    urls:
+      - https://example.com/$${ARCH}/manifest.yaml
+embeddedArtifactRegistry:
+  images:
+    - name: registry.example.com/app:${TAG}
+`
+
+	r := newTestResolver(t,
+		map[string]string{"ARCH": "x86_64", "TAG": "1.0"},
+		map[string]string{"ARCH": "aarch64", "SCC_CODE": "scc-code", "HELM_USER": "admin", "HELM_PASSWORD": "helm-password"},
+	)
+
+	document := parseDocument(t, definition)
+	require.NoError(t, r.Interpolate(document))
+
+	parsed, err := image.ParseDocument(document)
+	require.NoError(t, err)
+
+	assert.Equal(t, image.ArchTypeX86, parsed.Image.Arch)
+	assert.Equal(t, "base-x86_64.iso", parsed.Image.BaseImage)
+	assert.Equal(t, "$6$encrypted", parsed.OperatingSystem.Users[0].EncryptedPassword)
+	assert.Equal(t, "activation-key", parsed.OperatingSystem.Suma.ActivationKey)
+	assert.Equal(t, "scc-code", parsed.OperatingSystem.Packages.RegCode)
+	assert.Equal(t, "admin", parsed.Kubernetes.Helm.Repositories[0].Authentication.Username)
+	assert.Equal(t, "helm-password", parsed.Kubernetes.Helm.Repositories[0].Authentication.Password)
+	assert.Equal(t, "https://example.com/${ARCH}/manifest.yaml", parsed.Kubernetes.Manifests.URLs[0])
+	assert.Equal(t, "registry.example.com/app:1.0", parsed.EmbeddedArtifactRegistry.ContainerImages[0].Name)
+
+	assert.Equal(t, []string{"$6$encrypted", "activation-key", "helm-password", "scc-code"}, r.Secrets())
+}
+
+func TestInterpolate_TypedValues(t *testing.T) {
+	definition := `apiVersion: "1.1"
+operatingSystem:
+  enableFIPS: ${FIPS}
+  rawConfiguration:
+    diskSize: ${DISK_SIZE}
+`
+
+	r := newTestResolver(t, map[string]string{"FIPS": "true", "DISK_SIZE": "32G"}, nil)
+
+	document := parseDocument(t, definition)
+	require.NoError(t, r.Interpolate(document))
+
+	parsed, err := image.ParseDocument(document)
+	require.NoError(t, err)
+
+	assert.True(t, parsed.OperatingSystem.EnableFips)
+	assert.EqualValues(t, "32G", parsed.OperatingSystem.RawConfiguration.DiskSize)
+}
+
+func TestInterpolate_NoReferences(t *testing.T) {
+	definition := "apiVersion: 1.1\n# comment\nimage:\n    imageType: iso\n"
+
+	r := newTestResolver(t, nil, nil)
+
+	document := parseDocument(t, definition)
+	require.NoError(t, r.Interpolate(document))
+
+	assert.Equal(t, parseDocument(t, definition), document)
+	assert.Empty(t, r.Secrets())
+}
+
+func TestInterpolate_Errors(t *testing.T) {
+	tests := map[string]struct {
+		definition  string
+		expectedErr string
+	}{
+		"undefined variable": {
+			definition:  "image:\n  arch: ${ARCH}\n",
+			expectedErr: "line 2: variable 'ARCH' is not defined in the values file or the environment",
+		},
+		"invalid variable": {
+			definition:  "image:\n  arch: ${ARCH-1}\n",
+			expectedErr: "line 2: invalid variable reference '${ARCH-1}'",
+		},
+		"unset environment variable": {
+			definition:  "operatingSystem:\n  packages:\n    sccRegistrationCode: ${env:SCC_CODE}\n",
+			expectedErr: "line 3: resolving '${env:SCC_CODE}': environment variable 'SCC_CODE' is not set",
+		},
+		"missing file": {
+			definition:  "kubernetes:\n  version: ${file:missing}\n",
+			expectedErr: "line 2: resolving '${file:missing}': reading secret file",
+		},
+		"sops file outside of config dir": {
+			definition:  "kubernetes:\n  version: ${sops:../secrets.enc.yaml#version}\n",
+			expectedErr: "line 2: resolving '${sops:../secrets.enc.yaml#version}': encrypted file '../secrets.enc.yaml' must be located within the image configuration directory",
+		},
+		"sops decryption failure": {
+			definition:  "kubernetes:\n  version: ${sops:secrets.enc.yaml#version}\n",
+			expectedErr: "line 2: resolving '${sops:secrets.enc.yaml#version}': decrypting 'secrets.enc.yaml': no such key",
+		},
+		"unknown reference type": {
+			definition:  "kubernetes:\n  version: ${vault:secret/k8s}\n",
+			expectedErr: "line 2: unknown reference type 'vault' in '${vault:secret/k8s}'",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := newTestResolver(t, nil, nil)
+
+			err := r.Interpolate(parseDocument(t, test.definition))
+			require.Error(t, err)
+			assert.ErrorContains(t, err, test.expectedErr)
+		})
+	}
+}
+
+func TestInterpolate_Positions(t *testing.T) {
+	definition := "image:\n  arch: ${ARCH}\n  baseImage: base.iso\n"
+
+	r := newTestResolver(t, map[string]string{"ARCH": "x86_64"}, nil)
+
+	document := parseDocument(t, definition)
+	require.NoError(t, r.Interpolate(document))
+
+	arch := document.Root.Content[1].Content[1]
+	assert.Equal(t, "x86_64", arch.Value)
+	assert.Equal(t, 2, arch.Line)
+	assert.Equal(t, 9, arch.Column)
+
+	// Errors in values merged from other files name the file
+	document = parseDocument(t, "image:\n  arch: ${UNDEFINED}\n")
+	document.Files = map[*yaml.Node]string{document.Root.Content[1].Content[1]: "sites/site-a.yaml"}
+
+	err := r.Interpolate(document)
+	require.EqualError(t, err, "sites/site-a.yaml, line 2: variable 'UNDEFINED' is not defined in the values file or the environment")
+}
+
+func TestInterpolate_KeysNotInterpolated(t *testing.T) {
+	definition := "operatingSystem:\n  ${KEY}: value\n  kernelArgs:\n    - ${ARG}\n"
+
+	r := newTestResolver(t, map[string]string{"KEY": "time", "ARG": "quiet"}, nil)
+
+	document := parseDocument(t, definition)
+	require.NoError(t, r.Interpolate(document))
+
+	operatingSystem := document.Root.Content[1]
+	assert.Equal(t, "${KEY}", operatingSystem.Content[0].Value)
+	assert.Equal(t, "quiet", operatingSystem.Content[3].Content[0].Value)
+}
+
+func TestLoadValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("ARCH: x86_64\nNODES: 3\n"), 0o600))
+
+	values, err := LoadValues(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"ARCH": "x86_64", "NODES": "3"}, values)
+}
+
+func TestLoadValues_InvalidName(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "values.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("cluster-name: edge\n"), 0o600))
+
+	_, err := LoadValues(path)
+	assert.EqualError(t, err, "invalid variable name 'cluster-name'")
+}
+
+func TestLoadValues_Missing(t *testing.T) {
+	_, err := LoadValues("missing.yaml")
+	assert.ErrorContains(t, err, "reading values file")
+}
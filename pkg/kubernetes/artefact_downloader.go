@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/suse-edge/edge-image-builder/pkg/cache"
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/http"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
@@ -23,7 +26,9 @@ const (
 
 	rke2Binary     = "rke2.linux-%s.tar.gz"
 	rke2CoreImages = "rke2-images-core.linux-%s.tar.zst"
-	rke2Checksums  = "sha256sum-%s.txt"
+
+	// Both RKE2 and k3s publish checksums of all release artefacts in the same format.
+	checksumsFile = "sha256sum-%s.txt"
 
 	rke2CalicoImages = "rke2-images-calico.linux-%s.tar.zst"
 	rke2CanalImages  = "rke2-images-canal.linux-%s.tar.zst"
@@ -34,35 +39,62 @@ const (
 	k3sImages = "k3s-airgap-images-%s.tar.zst"
 )
 
-type cache interface {
-	Get(artefact string) (filepath string, err error)
-	Put(artefact string, reader io.Reader) error
+type rke2CNI struct {
+	cni string
+	// images is the archive of the images required by the CNI, if any.
+	images string
+}
+
+// rke2CNIImages lists the primary CNIs the RKE2 artefacts can be provided for.
+var rke2CNIImages = []rke2CNI{
+	{cni: image.CNITypeNone},
+	{cni: image.CNITypeCanal, images: rke2CanalImages},
+	{cni: image.CNITypeCalico, images: rke2CalicoImages},
+	{cni: image.CNITypeCilium, images: rke2CiliumImages},
+}
+
+// SupportedCNIs lists the primary CNIs RKE2 clusters can be built with.
+func SupportedCNIs() []string {
+	cnis := make([]string, 0, len(rke2CNIImages))
+	for _, c := range rke2CNIImages {
+		cnis = append(cnis, c.cni)
+	}
+
+	return cnis
+}
+
+type artefactCache interface {
+	CopyTo(artefact, dest string, perms os.FileMode) error
+	Put(artefact, source string, reader io.Reader) error
+	Remove(artefact string) error
 }
 
 type ArtefactDownloader struct {
-	Cache cache
+	Cache artefactCache
 }
 
-func (d ArtefactDownloader) DownloadRKE2Artefacts(arch image.Arch, version, cni string, multusEnabled bool, installPath, imagesPath string) error {
+func (d ArtefactDownloader) DownloadRKE2Artefacts(ctx context.Context, arch image.Arch, version, cni string, multusEnabled bool, installPath, imagesPath string) error {
 	if !strings.Contains(version, image.KubernetesDistroRKE2) {
 		return fmt.Errorf("invalid RKE2 version: '%s'", version)
 	}
 
-	if arch == image.ArchTypeARM {
-		log.Audit("WARNING: RKE2 support for aarch64 platforms is limited and experimental")
-	}
-
 	artefacts, err := rke2ImageArtefacts(cni, multusEnabled, arch)
 	if err != nil {
 		return fmt.Errorf("gathering RKE2 image artefacts: %w", err)
 	}
 
-	if err = d.downloadArtefacts(artefacts, rke2ReleaseURL, version, imagesPath); err != nil {
+	// The checksums file is also required by the RKE2 installer, so it is stored alongside the install artefacts.
+	checksums, err := d.downloadChecksums(ctx, arch, rke2ReleaseURL, version, installPath)
+	if err != nil {
+		return fmt.Errorf("downloading RKE2 checksums: %w", err)
+	}
+
+	if err = d.downloadArtefacts(ctx, artefacts, rke2ReleaseURL, version, imagesPath, checksums); err != nil {
 		return fmt.Errorf("downloading RKE2 image artefacts: %w", err)
 	}
 
 	artefacts = rke2InstallerArtefacts(arch)
-	if err = d.downloadArtefacts(artefacts, rke2ReleaseURL, version, installPath); err != nil {
+	if err = d.downloadArtefacts(ctx, artefacts, rke2ReleaseURL, version, installPath, checksums); err != nil {
 		return fmt.Errorf("downloading RKE2 install artefacts: %w", err)
 	}
 
@@ -74,7 +106,6 @@ func rke2InstallerArtefacts(arch image.Arch) []string {
 
 	return []string{
 		fmt.Sprintf(rke2Binary, artefactArch),
-		fmt.Sprintf(rke2Checksums, artefactArch),
 	}
 }
 
@@ -85,20 +116,21 @@ func rke2ImageArtefacts(cni string, multusEnabled bool, arch image.Arch) ([]stri
 
 	artefacts = append(artefacts, fmt.Sprintf(rke2CoreImages, artefactArch))
 
-	switch cni {
-	case "":
+	if cni == "" {
 		return nil, fmt.Errorf("CNI not specified")
-	case image.CNITypeNone:
-	case image.CNITypeCanal:
-		artefacts = append(artefacts, fmt.Sprintf(rke2CanalImages, artefactArch))
-	case image.CNITypeCalico:
-		artefacts = append(artefacts, fmt.Sprintf(rke2CalicoImages, artefactArch))
-	case image.CNITypeCilium:
-		artefacts = append(artefacts, fmt.Sprintf(rke2CiliumImages, artefactArch))
-	default:
+	}
+
+	index := slices.IndexFunc(rke2CNIImages, func(c rke2CNI) bool {
+		return c.cni == cni
+	})
+	if index < 0 {
 		return nil, fmt.Errorf("unsupported CNI: %s", cni)
 	}
 
+	if images := rke2CNIImages[index].images; images != "" {
+		artefacts = append(artefacts, fmt.Sprintf(images, artefactArch))
+	}
+
 	if multusEnabled {
 		artefacts = append(artefacts, fmt.Sprintf(rke2MultusImages, artefactArch))
 	}
@@ -106,18 +138,35 @@ func rke2ImageArtefacts(cni string, multusEnabled bool, arch image.Arch) ([]stri
 	return artefacts, nil
 }
 
-func (d ArtefactDownloader) DownloadK3sArtefacts(arch image.Arch, version, installPath, imagesPath string) error {
+func (d ArtefactDownloader) DownloadK3sArtefacts(ctx context.Context, arch image.Arch, version, installPath, imagesPath string) error {
 	if !strings.Contains(version, image.KubernetesDistroK3S) {
 		return fmt.Errorf("invalid k3s version: '%s'", version)
 	}
 
+	// The k3s install path must only contain the binary, so the checksums file is stored temporarily.
+	checksumsDir, err := os.MkdirTemp("", "k3s-checksums-")
+	if err != nil {
+		return fmt.Errorf("creating k3s checksums dir: %w", err)
+	}
+
+	defer func() {
+		if rmErr := os.RemoveAll(checksumsDir); rmErr != nil {
+			zap.S().Warnf("Removing k3s checksums dir failed: %v", rmErr)
+		}
+	}()
+
+	checksums, err := d.downloadChecksums(ctx, arch, k3sReleaseURL, version, checksumsDir)
+	if err != nil {
+		return fmt.Errorf("downloading k3s checksums: %w", err)
+	}
+
 	artefacts := k3sImageArtefacts(arch)
-	if err := d.downloadArtefacts(artefacts, k3sReleaseURL, version, imagesPath); err != nil {
+	if err = d.downloadArtefacts(ctx, artefacts, k3sReleaseURL, version, imagesPath, checksums); err != nil {
 		return fmt.Errorf("downloading k3s image artefacts: %w", err)
 	}
 
 	artefacts = k3sInstallerArtefacts(arch)
-	if err := d.downloadArtefacts(artefacts, k3sReleaseURL, version, installPath); err != nil {
+	if err = d.downloadArtefacts(ctx, artefacts, k3sReleaseURL, version, installPath, checksums); err != nil {
 		return fmt.Errorf("downloading k3s install artefacts: %w", err)
 	}
 
@@ -145,21 +194,75 @@ func k3sImageArtefacts(arch image.Arch) []string {
 	}
 }
 
-func (d ArtefactDownloader) downloadArtefacts(artefacts []string, releaseURL, version, destinationPath string) error {
+// downloadChecksums retrieves the checksums file of the given release and parses its contents.
+// Passing nil checksums to downloadArtefacts afterwards is only valid for the checksums file itself.
+func (d ArtefactDownloader) downloadChecksums(ctx context.Context, arch image.Arch, releaseURL, version, destinationPath string) (map[string]string, error) {
+	artefact := fmt.Sprintf(checksumsFile, arch.Short())
+
+	if err := d.downloadArtefacts(ctx, []string{artefact}, releaseURL, version, destinationPath, nil); err != nil {
+		return nil, err
+	}
+
+	checksums, err := parseChecksumsFile(filepath.Join(destinationPath, artefact))
+	if err != nil {
+		return nil, fmt.Errorf("parsing checksums file '%s': %w", artefact, err)
+	}
+
+	return checksums, nil
+}
+
+func (d ArtefactDownloader) downloadArtefacts(ctx context.Context, artefacts []string, releaseURL, version, destinationPath string, checksums map[string]string) error {
 	for _, artefact := range artefacts {
 		url := fmt.Sprintf(releaseURL, version, artefact)
 		path := filepath.Join(destinationPath, artefact)
 		cacheKey := cacheIdentifier(version, artefact)
 
+		var expectedChecksum string
+		if checksums != nil {
+			var ok bool
+			if expectedChecksum, ok = checksums[artefact]; !ok {
+				return fmt.Errorf("no published checksum found for artefact '%s'", artefact)
+			}
+		}
+
 		copied, err := d.copyArtefactFromCache(cacheKey, path)
 		if err != nil {
 			return fmt.Errorf("retrieving artefact '%s' from cache: %w", artefact, err)
 		}
 
-		if !copied {
-			if err = d.downloadArtefact(url, path, cacheKey); err != nil {
-				return fmt.Errorf("downloading artefact '%s': %w", artefact, err)
+		if copied && expectedChecksum != "" {
+			if err = verifyChecksum(path, expectedChecksum); err != nil {
+				zap.S().Warnf("Cached artefact '%s' does not match the published checksum and will be downloaded again: %v", artefact, err)
+
+				if err = d.Cache.Remove(cacheKey); err != nil {
+					return fmt.Errorf("removing artefact '%s' from cache: %w", artefact, err)
+				}
+
+				copied = false
+			}
+		}
+
+		if copied {
+			http.RecordCachedFile(ctx, url, path)
+			continue
+		}
+
+		if err = d.downloadArtefact(ctx, url, path, cacheKey); err != nil {
+			return fmt.Errorf("downloading artefact '%s': %w", artefact, err)
+		}
+
+		if expectedChecksum == "" {
+			continue
+		}
+
+		if err = verifyChecksum(path, expectedChecksum); err != nil {
+			log.Output(ctx).AuditErrorf("Kubernetes artefact '%s' is corrupted: its checksum does not match the published one.", artefact)
+
+			if removeErr := d.Cache.Remove(cacheKey); removeErr != nil {
+				zap.S().Warnf("Removing corrupted artefact '%s' from cache failed: %v", artefact, removeErr)
 			}
+
+			return fmt.Errorf("verifying artefact '%s': %w", artefact, err)
 		}
 	}
 
@@ -167,44 +270,48 @@ func (d ArtefactDownloader) downloadArtefacts(artefacts []string, releaseURL, ve
 }
 
 func (d ArtefactDownloader) copyArtefactFromCache(cacheKey, destPath string) (bool, error) {
-	sourcePath, err := d.Cache.Get(cacheKey)
-	if err != nil {
+	if err := d.Cache.CopyTo(cacheKey, destPath, fileio.NonExecutablePerms); err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			return false, nil
 		}
 
-		return false, fmt.Errorf("querying cache: %w", err)
-	}
-
-	zap.S().Infof("Copying artefact with identifier '%s' from cache", cacheKey)
+		if errors.Is(err, cache.ErrCorrupted) {
+			zap.S().Warnf("Cached artefact with identifier '%s' is corrupted and will be downloaded again: %v", cacheKey, err)
+			return false, nil
+		}
 
-	if err = fileio.CopyFile(sourcePath, destPath, fileio.NonExecutablePerms); err != nil {
 		return false, fmt.Errorf("copying from cache: %w", err)
 	}
 
+	zap.S().Infof("Copied artefact with identifier '%s' from cache", cacheKey)
+
 	return true, nil
 }
 
-func (d ArtefactDownloader) downloadArtefact(url, path, cacheKey string) error {
+func (d ArtefactDownloader) downloadArtefact(ctx context.Context, url, path, cacheKey string) error {
 	reader, writer := io.Pipe()
 
-	errGroup, ctx := errgroup.WithContext(context.Background())
+	errGroup, groupCtx := errgroup.WithContext(ctx)
 
 	errGroup.Go(func() error {
-		defer func() {
-			if err := writer.Close(); err != nil {
-				zap.S().Warnf("Closing pipe writer failed unexpectedly: %v", err)
-			}
-		}()
+		err := http.DownloadFile(groupCtx, url, path, writer)
 
-		if err := http.DownloadFile(ctx, url, path, writer); err != nil {
+		// Propagating the download error to the reader prevents
+		// the cache from storing a partially downloaded artefact.
+		if closeErr := writer.CloseWithError(err); closeErr != nil {
+			zap.S().Warnf("Closing pipe writer failed unexpectedly: %v", closeErr)
+		}
+
+		if err != nil {
 			return fmt.Errorf("downloading artefact: %w", err)
 		}
 		return nil
 	})
 
 	errGroup.Go(func() error {
-		if err := d.Cache.Put(cacheKey, reader); err != nil {
+		if err := d.Cache.Put(cacheKey, url, reader); err != nil {
+			// Unblock the download which would otherwise wait on the pipe indefinitely.
+			_ = reader.CloseWithError(err)
 			return fmt.Errorf("caching artefact: %w", err)
 		}
 
@@ -214,6 +321,37 @@ func (d ArtefactDownloader) downloadArtefact(url, path, cacheKey string) error {
 	return errGroup.Wait()
 }
 
+// RKE2CacheIdentifiers returns the cache identifiers of all artefacts required to install the given RKE2 version.
+func RKE2CacheIdentifiers(arch image.Arch, version, cni string, multusEnabled bool) ([]string, error) {
+	artefacts, err := rke2ImageArtefacts(cni, multusEnabled, arch)
+	if err != nil {
+		return nil, err
+	}
+
+	artefacts = append(artefacts, rke2InstallerArtefacts(arch)...)
+	artefacts = append(artefacts, fmt.Sprintf(checksumsFile, arch.Short()))
+
+	return cacheIdentifiers(version, artefacts), nil
+}
+
+// K3sCacheIdentifiers returns the cache identifiers of all artefacts required to install the given k3s version.
+func K3sCacheIdentifiers(arch image.Arch, version string) []string {
+	artefacts := k3sImageArtefacts(arch)
+	artefacts = append(artefacts, k3sInstallerArtefacts(arch)...)
+	artefacts = append(artefacts, fmt.Sprintf(checksumsFile, arch.Short()))
+
+	return cacheIdentifiers(version, artefacts)
+}
+
+func cacheIdentifiers(version string, artefacts []string) []string {
+	identifiers := make([]string, 0, len(artefacts))
+	for _, artefact := range artefacts {
+		identifiers = append(identifiers, cacheIdentifier(version, artefact))
+	}
+
+	return identifiers
+}
+
 func cacheIdentifier(version, artefact string) string {
 	return fmt.Sprintf("%s/%s", version, artefact)
 }
@@ -1,18 +1,27 @@
 package kubernetes
 
 import (
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
 )
 
 func TestRKE2InstallerArtefacts(t *testing.T) {
-	x86Artefacts := []string{"rke2.linux-amd64.tar.gz", "sha256sum-amd64.txt"}
+	x86Artefacts := []string{"rke2.linux-amd64.tar.gz"}
 	assert.Equal(t, x86Artefacts, rke2InstallerArtefacts(image.ArchTypeX86))
 
-	armArtefacts := []string{"rke2.linux-arm64.tar.gz", "sha256sum-arm64.txt"}
+	armArtefacts := []string{"rke2.linux-arm64.tar.gz"}
 	assert.Equal(t, armArtefacts, rke2InstallerArtefacts(image.ArchTypeARM))
 }
 
@@ -160,3 +169,140 @@ func TestK3sImageArtefacts(t *testing.T) {
 	armArtefacts := []string{"k3s-airgap-images-arm64.tar.zst"}
 	assert.Equal(t, armArtefacts, k3sImageArtefacts(image.ArchTypeARM))
 }
+
+type mockCache struct {
+	files   map[string]string
+	dir     string
+	removed []string
+}
+
+func (m *mockCache) CopyTo(artefact, dest string, perms os.FileMode) error {
+	path, ok := m.files[artefact]
+	if !ok {
+		return fs.ErrNotExist
+	}
+
+	return fileio.CopyFile(path, dest, perms)
+}
+
+func (m *mockCache) Put(artefact, _ string, reader io.Reader) error {
+	b, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+
+	if m.dir == "" {
+		return nil
+	}
+
+	path := filepath.Join(m.dir, filepath.Base(artefact))
+	if err = os.WriteFile(path, b, 0o600); err != nil {
+		return err
+	}
+
+	m.files[artefact] = path
+	return nil
+}
+
+func (m *mockCache) Remove(artefact string) error {
+	m.removed = append(m.removed, artefact)
+	delete(m.files, artefact)
+	return nil
+}
+
+func TestDownloadArtefacts(t *testing.T) {
+	const (
+		artefact = "rke2.linux-amd64.tar.gz"
+		cacheKey = "v1.30.3+rke2r1/rke2.linux-amd64.tar.gz"
+		// SHA-256 digest of "some-data"
+		checksum = "9332d94d5ee69ad17d310e62cd101d70f578024fd5e8d1647f8073f886c894e1"
+	)
+
+	tests := map[string]struct {
+		cachedContents     string
+		downloadedContents string
+		expectedError      string
+		expectedDownloads  int
+		expectedRemoved    []string
+		expectedCached     string
+	}{
+		"Verified cache hit": {
+			cachedContents: "some-data",
+			expectedCached: "some-data",
+		},
+		"Cache miss": {
+			downloadedContents: "some-data",
+			expectedDownloads:  1,
+			expectedCached:     "some-data",
+		},
+		"Mismatching cache hit is downloaded again": {
+			cachedContents:     "stale-data",
+			downloadedContents: "some-data",
+			expectedDownloads:  1,
+			expectedRemoved:    []string{cacheKey},
+			expectedCached:     "some-data",
+		},
+		"Mismatching download is not cached": {
+			downloadedContents: "corrupted-data",
+			expectedError:      "verifying artefact 'rke2.linux-amd64.tar.gz': checksum mismatch",
+			expectedDownloads:  1,
+			expectedRemoved:    []string{cacheKey},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var downloads int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				downloads++
+				_, _ = w.Write([]byte(test.downloadedContents))
+			}))
+			defer server.Close()
+
+			c := &mockCache{files: map[string]string{}, dir: t.TempDir()}
+			if test.cachedContents != "" {
+				require.NoError(t, c.Put(cacheKey, "", strings.NewReader(test.cachedContents)))
+			}
+
+			d := ArtefactDownloader{Cache: c}
+			destinationDir := t.TempDir()
+			checksums := map[string]string{artefact: checksum}
+
+			err := d.downloadArtefacts(context.Background(), []string{artefact}, server.URL+"/%s/%s", "v1.30.3+rke2r1", destinationDir, checksums)
+			if test.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedError)
+			} else {
+				require.NoError(t, err)
+
+				b, readErr := os.ReadFile(filepath.Join(destinationDir, artefact))
+				require.NoError(t, readErr)
+				assert.Equal(t, "some-data", string(b))
+			}
+
+			assert.Equal(t, test.expectedDownloads, downloads)
+			assert.Equal(t, test.expectedRemoved, c.removed)
+
+			if test.expectedCached == "" {
+				assert.NotContains(t, c.files, cacheKey)
+				return
+			}
+
+			require.Contains(t, c.files, cacheKey)
+			b, err := os.ReadFile(c.files[cacheKey])
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedCached, string(b))
+		})
+	}
+}
+
+func TestDownloadArtefacts_MissingChecksum(t *testing.T) {
+	d := ArtefactDownloader{Cache: &mockCache{}}
+
+	checksums := map[string]string{
+		"rke2.linux-amd64.tar.gz": "9332d94d5ee69ad17d310e62cd101d70f578024fd5e8d1647f8073f886c894e1",
+	}
+
+	err := d.downloadArtefacts(context.Background(), []string{"rke2-images-core.linux-amd64.tar.zst"}, rke2ReleaseURL, "v1.30.3+rke2r1", t.TempDir(), checksums)
+	assert.EqualError(t, err, "no published checksum found for artefact 'rke2-images-core.linux-amd64.tar.zst'")
+}
@@ -0,0 +1,72 @@
+package kubernetes
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// parseChecksumsFile parses a file in the format produced by `sha256sum`, e.g.
+//
+//	<sha256 digest>  rke2.linux-amd64.tar.gz
+//
+// and returns a map of artefact names to their respective digests.
+func parseChecksumsFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	return parseChecksums(file)
+}
+
+func parseChecksums(reader io.Reader) (map[string]string, error) {
+	checksums := map[string]string{}
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid checksum line: %q", line)
+		}
+
+		// Binary mode entries are prefixed with an asterisk
+		name := strings.TrimPrefix(fields[1], "*")
+		checksums[name] = strings.ToLower(fields[0])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading checksums: %w", err)
+	}
+
+	return checksums, nil
+}
+
+func verifyChecksum(path, expected string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return fmt.Errorf("reading file: %w", err)
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChecksums(t *testing.T) {
+	contents := `9332d94d5ee69ad17d310e62cd101d70f578024fd5e8d1647f8073f886c894e1  rke2.linux-amd64.tar.gz
+C0E3B0D2D5C0E9E4A1F3A5B7D2E3F4A5B6C7D8E9F0A1B2C3D4E5F6A7B8C9D0E1 *rke2-images-core.linux-amd64.tar.zst
+
+`
+
+	checksums, err := parseChecksums(strings.NewReader(contents))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"rke2.linux-amd64.tar.gz":              "9332d94d5ee69ad17d310e62cd101d70f578024fd5e8d1647f8073f886c894e1",
+		"rke2-images-core.linux-amd64.tar.zst": "c0e3b0d2d5c0e9e4a1f3a5b7d2e3f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1",
+	}, checksums)
+}
+
+func TestParseChecksums_Invalid(t *testing.T) {
+	_, err := parseChecksums(strings.NewReader("9332d94d5ee69ad17d310e62cd101d70f578024fd5e8d1647f8073f886c894e1"))
+	assert.EqualError(t, err, `invalid checksum line: "9332d94d5ee69ad17d310e62cd101d70f578024fd5e8d1647f8073f886c894e1"`)
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "artefact")
+	require.NoError(t, os.WriteFile(path, []byte("some-data"), 0o600))
+
+	assert.NoError(t, verifyChecksum(path, "9332d94d5ee69ad17d310e62cd101d70f578024fd5e8d1647f8073f886c894e1"))
+	assert.EqualError(t, verifyChecksum(path, "abc"),
+		"checksum mismatch: expected abc, got 9332d94d5ee69ad17d310e62cd101d70f578024fd5e8d1647f8073f886c894e1")
+}
@@ -42,7 +42,9 @@ type Cluster struct {
 	AgentConfig map[string]any
 }
 
-func NewCluster(kubernetes *image.Kubernetes, configPath string) (*Cluster, error) {
+// NewCluster creates the cluster configuration from the server and agent configuration files in the given path.
+// Defaults applied to the configuration are reported to the output.
+func NewCluster(kubernetes *image.Kubernetes, configPath string, output *log.Buffer) (*Cluster, error) {
 	serverConfigPath := filepath.Join(configPath, serverConfigFile)
 	serverConfig, err := ParseKubernetesConfig(serverConfigPath)
 	if err != nil {
@@ -50,11 +52,11 @@ func NewCluster(kubernetes *image.Kubernetes, configPath string) (*Cluster, erro
 	}
 
 	if len(kubernetes.Nodes) < 2 {
-		setSingleNodeConfigDefaults(kubernetes, serverConfig)
+		setSingleNodeConfigDefaults(kubernetes, serverConfig, output)
 		return &Cluster{ServerConfig: serverConfig}, nil
 	}
 
-	setMultiNodeConfigDefaults(kubernetes, serverConfig)
+	setMultiNodeConfigDefaults(kubernetes, serverConfig, output)
 
 	agentConfigPath := filepath.Join(configPath, agentConfigFile)
 	agentConfig, err := ParseKubernetesConfig(agentConfigPath)
@@ -133,9 +135,9 @@ func identifyInitialiserNode(kubernetes *image.Kubernetes) string {
 	return ""
 }
 
-func setSingleNodeConfigDefaults(kubernetes *image.Kubernetes, config map[string]any) {
+func setSingleNodeConfigDefaults(kubernetes *image.Kubernetes, config map[string]any, output *log.Buffer) {
 	if strings.Contains(kubernetes.Version, image.KubernetesDistroRKE2) {
-		setClusterCNI(config)
+		setClusterCNI(config, output)
 	}
 	if kubernetes.Network.APIVIP != "" {
 		appendClusterTLSSAN(config, kubernetes.Network.APIVIP)
@@ -150,7 +152,7 @@ func setSingleNodeConfigDefaults(kubernetes *image.Kubernetes, config map[string
 	delete(config, serverKey)
 }
 
-func setMultiNodeConfigDefaults(kubernetes *image.Kubernetes, config map[string]any) {
+func setMultiNodeConfigDefaults(kubernetes *image.Kubernetes, config map[string]any, output *log.Buffer) {
 	const (
 		k3sServerPort  = 6443
 		rke2ServerPort = 9345
@@ -158,7 +160,7 @@ func setMultiNodeConfigDefaults(kubernetes *image.Kubernetes, config map[string]
 
 	if strings.Contains(kubernetes.Version, image.KubernetesDistroRKE2) {
 		setClusterAPIAddress(config, kubernetes.Network.APIVIP, rke2ServerPort)
-		setClusterCNI(config)
+		setClusterCNI(config, output)
 	} else {
 		setClusterAPIAddress(config, kubernetes.Network.APIVIP, k3sServerPort)
 		appendDisabledServices(config, "servicelb")
@@ -173,23 +175,25 @@ func setMultiNodeConfigDefaults(kubernetes *image.Kubernetes, config map[string]
 }
 
 func setClusterToken(config map[string]any) {
-	if _, ok := config[tokenKey].(string); ok {
+	if token, ok := config[tokenKey].(string); ok {
+		log.RegisterSecrets(token)
 		return
 	}
 
 	token := uuid.NewString()
+	log.RegisterSecrets(token)
 
-	zap.S().Infof("Generated cluster token: %s", token)
+	zap.S().Info("Generated cluster token")
 	config[tokenKey] = token
 }
 
-func setClusterCNI(config map[string]any) {
+func setClusterCNI(config map[string]any, output *log.Buffer) {
 	if _, ok := config[cniKey]; ok {
 		return
 	}
 
 	auditMessage := fmt.Sprintf("The Kubernetes CNI is not explicitly set, defaulting to '%s'.", cniDefaultValue)
-	log.Audit(auditMessage)
+	output.Audit(auditMessage)
 
 	zap.S().Infof("CNI not set in config file, proceeding with CNI: %s", cniDefaultValue)
 
@@ -18,7 +18,7 @@ func TestNewCluster_SingleNodeRKE2_MissingConfig(t *testing.T) {
 		},
 	}
 
-	cluster, err := NewCluster(kubernetes, "")
+	cluster, err := NewCluster(kubernetes, "", nil)
 	require.NoError(t, err)
 
 	require.NotNil(t, cluster.ServerConfig)
@@ -43,7 +43,7 @@ func TestNewCluster_SingleNodeK3s_MissingConfig(t *testing.T) {
 		},
 	}
 
-	cluster, err := NewCluster(kubernetes, "")
+	cluster, err := NewCluster(kubernetes, "", nil)
 	require.NoError(t, err)
 
 	require.NotNil(t, cluster.ServerConfig)
@@ -67,7 +67,7 @@ func TestNewCluster_SingleNode_ExistingConfig(t *testing.T) {
 		},
 	}
 
-	cluster, err := NewCluster(kubernetes, "testdata")
+	cluster, err := NewCluster(kubernetes, "testdata", nil)
 	require.NoError(t, err)
 
 	require.NotNil(t, cluster.ServerConfig)
@@ -101,7 +101,7 @@ func TestNewCluster_MultiNodeRKE2_MissingConfig(t *testing.T) {
 		},
 	}
 
-	cluster, err := NewCluster(kubernetes, "")
+	cluster, err := NewCluster(kubernetes, "", nil)
 	require.NoError(t, err)
 
 	assert.Equal(t, "node1.suse.com", cluster.InitialiserName)
@@ -151,7 +151,7 @@ func TestNewCluster_MultiNodeRKE2_ExistingConfig(t *testing.T) {
 		},
 	}
 
-	cluster, err := NewCluster(kubernetes, "testdata")
+	cluster, err := NewCluster(kubernetes, "testdata", nil)
 	require.NoError(t, err)
 
 	assert.Equal(t, "node1.suse.com", cluster.InitialiserName)
@@ -195,7 +195,7 @@ func TestNewCluster_MultiNode_MissingInitialiser(t *testing.T) {
 		},
 	}
 
-	cluster, err := NewCluster(kubernetes, "")
+	cluster, err := NewCluster(kubernetes, "", nil)
 
 	assert.Error(t, err, "failed to determine cluster initialiser")
 	assert.Nil(t, cluster)
@@ -61,3 +61,14 @@ func parseCNIs(cnis []string) (cni string, multusEnabled bool, err error) {
 
 	return cni, multusEnabled, nil
 }
+
+// ConfiguredCNI extracts the CNI from the given server configuration
+// without modifying it, falling back to the default CNI if unset.
+func ConfiguredCNI(serverConfig map[string]any) (cni string, multusEnabled bool, err error) {
+	if _, ok := serverConfig[cniKey]; !ok {
+		return cniDefaultValue, false, nil
+	}
+
+	c := &Cluster{ServerConfig: serverConfig}
+	return c.ExtractCNI()
+}
@@ -16,9 +16,11 @@ const (
 	k3sInstallScriptURL  = "https://get.k3s.io"
 )
 
-type ScriptDownloader struct{}
+type ScriptDownloader struct {
+	Cache http.FileCache
+}
 
-func (d ScriptDownloader) DownloadInstallScript(distribution, destinationPath string) (string, error) {
+func (d ScriptDownloader) DownloadInstallScript(ctx context.Context, distribution, destinationPath string) (string, error) {
 	var scriptURL string
 
 	switch distribution {
@@ -33,7 +35,7 @@ func (d ScriptDownloader) DownloadInstallScript(distribution, destinationPath st
 	installer := fmt.Sprintf("%s_installer.sh", distribution)
 	destinationPath = filepath.Join(destinationPath, installer)
 
-	if err := http.DownloadFile(context.Background(), scriptURL, destinationPath, nil); err != nil {
+	if err := http.DownloadCachedFile(ctx, scriptURL, destinationPath, d.Cache, InstallScriptCacheIdentifier(distribution)); err != nil {
 		return "", fmt.Errorf("downloading script: %w", err)
 	}
 
@@ -43,3 +45,8 @@ func (d ScriptDownloader) DownloadInstallScript(distribution, destinationPath st
 
 	return installer, nil
 }
+
+// InstallScriptCacheIdentifier returns the cache identifier of the install script for the given distribution.
+func InstallScriptCacheIdentifier(distribution string) string {
+	return fmt.Sprintf("install-scripts/%s_installer.sh", distribution)
+}
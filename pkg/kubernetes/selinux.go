@@ -40,9 +40,12 @@ func SELinuxRepository(version string, sources *image.ArtifactSources) (image.Ad
 	}, nil
 }
 
-func DownloadSELinuxRPMsSigningKey(gpgKeysDir string) error {
+// SELinuxSigningKeyCacheIdentifier is the cache identifier of the key used to sign the SELinux RPMs.
+const SELinuxSigningKeyCacheIdentifier = "keys/rancher-public.key"
+
+func DownloadSELinuxRPMsSigningKey(ctx context.Context, gpgKeysDir string, cache http.FileCache) error {
 	const rancherSigningKeyURL = "https://rpm.rancher.io/public.key"
 	var signingKeyPath = filepath.Join(gpgKeysDir, "rancher-public.key")
 
-	return http.DownloadFile(context.Background(), rancherSigningKeyURL, signingKeyPath, nil)
+	return http.DownloadCachedFile(ctx, rancherSigningKeyURL, signingKeyPath, cache, SELinuxSigningKeyCacheIdentifier)
 }
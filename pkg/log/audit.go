@@ -14,50 +14,152 @@ const (
 
 	messageSuccess = "SUCCESS"
 	messageSkipped = "SKIPPED"
+	messageReused  = "REUSED " // leave the trailing space for consistent lengths
 	messageFailed  = "FAILED " // leave the trailing space for consistent lengths
+
+	warningPrefix = "WARNING: "
 )
 
 // Audit displays a message to the user. This shouldn't be used for debug logging purposes; all
 // messages passed in here should be user-readable.
 func Audit(message string) {
-	doAudit(message, nil)
+	doAudit(EventMessage, message, nil)
 }
 
 func Auditf(message string, args ...any) {
 	auditMe := fmt.Sprintf(message, args...)
-	doAudit(auditMe, nil)
+	doAudit(EventMessage, auditMe, nil)
 }
 
 func AuditInfo(message string) {
-	doAudit(message, zap.S().Info)
+	doAudit(EventMessage, message, zap.S().Info)
 }
 
 func AuditInfof(message string, args ...any) {
 	auditMe := fmt.Sprintf(message, args...)
-	doAudit(auditMe, zap.S().Info)
+	doAudit(EventMessage, auditMe, zap.S().Info)
+}
+
+// AuditWarning displays a warning to the user. The message is prefixed accordingly in the human-readable output.
+func AuditWarning(message string) {
+	doAudit(EventWarning, message, zap.S().Warn)
+}
+
+func AuditWarningf(message string, args ...any) {
+	auditMe := fmt.Sprintf(message, args...)
+	doAudit(EventWarning, auditMe, zap.S().Warn)
 }
 
 func AuditError(message string) {
-	doAudit(message, zap.S().Error)
+	doAudit(EventError, message, zap.S().Error)
+}
+
+func AuditErrorf(message string, args ...any) {
+	auditMe := fmt.Sprintf(message, args...)
+	doAudit(EventError, auditMe, zap.S().Error)
+}
+
+// AuditResult reports the final result of the executed command.
+// It is only displayed in the JSON output, since the human-readable output
+// already conveys the result through the preceding messages.
+func AuditResult(err error) {
+	if currentFormat() != OutputFormatJSON {
+		return
+	}
+
+	event := Event{Type: EventResult, Status: ResultSuccessful}
+	if err != nil {
+		event.Status = ResultFailed
+		event.Error = Redact(err.Error())
+	}
+
+	emit(&event)
+}
+
+// AuditValidationFailure reports a single problem found in the image definition along with its severity
+// ("error" or "warning") and location. It is only displayed in the JSON output, since the human-readable
+// output lists all problems in a single message.
+func AuditValidationFailure(component, message, severity string, location *Location) {
+	if currentFormat() != OutputFormatJSON {
+		return
+	}
+
+	emit(&Event{Type: EventValidationFailure, Component: component, Message: Redact(message), Severity: severity, Location: location})
+}
+
+// Component statuses passed to the observer registered through SetComponentStatusObserver.
+const (
+	ComponentStatusSuccessful = "successful"
+	ComponentStatusSkipped    = "skipped"
+	ComponentStatusReused     = "reused"
+	ComponentStatusFailed     = "failed"
+)
+
+var componentStatusObserver func(component, status string)
+
+// SetComponentStatusObserver registers a function which is notified of every audited component status.
+// Passing nil removes the observer.
+func SetComponentStatusObserver(observer func(component, status string)) {
+	componentStatusObserver = observer
+}
+
+// AuditComponentStarted reports that the configuration of a component has started.
+// It is only displayed in the JSON output.
+func AuditComponentStarted(component string) {
+	if currentFormat() != OutputFormatJSON {
+		return
+	}
+
+	emit(&Event{Type: EventComponentStarted, Component: component})
 }
 
 func AuditComponentSuccessful(component string) {
-	message := formatComponentStatus(component, messageSuccess)
-	Audit(message)
+	auditComponentStatus(component, messageSuccess, EventComponentSuccessful)
+	notifyComponentStatus(component, ComponentStatusSuccessful)
 }
 
 func AuditComponentSkipped(component string) {
-	message := formatComponentStatus(component, messageSkipped)
-	Audit(message)
+	auditComponentStatus(component, messageSkipped, EventComponentSkipped)
+	notifyComponentStatus(component, ComponentStatusSkipped)
+}
+
+// AuditComponentReused reports that the outputs of a component have been reused from a previous build.
+func AuditComponentReused(component string) {
+	auditComponentStatus(component, messageReused, EventComponentReused)
+	notifyComponentStatus(component, ComponentStatusReused)
 }
 
 func AuditComponentFailed(component string) {
-	message := formatComponentStatus(component, messageFailed)
-	Audit(message)
+	auditComponentStatus(component, messageFailed, EventComponentFailed)
+	notifyComponentStatus(component, ComponentStatusFailed)
 }
 
-func doAudit(message string, logFunc func(args ...any)) {
-	fmt.Println(message)
+func auditComponentStatus(component, status, eventType string) {
+	if currentFormat() == OutputFormatJSON {
+		emit(&Event{Type: eventType, Component: component})
+		return
+	}
+
+	printLine(formatComponentStatus(component, status))
+}
+
+func notifyComponentStatus(component, status string) {
+	if componentStatusObserver != nil {
+		componentStatusObserver(component, status)
+	}
+}
+
+func doAudit(eventType, message string, logFunc func(args ...any)) {
+	message = Redact(message)
+
+	if currentFormat() == OutputFormatJSON {
+		emit(&Event{Type: eventType, Message: message})
+	} else if eventType == EventWarning {
+		printLine(warningPrefix + message)
+	} else {
+		printLine(message)
+	}
+
 	if logFunc != nil {
 		logFunc(message)
 	}
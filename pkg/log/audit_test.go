@@ -26,6 +26,12 @@ func TestFormatComponentStatus(t *testing.T) {
 			status:    messageSkipped,
 			expected:  "My Component ................. [SKIPPED]",
 		},
+		{
+			testName:  "Reused message",
+			component: "rpm",
+			status:    messageReused,
+			expected:  "Rpm .......................... [REUSED ]",
+		},
 		{
 			testName:  "Failed message",
 			component: "MYCOMPONENT",
@@ -42,3 +48,24 @@ func TestFormatComponentStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestComponentStatusObserver(t *testing.T) {
+	statuses := map[string]string{}
+
+	SetComponentStatusObserver(func(component, status string) {
+		statuses[component] = status
+	})
+	defer SetComponentStatusObserver(nil)
+
+	AuditComponentSuccessful("users")
+	AuditComponentSkipped("proxy")
+	AuditComponentReused("kubernetes")
+	AuditComponentFailed("rpm")
+
+	assert.Equal(t, map[string]string{
+		"users":      ComponentStatusSuccessful,
+		"proxy":      ComponentStatusSkipped,
+		"kubernetes": ComponentStatusReused,
+		"rpm":        ComponentStatusFailed,
+	}, statuses)
+}
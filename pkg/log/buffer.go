@@ -0,0 +1,127 @@
+package log
+
+import (
+	"context"
+	"sync"
+)
+
+// Buffer holds the audit output of an operation running concurrently with others,
+// so that it can be displayed in a deterministic order once the operation completes.
+// Methods called on a nil Buffer display the output immediately.
+type Buffer struct {
+	mu      sync.Mutex
+	entries []func()
+}
+
+func NewBuffer() *Buffer {
+	return &Buffer{}
+}
+
+type outputKey struct{}
+
+// WithOutput returns a copy of the context through which the audit output of the operations
+// it is passed to, such as downloads, is held by the given buffer.
+func WithOutput(ctx context.Context, output *Buffer) context.Context {
+	return context.WithValue(ctx, outputKey{}, output)
+}
+
+// Output returns the buffer holding the audit output of operations run with the context.
+// The returned buffer is nil, i.e. displays the output immediately, if none has been set.
+func Output(ctx context.Context) *Buffer {
+	output, _ := ctx.Value(outputKey{}).(*Buffer)
+	return output
+}
+
+func (b *Buffer) add(entry func()) {
+	if b == nil {
+		entry()
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.entries = append(b.entries, entry)
+}
+
+// Flush displays the buffered output and empties the buffer.
+func (b *Buffer) Flush() {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	entries := b.entries
+	b.entries = nil
+	b.mu.Unlock()
+
+	for _, entry := range entries {
+		entry()
+	}
+}
+
+func (b *Buffer) Audit(message string) {
+	b.add(func() { Audit(message) })
+}
+
+func (b *Buffer) Auditf(message string, args ...any) {
+	b.add(func() { Auditf(message, args...) })
+}
+
+func (b *Buffer) AuditInfo(message string) {
+	b.add(func() { AuditInfo(message) })
+}
+
+func (b *Buffer) AuditWarning(message string) {
+	b.add(func() { AuditWarning(message) })
+}
+
+func (b *Buffer) AuditWarningf(message string, args ...any) {
+	b.add(func() { AuditWarningf(message, args...) })
+}
+
+func (b *Buffer) AuditErrorf(message string, args ...any) {
+	b.add(func() { AuditErrorf(message, args...) })
+}
+
+// NewProgressBar creates a progress bar for an operation consisting of the given number of items.
+// Progress of buffered operations cannot be displayed once they complete, so only the description is.
+func (b *Buffer) NewProgressBar(total int64, description string) ProgressBar {
+	if b == nil {
+		return NewProgressBar(total, description)
+	}
+
+	b.Audit(description)
+	return discardProgressBar{}
+}
+
+// NewBytesProgressBar creates a progress bar for an operation processing the given number of bytes.
+// Progress of buffered operations cannot be displayed once they complete, so only the description is.
+func (b *Buffer) NewBytesProgressBar(total int64, description string) ProgressBar {
+	if b == nil {
+		return NewBytesProgressBar(total, description)
+	}
+
+	b.Audit(description)
+	return discardProgressBar{}
+}
+
+func (b *Buffer) AuditComponentStarted(component string) {
+	b.add(func() { AuditComponentStarted(component) })
+}
+
+func (b *Buffer) AuditComponentSuccessful(component string) {
+	b.add(func() { AuditComponentSuccessful(component) })
+}
+
+func (b *Buffer) AuditComponentSkipped(component string) {
+	b.add(func() { AuditComponentSkipped(component) })
+}
+
+func (b *Buffer) AuditComponentReused(component string) {
+	b.add(func() { AuditComponentReused(component) })
+}
+
+func (b *Buffer) AuditComponentFailed(component string) {
+	b.add(func() { AuditComponentFailed(component) })
+}
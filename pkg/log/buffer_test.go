@@ -0,0 +1,63 @@
+package log
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffer(t *testing.T) {
+	buf := captureOutput(t, OutputFormatHuman)
+
+	b := NewBuffer()
+	b.AuditComponentStarted("users")
+	b.Auditf("Resolving %d packages...", 2)
+	b.AuditWarning("Package signature validation is disabled")
+	b.AuditComponentSuccessful("users")
+
+	assert.Empty(t, buf.String())
+
+	b.Flush()
+	expected := "Resolving 2 packages...\n" +
+		"WARNING: Package signature validation is disabled\n" +
+		"Users ........................ [SUCCESS]\n"
+	assert.Equal(t, expected, buf.String())
+
+	// Flushing again must not repeat the output
+	b.Flush()
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestBuffer_Nil(t *testing.T) {
+	buf := captureOutput(t, OutputFormatHuman)
+
+	var b *Buffer
+	b.Audit("Generating image customization components...")
+	assert.Equal(t, "Generating image customization components...\n", buf.String())
+
+	b.Flush()
+	assert.Equal(t, "Generating image customization components...\n", buf.String())
+}
+
+func TestBuffer_ProgressBar(t *testing.T) {
+	buf := captureOutput(t, OutputFormatJSON)
+
+	b := NewBuffer()
+	bar := b.NewBytesProgressBar(100, "Downloading file: rke2.linux-amd64.tar.gz")
+	_, err := bar.Write(make([]byte, 100))
+	assert.NoError(t, err)
+
+	// Only the description is displayed, once the buffer is flushed
+	assert.Empty(t, buf.String())
+
+	b.Flush()
+	assert.Equal(t, []Event{{Type: EventMessage, Message: "Downloading file: rke2.linux-amd64.tar.gz"}}, decodeEvents(t, buf))
+}
+
+func TestOutput(t *testing.T) {
+	assert.Nil(t, Output(context.Background()))
+
+	b := NewBuffer()
+	assert.Same(t, b, Output(WithOutput(context.Background(), b)))
+}
@@ -13,7 +13,10 @@ func ConfigureGlobalLogger(logFilename string) {
 	logConfig.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	logConfig.OutputPaths = []string{logFilename}
 
-	logger := zap.Must(logConfig.Build())
+	// Secrets registered at any point are scrubbed from all subsequent entries
+	logger := zap.Must(logConfig.Build(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return &redactingCore{Core: core}
+	})))
 
 	// Set our configured logger to be accessed globally by zap.L()
 	zap.ReplaceGlobals(logger)
@@ -0,0 +1,116 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	OutputFormatHuman = "human"
+	OutputFormatJSON  = "json"
+)
+
+// Event types emitted in the JSON output. These values are part of the
+// public interface of EIB and must not be changed.
+const (
+	EventMessage             = "message"
+	EventWarning             = "warning"
+	EventError               = "error"
+	EventComponentStarted    = "component.started"
+	EventComponentSuccessful = "component.successful"
+	EventComponentSkipped    = "component.skipped"
+	EventComponentReused     = "component.reused"
+	EventComponentFailed     = "component.failed"
+	EventProgress            = "progress"
+	EventResult              = "result"
+	EventValidationFailure   = "validation.failure"
+)
+
+const (
+	ResultSuccessful = "successful"
+	ResultFailed     = "failed"
+)
+
+// Event is a single audit event written as one line in the JSON output.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	Message   string    `json:"message,omitempty"`
+	Component string    `json:"component,omitempty"`
+	Progress  *Progress `json:"progress,omitempty"`
+	Status    string    `json:"status,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Severity  string    `json:"severity,omitempty"`
+	Location  *Location `json:"location,omitempty"`
+}
+
+// Location identifies a value in the image definition file or in the include or overlay file it was read from.
+type Location struct {
+	Path   string `json:"path,omitempty"`
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+type Progress struct {
+	Description string `json:"description"`
+	// Unit is either "bytes" or "items".
+	Unit    string `json:"unit"`
+	Current int64  `json:"current"`
+	Total   int64  `json:"total"`
+}
+
+var (
+	outputMu     sync.Mutex
+	outputFormat           = OutputFormatHuman
+	output       io.Writer = os.Stdout
+)
+
+// SetOutputFormat configures how audit messages are displayed to the user.
+func SetOutputFormat(format string) error {
+	switch format {
+	case OutputFormatHuman, OutputFormatJSON:
+	default:
+		return fmt.Errorf("unsupported output format '%s', must be either '%s' or '%s'",
+			format, OutputFormatHuman, OutputFormatJSON)
+	}
+
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	outputFormat = format
+	return nil
+}
+
+func currentFormat() string {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	return outputFormat
+}
+
+func printLine(message string) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	_, _ = fmt.Fprintln(output, message)
+}
+
+func emit(event *Event) {
+	event.Time = time.Now().UTC()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		// Events consist of plain values only, so this should never happen
+		data = []byte(fmt.Sprintf(`{"type":%q,"error":%q}`, EventError, err.Error()))
+	}
+
+	outputMu.Lock()
+	defer outputMu.Unlock()
+
+	_, _ = fmt.Fprintln(output, string(data))
+}
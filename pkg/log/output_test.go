@@ -0,0 +1,126 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func captureOutput(t *testing.T, format string) *bytes.Buffer {
+	var buf bytes.Buffer
+
+	require.NoError(t, SetOutputFormat(format))
+	previous := output
+	output = &buf
+
+	t.Cleanup(func() {
+		require.NoError(t, SetOutputFormat(OutputFormatHuman))
+		output = previous
+	})
+
+	return &buf
+}
+
+func decodeEvents(t *testing.T, buf *bytes.Buffer) []Event {
+	var events []Event
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var event Event
+		require.NoError(t, json.Unmarshal([]byte(line), &event), line)
+		assert.False(t, event.Time.IsZero())
+
+		// Only the presence of the timestamp is relevant for the comparisons
+		event.Time = time.Time{}
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func TestSetOutputFormat_Invalid(t *testing.T) {
+	assert.EqualError(t, SetOutputFormat("yaml"), "unsupported output format 'yaml', must be either 'human' or 'json'")
+}
+
+func TestHumanOutput(t *testing.T) {
+	buf := captureOutput(t, OutputFormatHuman)
+
+	AuditComponentStarted("users")
+	Audit("Generating image customization components...")
+	AuditWarning("Running EIB with disabled GPG validation is intended for development purposes only")
+	AuditComponentSuccessful("users")
+	AuditValidationFailure("Operating System", "Duplicate username found: bob", "error", &Location{Path: "operatingSystem.users[1].username", Line: 12, Column: 17})
+	AuditResult(nil)
+
+	assert.Equal(t, "Generating image customization components...\n"+
+		"WARNING: Running EIB with disabled GPG validation is intended for development purposes only\n"+
+		"Users ........................ [SUCCESS]\n", buf.String())
+}
+
+func TestJSONOutput(t *testing.T) {
+	buf := captureOutput(t, OutputFormatJSON)
+
+	Audit("Generating image customization components...")
+	AuditComponentStarted("users")
+	AuditComponentSuccessful("users")
+	AuditComponentStarted("proxy")
+	AuditComponentSkipped("proxy")
+	AuditComponentStarted("kubernetes")
+	AuditComponentReused("kubernetes")
+	AuditComponentStarted("rpm")
+	AuditWarningf("Running EIB with disabled GPG validation is intended for %s purposes only", "development")
+	AuditComponentFailed("rpm")
+	AuditErrorf("Resolving package '%s' failed", "vim")
+	AuditValidationFailure("Operating System", "Duplicate username found: bob", "error", &Location{Path: "operatingSystem.users[1].username", File: "sites/site-a.yaml", Line: 12, Column: 17})
+	AuditResult(errors.New("configuring image: boom"))
+
+	expected := []Event{
+		{Type: EventMessage, Message: "Generating image customization components..."},
+		{Type: EventComponentStarted, Component: "users"},
+		{Type: EventComponentSuccessful, Component: "users"},
+		{Type: EventComponentStarted, Component: "proxy"},
+		{Type: EventComponentSkipped, Component: "proxy"},
+		{Type: EventComponentStarted, Component: "kubernetes"},
+		{Type: EventComponentReused, Component: "kubernetes"},
+		{Type: EventComponentStarted, Component: "rpm"},
+		{Type: EventWarning, Message: "Running EIB with disabled GPG validation is intended for development purposes only"},
+		{Type: EventComponentFailed, Component: "rpm"},
+		{Type: EventError, Message: "Resolving package 'vim' failed"},
+		{
+			Type:      EventValidationFailure,
+			Component: "Operating System",
+			Message:   "Duplicate username found: bob",
+			Severity:  "error",
+			Location:  &Location{Path: "operatingSystem.users[1].username", File: "sites/site-a.yaml", Line: 12, Column: 17},
+		},
+		{Type: EventResult, Status: ResultFailed, Error: "configuring image: boom"},
+	}
+	assert.Equal(t, expected, decodeEvents(t, buf))
+}
+
+func TestJSONOutput_Progress(t *testing.T) {
+	buf := captureOutput(t, OutputFormatJSON)
+
+	bar := NewBytesProgressBar(100, "Downloading file: rke2.tar.gz")
+	for i := 0; i < 20; i++ {
+		_, err := bar.Write(make([]byte, 5))
+		require.NoError(t, err)
+	}
+
+	events := decodeEvents(t, buf)
+	require.Len(t, events, 11)
+
+	for i, event := range events {
+		assert.Equal(t, EventProgress, event.Type)
+		require.NotNil(t, event.Progress)
+		assert.Equal(t, "Downloading file: rke2.tar.gz", event.Progress.Description)
+		assert.Equal(t, progressUnitBytes, event.Progress.Unit)
+		assert.EqualValues(t, i*10, event.Progress.Current)
+		assert.EqualValues(t, 100, event.Progress.Total)
+	}
+}
@@ -0,0 +1,124 @@
+package log
+
+import (
+	"sync"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+const (
+	progressUnitBytes = "bytes"
+	progressUnitItems = "items"
+
+	// progressSteps is the number of progress events emitted over the
+	// course of an operation in the JSON output (i.e. every 10%).
+	progressSteps = 10
+)
+
+// ProgressBar tracks the progress of a long-running operation.
+type ProgressBar interface {
+	Add(num int) error
+	Add64(num int64) error
+	Write(p []byte) (int, error)
+}
+
+// NewProgressBar creates a progress bar for an operation consisting of the given number of items.
+func NewProgressBar(total int64, description string) ProgressBar {
+	if currentFormat() == OutputFormatJSON {
+		return newEventProgressBar(total, description, progressUnitItems)
+	}
+
+	return progressbar.Default(total, description)
+}
+
+// NewBytesProgressBar creates a progress bar for an operation (e.g. a download) processing the given number of bytes.
+func NewBytesProgressBar(total int64, description string) ProgressBar {
+	if currentFormat() == OutputFormatJSON {
+		return newEventProgressBar(total, description, progressUnitBytes)
+	}
+
+	return progressbar.DefaultBytes(total, description)
+}
+
+// discardProgressBar tracks no progress.
+type discardProgressBar struct{}
+
+func (discardProgressBar) Add(int) error {
+	return nil
+}
+
+func (discardProgressBar) Add64(int64) error {
+	return nil
+}
+
+func (discardProgressBar) Write(p []byte) (int, error) {
+	return len(p), nil
+}
+
+// eventProgressBar emits progress events instead of rendering a bar.
+type eventProgressBar struct {
+	mu          sync.Mutex
+	description string
+	unit        string
+	current     int64
+	total       int64
+	lastStep    int64
+}
+
+func newEventProgressBar(total int64, description, unit string) *eventProgressBar {
+	bar := &eventProgressBar{
+		description: description,
+		unit:        unit,
+		total:       total,
+		lastStep:    -1,
+	}
+
+	bar.emit()
+	return bar
+}
+
+func (b *eventProgressBar) Add(num int) error {
+	return b.Add64(int64(num))
+}
+
+func (b *eventProgressBar) Add64(num int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.current += num
+	b.emit()
+
+	return nil
+}
+
+func (b *eventProgressBar) Write(p []byte) (int, error) {
+	if err := b.Add(len(p)); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// emit only reports the progress once a new step has been reached
+// in order to avoid flooding the output with byte-level updates.
+func (b *eventProgressBar) emit() {
+	step := int64(0)
+	if b.total > 0 {
+		step = b.current * progressSteps / b.total
+	}
+
+	if step == b.lastStep {
+		return
+	}
+	b.lastStep = step
+
+	emit(&Event{
+		Type: EventProgress,
+		Progress: &Progress{
+			Description: b.description,
+			Unit:        b.unit,
+			Current:     b.current,
+			Total:       b.total,
+		},
+	})
+}
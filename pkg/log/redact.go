@@ -0,0 +1,146 @@
+package log
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	// RedactedPlaceholder replaces every occurrence of a registered secret.
+	RedactedPlaceholder = "[REDACTED]"
+
+	// Shorter values are not redacted since scrubbing them would garble unrelated output.
+	minSecretLength = 4
+
+	logFileSuffix = ".log"
+)
+
+var (
+	redactMu sync.RWMutex
+	secrets  []string
+	redactor = strings.NewReplacer()
+)
+
+// RegisterSecrets adds values which are scrubbed from the build log, the audit output and the tool
+// log files from now on. Values shorter than four characters are ignored.
+func RegisterSecrets(values ...string) {
+	redactMu.Lock()
+	defer redactMu.Unlock()
+
+	for _, value := range values {
+		if len(value) < minSecretLength || slices.Contains(secrets, value) {
+			continue
+		}
+
+		secrets = append(secrets, value)
+	}
+
+	// Longer secrets are replaced first so that secrets containing others are scrubbed entirely
+	slices.SortFunc(secrets, func(a, b string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, secret := range secrets {
+		pairs = append(pairs, secret, RedactedPlaceholder)
+	}
+
+	redactor = strings.NewReplacer(pairs...)
+}
+
+// Redact replaces every registered secret in the message.
+func Redact(message string) string {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+
+	return redactor.Replace(message)
+}
+
+// RedactLogFiles scrubs the registered secrets from all log files written by the tools run during
+// the build, which are located anywhere under the given directory.
+func RedactLogFiles(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() || !strings.HasSuffix(d.Name(), logFileSuffix) {
+			return nil
+		}
+
+		return redactFile(path)
+	})
+}
+
+func redactFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading log file '%s': %w", path, err)
+	}
+
+	redacted := []byte(Redact(string(data)))
+	if bytes.Equal(data, redacted) {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("describing log file '%s': %w", path, err)
+	}
+
+	if err = os.WriteFile(path, redacted, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("writing log file '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+// redactingCore scrubs the registered secrets from every entry written to the wrapped core.
+type redactingCore struct {
+	zapcore.Core
+}
+
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+func (c *redactingCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = Redact(entry.Message)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	redacted := make([]zapcore.Field, 0, len(fields))
+
+	for _, field := range fields {
+		switch field.Type {
+		case zapcore.StringType:
+			field.String = Redact(field.String)
+		case zapcore.ErrorType:
+			if err, ok := field.Interface.(error); ok {
+				field = zapcore.Field{Key: field.Key, Type: zapcore.StringType, String: Redact(err.Error())}
+			}
+		}
+
+		redacted = append(redacted, field)
+	}
+
+	return redacted
+}
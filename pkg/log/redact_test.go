@@ -0,0 +1,88 @@
+package log
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func resetSecrets(t *testing.T) {
+	t.Cleanup(func() {
+		redactMu.Lock()
+		defer redactMu.Unlock()
+
+		secrets = nil
+		redactor = strings.NewReplacer()
+	})
+}
+
+func TestRedact(t *testing.T) {
+	resetSecrets(t)
+
+	assert.Equal(t, "helm repo add --password s3cr3t", Redact("helm repo add --password s3cr3t"))
+
+	RegisterSecrets("s3cr3t", "s3cr3t-extended", "abc", "")
+
+	assert.Equal(t, "helm repo add --password [REDACTED]", Redact("helm repo add --password s3cr3t"))
+	assert.Equal(t, "token: [REDACTED]", Redact("token: s3cr3t-extended"))
+	assert.Equal(t, "abc", Redact("abc"))
+}
+
+func TestRedactLogFiles(t *testing.T) {
+	resetSecrets(t)
+	RegisterSecrets("s3cr3t")
+
+	dir := t.TempDir()
+
+	toolLog := filepath.Join(dir, "combustion", "helm-repo-add.log")
+	require.NoError(t, os.MkdirAll(filepath.Dir(toolLog), 0o755))
+	require.NoError(t, os.WriteFile(toolLog, []byte("command: helm repo add --password s3cr3t\n"), 0o600))
+
+	script := filepath.Join(dir, "combustion", "10-rpm-install.sh")
+	require.NoError(t, os.WriteFile(script, []byte("SUSEConnect -r s3cr3t\n"), 0o700))
+
+	require.NoError(t, RedactLogFiles(dir))
+
+	data, err := os.ReadFile(toolLog)
+	require.NoError(t, err)
+	assert.Equal(t, "command: helm repo add --password [REDACTED]\n", string(data))
+
+	info, err := os.Stat(toolLog)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Scripts executed on the node require the secrets and must not be modified
+	data, err = os.ReadFile(script)
+	require.NoError(t, err)
+	assert.Equal(t, "SUSEConnect -r s3cr3t\n", string(data))
+}
+
+func TestRedactingCore(t *testing.T) {
+	resetSecrets(t)
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(&redactingCore{Core: core})
+
+	RegisterSecrets("s3cr3t")
+
+	logger.With(zap.String("password", "s3cr3t")).Info("Using token s3cr3t", zap.Error(errors.New("login with s3cr3t failed")))
+	logger.Sugar().Infof("Generated token: %s", "s3cr3t")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "Using token [REDACTED]", entries[0].Message)
+	assert.Equal(t, map[string]any{
+		"password": "[REDACTED]",
+		"error":    "login with [REDACTED] failed",
+	}, entries[0].ContextMap())
+	assert.Equal(t, "Generated token: [REDACTED]", entries[1].Message)
+}
@@ -1,15 +1,18 @@
 package network
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os/exec"
+
+	"github.com/suse-edge/edge-image-builder/pkg/process"
 )
 
 type ConfigGenerator struct{}
 
-func (ConfigGenerator) GenerateNetworkConfig(configDir, outputDir string, outputWriter io.Writer) error {
-	cmd := generateCommand(configDir, outputDir, outputWriter)
+func (ConfigGenerator) GenerateNetworkConfig(ctx context.Context, configDir, outputDir string, outputWriter io.Writer) error {
+	cmd := generateCommand(ctx, configDir, outputDir, outputWriter)
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("running generate command: %w", err)
 	}
@@ -17,8 +20,8 @@ func (ConfigGenerator) GenerateNetworkConfig(configDir, outputDir string, output
 	return nil
 }
 
-func generateCommand(configDir, outputDir string, output io.Writer) *exec.Cmd {
-	cmd := exec.Command("nmc", "generate",
+func generateCommand(ctx context.Context, configDir, outputDir string, output io.Writer) *exec.Cmd {
+	cmd := process.Command(ctx, "nmc", "generate",
 		"--config-dir", configDir,
 		"--output-dir", outputDir)
 
@@ -1,12 +1,14 @@
 package network
 
 import (
+	"context"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/process"
 )
 
 func setup(t *testing.T) (inputDir, outputDir string, teardown func()) {
@@ -28,7 +30,7 @@ func TestGenerateCommand(t *testing.T) {
 
 	var sb strings.Builder
 
-	cmd := generateCommand(inputConfigDir, outputConfigDir, &sb)
+	cmd := generateCommand(context.Background(), inputConfigDir, outputConfigDir, &sb)
 
 	expectedArgs := []string{
 		"nmc",
@@ -40,6 +42,10 @@ func TestGenerateCommand(t *testing.T) {
 	assert.Equal(t, expectedArgs, cmd.Args)
 	assert.Equal(t, &sb, cmd.Stdout)
 	assert.Equal(t, &sb, cmd.Stderr)
+
+	// The command is interrupted along with the build
+	assert.NotNil(t, cmd.Cancel)
+	assert.Equal(t, process.WaitDelay, cmd.WaitDelay)
 }
 
 // TODO: Set up working example once nmc is available as an RPM
@@ -50,7 +56,7 @@ func TestConfigGenerator_GenerateNetworkConfig_MissingExecutable(t *testing.T) {
 	var sb strings.Builder
 	var generator ConfigGenerator
 
-	err := generator.GenerateNetworkConfig(inputConfigDir, outputConfigDir, &sb)
+	err := generator.GenerateNetworkConfig(context.Background(), inputConfigDir, outputConfigDir, &sb)
 	require.Error(t, err)
 	assert.ErrorContains(t, err, "running generate command")
 	assert.ErrorContains(t, err, "executable file not found")
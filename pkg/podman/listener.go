@@ -1,6 +1,7 @@
 package podman
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -22,7 +23,7 @@ const (
 
 // creates a listening service that answers API calls for Podman (https://docs.podman.io/en/v4.8.3/markdown/podman-system-service.1.html)
 // only way to start the service from within a container - https://github.com/containers/podman/tree/v4.8.3/pkg/bindings#starting-the-service-manually
-func setupAPIListener(out string) error {
+func setupAPIListener(ctx context.Context, out string) error {
 	log.AuditInfo("Setting up Podman API listener...")
 
 	logFile, err := os.Create(filepath.Join(out, podmanListenerLogFile))
@@ -38,7 +39,7 @@ func setupAPIListener(out string) error {
 		return fmt.Errorf("error running podman system service: %w", err)
 	}
 
-	return waitForPodmanSock()
+	return waitForPodmanSock(ctx)
 }
 
 func preparePodmanCommand(out io.Writer) *exec.Cmd {
@@ -50,7 +51,7 @@ func preparePodmanCommand(out io.Writer) *exec.Cmd {
 	return cmd
 }
 
-func waitForPodmanSock() error {
+func waitForPodmanSock(ctx context.Context) error {
 	const (
 		retries      = 5
 		sleepSeconds = 3
@@ -64,7 +65,11 @@ func waitForPodmanSock() error {
 		}
 
 		zap.S().Infof("'%s' file is not yet created, retrying in %d seconds", podmanSocketPath, sleepSeconds)
-		time.Sleep(sleepSeconds * time.Second)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for '%s' file: %w", podmanSocketPath, context.Cause(ctx))
+		case <-time.After(sleepSeconds * time.Second):
+		}
 	}
 
 	return fmt.Errorf("'%s' file was not created in the expected time", podmanSocketPath)
@@ -36,8 +36,10 @@ type Podman struct {
 //
 // Parameters:
 //   - out - location for podman to output any logs created as a result of podman commands
-func New(out string) (*Podman, error) {
-	if err := setupAPIListener(out); err != nil {
+//
+// The connection outlives the given context, which only cancels the setup itself.
+func New(ctx context.Context, out string) (*Podman, error) {
+	if err := setupAPIListener(ctx, out); err != nil {
 		return nil, fmt.Errorf("creating new podman instance: %w", err)
 	}
 
@@ -52,18 +54,34 @@ func New(out string) (*Podman, error) {
 	}, nil
 }
 
+// callContext returns a context carrying the podman connection which is cancelled along with the given context.
+func (p *Podman) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	callCtx, cancel := context.WithCancel(p.context)
+	stop := context.AfterFunc(ctx, cancel)
+
+	return callCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
 // Import imports a tarball and saves it as a filesystem image
 //
 // Parameters:
 //   - tarball - path to the tarball to be imported
 //   - ref 	  - name for the image that will be created from the tarball
-func (p *Podman) Import(tarball, ref string) error {
+func (p *Podman) Import(ctx context.Context, tarball, ref string) error {
 	zap.S().Infof("Importing image '%s' from tarball...", ref)
 	f, err := os.Open(tarball)
 	if err != nil {
 		return fmt.Errorf("opening tarball %s: %w", tarball, err)
 	}
-	_, err = images.Import(p.context, f, &images.ImportOptions{Reference: &ref})
+	defer f.Close()
+
+	callCtx, cancel := p.callContext(ctx)
+	defer cancel()
+
+	_, err = images.Import(callCtx, f, &images.ImportOptions{Reference: &ref})
 	if err != nil {
 		return fmt.Errorf("importing tarball %s: %w", tarball, err)
 	}
@@ -73,7 +91,7 @@ func (p *Podman) Import(tarball, ref string) error {
 
 // Build looks for a 'Dockerfile' in the given context and build a podman image
 // from it.
-func (p *Podman) Build(imageContext, imageName string) error {
+func (p *Podman) Build(ctx context.Context, imageContext, imageName string) error {
 	zap.S().Infof("Building image %s...", imageName)
 
 	logFile, err := os.Create(filepath.Join(p.out, podmanBuildLogFile))
@@ -91,7 +109,10 @@ func (p *Podman) Build(imageContext, imageName string) error {
 		},
 	}
 
-	_, err = images.Build(p.context, []string{dockerfile}, eOpts)
+	callCtx, cancel := p.callContext(ctx)
+	defer cancel()
+
+	_, err = images.Build(callCtx, []string{dockerfile}, eOpts)
 	if err != nil {
 		return fmt.Errorf("building image from context %s: %w", imageContext, err)
 	}
@@ -100,11 +121,14 @@ func (p *Podman) Build(imageContext, imageName string) error {
 }
 
 // Create creates a container from the given image. Returns the id of the container.
-func (p *Podman) Create(img string) (string, error) {
+func (p *Podman) Create(ctx context.Context, img string) (string, error) {
 	zap.S().Infof("Creating container from %s image...", img)
 
+	callCtx, cancel := p.callContext(ctx)
+	defer cancel()
+
 	s := specgen.NewSpecGenerator(img, false)
-	createResponse, err := containers.CreateWithSpec(p.context, s, nil)
+	createResponse, err := containers.CreateWithSpec(callCtx, s, nil)
 	if err != nil {
 		return "", fmt.Errorf("creating container with spec %v: %w", s, err)
 	}
@@ -117,13 +141,16 @@ func (p *Podman) Create(img string) (string, error) {
 //
 // Note: No need to create a placeholder file/directory in dest. The file/directory will be created
 // automatically upon copying from source.
-func (p *Podman) Copy(id, src, dest string) error {
+func (p *Podman) Copy(ctx context.Context, id, src, dest string) error {
 	zap.S().Infof("Copying %s from container %s to %s", src, id, dest)
 
 	reader, writer := io.Pipe()
 	defer reader.Close()
 
-	copyFunc, err := containers.CopyToArchive(p.context, id, src, writer)
+	callCtx, cancel := p.callContext(ctx)
+	defer cancel()
+
+	copyFunc, err := containers.CopyToArchive(callCtx, id, src, writer)
 	if err != nil {
 		return fmt.Errorf("creating copy function for archive from %s: %w", src, err)
 	}
@@ -134,7 +161,9 @@ func (p *Podman) Copy(id, src, dest string) error {
 			zap.S().Errorf("Copying %s to %s failed: %s", src, dest, err)
 		}
 
-		writer.Close()
+		// Failed copies must not end the archive as if it were complete, which happens if they are
+		// interrupted at an entry boundary, so that the extraction fails instead of leaving partial contents
+		writer.CloseWithError(err)
 	}()
 
 	if err := untar(reader, dest); err != nil {
@@ -144,6 +173,19 @@ func (p *Podman) Copy(id, src, dest string) error {
 	return nil
 }
 
+// Remove removes the given container. It is not cancellable, so that containers
+// can still be cleaned up once the build has been interrupted.
+func (p *Podman) Remove(id string) error {
+	zap.S().Infof("Removing container %s...", id)
+
+	force := true
+	if _, err := containers.Remove(p.context, id, &containers.RemoveOptions{Force: &force}); err != nil {
+		return fmt.Errorf("removing container %s: %w", id, err)
+	}
+
+	return nil
+}
+
 func untar(arch io.Reader, dest string) error {
 	const (
 		chunkSize = 4096
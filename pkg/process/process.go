@@ -0,0 +1,28 @@
+package process
+
+import (
+	"context"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// WaitDelay is the time given to the processes of a cancelled command to terminate before they are killed.
+const WaitDelay = 30 * time.Second
+
+// Command creates a command which is terminated, along with the processes it started,
+// once the context is cancelled.
+//
+// The command is run in its own process group, which receives SIGTERM on cancellation, so that
+// scripts are able to clean up and the tools they launch (e.g. guestfish) are not left running.
+// Processes which do not terminate within WaitDelay are killed.
+func Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = WaitDelay
+
+	return cmd
+}
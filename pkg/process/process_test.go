@@ -0,0 +1,43 @@
+package process
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand_CancelTerminatesProcessGroup(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "terminated")
+	started := filepath.Join(t.TempDir(), "started")
+
+	// The child records being terminated, which only happens if the whole process group is signalled
+	child := `trap 'touch "$MARKER"; exit 0' TERM; touch "$STARTED"; while :; do sleep 0.1; done`
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := Command(ctx, "sh", "-c", `sh -c "$CHILD" & wait`)
+	cmd.Env = append(os.Environ(), "CHILD="+child, "MARKER="+marker, "STARTED="+started)
+
+	require.NoError(t, cmd.Start())
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(started)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+
+	err := cmd.Wait()
+	require.Error(t, err)
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(marker)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"os"
@@ -36,7 +37,7 @@ func (r *Registry) HelmCharts() ([]*HelmCRD, error) {
 	return crds, nil
 }
 
-func (r *Registry) helmChartImages() ([]string, error) {
+func (r *Registry) helmChartImages(ctx context.Context) ([]string, error) {
 	var containerImages []string
 
 	for _, chart := range r.helmCharts {
@@ -45,7 +46,7 @@ func (r *Registry) helmChartImages() ([]string, error) {
 			valuesPath = filepath.Join(r.helmValuesDir, chart.ValuesFile)
 		}
 
-		images, err := r.getChartContainerImages(&chart.HelmChart, chart.localPath, valuesPath, r.kubeVersion)
+		images, err := r.getChartContainerImages(ctx, &chart.HelmChart, chart.localPath, valuesPath, r.kubeVersion)
 		if err != nil {
 			return nil, err
 		}
@@ -56,8 +57,8 @@ func (r *Registry) helmChartImages() ([]string, error) {
 	return containerImages, nil
 }
 
-func (r *Registry) getChartContainerImages(chart *image.HelmChart, chartPath, valuesPath, kubeVersion string) ([]string, error) {
-	chartResources, err := r.helmClient.Template(chart.Name, chartPath, chart.Version, valuesPath, kubeVersion, chart.TargetNamespace, chart.APIVersions)
+func (r *Registry) getChartContainerImages(ctx context.Context, chart *image.HelmChart, chartPath, valuesPath, kubeVersion string) ([]string, error) {
+	chartResources, err := r.helmClient.Template(ctx, chart.Name, chartPath, chart.Version, valuesPath, kubeVersion, chart.TargetNamespace, chart.APIVersions)
 	if err != nil {
 		return nil, fmt.Errorf("templating chart: %w", err)
 	}
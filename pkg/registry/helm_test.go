@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -19,28 +20,28 @@ type mockHelmClient struct {
 	templateFunc      func(chart, repository, version, valuesFilePath, kubeVersion, targetNamespace string, apiVersions []string) ([]map[string]any, error)
 }
 
-func (m mockHelmClient) AddRepo(repository *image.HelmRepository) error {
+func (m mockHelmClient) AddRepo(_ context.Context, repository *image.HelmRepository) error {
 	if m.addRepoFunc != nil {
 		return m.addRepoFunc(repository)
 	}
 	panic("not implemented")
 }
 
-func (m mockHelmClient) RegistryLogin(repository *image.HelmRepository) error {
+func (m mockHelmClient) RegistryLogin(_ context.Context, repository *image.HelmRepository) error {
 	if m.registryLoginFunc != nil {
 		return m.registryLoginFunc(repository)
 	}
 	panic("not implemented")
 }
 
-func (m mockHelmClient) Pull(chart string, repository *image.HelmRepository, version, destDir string) (string, error) {
+func (m mockHelmClient) Pull(_ context.Context, chart string, repository *image.HelmRepository, version, destDir string) (string, error) {
 	if m.pullFunc != nil {
 		return m.pullFunc(chart, repository, version, destDir)
 	}
 	panic("not implemented")
 }
 
-func (m mockHelmClient) Template(chart, repository, version, valuesFilePath, kubeVersion, targetNamespace string, apiVersions []string) ([]map[string]any, error) {
+func (m mockHelmClient) Template(_ context.Context, chart, repository, version, valuesFilePath, kubeVersion, targetNamespace string, apiVersions []string) ([]map[string]any, error) {
 	if m.templateFunc != nil {
 		return m.templateFunc(chart, repository, version, valuesFilePath, kubeVersion, targetNamespace, apiVersions)
 	}
@@ -50,7 +51,7 @@ func (m mockHelmClient) Template(chart, repository, version, valuesFilePath, kub
 func TestRegistry_HelmChartImages_Empty(t *testing.T) {
 	var registry Registry
 
-	images, err := registry.helmChartImages()
+	images, err := registry.helmChartImages(context.Background())
 	require.NoError(t, err)
 	assert.Empty(t, images)
 }
@@ -74,7 +75,7 @@ func TestRegistry_HelmChartImages_TemplateError(t *testing.T) {
 		},
 	}
 
-	images, err := registry.helmChartImages()
+	images, err := registry.helmChartImages(context.Background())
 	require.Error(t, err)
 	assert.ErrorContains(t, err, "templating chart: failed templating")
 	assert.Nil(t, images)
@@ -114,7 +115,7 @@ func TestRegistry_HelmChartImages(t *testing.T) {
 		},
 	}
 
-	images, err := registry.helmChartImages()
+	images, err := registry.helmChartImages(context.Background())
 	require.NoError(t, err)
 	assert.ElementsMatch(t, images, []string{"apache-image:1.1.1", "apache-image:1.2.3"})
 }
@@ -131,7 +132,7 @@ func TestDownloadChart_FailedAddingRepo(t *testing.T) {
 		},
 	}
 
-	chartPath, err := downloadChart(helmClient, helmChart, helmRepo, "")
+	chartPath, err := downloadChart(context.Background(), helmClient, helmChart, helmRepo, "")
 	require.Error(t, err)
 	assert.ErrorContains(t, err, "adding repo: failed to add repo")
 	assert.Empty(t, chartPath)
@@ -156,7 +157,7 @@ func TestDownloadChart_ValidRegistryLogin(t *testing.T) {
 		},
 	}
 
-	chartPath, err := downloadChart(helmClient, helmChart, helmRepo, "")
+	chartPath, err := downloadChart(context.Background(), helmClient, helmChart, helmRepo, "")
 	require.NoError(t, err)
 	assert.Equal(t, "apache-chart.tgz", chartPath)
 }
@@ -177,7 +178,7 @@ func TestDownloadChart_FailedRegistryLogin(t *testing.T) {
 		},
 	}
 
-	chartPath, err := downloadChart(helmClient, helmChart, helmRepo, "")
+	chartPath, err := downloadChart(context.Background(), helmClient, helmChart, helmRepo, "")
 	require.Error(t, err)
 	assert.ErrorContains(t, err, "logging into registry: wrong credentials")
 	assert.Empty(t, chartPath)
@@ -198,7 +199,7 @@ func TestDownloadChart_FailedPulling(t *testing.T) {
 		},
 	}
 
-	chartPath, err := downloadChart(helmClient, helmChart, helmRepo, "")
+	chartPath, err := downloadChart(context.Background(), helmClient, helmChart, helmRepo, "")
 	require.Error(t, err)
 	assert.ErrorContains(t, err, "pulling chart: failed pulling chart")
 	assert.Empty(t, chartPath)
@@ -224,7 +225,7 @@ func TestDownloadChart(t *testing.T) {
 		},
 	}
 
-	chartPath, err := downloadChart(helmClient, helmChart, helmRepo, "")
+	chartPath, err := downloadChart(context.Background(), helmClient, helmChart, helmRepo, "")
 	require.NoError(t, err)
 	assert.Equal(t, "apache-chart.tgz", chartPath)
 }
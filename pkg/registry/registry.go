@@ -4,23 +4,30 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/schollz/progressbar/v3"
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
 	"github.com/suse-edge/edge-image-builder/pkg/http"
 	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/log"
 	"go.uber.org/zap"
 )
 
 type helmClient interface {
-	AddRepo(repository *image.HelmRepository) error
-	RegistryLogin(repository *image.HelmRepository) error
-	Pull(chart string, repository *image.HelmRepository, version, destDir string) (string, error)
-	Template(chart, repository, version, valuesFilePath, kubeVersion, targetNamespace string, apiVersions []string) ([]map[string]any, error)
+	AddRepo(ctx context.Context, repository *image.HelmRepository) error
+	RegistryLogin(ctx context.Context, repository *image.HelmRepository) error
+	Pull(ctx context.Context, chart string, repository *image.HelmRepository, version, destDir string) (string, error)
+	Template(ctx context.Context, chart, repository, version, valuesFilePath, kubeVersion, targetNamespace string, apiVersions []string) ([]map[string]any, error)
+}
+
+type fileCache interface {
+	CopyTo(identifier, dest string, perms os.FileMode) error
+	Put(identifier, source string, reader io.Reader) error
+	Remove(identifier string) error
 }
 
 type helmChart struct {
@@ -38,13 +45,13 @@ type Registry struct {
 	kubeVersion    string
 }
 
-func New(ctx *image.Context, localManifestsDir string, helmClient helmClient, helmValuesDir string) (*Registry, error) {
-	manifestsDir, err := storeManifests(ctx, localManifestsDir)
+func New(ctx *image.Context, localManifestsDir string, helmClient helmClient, helmValuesDir string, cache fileCache) (*Registry, error) {
+	manifestsDir, err := storeManifests(ctx, localManifestsDir, cache)
 	if err != nil {
 		return nil, fmt.Errorf("storing manifests: %w", err)
 	}
 
-	charts, err := storeHelmCharts(ctx, helmClient)
+	charts, err := storeHelmCharts(ctx, helmClient, cache)
 	if err != nil {
 		return nil, fmt.Errorf("storing helm charts: %w", err)
 	}
@@ -63,7 +70,7 @@ func (r *Registry) ManifestsPath() string {
 	return r.manifestsDir
 }
 
-func storeManifests(ctx *image.Context, localManifestsDir string) (string, error) {
+func storeManifests(ctx *image.Context, localManifestsDir string, cache fileCache) (string, error) {
 	const manifestsDir = "manifests"
 
 	var manifestsPathPopulated bool
@@ -79,7 +86,7 @@ func storeManifests(ctx *image.Context, localManifestsDir string) (string, error
 		for index, manifestURL := range manifestURLs {
 			filePath := filepath.Join(manifestsDestDir, fmt.Sprintf("dl-manifest-%d.yaml", index+1))
 
-			if err := http.DownloadFile(context.Background(), manifestURL, filePath, nil); err != nil {
+			if err := http.DownloadCachedFile(ctx.Context(), manifestURL, filePath, cache, ManifestCacheIdentifier(manifestURL)); err != nil {
 				return "", fmt.Errorf("downloading manifest '%s': %w", manifestURL, err)
 			}
 		}
@@ -104,14 +111,14 @@ func storeManifests(ctx *image.Context, localManifestsDir string) (string, error
 	return manifestsDestDir, nil
 }
 
-func storeHelmCharts(ctx *image.Context, helmClient helmClient) ([]*helmChart, error) {
+func storeHelmCharts(ctx *image.Context, helmClient helmClient, cache fileCache) ([]*helmChart, error) {
 	helm := &ctx.ImageDefinition.Kubernetes.Helm
 
 	if len(helm.Charts) == 0 {
 		return nil, nil
 	}
 
-	bar := progressbar.Default(int64(len(helm.Charts)), "Pulling selected Helm charts...")
+	bar := log.NewProgressBar(int64(len(helm.Charts)), "Pulling selected Helm charts...")
 
 	helmDir := filepath.Join(ctx.BuildDir, "helm")
 	if err := os.MkdirAll(helmDir, os.ModePerm); err != nil {
@@ -128,7 +135,7 @@ func storeHelmCharts(ctx *image.Context, helmClient helmClient) ([]*helmChart, e
 			return nil, fmt.Errorf("repository not found for chart %s", helm.Charts[i].Name)
 		}
 
-		localPath, err := downloadChart(helmClient, &helm.Charts[i], repository, helmDir)
+		localPath, err := retrieveChart(ctx.Context(), helmClient, cache, &helm.Charts[i], repository, helmDir)
 		if err != nil {
 			return nil, fmt.Errorf("downloading chart: %w", err)
 		}
@@ -139,6 +146,8 @@ func storeHelmCharts(ctx *image.Context, helmClient helmClient) ([]*helmChart, e
 			repositoryURL: repository.URL,
 		})
 
+		ctx.Recorder().AddHelmChart(helm.Charts[i].Name, repository.URL, helm.Charts[i].Version)
+
 		_ = bar.Add(1)
 	}
 
@@ -159,18 +168,75 @@ func mapChartsToRepos(helm *image.Helm) map[string]*image.HelmRepository {
 	return chartRepoMap
 }
 
-func downloadChart(helmClient helmClient, chart *image.HelmChart, repo *image.HelmRepository, destDir string) (string, error) {
+// retrieveChart copies the chart from the cache if available, pulling and caching it otherwise.
+func retrieveChart(ctx context.Context, helmClient helmClient, cache fileCache, chart *image.HelmChart, repo *image.HelmRepository, destDir string) (string, error) {
+	if cache == nil {
+		return downloadChart(ctx, helmClient, chart, repo, destDir)
+	}
+
+	cacheKey := ChartCacheIdentifier(repo.URL, chart.Name, chart.Version)
+
+	chartDir := filepath.Join(destDir, chart.Name)
+	if err := os.MkdirAll(chartDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("creating chart dir %q: %w", chartDir, err)
+	}
+
+	cachedChartPath := filepath.Join(chartDir, fmt.Sprintf("%s-%s.tgz", chart.Name, chart.Version))
+	err := cache.CopyTo(cacheKey, cachedChartPath, fileio.NonExecutablePerms)
+	if err == nil {
+		zap.S().Infof("Using cached chart with identifier '%s'", cacheKey)
+		return cachedChartPath, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		zap.S().Warnf("Copying chart '%s' from cache failed: %v", cacheKey, err)
+	}
+
+	// The chart directory is only kept for cached charts, which are not pulled into it.
+	_ = os.Remove(chartDir)
+
+	if http.IsOffline() {
+		return "", fmt.Errorf("chart '%s' version '%s' is not cached: %w", chart.Name, chart.Version, http.ErrOffline)
+	}
+
+	chartPath, err := downloadChart(ctx, helmClient, chart, repo, destDir)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(chartPath)
+	if err != nil {
+		return "", fmt.Errorf("opening chart: %w", err)
+	}
+	defer file.Close()
+
+	if err = cache.Put(cacheKey, repo.URL, file); err != nil && !errors.Is(err, fs.ErrExist) {
+		zap.S().Warnf("Caching chart '%s' failed: %v", cacheKey, err)
+	}
+
+	return chartPath, nil
+}
+
+// ChartCacheIdentifier returns the cache identifier of the given Helm chart version.
+func ChartCacheIdentifier(repositoryURL, chart, version string) string {
+	return fmt.Sprintf("charts/%s/%s/%s", strings.TrimSuffix(repositoryURL, "/"), chart, version)
+}
+
+// ManifestCacheIdentifier returns the cache identifier of the manifest located at the given URL.
+func ManifestCacheIdentifier(manifestURL string) string {
+	return fmt.Sprintf("manifests/%s", manifestURL)
+}
+
+func downloadChart(ctx context.Context, helmClient helmClient, chart *image.HelmChart, repo *image.HelmRepository, destDir string) (string, error) {
 	if strings.HasPrefix(repo.URL, "http") {
-		if err := helmClient.AddRepo(repo); err != nil {
+		if err := helmClient.AddRepo(ctx, repo); err != nil {
 			return "", fmt.Errorf("adding repo: %w", err)
 		}
 	} else if repo.Authentication.Username != "" && repo.Authentication.Password != "" {
-		if err := helmClient.RegistryLogin(repo); err != nil {
+		if err := helmClient.RegistryLogin(ctx, repo); err != nil {
 			return "", fmt.Errorf("logging into registry: %w", err)
 		}
 	}
 
-	chartPath, err := helmClient.Pull(chart.Name, repo, chart.Version, destDir)
+	chartPath, err := helmClient.Pull(ctx, chart.Name, repo, chart.Version, destDir)
 	if err != nil {
 		return "", fmt.Errorf("pulling chart: %w", err)
 	}
@@ -178,13 +244,13 @@ func downloadChart(helmClient helmClient, chart *image.HelmChart, repo *image.He
 	return chartPath, nil
 }
 
-func (r *Registry) ContainerImages() ([]string, error) {
+func (r *Registry) ContainerImages(ctx context.Context) ([]string, error) {
 	manifestImages, err := r.manifestImages()
 	if err != nil {
 		return nil, fmt.Errorf("getting container images from manifests: %w", err)
 	}
 
-	chartImages, err := r.helmChartImages()
+	chartImages, err := r.helmChartImages(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("getting container images from helm charts: %w", err)
 	}
@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -28,7 +29,7 @@ func TestRegistry_New_InvalidManifestURL(t *testing.T) {
 		},
 	}
 
-	_, err := New(ctx, "", nil, "")
+	_, err := New(ctx, "", nil, "", nil)
 	require.Error(t, err)
 
 	assert.ErrorContains(t, err, "downloading manifest 'k8s.io/examples/application/nginx-app.yaml'")
@@ -76,7 +77,7 @@ func TestRegistry_ContainerImages(t *testing.T) {
 		},
 	}
 
-	images, err := registry.ContainerImages()
+	images, err := registry.ContainerImages(context.Background())
 	require.NoError(t, err)
 
 	assert.ElementsMatch(t, images, []string{
@@ -0,0 +1,533 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/log"
+)
+
+// Filename is the name of the report written next to the output image.
+const Filename = "build-report.json"
+
+const (
+	StatusSuccessful = log.ComponentStatusSuccessful
+	StatusSkipped    = log.ComponentStatusSkipped
+	StatusReused     = log.ComponentStatusReused
+	StatusFailed     = log.ComponentStatusFailed
+)
+
+// Report summarises a single image build in a machine-readable form.
+//
+// All methods are safe for concurrent use and are no-ops on a nil *Report,
+// so that callers do not need to guard against builds without a report.
+type Report struct {
+	mu sync.Mutex
+
+	componentStatuses map[string]string
+
+	EIBVersion       string `json:"eibVersion"`
+	SchemaVersion    string `json:"schemaVersion,omitempty"`
+	DefinitionFile   string `json:"definitionFile,omitempty"`
+	DefinitionDigest string `json:"definitionDigest,omitempty"`
+	// DefinitionSources are the files the definition was combined with, i.e. its includes and overlays.
+	DefinitionSources []File           `json:"definitionSources,omitempty"`
+	Started           time.Time        `json:"started"`
+	Finished          time.Time        `json:"finished"`
+	DurationSeconds   float64          `json:"durationSeconds"`
+	Result            string           `json:"result"`
+	Error             string           `json:"error,omitempty"`
+	Components        []Component      `json:"components"`
+	Artefacts         []Artefact       `json:"artefacts"`
+	Packages          []Package        `json:"packages"`
+	ContainerImages   []ContainerImage `json:"containerImages"`
+	HelmCharts        []HelmChart      `json:"helmCharts"`
+	SideLoadedFiles   []File           `json:"sideLoadedFiles"`
+	BaseImages        []File           `json:"baseImages"`
+	Images            []Image          `json:"images"`
+}
+
+type Component struct {
+	Name            string   `json:"name"`
+	Arch            string   `json:"arch"`
+	Status          string   `json:"status"`
+	DurationSeconds float64  `json:"durationSeconds"`
+	Scripts         []string `json:"scripts"`
+	Error           string   `json:"error,omitempty"`
+}
+
+type Artefact struct {
+	URL string `json:"url"`
+	// Arch is the architecture of the images the artefact was retrieved for.
+	// It is empty for artefacts retrieved for all architectures.
+	Arch string `json:"arch,omitempty"`
+	File
+	// Cached indicates that the artefact was retrieved from the artefact cache instead of being downloaded.
+	Cached bool `json:"cached"`
+}
+
+// Package is an RPM package installed in the image.
+type Package struct {
+	Name string `json:"name"`
+	// Version contains both the version and the release of the package (e.g. "9.1.0836-1.1").
+	Version string `json:"version"`
+	Arch    string `json:"arch"`
+	// Repository is the alias of the repository the package was resolved from.
+	Repository string `json:"repository"`
+}
+
+type ContainerImage struct {
+	Name string `json:"name"`
+	Arch string `json:"arch"`
+	// Digest is the digest of the image manifest resolved when pulling the image (e.g. "sha256:4c0f...").
+	Digest string `json:"digest"`
+	// ArchiveSHA256 is the SHA-256 digest of the registry archive the image is embedded as.
+	ArchiveSHA256 string `json:"archiveSha256"`
+}
+
+type HelmChart struct {
+	Name       string `json:"name"`
+	Repository string `json:"repository"`
+	Version    string `json:"version"`
+}
+
+type File struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Image is an image produced by the build.
+type Image struct {
+	File
+	Type string `json:"type"`
+	Arch string `json:"arch"`
+}
+
+func New(eibVersion string) *Report {
+	return &Report{
+		EIBVersion:        eibVersion,
+		Started:           time.Now().UTC(),
+		componentStatuses: map[string]string{},
+	}
+}
+
+// SetDefinition records the schema version and the digests of the image definition file
+// and of the includes and overlays it was combined with.
+func (r *Report) SetDefinition(path, schemaVersion string, sources []string) error {
+	if r == nil {
+		return nil
+	}
+
+	file, err := describeFile(path)
+	if err != nil {
+		return fmt.Errorf("describing definition file: %w", err)
+	}
+
+	var definitionSources []File
+	for _, source := range sources {
+		var sourceFile File
+		if sourceFile, err = describeFile(source); err != nil {
+			return fmt.Errorf("describing definition source '%s': %w", source, err)
+		}
+
+		definitionSources = append(definitionSources, sourceFile)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.DefinitionFile = path
+	r.DefinitionDigest = file.SHA256
+	r.DefinitionSources = definitionSources
+	r.SchemaVersion = schemaVersion
+
+	return nil
+}
+
+// RecordComponentStatus stores the audited status of a component.
+// It is used to determine the status of components recorded afterward.
+func (r *Report) RecordComponentStatus(component, status string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.componentStatuses[component] = status
+}
+
+// AddComponent records the outcome of configuring a combustion component for the given architecture.
+func (r *Report) AddComponent(name, arch string, duration time.Duration, scripts []string, err error) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	component := Component{
+		Name:            name,
+		Arch:            arch,
+		DurationSeconds: duration.Seconds(),
+		Scripts:         append([]string{}, scripts...),
+	}
+
+	switch status, ok := r.componentStatuses[name]; {
+	case err != nil:
+		component.Status = StatusFailed
+		component.Error = log.Redact(err.Error())
+	case ok:
+		component.Status = status
+	case len(scripts) == 0:
+		component.Status = StatusSkipped
+	default:
+		component.Status = StatusSuccessful
+	}
+
+	r.Components = append(r.Components, component)
+}
+
+// RecordDownload records a file retrieved from the given URL and stored at the given path.
+func (r *Report) RecordDownload(url, path string, cached bool) {
+	r.recordDownload(url, path, "", cached)
+}
+
+// ForArch returns a recorder attributing the recorded downloads to the given architecture.
+func (r *Report) ForArch(arch string) *ArchRecorder {
+	return &ArchRecorder{report: r, arch: arch}
+}
+
+// ArchRecorder records downloads retrieved for a single architecture in the report.
+type ArchRecorder struct {
+	report *Report
+	arch   string
+}
+
+// RecordDownload records a file retrieved from the given URL and stored at the given path.
+func (a *ArchRecorder) RecordDownload(url, path string, cached bool) {
+	a.report.recordDownload(url, path, a.arch, cached)
+}
+
+func (r *Report) recordDownload(url, path, arch string, cached bool) {
+	if r == nil {
+		return
+	}
+
+	file, err := describeFile(path)
+	if err != nil {
+		// The file has been successfully retrieved, so this is unexpected but not worth failing the build over.
+		file = File{Path: path}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Artefacts = append(r.Artefacts, Artefact{URL: url, Arch: arch, File: file, Cached: cached})
+}
+
+// AddPackage records a resolved RPM package.
+// The version contains both the version and the release of the package.
+func (r *Report) AddPackage(name, version, arch, repository string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Packages = append(r.Packages, Package{Name: name, Version: version, Arch: arch, Repository: repository})
+}
+
+// AddContainerImage records a container image of the given architecture and manifest digest
+// embedded in the artifact registry through the given archive.
+func (r *Report) AddContainerImage(name, arch, digest, archivePath string) error {
+	if r == nil {
+		return nil
+	}
+
+	file, err := describeFile(archivePath)
+	if err != nil {
+		return fmt.Errorf("describing image archive: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ContainerImages = append(r.ContainerImages, ContainerImage{Name: name, Arch: arch, Digest: digest, ArchiveSHA256: file.SHA256})
+	return nil
+}
+
+// AddHelmChart records a Helm chart installed through the image.
+func (r *Report) AddHelmChart(name, repository, version string) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.HelmCharts = append(r.HelmCharts, HelmChart{Name: name, Repository: repository, Version: version})
+}
+
+// AddSideLoadedFile records a file provided through the image configuration directory.
+// The name is the path of the file relative to the configuration directory.
+func (r *Report) AddSideLoadedFile(name, path string) error {
+	if r == nil {
+		return nil
+	}
+
+	file, err := describeFile(path)
+	if err != nil {
+		return fmt.Errorf("describing side-loaded file: %w", err)
+	}
+	file.Path = name
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.SideLoadedFiles = append(r.SideLoadedFiles, file)
+	return nil
+}
+
+// Merge records the artefacts, packages, container images, Helm charts and side-loaded files
+// collected by another report, e.g. one scoped to a single component.
+func (r *Report) Merge(other *Report) {
+	if r == nil || other == nil {
+		return
+	}
+
+	other.mu.Lock()
+	defer other.mu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Artefacts = append(r.Artefacts, other.Artefacts...)
+	r.Packages = append(r.Packages, other.Packages...)
+	r.ContainerImages = append(r.ContainerImages, other.ContainerImages...)
+	r.HelmCharts = append(r.HelmCharts, other.HelmCharts...)
+	r.SideLoadedFiles = append(r.SideLoadedFiles, other.SideLoadedFiles...)
+}
+
+// AddBaseImage records a base image customized by the build.
+func (r *Report) AddBaseImage(path string) error {
+	if r == nil {
+		return nil
+	}
+
+	file, err := describeFile(path)
+	if err != nil {
+		return fmt.Errorf("describing base image: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !slices.ContainsFunc(r.BaseImages, func(f File) bool { return f.Path == path }) {
+		r.BaseImages = append(r.BaseImages, file)
+	}
+
+	return nil
+}
+
+// AddImage records an image produced by the build.
+func (r *Report) AddImage(path, imageType, arch string) error {
+	if r == nil {
+		return nil
+	}
+
+	file, err := describeFile(path)
+	if err != nil {
+		return fmt.Errorf("describing image: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Images = append(r.Images, Image{File: file, Type: imageType, Arch: arch})
+	return nil
+}
+
+// FindBaseImage returns the recorded base image stored at the given path, if any.
+func (r *Report) FindBaseImage(path string) *File {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.BaseImages {
+		if r.BaseImages[i].Path == path {
+			return &r.BaseImages[i]
+		}
+	}
+
+	return nil
+}
+
+// FindImage returns the recorded image stored at the given path, if any.
+func (r *Report) FindImage(path string) *Image {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.Images {
+		if r.Images[i].Path == path {
+			return &r.Images[i]
+		}
+	}
+
+	return nil
+}
+
+// Finish records the result of the build and orders the collected details.
+func (r *Report) Finish(buildErr error) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sort()
+
+	r.Finished = time.Now().UTC()
+	r.DurationSeconds = r.Finished.Sub(r.Started).Seconds()
+
+	if buildErr != nil {
+		r.Result = StatusFailed
+		r.Error = log.Redact(buildErr.Error())
+	} else {
+		r.Result = StatusSuccessful
+	}
+}
+
+// Write stores the report in JSON format at the given path.
+func (r *Report) Write(path string) error {
+	if r == nil {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling report: %w", err)
+	}
+
+	if err = os.WriteFile(path, append(data, '\n'), fileio.NonExecutablePerms); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+
+	return nil
+}
+
+// sort orders the collections whose insertion order is not meaningful and removes duplicate entries
+// recorded while building images for multiple architectures, so that reports of identical builds
+// can be compared directly. Components and images are intentionally left in build order.
+func (r *Report) sort() {
+	sort.SliceStable(r.Artefacts, func(i, j int) bool {
+		a, b := r.Artefacts[i], r.Artefacts[j]
+		if a.URL != b.URL {
+			return a.URL < b.URL
+		}
+		return a.Arch < b.Arch
+	})
+	r.Artefacts = slices.CompactFunc(r.Artefacts, func(a, b Artefact) bool {
+		return a.URL == b.URL && a.Arch == b.Arch && a.SHA256 == b.SHA256
+	})
+
+	sort.Slice(r.Packages, func(i, j int) bool {
+		a, b := r.Packages[i], r.Packages[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Arch != b.Arch {
+			return a.Arch < b.Arch
+		}
+		return a.Version < b.Version
+	})
+	r.Packages = slices.Compact(r.Packages)
+
+	sort.Slice(r.ContainerImages, func(i, j int) bool {
+		a, b := r.ContainerImages[i], r.ContainerImages[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Arch < b.Arch
+	})
+	r.ContainerImages = slices.Compact(r.ContainerImages)
+
+	sort.Slice(r.HelmCharts, func(i, j int) bool {
+		return r.HelmCharts[i].Name < r.HelmCharts[j].Name
+	})
+	r.HelmCharts = slices.Compact(r.HelmCharts)
+
+	sort.Slice(r.SideLoadedFiles, func(i, j int) bool {
+		return r.SideLoadedFiles[i].Path < r.SideLoadedFiles[j].Path
+	})
+	r.SideLoadedFiles = slices.Compact(r.SideLoadedFiles)
+
+	// Avoid null values in the output
+	if r.Components == nil {
+		r.Components = []Component{}
+	}
+	if r.Artefacts == nil {
+		r.Artefacts = []Artefact{}
+	}
+	if r.Packages == nil {
+		r.Packages = []Package{}
+	}
+	if r.ContainerImages == nil {
+		r.ContainerImages = []ContainerImage{}
+	}
+	if r.HelmCharts == nil {
+		r.HelmCharts = []HelmChart{}
+	}
+	if r.SideLoadedFiles == nil {
+		r.SideLoadedFiles = []File{}
+	}
+	if r.BaseImages == nil {
+		r.BaseImages = []File{}
+	}
+	if r.Images == nil {
+		r.Images = []Image{}
+	}
+}
+
+func describeFile(path string) (File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return File{}, fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return File{}, fmt.Errorf("reading file: %w", err)
+	}
+
+	return File{
+		Path:   path,
+		Size:   size,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
@@ -0,0 +1,200 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testContents       = "some-contents"
+	testContentsDigest = "6e32ea34db1b3755d7dec972eb72c705338f0dd8e0be881d966963438fb2e800"
+)
+
+func writeTestFile(t *testing.T, name string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(testContents), 0o600))
+
+	return path
+}
+
+func TestAddComponent(t *testing.T) {
+	r := New("v1.2.0")
+
+	r.RecordComponentStatus("custom", StatusSuccessful)
+	r.RecordComponentStatus("users", StatusSkipped)
+
+	r.AddComponent("custom", "x86_64", time.Second, nil, nil)
+	r.AddComponent("users", "x86_64", time.Millisecond, nil, nil)
+	r.AddComponent("message", "x86_64", 2*time.Second, []string{"48-message.sh"}, nil)
+	r.AddComponent("cleanup", "x86_64", 0, nil, nil)
+	r.AddComponent("rpm", "aarch64", 3*time.Second, nil, errors.New("resolution failed"))
+
+	assert.Equal(t, []Component{
+		{Name: "custom", Arch: "x86_64", Status: StatusSuccessful, DurationSeconds: 1, Scripts: []string{}},
+		{Name: "users", Arch: "x86_64", Status: StatusSkipped, DurationSeconds: 0.001, Scripts: []string{}},
+		{Name: "message", Arch: "x86_64", Status: StatusSuccessful, DurationSeconds: 2, Scripts: []string{"48-message.sh"}},
+		{Name: "cleanup", Arch: "x86_64", Status: StatusSkipped, DurationSeconds: 0, Scripts: []string{}},
+		{Name: "rpm", Arch: "aarch64", Status: StatusFailed, DurationSeconds: 3, Scripts: []string{}, Error: "resolution failed"},
+	}, r.Components)
+}
+
+func TestMerge(t *testing.T) {
+	r := New("v1.2.0")
+	r.AddPackage("vim", "9.1.0836-1.1", "x86_64", "SLE-Micro")
+
+	component := New("v1.2.0")
+	component.RecordDownload("https://get.k3s.io", "missing", true)
+	component.AddPackage("git", "2.43.0-1.1", "x86_64", "SLE-Micro")
+	component.AddHelmChart("metallb", "https://suse-edge.github.io/charts", "0.14.3")
+	component.RecordComponentStatus("rpm", StatusSuccessful)
+
+	r.Merge(component)
+	r.Merge(nil)
+
+	assert.Equal(t, []Artefact{{URL: "https://get.k3s.io", File: File{Path: "missing"}, Cached: true}}, r.Artefacts)
+	assert.Equal(t, []Package{
+		{Name: "vim", Version: "9.1.0836-1.1", Arch: "x86_64", Repository: "SLE-Micro"},
+		{Name: "git", Version: "2.43.0-1.1", Arch: "x86_64", Repository: "SLE-Micro"},
+	}, r.Packages)
+	assert.Equal(t, []HelmChart{{Name: "metallb", Repository: "https://suse-edge.github.io/charts", Version: "0.14.3"}}, r.HelmCharts)
+	assert.Empty(t, r.componentStatuses)
+}
+
+func TestNilReport(t *testing.T) {
+	var r *Report
+
+	assert.NotPanics(t, func() {
+		r.RecordComponentStatus("custom", StatusSuccessful)
+		r.AddComponent("custom", "x86_64", time.Second, nil, nil)
+		r.RecordDownload("https://get.k3s.io", "missing", false)
+		r.ForArch("x86_64").RecordDownload("https://get.k3s.io", "missing", false)
+		r.AddPackage("vim", "", "", "")
+		r.AddHelmChart("metallb", "https://suse-edge.github.io/charts", "0.14.3")
+		r.Finish(nil)
+	})
+
+	assert.NoError(t, r.SetDefinition("missing", "1.1", []string{"missing"}))
+	assert.NoError(t, r.AddContainerImage("nginx", "x86_64", "sha256:4c0f", "missing"))
+	assert.NoError(t, r.AddBaseImage("missing"))
+	assert.NoError(t, r.AddImage("missing", "iso", "x86_64"))
+	assert.Nil(t, r.FindBaseImage("missing"))
+	assert.Nil(t, r.FindImage("missing"))
+	assert.NoError(t, r.Write("missing"))
+}
+
+func TestWrite(t *testing.T) {
+	r := New("v1.2.0")
+
+	require.NoError(t, r.SetDefinition(writeTestFile(t, "definition.yaml"), "1.1", []string{writeTestFile(t, "site-a.yaml")}))
+	r.AddComponent("message", "x86_64", time.Second, []string{"48-message.sh"}, nil)
+	r.RecordDownload("https://get.rke2.io", writeTestFile(t, "rke2_installer.sh"), false)
+	r.ForArch("aarch64").RecordDownload("https://get.rke2.io", writeTestFile(t, "rke2_installer.sh"), false)
+	r.RecordDownload("https://example.com/manifest.yaml", writeTestFile(t, "dl-manifest-1.yaml"), true)
+	r.AddPackage("zsh", "5.9-1.1", "x86_64", "SLE-Micro")
+	r.AddPackage("vim", "9.1.0836-1.1", "x86_64", "addrepo0")
+	// Images of the same architecture are built from the same packages
+	r.AddPackage("vim", "9.1.0836-1.1", "x86_64", "addrepo0")
+	require.NoError(t, r.AddSideLoadedFile("custom/files/motd", writeTestFile(t, "motd")))
+	require.NoError(t, r.AddContainerImage("nginx:1.25", "x86_64", "sha256:4c0f", writeTestFile(t, "nginx-registry.tar.zst")))
+	r.AddHelmChart("metallb", "https://suse-edge.github.io/charts", "0.14.3")
+	baseImagePath := writeTestFile(t, "SL-Micro.x86_64-6.0-Base-GM2.raw")
+	require.NoError(t, r.AddBaseImage(baseImagePath))
+	require.NoError(t, r.AddBaseImage(baseImagePath))
+	imagePath := writeTestFile(t, "eib-image.iso")
+	require.NoError(t, r.AddImage(imagePath, "iso", "x86_64"))
+	r.Finish(nil)
+
+	path := filepath.Join(t.TempDir(), Filename)
+	require.NoError(t, r.Write(path))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var written map[string]any
+	require.NoError(t, json.Unmarshal(b, &written))
+
+	assert.Equal(t, "v1.2.0", written["eibVersion"])
+	assert.Equal(t, "1.1", written["schemaVersion"])
+	assert.Equal(t, testContentsDigest, written["definitionDigest"])
+
+	definitionSources := written["definitionSources"].([]any)
+	require.Len(t, definitionSources, 1)
+	assert.Equal(t, testContentsDigest, definitionSources[0].(map[string]any)["sha256"])
+	assert.Equal(t, StatusSuccessful, written["result"])
+	assert.NotContains(t, written, "error")
+
+	artefacts := written["artefacts"].([]any)
+	require.Len(t, artefacts, 3)
+	assert.Equal(t, "https://example.com/manifest.yaml", artefacts[0].(map[string]any)["url"])
+	assert.Equal(t, true, artefacts[0].(map[string]any)["cached"])
+	assert.Equal(t, "https://get.rke2.io", artefacts[1].(map[string]any)["url"])
+	assert.Equal(t, testContentsDigest, artefacts[1].(map[string]any)["sha256"])
+	assert.EqualValues(t, len(testContents), artefacts[1].(map[string]any)["size"])
+	assert.NotContains(t, artefacts[1], "arch")
+	assert.Equal(t, "https://get.rke2.io", artefacts[2].(map[string]any)["url"])
+	assert.Equal(t, "aarch64", artefacts[2].(map[string]any)["arch"])
+
+	assert.Equal(t, []any{
+		map[string]any{"name": "vim", "version": "9.1.0836-1.1", "arch": "x86_64", "repository": "addrepo0"},
+		map[string]any{"name": "zsh", "version": "5.9-1.1", "arch": "x86_64", "repository": "SLE-Micro"},
+	}, written["packages"])
+	assert.Equal(t, []any{map[string]any{
+		"path":   "custom/files/motd",
+		"size":   float64(len(testContents)),
+		"sha256": testContentsDigest,
+	}}, written["sideLoadedFiles"])
+	assert.Equal(t, []any{map[string]any{
+		"name":          "nginx:1.25",
+		"arch":          "x86_64",
+		"digest":        "sha256:4c0f",
+		"archiveSha256": testContentsDigest,
+	}}, written["containerImages"])
+	assert.Equal(t, []any{map[string]any{
+		"name":       "metallb",
+		"repository": "https://suse-edge.github.io/charts",
+		"version":    "0.14.3",
+	}}, written["helmCharts"])
+
+	baseImages := written["baseImages"].([]any)
+	require.Len(t, baseImages, 1)
+	assert.Equal(t, testContentsDigest, baseImages[0].(map[string]any)["sha256"])
+
+	images := written["images"].([]any)
+	require.Len(t, images, 1)
+	img := images[0].(map[string]any)
+	assert.Equal(t, testContentsDigest, img["sha256"])
+	assert.EqualValues(t, len(testContents), img["size"])
+	assert.Equal(t, "iso", img["type"])
+	assert.Equal(t, "x86_64", img["arch"])
+
+	assert.Equal(t, "iso", r.FindImage(imagePath).Type)
+	assert.Equal(t, testContentsDigest, r.FindBaseImage(baseImagePath).SHA256)
+	assert.Nil(t, r.FindImage(baseImagePath))
+}
+
+func TestWrite_Failed(t *testing.T) {
+	r := New("v1.2.0")
+	r.Finish(errors.New("configuring image: boom"))
+
+	path := filepath.Join(t.TempDir(), Filename)
+	require.NoError(t, r.Write(path))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var written map[string]any
+	require.NoError(t, json.Unmarshal(b, &written))
+
+	assert.Equal(t, StatusFailed, written["result"])
+	assert.Equal(t, "configuring image: boom", written["error"])
+	assert.Equal(t, []any{}, written["components"])
+	assert.Equal(t, []any{}, written["artefacts"])
+	assert.Equal(t, []any{}, written["images"])
+}
@@ -1,12 +1,14 @@
 package rpm
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 
+	"github.com/suse-edge/edge-image-builder/pkg/process"
 	"go.uber.org/zap"
 )
 
@@ -25,7 +27,7 @@ func NewRepoCreator(logOut string) *RepoCreator {
 	}
 }
 
-func (r *RepoCreator) Create(path string) error {
+func (r *RepoCreator) Create(ctx context.Context, path string) error {
 	zap.S().Infof("Creating RPM repository from '%s'", path)
 
 	logFile, err := os.Create(filepath.Join(r.logOut, createRepoLog))
@@ -34,7 +36,7 @@ func (r *RepoCreator) Create(path string) error {
 	}
 	defer logFile.Close()
 
-	cmd := prepareRepoCommand(path, logFile)
+	cmd := prepareRepoCommand(ctx, path, logFile)
 	err = cmd.Run()
 	if err != nil {
 		return fmt.Errorf("error running createrepo: %w", err)
@@ -44,8 +46,8 @@ func (r *RepoCreator) Create(path string) error {
 	return nil
 }
 
-func prepareRepoCommand(path string, w io.Writer) *exec.Cmd {
-	cmd := exec.Command(createRepoExec, path)
+func prepareRepoCommand(ctx context.Context, path string, w io.Writer) *exec.Cmd {
+	cmd := process.Command(ctx, createRepoExec, path)
 	cmd.Stdout = w
 	cmd.Stderr = w
 
@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 
 	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/process"
 	"github.com/suse-edge/edge-image-builder/pkg/template"
 	"go.uber.org/zap"
 )
@@ -24,7 +26,7 @@ const (
 var prepareTraballTemplate string
 
 type ImageImporter interface {
-	Import(tarball, ref string) error
+	Import(ctx context.Context, tarball, ref string) error
 }
 
 type TarballImageBuilder struct {
@@ -50,7 +52,7 @@ func NewTarballBuilder(workDir, imgPath, imgType, arch string, importer ImageImp
 	}
 }
 
-func (t *TarballImageBuilder) Build() (string, error) {
+func (t *TarballImageBuilder) Build(ctx context.Context) (string, error) {
 	zap.L().Info("Building tarball image...")
 	defer os.RemoveAll(t.getTarballImgDir())
 
@@ -62,12 +64,12 @@ func (t *TarballImageBuilder) Build() (string, error) {
 		return "", fmt.Errorf("writing the tarball image script: %w", err)
 	}
 
-	if err := t.runTarballImageScript(); err != nil {
+	if err := t.runTarballImageScript(ctx); err != nil {
 		return "", fmt.Errorf("running the tarball image script: %w", err)
 	}
 
 	tarballPath := filepath.Join(t.getTarballImgDir(), tarballName)
-	if err := t.imgImporter.Import(tarballPath, tarballImgRef); err != nil {
+	if err := t.imgImporter.Import(ctx, tarballPath, tarballImgRef); err != nil {
 		return "", fmt.Errorf("importing the tarball image: %w", err)
 	}
 
@@ -119,14 +121,14 @@ func (t *TarballImageBuilder) writeTarballImageScript() error {
 	return nil
 }
 
-func (t *TarballImageBuilder) runTarballImageScript() error {
+func (t *TarballImageBuilder) runTarballImageScript(ctx context.Context) error {
 	logFile, err := os.Create(filepath.Join(t.dir, prepareTarballScriptLog))
 	if err != nil {
 		return fmt.Errorf("generating prepare tarball image log file: %w", err)
 	}
 	defer logFile.Close()
 
-	cmd := t.prepareTarballImageCmd(logFile)
+	cmd := t.prepareTarballImageCmd(ctx, logFile)
 	if err = cmd.Run(); err != nil {
 		return fmt.Errorf("run script failure: %w", err)
 	}
@@ -134,9 +136,9 @@ func (t *TarballImageBuilder) runTarballImageScript() error {
 	return nil
 }
 
-func (t *TarballImageBuilder) prepareTarballImageCmd(log io.Writer) *exec.Cmd {
+func (t *TarballImageBuilder) prepareTarballImageCmd(ctx context.Context, log io.Writer) *exec.Cmd {
 	scriptPath := filepath.Join(t.dir, prepareTarballScriptName)
-	cmd := exec.Command(scriptPath)
+	cmd := process.Command(ctx, scriptPath)
 	cmd.Stdout = log
 	cmd.Stderr = log
 	return cmd
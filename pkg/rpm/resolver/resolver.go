@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	"context"
 	_ "embed"
 	"fmt"
 	"os"
@@ -29,13 +30,14 @@ var dockerfileTemplate string
 var rpmResolutionScriptTemplate string
 
 type Podman interface {
-	Build(context, name string) error
-	Create(img string) (string, error)
-	Copy(id, src, dest string) error
+	Build(ctx context.Context, buildContext, name string) error
+	Create(ctx context.Context, img string) (string, error)
+	Copy(ctx context.Context, id, src, dest string) error
+	Remove(id string) error
 }
 
 type BaseResolverImageBuilder interface {
-	Build() (string, error)
+	Build(ctx context.Context) (string, error)
 }
 
 type Resolver struct {
@@ -81,7 +83,9 @@ func New(workDir string, podman Podman, baseImageBuilder BaseResolverImageBuilde
 // - localRPMConfig - configuration for locally provided RPMs
 //
 // - outputDir - directory in which the resolver will create a directory containing the resolved rpms.
-func (r *Resolver) Resolve(packages *image.Packages, localRPMConfig *image.LocalRPMConfig, outputDir string) (rpmDirPath string, pkgList []string, err error) {
+//
+// The default mounts and the resolver container are restored and removed even if the context is cancelled.
+func (r *Resolver) Resolve(ctx context.Context, packages *image.Packages, localRPMConfig *image.LocalRPMConfig, outputDir string) (rpmDirPath string, pkgList []string, err error) {
 	zap.L().Info("Resolving package dependencies...")
 
 	revert, err := mount.DisableDefaultMounts(r.overrideMountsPath)
@@ -94,7 +98,7 @@ func (r *Resolver) Resolve(packages *image.Packages, localRPMConfig *image.Local
 		}
 	}()
 
-	if r.baseImageRef, err = r.baseResolverImageBuilder.Build(); err != nil {
+	if r.baseImageRef, err = r.baseResolverImageBuilder.Build(ctx); err != nil {
 		return "", nil, fmt.Errorf("building base resolver image: %w", err)
 	}
 
@@ -102,16 +106,21 @@ func (r *Resolver) Resolve(packages *image.Packages, localRPMConfig *image.Local
 		return "", nil, fmt.Errorf("generating context for the resolver image: %w", err)
 	}
 
-	if err = r.podman.Build(r.generateBuildContextPath(), resolverImageRef); err != nil {
+	if err = r.podman.Build(ctx, r.generateBuildContextPath(), resolverImageRef); err != nil {
 		return "", nil, fmt.Errorf("building resolver image: %w", err)
 	}
 
-	id, err := r.podman.Create(resolverImageRef)
+	id, err := r.podman.Create(ctx, resolverImageRef)
 	if err != nil {
 		return "", nil, fmt.Errorf("run container from resolver image %s: %w", resolverImageRef, err)
 	}
+	defer func() {
+		if removeErr := r.podman.Remove(id); removeErr != nil {
+			zap.S().Warnf("failed to remove resolver container: %s", removeErr)
+		}
+	}()
 
-	err = r.podman.Copy(id, r.generateResolverImgRPMRepoPath(), outputDir)
+	err = r.podman.Copy(ctx, id, r.generateResolverImgRPMRepoPath(), outputDir)
 	if err != nil {
 		return "", nil, fmt.Errorf("copying resolved package cache to %s: %w", outputDir, err)
 	}
@@ -0,0 +1,348 @@
+package sbom
+
+import (
+	"crypto/sha1" //nolint:gosec // SHA-1 checksums are mandatory for files in SPDX 2.3
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/suse-edge/edge-image-builder/pkg/fileio"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
+)
+
+// FileSuffix is appended to the name of the output image to form the name of its SBOM.
+const FileSuffix = ".spdx.json"
+
+const (
+	spdxVersion       = "SPDX-2.3"
+	dataLicense       = "CC0-1.0"
+	documentID        = "SPDXRef-DOCUMENT"
+	imageID           = "SPDXRef-Image"
+	noAssertion       = "NOASSERTION"
+	noArch            = "noarch"
+	namespacePrefix   = "https://github.com/suse-edge/edge-image-builder/spdx"
+	relationDescribes = "DESCRIBES"
+	relationContains  = "CONTAINS"
+	relationGenerated = "GENERATED_FROM"
+)
+
+// Document is an SPDX 2.3 document in its JSON representation.
+type Document struct {
+	SPDXVersion       string         `json:"spdxVersion"`
+	DataLicense       string         `json:"dataLicense"`
+	SPDXID            string         `json:"SPDXID"`
+	Name              string         `json:"name"`
+	DocumentNamespace string         `json:"documentNamespace"`
+	CreationInfo      CreationInfo   `json:"creationInfo"`
+	Packages          []Package      `json:"packages"`
+	Files             []File         `json:"files,omitempty"`
+	Relationships     []Relationship `json:"relationships"`
+}
+
+type CreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type Package struct {
+	SPDXID                string        `json:"SPDXID"`
+	Name                  string        `json:"name"`
+	VersionInfo           string        `json:"versionInfo,omitempty"`
+	DownloadLocation      string        `json:"downloadLocation"`
+	FilesAnalyzed         bool          `json:"filesAnalyzed"`
+	Checksums             []Checksum    `json:"checksums,omitempty"`
+	PrimaryPackagePurpose string        `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs          []ExternalRef `json:"externalRefs,omitempty"`
+	Comment               string        `json:"comment,omitempty"`
+}
+
+type File struct {
+	SPDXID    string     `json:"SPDXID"`
+	FileName  string     `json:"fileName"`
+	Checksums []Checksum `json:"checksums"`
+}
+
+type Checksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type ExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type Relationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var invalidIDCharacters = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// Generate assembles the SBOM of the image built for the given context from the details collected
+// in the build report. It must only be called once the build report has been finished.
+func Generate(ctx *image.Context, buildReport *report.Report) (*Document, error) {
+	builtImage := buildReport.FindImage(ctx.OutputImagePath())
+	if builtImage == nil {
+		return nil, fmt.Errorf("build report does not contain the built image")
+	}
+
+	definition := ctx.ImageDefinition
+	arch := string(definition.Image.Arch)
+
+	doc := &Document{
+		SPDXVersion: spdxVersion,
+		DataLicense: dataLicense,
+		SPDXID:      documentID,
+		Name:        definition.Image.OutputImageName,
+		DocumentNamespace: fmt.Sprintf("%s/%s-%s", namespacePrefix,
+			url.PathEscape(definition.Image.OutputImageName), builtImage.SHA256),
+		CreationInfo: CreationInfo{
+			Created:  time.Now().UTC().Format(time.RFC3339),
+			Creators: []string{fmt.Sprintf("Tool: edge-image-builder-%s", buildReport.EIBVersion)},
+		},
+		Relationships: []Relationship{
+			{SPDXElementID: documentID, RelationshipType: relationDescribes, RelatedSPDXElement: imageID},
+		},
+	}
+
+	doc.addPackage(Package{
+		SPDXID:                imageID,
+		Name:                  definition.Image.OutputImageName,
+		DownloadLocation:      noAssertion,
+		Checksums:             sha256Checksum(builtImage.SHA256),
+		PrimaryPackagePurpose: "OPERATING-SYSTEM",
+	}, "")
+
+	doc.addPackage(baseImagePackage(ctx, buildReport), relationGenerated)
+
+	if k8s := definition.Kubernetes; k8s.Version != "" {
+		distribution := image.KubernetesDistroK3S
+		if strings.Contains(k8s.Version, image.KubernetesDistroRKE2) {
+			distribution = image.KubernetesDistroRKE2
+		}
+
+		doc.addPackage(Package{
+			SPDXID:                "SPDXRef-Kubernetes-" + distribution,
+			Name:                  distribution,
+			VersionInfo:           k8s.Version,
+			DownloadLocation:      noAssertion,
+			PrimaryPackagePurpose: "APPLICATION",
+		}, relationContains)
+	}
+
+	for _, pkg := range buildReport.Packages {
+		// Packages resolved for other architectures are included in the report of multi-architecture builds
+		if pkg.Arch != arch && pkg.Arch != noArch {
+			continue
+		}
+
+		doc.addPackage(rpmPackage(pkg), relationContains)
+	}
+
+	for _, img := range buildReport.ContainerImages {
+		if img.Arch != arch {
+			continue
+		}
+
+		// Manifest digests of other algorithms are not described
+		digest, ok := strings.CutPrefix(img.Digest, "sha256:")
+		if !ok {
+			digest = ""
+		}
+
+		doc.addPackage(Package{
+			SPDXID:                "SPDXRef-ContainerImage-" + img.Name,
+			Name:                  img.Name,
+			DownloadLocation:      noAssertion,
+			Checksums:             sha256Checksum(digest),
+			PrimaryPackagePurpose: "CONTAINER",
+			Comment:               "The checksum is the digest of the image manifest",
+		}, relationContains)
+	}
+
+	for _, chart := range buildReport.HelmCharts {
+		doc.addPackage(Package{
+			SPDXID:                fmt.Sprintf("SPDXRef-HelmChart-%s-%s", chart.Name, chart.Version),
+			Name:                  chart.Name,
+			VersionInfo:           chart.Version,
+			DownloadLocation:      chart.Repository,
+			PrimaryPackagePurpose: "APPLICATION",
+		}, relationContains)
+	}
+
+	for _, artefact := range buildReport.Artefacts {
+		// Artefacts retrieved for other architectures are included in the report of multi-architecture builds
+		if artefact.Arch != "" && artefact.Arch != arch {
+			continue
+		}
+
+		doc.addPackage(Package{
+			SPDXID:                "SPDXRef-Artefact-" + filepath.Base(artefact.Path),
+			Name:                  filepath.Base(artefact.Path),
+			DownloadLocation:      artefact.URL,
+			Checksums:             sha256Checksum(artefact.SHA256),
+			PrimaryPackagePurpose: "FILE",
+		}, relationContains)
+	}
+
+	for _, file := range buildReport.SideLoadedFiles {
+		if err := doc.addSideLoadedFile(ctx, file); err != nil {
+			return nil, fmt.Errorf("describing side-loaded file '%s': %w", file.Path, err)
+		}
+	}
+
+	return doc, nil
+}
+
+// Write stores the document in JSON format at the given path.
+func (d *Document) Write(path string) error {
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling SBOM: %w", err)
+	}
+
+	if err = os.WriteFile(path, append(data, '\n'), fileio.NonExecutablePerms); err != nil {
+		return fmt.Errorf("writing SBOM: %w", err)
+	}
+
+	return nil
+}
+
+// addPackage adds the package to the document and relates it to the output image.
+func (d *Document) addPackage(pkg Package, relationship string) {
+	pkg.SPDXID = d.uniqueID(pkg.SPDXID)
+	d.Packages = append(d.Packages, pkg)
+
+	if relationship != "" {
+		d.Relationships = append(d.Relationships, Relationship{
+			SPDXElementID:      imageID,
+			RelationshipType:   relationship,
+			RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+}
+
+func (d *Document) addSideLoadedFile(ctx *image.Context, file report.File) error {
+	// SPDX requires a SHA-1 checksum for every file, which the build report does not record
+	sha1Sum, err := fileSHA1(filepath.Join(ctx.ImageConfigDir, file.Path))
+	if err != nil {
+		return err
+	}
+
+	id := d.uniqueID("SPDXRef-File-" + file.Path)
+
+	d.Files = append(d.Files, File{
+		SPDXID:   id,
+		FileName: "./" + filepath.ToSlash(file.Path),
+		Checksums: []Checksum{
+			{Algorithm: "SHA1", ChecksumValue: sha1Sum},
+			{Algorithm: "SHA256", ChecksumValue: file.SHA256},
+		},
+	})
+
+	d.Relationships = append(d.Relationships, Relationship{
+		SPDXElementID:      imageID,
+		RelationshipType:   relationContains,
+		RelatedSPDXElement: id,
+	})
+
+	return nil
+}
+
+// uniqueID sanitises the given identifier and makes sure it is not yet used within the document.
+func (d *Document) uniqueID(id string) string {
+	id = invalidIDCharacters.ReplaceAllString(id, "-")
+
+	used := func(candidate string) bool {
+		for _, p := range d.Packages {
+			if p.SPDXID == candidate {
+				return true
+			}
+		}
+		for _, f := range d.Files {
+			if f.SPDXID == candidate {
+				return true
+			}
+		}
+		return false
+	}
+
+	candidate := id
+	for i := 2; used(candidate); i++ {
+		candidate = fmt.Sprintf("%s-%d", id, i)
+	}
+
+	return candidate
+}
+
+func baseImagePackage(ctx *image.Context, buildReport *report.Report) Package {
+	pkg := Package{
+		SPDXID:                "SPDXRef-BaseImage",
+		Name:                  ctx.ImageDefinition.Image.BaseImage,
+		DownloadLocation:      noAssertion,
+		PrimaryPackagePurpose: "OPERATING-SYSTEM",
+	}
+
+	if baseImage := buildReport.FindBaseImage(ctx.BaseImagePath()); baseImage != nil {
+		pkg.Checksums = sha256Checksum(baseImage.SHA256)
+	}
+
+	return pkg
+}
+
+func rpmPackage(pkg report.Package) Package {
+	p := Package{
+		SPDXID:                fmt.Sprintf("SPDXRef-RPM-%s-%s-%s", pkg.Name, pkg.Version, pkg.Arch),
+		Name:                  pkg.Name,
+		VersionInfo:           pkg.Version,
+		DownloadLocation:      noAssertion,
+		PrimaryPackagePurpose: "INSTALL",
+		ExternalRefs: []ExternalRef{
+			{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  fmt.Sprintf("pkg:rpm/%s@%s?arch=%s", url.PathEscape(pkg.Name), url.PathEscape(pkg.Version), url.QueryEscape(pkg.Arch)),
+			},
+		},
+	}
+
+	if pkg.Repository != "" {
+		p.Comment = fmt.Sprintf("Resolved from repository '%s'", pkg.Repository)
+	}
+
+	return p
+}
+
+func sha256Checksum(digest string) []Checksum {
+	if digest == "" {
+		return nil
+	}
+
+	return []Checksum{{Algorithm: "SHA256", ChecksumValue: digest}}
+}
+
+func fileSHA1(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha1.New() //nolint:gosec // SHA-1 checksums are mandatory for files in SPDX 2.3
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("reading file: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,154 @@
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/suse-edge/edge-image-builder/pkg/image"
+	"github.com/suse-edge/edge-image-builder/pkg/report"
+)
+
+const (
+	testContents       = "some-contents"
+	testContentsSHA256 = "6e32ea34db1b3755d7dec972eb72c705338f0dd8e0be881d966963438fb2e800"
+)
+
+func writeTestFile(t *testing.T, path string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(testContents), 0o600))
+}
+
+func setupContext(t *testing.T) (*image.Context, *report.Report) {
+	configDir := t.TempDir()
+
+	writeTestFile(t, filepath.Join(configDir, "base-images", "SL-Micro.x86_64-6.0-Base-SelfInstall-GM2.install.iso"))
+	writeTestFile(t, filepath.Join(configDir, "eib-image.iso"))
+	writeTestFile(t, filepath.Join(configDir, "custom", "files", "motd"))
+	writeTestFile(t, filepath.Join(configDir, "artefacts", "rke2.linux-amd64.tar.gz"))
+	writeTestFile(t, filepath.Join(configDir, "artefacts", "rke2.linux-arm64.tar.gz"))
+
+	ctx := &image.Context{
+		ImageConfigDir: configDir,
+		ImageDefinition: &image.Definition{
+			Image: image.Image{
+				ImageType:       image.TypeISO,
+				Arch:            image.ArchTypeX86,
+				BaseImage:       "SL-Micro.x86_64-6.0-Base-SelfInstall-GM2.install.iso",
+				OutputImageName: "eib-image.iso",
+			},
+			Kubernetes: image.Kubernetes{
+				Version: "v1.30.3+rke2r1",
+			},
+		},
+	}
+
+	r := report.New("v1.2.0")
+	r.AddPackage("vim", "9.1.0836-1.1", "x86_64", "addrepo0")
+	r.AddPackage("vim", "9.1.0836-1.1", "aarch64", "addrepo0")
+	require.NoError(t, r.AddContainerImage("nginx:1.25", "x86_64", "sha256:"+testContentsSHA256, filepath.Join(configDir, "eib-image.iso")))
+	require.NoError(t, r.AddContainerImage("nginx:1.25", "aarch64", "sha256:"+testContentsSHA256, filepath.Join(configDir, "eib-image.iso")))
+	r.AddHelmChart("metallb", "https://suse-edge.github.io/charts", "0.14.3")
+	r.RecordDownload("https://example.com/manifest.yaml", filepath.Join(configDir, "custom", "files", "motd"), false)
+	r.ForArch("x86_64").RecordDownload("https://example.com/rke2.linux-amd64.tar.gz", filepath.Join(configDir, "artefacts", "rke2.linux-amd64.tar.gz"), false)
+	r.ForArch("aarch64").RecordDownload("https://example.com/rke2.linux-arm64.tar.gz", filepath.Join(configDir, "artefacts", "rke2.linux-arm64.tar.gz"), false)
+	require.NoError(t, r.AddSideLoadedFile("custom/files/motd", filepath.Join(configDir, "custom", "files", "motd")))
+	require.NoError(t, r.AddBaseImage(filepath.Join(configDir, "base-images", "SL-Micro.x86_64-6.0-Base-SelfInstall-GM2.install.iso")))
+	require.NoError(t, r.AddImage(filepath.Join(configDir, "eib-image.iso"), image.TypeISO, string(image.ArchTypeX86)))
+	r.Finish(nil)
+
+	return ctx, r
+}
+
+func TestGenerate(t *testing.T) {
+	ctx, r := setupContext(t)
+
+	doc, err := Generate(ctx, r)
+	require.NoError(t, err)
+
+	assert.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	assert.Equal(t, "eib-image.iso", doc.Name)
+	assert.Equal(t, "https://github.com/suse-edge/edge-image-builder/spdx/eib-image.iso-"+testContentsSHA256, doc.DocumentNamespace)
+	assert.Equal(t, []string{"Tool: edge-image-builder-v1.2.0"}, doc.CreationInfo.Creators)
+
+	packages := map[string]Package{}
+	for _, p := range doc.Packages {
+		packages[p.SPDXID] = p
+	}
+
+	require.Len(t, packages, 8)
+
+	assert.Equal(t, "OPERATING-SYSTEM", packages["SPDXRef-Image"].PrimaryPackagePurpose)
+	assert.Equal(t, "SL-Micro.x86_64-6.0-Base-SelfInstall-GM2.install.iso", packages["SPDXRef-BaseImage"].Name)
+	assert.Equal(t, testContentsSHA256, packages["SPDXRef-BaseImage"].Checksums[0].ChecksumValue)
+	assert.Equal(t, "v1.30.3+rke2r1", packages["SPDXRef-Kubernetes-rke2"].VersionInfo)
+
+	rpm := packages["SPDXRef-RPM-vim-9.1.0836-1.1-x86-64"]
+	assert.Equal(t, "vim", rpm.Name)
+	assert.Equal(t, "9.1.0836-1.1", rpm.VersionInfo)
+	assert.Equal(t, "pkg:rpm/vim@9.1.0836-1.1?arch=x86_64", rpm.ExternalRefs[0].ReferenceLocator)
+	assert.Equal(t, "Resolved from repository 'addrepo0'", rpm.Comment)
+
+	assert.Equal(t, "nginx:1.25", packages["SPDXRef-ContainerImage-nginx-1.25"].Name)
+	assert.Equal(t, []Checksum{{Algorithm: "SHA256", ChecksumValue: testContentsSHA256}},
+		packages["SPDXRef-ContainerImage-nginx-1.25"].Checksums)
+	assert.Equal(t, "https://suse-edge.github.io/charts", packages["SPDXRef-HelmChart-metallb-0.14.3"].DownloadLocation)
+	assert.Equal(t, "https://example.com/manifest.yaml", packages["SPDXRef-Artefact-motd"].DownloadLocation)
+	assert.Contains(t, packages, "SPDXRef-Artefact-rke2.linux-amd64.tar.gz")
+	assert.NotContains(t, packages, "SPDXRef-Artefact-rke2.linux-arm64.tar.gz")
+
+	require.Len(t, doc.Files, 1)
+	assert.Equal(t, File{
+		SPDXID:   "SPDXRef-File-custom-files-motd",
+		FileName: "./custom/files/motd",
+		Checksums: []Checksum{
+			{Algorithm: "SHA1", ChecksumValue: "21202296bf50267250155e46d3b9eb3e4c1acb7e"},
+			{Algorithm: "SHA256", ChecksumValue: testContentsSHA256},
+		},
+	}, doc.Files[0])
+
+	assert.Contains(t, doc.Relationships, Relationship{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Image"})
+	assert.Contains(t, doc.Relationships, Relationship{SPDXElementID: "SPDXRef-Image", RelationshipType: "GENERATED_FROM", RelatedSPDXElement: "SPDXRef-BaseImage"})
+	assert.Contains(t, doc.Relationships, Relationship{SPDXElementID: "SPDXRef-Image", RelationshipType: "CONTAINS", RelatedSPDXElement: "SPDXRef-File-custom-files-motd"})
+	assert.Len(t, doc.Relationships, 9)
+}
+
+func TestGenerate_MissingImage(t *testing.T) {
+	ctx, _ := setupContext(t)
+
+	_, err := Generate(ctx, report.New("v1.2.0"))
+	assert.EqualError(t, err, "build report does not contain the built image")
+}
+
+func TestWrite(t *testing.T) {
+	ctx, r := setupContext(t)
+
+	doc, err := Generate(ctx, r)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "eib-image.iso"+FileSuffix)
+	require.NoError(t, doc.Write(path))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var written map[string]any
+	require.NoError(t, json.Unmarshal(b, &written))
+
+	assert.Equal(t, "SPDX-2.3", written["spdxVersion"])
+	assert.Equal(t, "SPDXRef-DOCUMENT", written["SPDXID"])
+	assert.Len(t, written["packages"], 8)
+}
+
+func TestUniqueID(t *testing.T) {
+	doc := &Document{}
+
+	doc.addPackage(Package{SPDXID: "SPDXRef-Artefact-rke2_installer.sh"}, "")
+	doc.addPackage(Package{SPDXID: "SPDXRef-Artefact-rke2_installer.sh"}, "")
+
+	assert.Equal(t, "SPDXRef-Artefact-rke2-installer.sh", doc.Packages[0].SPDXID)
+	assert.Equal(t, "SPDXRef-Artefact-rke2-installer.sh-2", doc.Packages[1].SPDXID)
+}
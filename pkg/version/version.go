@@ -7,11 +7,16 @@ import (
 )
 
 const (
-	version10 = "1.0"
-	version11 = "1.1"
+	SchemaVersion10 = "1.0"
+	SchemaVersion11 = "1.1"
+	SchemaVersion12 = "1.2"
+
+	// LatestSchemaVersion is the schema version definitions are upgraded to.
+	LatestSchemaVersion = SchemaVersion12
 )
 
-var SupportedSchemaVersions = []string{version10, version11}
+// SupportedSchemaVersions lists the schema versions in ascending order.
+var SupportedSchemaVersions = []string{SchemaVersion10, SchemaVersion11, SchemaVersion12}
 
 var version string
 